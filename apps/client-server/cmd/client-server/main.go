@@ -12,13 +12,17 @@ import (
 	"log"
 	"mime"
 	"net/http"
+	"os"
+	"path/filepath"
 	"regexp"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/stonebraker/lap/apps/client-server/internal/httpx"
 	"github.com/stonebraker/lap/apps/demo-utils/artifacts"
 	"github.com/stonebraker/lap/sdks/go/pkg/lap/sanitize"
+	"github.com/stonebraker/lap/sdks/go/pkg/lap/verify"
 
 	"github.com/go-chi/chi/v5"
 )
@@ -35,6 +39,7 @@ type VerificationResult struct {
 	Failure              *FailureDetails        `json:"failure"`
 	Context              *VerificationContext   `json:"context"`
 	Error                string                 `json:"error,omitempty"`
+	Identity             string                 `json:"-"` // "verified as Alice (your contact)", from the address book
 }
 
 type FailureDetails struct {
@@ -48,6 +53,8 @@ type VerificationContext struct {
 	ResourceAttestationURL  string `json:"resource_attestation_url"`
 	NamespaceAttestationURL string `json:"namespace_attestation_url"`
 	VerifiedAt             int64  `json:"verified_at"`
+	FreshUntil             int64  `json:"fresh_until,omitempty"`
+	PublisherClaim         string `json:"publisher_claim,omitempty"`
 }
 
 // ProcessedFragment holds the fragment data with decoded canonical content
@@ -74,11 +81,25 @@ type ProfileData struct {
 	Error       string
 }
 
+// addressBook names publisher keys in verification results. It starts from the reader's contacts
+// and learns names from publishers' verified profile fragments, which are kept in memory only.
+var (
+	addressBook   = &verify.AddressBook{}
+	addressBookMu sync.Mutex
+)
+
 func main() {
 	addr := flag.String("addr", ":8081", "address to listen on")
 	dir := flag.String("dir", "apps/client-server/static", "directory to serve")
+	contacts := flag.String("contacts", defaultContactsPath(), "address book naming publishers (as managed by verifier contacts)")
 	flag.Parse()
 
+	book, err := verify.LoadAddressBook(*contacts)
+	if err != nil {
+		log.Fatal(err)
+	}
+	addressBook = book
+
 	// Serve .htmx files as HTML
 	_ = mime.AddExtensionType(".htmx", "text/html; charset=utf-8")
 
@@ -179,6 +200,9 @@ func serverSideFetchHandler(w http.ResponseWriter, r *http.Request) {
 		profileData = nil
 	}
 	
+	// Name the publisher, now that its profile may have added it to the address book
+	describePublisher(verificationResult)
+	
 	// Render the page with the processed fragment and verification result
 	renderFragmentPageWithVerificationAndNamespaceAttestation(w, processedFragment, fragmentURL, postID, verificationResult, resourceAttestation, resourceAttestationURL, namespaceAttestation, namespaceAttestationURL, profileData)
 }
@@ -449,9 +473,57 @@ func fetchProfileFragment(namespaceURL string) *ProfileData {
 		}
 	}
 	
+	learnProfileContact(string(profileHTML), profileURL)
 	return parseProfileFromHTML(string(profileHTML))
 }
 
+// learnProfileContact verifies a profile fragment and, if it verifies, adds the name it declares to
+// the address book. Names the reader entered or imported are never replaced by a profile.
+func learnProfileContact(profileHTML, profileURL string) {
+	result := verifyFragment(profileHTML, profileURL)
+	if result.Error != "" || !result.Verified || result.Context == nil {
+		return
+	}
+	// Only the verified canonical content is trusted for the name, never the preview
+	canonical := processFragment(profileHTML, result).CanonicalRaw
+	contact, err := verify.ProfileContact(sdkResult(result), profileURL, []byte(canonical))
+	if err != nil {
+		log.Printf("Profile %s: %v", profileURL, err)
+		return
+	}
+	addressBookMu.Lock()
+	defer addressBookMu.Unlock()
+	_ = addressBook.Add(contact)
+}
+
+// describePublisher sets the result's identity line from the address book
+func describePublisher(result *VerificationResult) {
+	if result.Error != "" || !result.Verified {
+		return
+	}
+	addressBookMu.Lock()
+	defer addressBookMu.Unlock()
+	result.Identity = addressBook.Describe(sdkResult(result))
+}
+
+// sdkResult converts the verifier service's answer to the SDK result the address book reads
+func sdkResult(result *VerificationResult) verify.VerificationResult {
+	converted := verify.VerificationResult{Verified: result.Verified, ResourcePresence: result.ResourcePresence}
+	if result.Context != nil {
+		converted.Context = &verify.VerificationContext{PublisherClaim: result.Context.PublisherClaim}
+	}
+	return converted
+}
+
+// defaultContactsPath returns the address book location shared with the verifier CLI
+func defaultContactsPath() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "contacts.json"
+	}
+	return filepath.Join(dir, "lap", "contacts.json")
+}
+
 // extractAttestationURLsFromHTML extracts resource and namespace attestation URLs from raw HTML fragment
 func extractAttestationURLsFromHTML(htmlContent string) (string, string) {
 	var resourceURL, namespaceURL string
@@ -659,6 +731,8 @@ func westleyPageHandler(w http.ResponseWriter, r *http.Request) {
 		profileData = nil
 	}
 	
+	describePublisher(verificationResult)
+	
 	// Render Westley's page with Alice's post integrated
 	renderWestleyPageWithAlicePost(w, processedFragment, verificationResult, profileData)
 }
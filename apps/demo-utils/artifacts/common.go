@@ -9,10 +9,14 @@ import (
 	"strings"
 )
 
-// StoredKey represents a stored key pair in JSON format
+// StoredKey represents a stored key pair in JSON format.
+// Alg is empty for secp256k1 (bip340) keys, which keep their public key in PubKeyXOnly;
+// keys for other signature algorithms use PubKeyHex.
 type StoredKey struct {
+	Alg           string `json:"alg,omitempty"`
 	PrivKeyHex    string `json:"privkey_hex"`
-	PubKeyXOnly   string `json:"pubkey_xonly_hex"`
+	PubKeyXOnly   string `json:"pubkey_xonly_hex,omitempty"`
+	PubKeyHex     string `json:"pubkey_hex,omitempty"`
 	CreatedAtUnix int64  `json:"created_at"`
 }
 
@@ -0,0 +1,70 @@
+// Package artifacts provides demo utilities for LAP artifact management.
+package artifacts
+
+import (
+	"fmt"
+
+	"github.com/stonebraker/lap/sdks/go/pkg/lap/canonical"
+	"github.com/stonebraker/lap/sdks/go/pkg/lap/crypto"
+	"github.com/stonebraker/lap/sdks/go/pkg/lap/wire"
+)
+
+// CoSignResourceAttestation adds a co-publisher to the RA at raPath, signing its fragment URL and hash
+// with the co-publisher's namespace key: privHex when given, otherwise the key stored in keysDir by
+// CreateNamespaceAttestation for alg. namespaceAttestationURL is the co-publisher's own NA, which must
+// use the same key. Signing again replaces the co-publisher's earlier entry. The RA is rewritten in place.
+func CoSignResourceAttestation(raPath, namespaceAttestationURL, privHex, keysDir, alg string) (wire.CoPublisher, error) {
+	ra, err := readResourceAttestation(raPath)
+	if err != nil {
+		return wire.CoPublisher{}, err
+	}
+	signer, err := namespaceSigner(privHex, keysDir, alg)
+	if err != nil {
+		return wire.CoPublisher{}, err
+	}
+	if signer.PublicKeyHex() == ra.PublisherClaim {
+		return wire.CoPublisher{}, fmt.Errorf("%s is the resource's publisher, not a co-publisher", signer.PublicKeyHex())
+	}
+
+	payloadBytes, err := canonical.MarshalCoPublisherPayloadCanonical(ra.CoPublisherPayload())
+	if err != nil {
+		return wire.CoPublisher{}, fmt.Errorf("canonical marshal: %w", err)
+	}
+	sigHex, err := signer.SignHex(crypto.HashSHA256(payloadBytes))
+	if err != nil {
+		return wire.CoPublisher{}, fmt.Errorf("sign: %w", err)
+	}
+	coPublisher := wire.CoPublisher{
+		PublisherClaim:          signer.PublicKeyHex(),
+		NamespaceAttestationURL: namespaceAttestationURL,
+		Sig:                     sigHex,
+	}
+
+	replaced := false
+	for i, cp := range ra.CoPublishers {
+		if cp.PublisherClaim == coPublisher.PublisherClaim {
+			ra.CoPublishers[i] = coPublisher
+			replaced = true
+		}
+	}
+	if !replaced {
+		ra.CoPublishers = append(ra.CoPublishers, coPublisher)
+	}
+	return coPublisher, WriteJSON0600(raPath, ra)
+}
+
+// namespaceSigner returns the signer for privHex when given, otherwise the namespace key stored in keysDir for alg
+func namespaceSigner(privHex, keysDir, alg string) (crypto.Signer, error) {
+	alg = crypto.NormalizeSignatureAlgorithm(alg)
+	if privHex != "" {
+		signer, err := crypto.ParseSignerHex(alg, privHex)
+		if err != nil {
+			return nil, fmt.Errorf("invalid privkey: %w", err)
+		}
+		return signer, nil
+	}
+	if signer := loadStoredSigner(namespaceKeyPath(keysDir, alg), alg); signer != nil {
+		return signer, nil
+	}
+	return nil, fmt.Errorf("no %s namespace key in %s; create the namespace attestation first or pass a private key", alg, keysDir)
+}
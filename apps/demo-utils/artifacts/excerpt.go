@@ -0,0 +1,83 @@
+// Package artifacts provides demo utilities for LAP artifact management.
+package artifacts
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/stonebraker/lap/sdks/go/pkg/lap/canonical"
+	"github.com/stonebraker/lap/sdks/go/pkg/lap/crypto"
+	"github.com/stonebraker/lap/sdks/go/pkg/lap/wire"
+)
+
+// CreateExcerpt creates an HTML excerpt quoting one top-level block of another publisher's resource.
+// inPath holds the quoted resource's canonical content, e.g. decoded from its fragment's data URL;
+// canonProfile must match the profile of its Resource Attestation, whose block_root the excerpt's
+// inclusion proof leads to. blockIndex selects the block, counted from 0.
+func CreateExcerpt(inPath, fragmentURL, publisherClaim, resourceAttestationURL, namespaceAttestationURL, canonProfile string, blockIndex int, outPath string) error {
+	raw, err := os.ReadFile(inPath)
+	if err != nil {
+		return fmt.Errorf("read %s: %w", inPath, err)
+	}
+	content, err := canonical.CanonicalizeContent(canonProfile, raw)
+	if err != nil {
+		return fmt.Errorf("canonicalize: %w", err)
+	}
+	if u, err := url.Parse(fragmentURL); err != nil || u.Scheme == "" || u.Host == "" {
+		return fmt.Errorf("invalid url (expect absolute): %s", fragmentURL)
+	}
+
+	blocks := canonical.SplitBlocks(content)
+	if blockIndex < 0 || blockIndex >= len(blocks) {
+		return fmt.Errorf("block %d out of range: content has %d blocks", blockIndex, len(blocks))
+	}
+	leaves := make([][32]byte, len(blocks))
+	for i, block := range blocks {
+		leaves[i] = crypto.MerkleLeafHash(block)
+	}
+	proof, err := crypto.MerkleInclusionProof(leaves, blockIndex)
+	if err != nil {
+		return err
+	}
+	hexProof := make([]string, len(proof))
+	for i, h := range proof {
+		hexProof[i] = hex.EncodeToString(h[:])
+	}
+
+	block := blocks[blockIndex]
+	excerpt := "" +
+		"<blockquote\n" +
+		"  data-la-spec=\"v0.2\"\n" +
+		fmt.Sprintf("  data-la-excerpt-of=\"%s\"\n", fragmentURL) +
+		fmt.Sprintf("  cite=\"%s\"\n", fragmentURL) +
+		">\n" +
+		"  <section class=\"la-preview\">\n" +
+		indentContent(string(block), "    ") + "\n" +
+		"  </section>\n" +
+		"  <link\n" +
+		"    rel=\"canonical\"\n" +
+		fmt.Sprintf("    type=\"%s\"\n", wire.DefaultContentType) +
+		fmt.Sprintf("    data-la-publisher-claim=\"%s\"\n", publisherClaim) +
+		fmt.Sprintf("    data-la-resource-attestation-url=\"%s\"\n", resourceAttestationURL) +
+		fmt.Sprintf("    data-la-namespace-attestation-url=\"%s\"\n", namespaceAttestationURL) +
+		fmt.Sprintf("    data-la-block-index=\"%d\"\n", blockIndex) +
+		fmt.Sprintf("    data-la-block-count=\"%d\"\n", len(blocks)) +
+		fmt.Sprintf("    data-la-block-proof=\"%s\"\n", strings.Join(hexProof, ",")) +
+		fmt.Sprintf("    href=\"data:%s;base64,%s\"\n", wire.DefaultContentType, base64.StdEncoding.EncodeToString(block)) +
+		"    hidden\n" +
+		"  />\n" +
+		"</blockquote>"
+
+	if outPath == "" {
+		outPath = filepath.Join(filepath.Dir(inPath), "excerpt.htmx")
+	}
+	if err := os.MkdirAll(filepath.Dir(outPath), 0755); err != nil {
+		return fmt.Errorf("mkdir: %w", err)
+	}
+	return os.WriteFile(outPath, []byte(excerpt), 0644)
+}
@@ -3,21 +3,52 @@ package artifacts
 
 import (
 	"encoding/base64"
+	"encoding/json"
 	"fmt"
 	"net/url"
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
+
+	"github.com/stonebraker/lap/sdks/go/pkg/lap/canonical"
+	"github.com/stonebraker/lap/sdks/go/pkg/lap/crypto"
+	"github.com/stonebraker/lap/sdks/go/pkg/lap/wire"
 )
 
+// FragmentOptions holds the optional settings of a new fragment. The zero value inlines raw HTML content.
+type FragmentOptions struct {
+	CanonProfile       string   // Must match the profile used for the Resource Attestation; empty selects "raw"
+	ContentType        string   // Media type of the content; empty selects text/html
+	ContentURL         string   // Same-origin URL serving the content bytes, referenced instead of inlining them
+	StapleRAPath       string   // Resource Attestation file to embed; requires StapleNAPath
+	StapleNAPath       string   // Namespace Attestation file to embed; requires StapleRAPath
+	CoPublishersRAPath string   // Co-signed Resource Attestation whose co-publishers the fragment names
+	Recipients         []string // X-only public keys the content is sealed to
+}
+
 // CreateFragment creates a v0.2 HTML fragment from the given content
 func CreateFragment(inPath, resURL, base, publisherClaim, resourceAttestationURL, namespaceAttestationURL, outPath string) error {
+	return CreateFragmentWithOptions(inPath, resURL, base, publisherClaim, resourceAttestationURL, namespaceAttestationURL, outPath, FragmentOptions{})
+}
+
+// CreateFragmentWithOptions creates a v0.2 HTML fragment from the given content.
+// With stapled attestations, the exact bytes of those files are embedded in the fragment so verifiers
+// can skip the live fetches. With recipients, the content is sealed to them and only a placeholder
+// preview is shown.
+func CreateFragmentWithOptions(inPath, resURL, base, publisherClaim, resourceAttestationURL, namespaceAttestationURL, outPath string, opts FragmentOptions) error {
 	// Read input file
-	body, err := os.ReadFile(inPath)
+	raw, err := os.ReadFile(inPath)
 	if err != nil {
 		return fmt.Errorf("read %s: %w", inPath, err)
 	}
 
+	// Embed the canonicalized bytes so the fragment carries exactly what was hashed
+	body, err := canonical.CanonicalizeContent(opts.CanonProfile, raw)
+	if err != nil {
+		return fmt.Errorf("canonicalize: %w", err)
+	}
+
 	// Build payload URL with optional base override
 	var u url.URL
 	if base != "" {
@@ -52,12 +83,63 @@ func CreateFragment(inPath, resURL, base, publisherClaim, resourceAttestationURL
 	u.Host = hu
 	payloadURL := u.String()
 
+	// Read attestations to staple, if requested
+	var stapled string
+	if opts.StapleRAPath != "" || opts.StapleNAPath != "" {
+		if opts.StapleRAPath == "" || opts.StapleNAPath == "" {
+			return fmt.Errorf("stapling requires both a resource and a namespace attestation")
+		}
+		stapled, err = stapledAttestationsHTML(opts.StapleRAPath, opts.StapleNAPath, time.Now().Unix())
+		if err != nil {
+			return err
+		}
+	}
+
+	// Name the co-publishers of a co-authored resource, in RA order
+	var coPublishers string
+	if opts.CoPublishersRAPath != "" {
+		ra, err := readResourceAttestation(opts.CoPublishersRAPath)
+		if err != nil {
+			return fmt.Errorf("co-publishers: %w", err)
+		}
+		if len(ra.CoPublishers) == 0 {
+			return fmt.Errorf("co-publishers: %s lists none", opts.CoPublishersRAPath)
+		}
+		coPublishers = fmt.Sprintf("    %s=\"%s\"\n", wire.CoPublishersAttribute, wire.FormatCoPublishers(wire.ListedCoPublishers(ra)))
+	}
+
 	// Build v0.2 fragment HTML structure
-	// Base64 of the exact canonical body bytes
-	b64 := base64.StdEncoding.EncodeToString(body)
+	contentType := opts.ContentType
+	if contentType == "" {
+		contentType = wire.DefaultContentType
+	}
+	// The href carries base64 of the exact canonical body bytes, or points at them
+	href := opts.ContentURL
+	if href == "" {
+		href = fmt.Sprintf("data:%s;base64,%s", contentType, base64.StdEncoding.EncodeToString(body))
+	}
+
+	preview := previewHTML(contentType, href, body)
+
+	// Subscriber-only content is sealed to the recipients; the RA still hashes the plaintext
+	if len(opts.Recipients) > 0 {
+		if opts.ContentURL != "" {
+			return fmt.Errorf("encrypted content must be inlined, not referenced by URL")
+		}
+		sealed, err := crypto.SealContent(body, opts.Recipients)
+		if err != nil {
+			return fmt.Errorf("encrypt: %w", err)
+		}
+		sealedJSON, err := json.Marshal(sealed)
+		if err != nil {
+			return fmt.Errorf("encrypt: %w", err)
+		}
+		href = fmt.Sprintf("data:%s;base64,%s", wire.EncryptedContentType, base64.StdEncoding.EncodeToString(sealedJSON))
+		preview = "<p class=\"la-subscribers-only\">This content is for subscribers only.</p>"
+	}
 
-	// Indent the content body to match the fragment structure
-	indentedBody := indentContent(string(body), "    ")
+	// Indent the preview to match the fragment structure
+	indentedBody := indentContent(preview, "    ")
 
 	article := "" +
 		"<article\n" +
@@ -69,13 +151,15 @@ func CreateFragment(inPath, resURL, base, publisherClaim, resourceAttestationURL
 		"  </section>\n" +
 		"  <link\n" +
 		"    rel=\"canonical\"\n" +
-		"    type=\"text/html\"\n" +
+		fmt.Sprintf("    type=\"%s\"\n", contentType) +
 		fmt.Sprintf("    data-la-publisher-claim=\"%s\"\n", publisherClaim) +
 		fmt.Sprintf("    data-la-resource-attestation-url=\"%s\"\n", resourceAttestationURL) +
 		fmt.Sprintf("    data-la-namespace-attestation-url=\"%s\"\n", namespaceAttestationURL) +
-		fmt.Sprintf("    href=\"data:text/html;base64,%s\"\n", b64) +
+		coPublishers +
+		fmt.Sprintf("    href=\"%s\"\n", href) +
 		"    hidden\n" +
 		"  />\n" +
+		stapled +
 		"</article>"
 
 	// Determine output path
@@ -91,6 +175,40 @@ func CreateFragment(inPath, resURL, base, publisherClaim, resourceAttestationURL
 	return os.WriteFile(outPath, []byte(article), 0644)
 }
 
+// previewHTML returns the preview section body: HTML content itself, an image, or a link to other media
+func previewHTML(contentType, src string, body []byte) string {
+	switch {
+	case strings.HasPrefix(contentType, wire.DefaultContentType):
+		return string(body)
+	case strings.HasPrefix(contentType, "image/"):
+		return fmt.Sprintf("<img src=\"%s\" alt=\"\" />", src)
+	default:
+		return fmt.Sprintf("<a href=\"%s\">%s (%d bytes)</a>", src, contentType, len(body))
+	}
+}
+
+// stapledAttestationsHTML returns <script type="application/lap+json"> elements holding the exact
+// bytes of the RA and NA files, marked with the staple time
+func stapledAttestationsHTML(raPath, naPath string, stapledAt int64) (string, error) {
+	var out strings.Builder
+	for _, staple := range []struct{ kind, path string }{
+		{"resource-attestation", raPath},
+		{"namespace-attestation", naPath},
+	} {
+		data, err := os.ReadFile(staple.path)
+		if err != nil {
+			return "", fmt.Errorf("read %s: %w", staple.path, err)
+		}
+		// The JSON is embedded verbatim, so it must not be able to close the script element
+		if strings.Contains(strings.ToLower(string(data)), "</script") {
+			return "", fmt.Errorf("%s cannot be stapled: contains </script", staple.path)
+		}
+		fmt.Fprintf(&out, "  <script type=\"application/lap+json\" data-la-stapled=\"%s\" data-la-stapled-at=\"%d\">%s</script>\n",
+			staple.kind, stapledAt, data)
+	}
+	return out.String(), nil
+}
+
 // UpdateHostFile updates a host HTML file with a new fragment and formats it
 func UpdateHostFile(hostPath, fragmentURL, fragmentHTML string) error {
 	hostBytes, err := os.ReadFile(hostPath)
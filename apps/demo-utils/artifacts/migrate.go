@@ -0,0 +1,297 @@
+// Package artifacts provides demo utilities for LAP artifact management.
+package artifacts
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"mime"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/stonebraker/lap/sdks/go/pkg/lap/canonical"
+	"github.com/stonebraker/lap/sdks/go/pkg/lap/crypto"
+	"github.com/stonebraker/lap/sdks/go/pkg/lap/wire"
+)
+
+// MigrationReport lists what a migration converted and what it left alone, with the reason
+type MigrationReport struct {
+	From       string           `json:"from"`
+	Root       string           `json:"root"`
+	Namespaces []MigratedFile   `json:"namespaces"`
+	Resources  []MigratedFile   `json:"resources"`
+	Skipped    []MigrationIssue `json:"skipped"`
+}
+
+// MigratedFile is an artifact re-emitted in the v0.2 format
+type MigratedFile struct {
+	URL  string `json:"url"`
+	Path string `json:"path"`
+}
+
+// MigrationIssue is a v0.1 artifact that could not be converted
+type MigrationIssue struct {
+	Path   string `json:"path"`
+	URL    string `json:"url,omitempty"`
+	Reason string `json:"reason"`
+}
+
+// migratedNamespace is a v0.2 NA that resources are migrated under, written in place of a v0.1 one
+// or left by an earlier run
+type migratedNamespace struct {
+	namespace    string // v0.2 namespace URL, ending in "/"
+	url          string // Where the NA is served
+	dir          string // Directory holding the NA, under root
+	publisherKey string
+}
+
+// MigrateV01 converts the v0.1 artifacts under root to v0.2. Each v0.1 Namespace Attestation is
+// re-issued for the namespace it is served in, signed with the same publisher key, which must be
+// given as privHex or stored in keysDir. The canonical bytes of each v0.1 fragment are recovered
+// from its data URL and checked against its signed stapled attestation; they are then written to
+// content.htmx next to a new _la_resource.json and index.htmx. Replaced v0.1 attestations are kept
+// with a .bak suffix. Artifacts that cannot be converted are listed in the report's Skipped. v0.2 NAs
+// already in the tree are kept and cover resources too, so running the migration again is safe.
+func MigrateV01(root, keysDir, privHex string) (MigrationReport, error) {
+	report := MigrationReport{From: wire.SpecV01, Root: root}
+
+	var naPaths, pagePaths []string
+	err := filepath.WalkDir(root, func(p string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		switch ext := filepath.Ext(p); {
+		case d.Name() == "_la_namespace.json":
+			naPaths = append(naPaths, p)
+		case ext == ".html" || ext == ".htmx":
+			pagePaths = append(pagePaths, p)
+		}
+		return nil
+	})
+	if err != nil {
+		return report, fmt.Errorf("walk %s: %w", root, err)
+	}
+
+	// Namespaces first: every resource is re-attested under the NA covering it
+	var namespaces []migratedNamespace
+	for _, naPath := range naPaths {
+		ns, migrated, issue := migrateNamespaceV01(naPath, keysDir, privHex)
+		if issue != nil {
+			report.Skipped = append(report.Skipped, *issue)
+			continue
+		}
+		namespaces = append(namespaces, *ns)
+		if migrated {
+			report.Namespaces = append(report.Namespaces, MigratedFile{URL: ns.url, Path: naPath})
+		}
+	}
+	// Longest namespace first, so the nearest NA covers each resource
+	sort.Slice(namespaces, func(i, j int) bool { return len(namespaces[i].namespace) > len(namespaces[j].namespace) })
+
+	seen := map[string]bool{}
+	for _, pagePath := range pagePaths {
+		data, err := os.ReadFile(pagePath)
+		if err != nil {
+			report.Skipped = append(report.Skipped, MigrationIssue{Path: pagePath, Reason: err.Error()})
+			continue
+		}
+		for _, article := range legacyArticles(string(data)) {
+			fragment, err := wire.ParseFragmentV01(article)
+			if err != nil {
+				report.Skipped = append(report.Skipped, MigrationIssue{Path: pagePath, URL: fragment.URL, Reason: err.Error()})
+				continue
+			}
+			// Host pages repeat the fragments of the resources they list
+			if seen[fragment.URL] {
+				continue
+			}
+			seen[fragment.URL] = true
+
+			indexPath, err := migrateFragmentV01(fragment, namespaces)
+			if err != nil {
+				report.Skipped = append(report.Skipped, MigrationIssue{Path: pagePath, URL: fragment.URL, Reason: err.Error()})
+				continue
+			}
+			report.Resources = append(report.Resources, MigratedFile{URL: fragment.URL, Path: indexPath})
+		}
+	}
+	return report, nil
+}
+
+// migrateNamespaceV01 re-issues the v0.1 NA at naPath as a v0.2 NA in the same directory. A v0.2 NA
+// is returned as it is, with migrated false.
+func migrateNamespaceV01(naPath, keysDir, privHex string) (*migratedNamespace, bool, *MigrationIssue) {
+	data, err := os.ReadFile(naPath)
+	if err != nil {
+		return nil, false, &MigrationIssue{Path: naPath, Reason: err.Error()}
+	}
+	na, err := wire.DecodeNamespaceAttestationV01(bytes.NewReader(data))
+	if err != nil {
+		if v02, v02Err := wire.DecodeNamespaceAttestation(bytes.NewReader(data)); v02Err == nil {
+			return &migratedNamespace{
+				namespace:    v02.Payload.Namespace,
+				url:          strings.TrimSuffix(v02.Payload.Namespace, "/") + "/_la_namespace.json",
+				dir:          filepath.Dir(naPath),
+				publisherKey: v02.Key,
+			}, false, nil
+		}
+		return nil, false, &MigrationIssue{Path: naPath, Reason: fmt.Sprintf("not a v0.1 namespace attestation: %v", err)}
+	}
+
+	// The v0.2 namespace is the one the NA is served in; its other namespaces need their own NAs
+	var namespace, naURL string
+	for _, ns := range na.Payload.Namespace {
+		u, err := url.Parse(ns)
+		if err == nil && u.Path+"_la_namespace.json" == na.Payload.AttestationPath {
+			namespace = ns
+			naURL = strings.TrimSuffix(ns, "/") + "/_la_namespace.json"
+		}
+	}
+	if namespace == "" {
+		return nil, false, &MigrationIssue{Path: naPath, Reason: fmt.Sprintf("no namespace matches attestation_path %s", na.Payload.AttestationPath)}
+	}
+
+	// The v0.2 NA must be signed by the same publisher
+	key, err := publisherPrivateKey(na.PublisherKey, keysDir, privHex)
+	if err != nil {
+		return nil, false, &MigrationIssue{Path: naPath, URL: naURL, Reason: err.Error()}
+	}
+	if err := os.WriteFile(naPath+".bak", data, 0600); err != nil {
+		return nil, false, &MigrationIssue{Path: naPath, URL: naURL, Reason: fmt.Sprintf("backup: %v", err)}
+	}
+	if _, err := CreateNamespaceAttestation(namespace, "", key, filepath.Dir(naPath), keysDir, "", false); err != nil {
+		return nil, false, &MigrationIssue{Path: naPath, URL: naURL, Reason: err.Error()}
+	}
+
+	return &migratedNamespace{
+		namespace:    namespace,
+		url:          naURL,
+		dir:          filepath.Dir(naPath),
+		publisherKey: na.PublisherKey,
+	}, true, nil
+}
+
+// migrateFragmentV01 recovers a v0.1 fragment's canonical bytes and re-emits the resource as v0.2
+// under the migrated NA covering it. It returns the path of the new index.htmx.
+func migrateFragmentV01(fragment wire.FragmentV01, namespaces []migratedNamespace) (string, error) {
+	// Only bytes matching the signed stapled attestation are carried over
+	ra := fragment.StapledResourceAttestation
+	payloadBytes, err := canonical.MarshalResourcePayloadV01Canonical(ra.Payload.ToCanonical())
+	if err != nil {
+		return "", fmt.Errorf("canonical marshal: %w", err)
+	}
+	if ok, err := crypto.VerifySchnorrHex(ra.ResourceKey, ra.Sig, crypto.HashSHA256(payloadBytes)); err != nil || !ok {
+		return "", fmt.Errorf("stapled attestation signature invalid")
+	}
+	if ra.Payload.URL != fragment.URL {
+		return "", fmt.Errorf("stapled attestation is for %s", ra.Payload.URL)
+	}
+	if hash := crypto.ComputeContentHashField(fragment.CanonicalContent); hash != ra.Payload.Hash {
+		return "", fmt.Errorf("canonical bytes do not match the attested hash %s", ra.Payload.Hash)
+	}
+
+	var ns *migratedNamespace
+	for i := range namespaces {
+		if strings.HasPrefix(fragment.URL+"/", namespaces[i].namespace) {
+			ns = &namespaces[i]
+			break
+		}
+	}
+	if ns == nil {
+		return "", fmt.Errorf("no migrated namespace attestation covers this resource")
+	}
+
+	// The resource lives at the same path below the NA's directory as its URL below the namespace
+	resourceURL, err := url.Parse(fragment.URL)
+	if err != nil {
+		return "", fmt.Errorf("invalid url: %w", err)
+	}
+	namespaceURL, err := url.Parse(ns.namespace)
+	if err != nil {
+		return "", fmt.Errorf("invalid namespace: %w", err)
+	}
+	rel := strings.TrimPrefix(path.Clean(resourceURL.Path), namespaceURL.Path)
+	dir := filepath.Join(ns.dir, filepath.FromSlash(rel))
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", fmt.Errorf("mkdir %s: %w", dir, err)
+	}
+
+	// HTML, the v0.2 default media type, is left implicit
+	contentType := ""
+	if mediaType, _, err := mime.ParseMediaType(fragment.ContentType); err == nil && mediaType != wire.DefaultContentType {
+		contentType = mediaType
+	}
+
+	contentPath := filepath.Join(dir, "content.htmx")
+	raPath := filepath.Join(dir, "_la_resource.json")
+	indexPath := filepath.Join(dir, "index.htmx")
+	if err := os.WriteFile(contentPath, fragment.CanonicalContent, 0644); err != nil {
+		return "", fmt.Errorf("write %s: %w", contentPath, err)
+	}
+	// A backup left by an earlier run holds the v0.1 RA; the RA beside it is that run's v0.2 one
+	if _, err := os.Stat(raPath + ".bak"); os.IsNotExist(err) {
+		if old, err := os.ReadFile(raPath); err == nil {
+			if err := os.WriteFile(raPath+".bak", old, 0600); err != nil {
+				return "", fmt.Errorf("backup: %w", err)
+			}
+		}
+	}
+	resourceAttestationURL := strings.TrimSuffix(fragment.URL, "/") + "/_la_resource.json"
+	if err := CreateResourceAttestationWithOptions(contentPath, fragment.URL, "", ns.publisherKey, ns.url, raPath, ResourceAttestationOptions{ContentType: contentType}); err != nil {
+		return "", err
+	}
+	if err := CreateFragmentWithOptions(contentPath, fragment.URL, "", ns.publisherKey, resourceAttestationURL, ns.url, indexPath, FragmentOptions{ContentType: contentType}); err != nil {
+		return "", err
+	}
+	return indexPath, nil
+}
+
+// publisherPrivateKey returns the private key for publisherKey: privHex if it matches, otherwise the
+// first key stored in keysDir that does
+func publisherPrivateKey(publisherKey, keysDir, privHex string) (string, error) {
+	if privHex != "" {
+		signer, err := crypto.ParseSignerHex(crypto.SignatureAlgorithmBIP340, privHex)
+		if err != nil {
+			return "", fmt.Errorf("invalid privkey: %w", err)
+		}
+		if signer.PublicKeyHex() == publisherKey {
+			return privHex, nil
+		}
+	}
+	entries, _ := os.ReadDir(keysDir)
+	for _, entry := range entries {
+		data, err := os.ReadFile(filepath.Join(keysDir, entry.Name()))
+		if err != nil {
+			continue
+		}
+		var stored StoredKey
+		if json.Unmarshal(data, &stored) == nil && stored.Alg == "" && stored.PubKeyXOnly == publisherKey {
+			return stored.PrivKeyHex, nil
+		}
+	}
+	return "", fmt.Errorf("no private key for publisher key %s", publisherKey)
+}
+
+// legacyArticles returns each v0.1 <article> in an HTML document
+func legacyArticles(document string) []string {
+	var articles []string
+	for {
+		idx := strings.Index(document, wire.LegacySpecAttribute+"=")
+		if idx < 0 {
+			return articles
+		}
+		start := strings.LastIndex(document[:idx], "<article")
+		end := strings.Index(document[idx:], "</article>")
+		if start < 0 || end < 0 {
+			return articles
+		}
+		end += idx + len("</article>")
+		articles = append(articles, document[start:end])
+		document = document[end:]
+	}
+}
@@ -2,7 +2,6 @@
 package artifacts
 
 import (
-	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"os"
@@ -11,15 +10,14 @@ import (
 	"strings"
 	"time"
 
-	"github.com/btcsuite/btcd/btcec/v2"
-	"github.com/btcsuite/btcd/btcec/v2/schnorr"
 	"github.com/stonebraker/lap/sdks/go/pkg/lap/canonical"
 	"github.com/stonebraker/lap/sdks/go/pkg/lap/crypto"
 	"github.com/stonebraker/lap/sdks/go/pkg/lap/wire"
 )
 
-// CreateNamespaceAttestation creates a v0.2 Namespace Attestation
-func CreateNamespaceAttestation(namespace, expStr, privHexFlag, outDir, keysDir string, rotate bool) (string, error) {
+// CreateNamespaceAttestation creates a v0.2 Namespace Attestation.
+// alg selects the signature algorithm ("bip340", "ed25519" or "ecdsa-p256"); empty selects bip340.
+func CreateNamespaceAttestation(namespace, expStr, privHexFlag, outDir, keysDir, alg string, rotate bool) (string, error) {
 	// Parse or set expiration timestamp
 	var exp int64
 	var err error
@@ -33,60 +31,50 @@ func CreateNamespaceAttestation(namespace, expStr, privHexFlag, outDir, keysDir
 		exp = time.Now().AddDate(1, 0, 0).Unix()
 	}
 
-	// Get or generate private key
-	var priv *btcec.PrivateKey
-	var pubHex string
+	alg = crypto.NormalizeSignatureAlgorithm(alg)
+
+	// Get or generate private key; previous is the key replaced by a rotation
+	var signer, previous crypto.Signer
 
 	if privHexFlag != "" {
-		priv, err = crypto.ParsePrivateKeyHex(privHexFlag)
+		signer, err = crypto.ParseSignerHex(alg, privHexFlag)
 		if err != nil {
 			return "", fmt.Errorf("invalid privkey: %w", err)
 		}
-		pub := priv.PubKey()
-		pubHex = hex.EncodeToString(schnorr.SerializePubKey(pub))
 	} else {
-		// Check if this is for Alice's namespace and use her specific key
-		if strings.Contains(namespace, "/people/alice/") {
+		// Check if this is for Alice's namespace and use her specific key (a secp256k1 key)
+		if alg == crypto.SignatureAlgorithmBIP340 && strings.Contains(namespace, "/people/alice/") {
 			aliceKeyPath := filepath.Join(keysDir, "alice_publisher_key.json")
-			if data, err := os.ReadFile(aliceKeyPath); err == nil {
-				var stored StoredKey
-				if json.Unmarshal(data, &stored) == nil {
-					priv, err = crypto.ParsePrivateKeyHex(stored.PrivKeyHex)
-					if err == nil {
-						pubHex = stored.PubKeyXOnly
-					}
-				}
-			}
+			signer = loadStoredSigner(aliceKeyPath, alg)
 		}
 		
 		// If not Alice or Alice key not found, try to load existing key from keys directory
-		if priv == nil {
-			keyPath := filepath.Join(keysDir, "namespace_key.json")
+		if signer == nil {
+			keyPath := namespaceKeyPath(keysDir, alg)
 			if !rotate {
-				if data, err := os.ReadFile(keyPath); err == nil {
-					var stored StoredKey
-					if json.Unmarshal(data, &stored) == nil {
-						priv, err = crypto.ParsePrivateKeyHex(stored.PrivKeyHex)
-						if err == nil {
-							pubHex = stored.PubKeyXOnly
-						}
-					}
-				}
+				signer = loadStoredSigner(keyPath, alg)
+			} else {
+				previous = loadStoredSigner(keyPath, alg)
 			}
 
 			// Generate new key if none exists or rotate requested
-			if priv == nil {
-				priv, pubHex, err = crypto.GenerateKeyPair()
+			if signer == nil {
+				signer, err = crypto.GenerateSigner(alg)
 				if err != nil {
 					return "", fmt.Errorf("generate keypair: %w", err)
 				}
 
 				// Store the new key
 				stored := StoredKey{
-					PrivKeyHex:    hex.EncodeToString(priv.Serialize()),
-					PubKeyXOnly:   pubHex,
+					PrivKeyHex:    signer.PrivateKeyHex(),
 					CreatedAtUnix: time.Now().Unix(),
 				}
+				if alg == crypto.SignatureAlgorithmBIP340 {
+					stored.PubKeyXOnly = signer.PublicKeyHex()
+				} else {
+					stored.Alg = alg
+					stored.PubKeyHex = signer.PublicKeyHex()
+				}
 				if err := os.MkdirAll(keysDir, 0700); err != nil {
 					return "", fmt.Errorf("mkdir %s: %w", keysDir, err)
 				}
@@ -97,14 +85,20 @@ func CreateNamespaceAttestation(namespace, expStr, privHexFlag, outDir, keysDir
 		}
 	}
 
-	// Create v0.2 Namespace Attestation
-	payload := wire.NamespacePayload{
-		Namespace: namespace,
-		Exp:       exp,
+	// Create v0.2 Namespace Attestation; alg is omitted for the bip340 default
+	attestation := wire.NamespaceAttestation{
+		Payload: wire.NamespacePayload{
+			Namespace: namespace,
+			Exp:       exp,
+		},
+		Key: signer.PublicKeyHex(),
+	}
+	if alg != crypto.SignatureAlgorithmBIP340 {
+		attestation.Alg = alg
 	}
 
-	// Marshal to canonical JSON for signing
-	payloadBytes, err := canonical.MarshalNamespacePayloadCanonical(payload.ToCanonical())
+	// Marshal to canonical JSON for signing; the payload covers a non-default alg
+	payloadBytes, err := canonical.MarshalNamespacePayloadCanonical(attestation.SignedPayload())
 	if err != nil {
 		return "", fmt.Errorf("canonical marshal: %w", err)
 	}
@@ -113,18 +107,10 @@ func CreateNamespaceAttestation(namespace, expStr, privHexFlag, outDir, keysDir
 	digest := crypto.HashSHA256(payloadBytes)
 
 	// Sign the digest
-	sigHex, err := crypto.SignSchnorrHex(priv, digest)
-	if err != nil {
+	if attestation.Sig, err = signer.SignHex(digest); err != nil {
 		return "", fmt.Errorf("sign: %w", err)
 	}
 
-	// Create the full attestation object
-	attestation := wire.NamespaceAttestation{
-		Payload: payload,
-		Key:     pubHex,
-		Sig:     sigHex,
-	}
-
 	// Determine output directory and path
 	if outDir == "" {
 		outDir = "."
@@ -144,5 +130,83 @@ func CreateNamespaceAttestation(namespace, expStr, privHexFlag, outDir, keysDir
 		return "", fmt.Errorf("write %s: %w", outputPath, err)
 	}
 
+	// A rotated key is announced by the key it replaces, so readers who pinned it can follow the change
+	if previous != nil {
+		if err := appendKeyRotation(wire.KeyRotationsLocation(outputPath), previous, signer, namespace); err != nil {
+			return "", err
+		}
+	}
+
 	return outputPath, nil
 }
+
+// appendKeyRotation adds a rotation from previous to next, signed by previous, to the rotation index
+// at path, creating it if needed
+func appendKeyRotation(path string, previous, next crypto.Signer, namespace string) error {
+	var index wire.KeyRotationIndex
+	if f, err := os.Open(path); err == nil {
+		index, err = wire.DecodeKeyRotationIndex(f)
+		f.Close()
+		if err != nil {
+			return fmt.Errorf("parse %s: %w", path, err)
+		}
+	}
+
+	rotation := wire.KeyRotation{
+		Payload: wire.KeyRotationPayload{
+			Namespace: namespace,
+			NewKey:    next.PublicKeyHex(),
+			Iat:       time.Now().Unix(),
+		},
+		Key: previous.PublicKeyHex(),
+	}
+	if alg := next.Algorithm(); alg != crypto.SignatureAlgorithmBIP340 {
+		rotation.Payload.NewAlg = alg
+	}
+	if alg := previous.Algorithm(); alg != crypto.SignatureAlgorithmBIP340 {
+		rotation.Alg = alg
+	}
+	payloadBytes, err := canonical.MarshalKeyRotationPayloadCanonical(rotation.SignedPayload())
+	if err != nil {
+		return fmt.Errorf("canonical marshal: %w", err)
+	}
+	if rotation.Sig, err = previous.SignHex(crypto.HashSHA256(payloadBytes)); err != nil {
+		return fmt.Errorf("sign rotation: %w", err)
+	}
+
+	index.Rotations = append(index.Rotations, rotation)
+	if err := WriteJSON0600(path, index); err != nil {
+		return fmt.Errorf("write %s: %w", path, err)
+	}
+	return nil
+}
+
+// namespaceKeyPath returns the stored key path for the given signature algorithm.
+// bip340 keeps the original namespace_key.json name so existing key directories keep working.
+func namespaceKeyPath(keysDir, alg string) string {
+	if alg == crypto.SignatureAlgorithmBIP340 {
+		return filepath.Join(keysDir, "namespace_key.json")
+	}
+	return filepath.Join(keysDir, fmt.Sprintf("namespace_key_%s.json", alg))
+}
+
+// loadStoredSigner reads a StoredKey file and returns a signer for it, or nil if the file is
+// missing, unreadable, or holds a key for a different algorithm
+func loadStoredSigner(path, alg string) crypto.Signer {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+	var stored StoredKey
+	if json.Unmarshal(data, &stored) != nil {
+		return nil
+	}
+	if crypto.NormalizeSignatureAlgorithm(stored.Alg) != alg {
+		return nil
+	}
+	signer, err := crypto.ParseSignerHex(alg, stored.PrivKeyHex)
+	if err != nil {
+		return nil
+	}
+	return signer
+}
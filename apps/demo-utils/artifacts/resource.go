@@ -2,18 +2,45 @@
 package artifacts
 
 import (
+	"encoding/hex"
 	"fmt"
 	"net/url"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
+	"time"
 
+	"github.com/stonebraker/lap/sdks/go/pkg/lap/canonical"
 	"github.com/stonebraker/lap/sdks/go/pkg/lap/crypto"
 	"github.com/stonebraker/lap/sdks/go/pkg/lap/wire"
 )
 
+// ResourceAttestationOptions holds the optional settings of a new Resource Attestation. The zero
+// value attests raw HTML content with the default hash.
+type ResourceAttestationOptions struct {
+	HashAlg       string // Content hash algorithm (e.g. "sha256", "sha512"); empty selects the default
+	CanonProfile  string // Canonicalization profile applied before hashing; empty selects "raw"
+	ContentType   string // Media type of the content (e.g. "image/png"); empty selects text/html
+	CommitBlocks  bool   // Add a block_root over the top-level HTML blocks so other sites can quote verifiable excerpts
+	PreviousPath  string // RA of the version this one replaces
+	InReplyToPath string // RA of the parent post, possibly from another site
+	Exp           string // Unix seconds after which the RA no longer verifies
+	MaxAge        int64  // Seconds a fetched copy of the RA stays fresh
+}
+
 // CreateResourceAttestation creates a v0.2 Resource Attestation for the given content
 func CreateResourceAttestation(inPath, resURL, base, publisherClaim, namespaceAttestationURL, outPath string) error {
+	return CreateResourceAttestationWithOptions(inPath, resURL, base, publisherClaim, namespaceAttestationURL, outPath, ResourceAttestationOptions{})
+}
+
+// CreateResourceAttestationWithOptions creates a v0.2 Resource Attestation for the given content.
+// With the merkle-sha256 hash a proof sidecar is also written next to the RA (see wire.MerkleProofsLocation).
+// With opts.PreviousPath the hash and history of the replaced RA become previous_hashes and the version
+// is incremented, so embeds of earlier versions verify as stale rather than forged. With
+// opts.InReplyToPath the new RA replies to the parent's fragment URL and current hash. opts.Exp or
+// opts.MaxAge also stamps iat with the current time.
+func CreateResourceAttestationWithOptions(inPath, resURL, base, publisherClaim, namespaceAttestationURL, outPath string, opts ResourceAttestationOptions) error {
 	// Read input file
 	body, err := os.ReadFile(inPath)
 	if err != nil {
@@ -54,14 +81,84 @@ func CreateResourceAttestation(inPath, resURL, base, publisherClaim, namespaceAt
 	u.Host = hu
 	payloadURL := u.String()
 
+	// Canonicalize the content, then hash it with the requested algorithm
+	content, err := canonical.CanonicalizeContent(opts.CanonProfile, body)
+	if err != nil {
+		return fmt.Errorf("canonicalize: %w", err)
+	}
+	hashField, err := crypto.ComputeContentHashFieldWithAlgorithm(opts.HashAlg, content)
+	if err != nil {
+		return fmt.Errorf("hash: %w", err)
+	}
+
 	// Create v0.2 Resource Attestation
 	att := wire.ResourceAttestation{
 		FragmentURL:             payloadURL,
-		Hash:                    crypto.ComputeContentHashField(body),
+		Hash:                    hashField,
 		PublisherClaim:          publisherClaim,
 		NamespaceAttestationURL: namespaceAttestationURL,
 	}
 
+	// The raw profile is the default and is left implicit
+	if profile := canonical.NormalizeProfile(opts.CanonProfile); profile != canonical.ProfileRaw {
+		att.Canonicalization = profile
+	}
+
+	// So is HTML content
+	if opts.ContentType != "" && opts.ContentType != wire.DefaultContentType {
+		att.ContentType = opts.ContentType
+	}
+
+	// A Merkle root commits to the content length too, so range proofs cannot claim another size
+	if alg, _, _ := strings.Cut(hashField, ":"); alg == crypto.MerkleHashAlgorithm {
+		att.Size = int64(len(content))
+	}
+
+	// Excerpts are single blocks of the canonical HTML
+	if opts.CommitBlocks {
+		if att.ContentType != "" {
+			return fmt.Errorf("block commitments require %s content, got %s", wire.DefaultContentType, opts.ContentType)
+		}
+		blocks := canonical.SplitBlocks(content)
+		att.BlockRoot = crypto.MerkleRootField(blocks)
+		att.BlockCount = len(blocks)
+	}
+
+	// Continue the version history of the attestation being replaced
+	if opts.PreviousPath != "" {
+		if err := continueHistory(&att, opts.PreviousPath); err != nil {
+			return err
+		}
+	}
+
+	// Link a reply to the parent content it answers
+	if opts.InReplyToPath != "" {
+		parent, err := readResourceAttestation(opts.InReplyToPath)
+		if err != nil {
+			return fmt.Errorf("parent attestation: %w", err)
+		}
+		att.InReplyTo = &wire.ReplyReference{FragmentURL: parent.FragmentURL, Hash: parent.Hash}
+	}
+
+	// Freshness hints are relative to the time of issue
+	if opts.Exp != "" || opts.MaxAge != 0 {
+		if opts.MaxAge < 0 {
+			return fmt.Errorf("invalid max_age: %d", opts.MaxAge)
+		}
+		att.IAT = time.Now().Unix()
+		att.MaxAge = opts.MaxAge
+		if opts.Exp != "" {
+			exp, err := strconv.ParseInt(opts.Exp, 10, 64)
+			if err != nil {
+				return fmt.Errorf("invalid exp: %w", err)
+			}
+			if exp <= att.IAT {
+				return fmt.Errorf("exp %d is not in the future", exp)
+			}
+			att.Exp = exp
+		}
+	}
+
 	// Determine output path
 	if outPath == "" {
 		dir := filepath.Dir(inPath)
@@ -73,5 +170,72 @@ func CreateResourceAttestation(inPath, resURL, base, publisherClaim, namespaceAt
 		return fmt.Errorf("mkdir: %w", err)
 	}
 	
-	return WriteJSON0600(outPath, att)
+	if err := WriteJSON0600(outPath, att); err != nil {
+		return err
+	}
+
+	// Merkle roots come with the inclusion proofs needed to verify byte ranges
+	if alg, _, _ := strings.Cut(hashField, ":"); alg == crypto.MerkleHashAlgorithm {
+		return WriteJSON0600(wire.MerkleProofsLocation(outPath), CreateMerkleProofs(hashField, content))
+	}
+	return nil
+}
+
+// continueHistory makes att the next version after the RA at previousPath. Re-attesting unchanged
+// content keeps the previous version, history and co-publishers.
+func continueHistory(att *wire.ResourceAttestation, previousPath string) error {
+	previous, err := readResourceAttestation(previousPath)
+	if err != nil {
+		return fmt.Errorf("previous attestation: %w", err)
+	}
+	if previous.FragmentURL != att.FragmentURL {
+		return fmt.Errorf("previous attestation covers %s, not %s", previous.FragmentURL, att.FragmentURL)
+	}
+
+	version := previous.Version
+	if version == 0 {
+		version = len(previous.PreviousHashes) + 1
+	}
+	if previous.Hash == att.Hash {
+		att.Version = version
+		att.PreviousHashes = previous.PreviousHashes
+		att.CoPublishers = previous.CoPublishers // Their signatures still cover the unchanged content
+		return nil
+	}
+	att.Version = version + 1
+	att.PreviousHashes = append([]string{previous.Hash}, previous.PreviousHashes...)
+	return nil
+}
+
+// readResourceAttestation strictly decodes the RA file at path
+func readResourceAttestation(path string) (wire.ResourceAttestation, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return wire.ResourceAttestation{}, err
+	}
+	defer f.Close()
+	ra, err := wire.DecodeResourceAttestation(f)
+	if err != nil {
+		return ra, fmt.Errorf("parse %s: %w", path, err)
+	}
+	return ra, nil
+}
+
+// CreateMerkleProofs returns the proof sidecar for content whose RA hash field is the Merkle root hashField.
+func CreateMerkleProofs(hashField string, content []byte) wire.MerkleProofs {
+	leaves := crypto.MerkleLeafHashes(content)
+	proofs := wire.MerkleProofs{
+		Hash:      hashField,
+		ChunkSize: crypto.MerkleChunkSize,
+		Size:      int64(len(content)),
+		Proofs:    make([][]string, len(leaves)),
+	}
+	for i := range leaves {
+		proof, _ := crypto.MerkleInclusionProof(leaves, i) // i is always in range
+		proofs.Proofs[i] = make([]string, len(proof))
+		for j, h := range proof {
+			proofs.Proofs[i][j] = hex.EncodeToString(h[:])
+		}
+	}
+	return proofs
 }
@@ -0,0 +1,82 @@
+// Package artifacts provides demo utilities for LAP artifact management.
+package artifacts
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/stonebraker/lap/sdks/go/pkg/lap/canonical"
+	"github.com/stonebraker/lap/sdks/go/pkg/lap/crypto"
+	"github.com/stonebraker/lap/sdks/go/pkg/lap/wire"
+)
+
+// CreateStatement signs a statement about the resource attested by the RA at subjectRAPath and adds it
+// to the issuer's statement index at outPath (default: ./_la_statements.json), creating the index if needed.
+// The statement is signed with the issuer's namespace key: privHex when given, otherwise the key stored
+// in keysDir by CreateNamespaceAttestation for alg. namespaceAttestationURL is the issuer's NA, whose
+// directory the index must be published in.
+func CreateStatement(subjectRAPath, statementType, body, namespaceAttestationURL, privHex, keysDir, alg, outPath string) (string, error) {
+	subject, err := readResourceAttestation(subjectRAPath)
+	if err != nil {
+		return "", fmt.Errorf("subject attestation: %w", err)
+	}
+	known := false
+	for _, t := range wire.StatementTypes {
+		known = known || t == statementType
+	}
+	if !known {
+		return "", fmt.Errorf("unknown statement type %q (expected %s)", statementType, strings.Join(wire.StatementTypes, ", "))
+	}
+
+	signer, err := namespaceSigner(privHex, keysDir, alg)
+	if err != nil {
+		return "", err
+	}
+
+	payload := wire.StatementPayload{
+		FragmentURL:             subject.FragmentURL,
+		Hash:                    subject.Hash,
+		Type:                    statementType,
+		Body:                    body,
+		NamespaceAttestationURL: namespaceAttestationURL,
+		Iat:                     time.Now().Unix(),
+	}
+	statement := wire.Statement{Payload: payload, Key: signer.PublicKeyHex()}
+	if alg = crypto.NormalizeSignatureAlgorithm(alg); alg != crypto.SignatureAlgorithmBIP340 {
+		statement.Alg = alg
+	}
+	payloadBytes, err := canonical.MarshalStatementPayloadCanonical(statement.SignedPayload())
+	if err != nil {
+		return "", fmt.Errorf("canonical marshal: %w", err)
+	}
+	if statement.Sig, err = signer.SignHex(crypto.HashSHA256(payloadBytes)); err != nil {
+		return "", fmt.Errorf("sign: %w", err)
+	}
+
+	if outPath == "" {
+		outPath = wire.StatementsFileName
+	}
+	var index wire.StatementIndex
+	if f, err := os.Open(outPath); err == nil {
+		index, err = wire.DecodeStatementIndex(f)
+		f.Close()
+		if err != nil {
+			return "", fmt.Errorf("parse %s: %w", outPath, err)
+		}
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return "", fmt.Errorf("read %s: %w", outPath, err)
+	}
+	index.Statements = append(index.Statements, statement)
+
+	if err := os.MkdirAll(filepath.Dir(outPath), 0755); err != nil {
+		return "", fmt.Errorf("mkdir: %w", err)
+	}
+	if err := WriteJSON0600(outPath, index); err != nil {
+		return "", fmt.Errorf("write %s: %w", outPath, err)
+	}
+	return outPath, nil
+}
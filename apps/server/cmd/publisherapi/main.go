@@ -24,6 +24,9 @@ func main() {
 	_ = mime.AddExtensionType(".htmx", "text/html; charset=utf-8")
 
 	mux := chi.NewRouter()
+
+	// Attach each fragment's Resource Attestation to its responses
+	mux.Use(httpx.ResourceAttestationHeader(*dir))
 	
 	// Add PUT handler for frc posts
 	mux.Put("/people/alice/frc/posts/{postID}", func(w http.ResponseWriter, r *http.Request) {
@@ -0,0 +1,78 @@
+package httpx
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/stonebraker/lap/sdks/go/pkg/lap/wire"
+)
+
+// resourceAttestationFile is the RA file name stored alongside a fragment's index file.
+const resourceAttestationFile = "_la_resource.json"
+
+// ResourceAttestationHeader returns middleware that attaches the Resource Attestation of a resource
+// to GET and HEAD responses for it. A directory holding _la_resource.json is attested by that file,
+// and any other file, such as an image, by a sibling "<name>._la_resource.json". The response gets
+// the RA as a base64url LAP-Resource-Attestation header and a Link header pointing at the RA file.
+// Invalid RA files are logged and left off the response so verifiers fall back to the RA URL.
+func ResourceAttestationHeader(baseDir string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			if req.Method == http.MethodGet || req.Method == http.MethodHead {
+				if raURLPath, value, ok := attestationFor(baseDir, req.URL.Path); ok {
+					w.Header().Set(wire.AttestationHeaderName, value)
+					w.Header().Add("Link", fmt.Sprintf("<%s>; rel=\"%s\"", raURLPath, wire.LinkRelResourceAttestation))
+				}
+			}
+			next.ServeHTTP(w, req)
+		})
+	}
+}
+
+// attestationFor locates the RA stored for urlPath and returns its URL path and encoded header value
+func attestationFor(baseDir, urlPath string) (string, string, bool) {
+	absBase, err := filepath.Abs(baseDir)
+	if err != nil {
+		return "", "", false
+	}
+	cleanPath := path.Clean("/" + urlPath)
+	absPath, err := filepath.Abs(filepath.Join(absBase, cleanPath))
+	// Compare with a trailing separator so a sibling such as "<base>-other" is not inside base
+	if err != nil || (absPath != absBase && !strings.HasPrefix(absPath, absBase+string(os.PathSeparator))) {
+		return "", "", false
+	}
+	info, err := os.Stat(absPath)
+	if err != nil {
+		return "", "", false
+	}
+
+	raPath := filepath.Join(absPath, resourceAttestationFile)
+	raURLPath := path.Join(cleanPath, resourceAttestationFile)
+	if !info.IsDir() {
+		raPath = absPath + "." + resourceAttestationFile
+		raURLPath = cleanPath + "." + resourceAttestationFile
+	}
+
+	f, err := os.Open(raPath)
+	if err != nil {
+		return "", "", false
+	}
+	defer f.Close()
+
+	ra, err := wire.DecodeResourceAttestation(f)
+	if err != nil {
+		log.Printf("skipping %s header: %s: %v", wire.AttestationHeaderName, raPath, err)
+		return "", "", false
+	}
+	value, err := wire.EncodeAttestationHeader(ra)
+	if err != nil {
+		log.Printf("skipping %s header: %s: %v", wire.AttestationHeaderName, raPath, err)
+		return "", "", false
+	}
+	return raURLPath, value, true
+}
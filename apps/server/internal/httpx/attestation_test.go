@@ -0,0 +1,110 @@
+package httpx
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stonebraker/lap/sdks/go/pkg/lap/wire"
+)
+
+// writeRA writes an RA for fragmentURL at path, creating its directory
+func writeRA(t *testing.T, path, fragmentURL string) wire.ResourceAttestation {
+	t.Helper()
+	ra := wire.ResourceAttestation{
+		FragmentURL:             fragmentURL,
+		Hash:                    "sha256:ab",
+		PublisherClaim:          "cd",
+		NamespaceAttestationURL: "https://example.com/_la_namespace.json",
+	}
+	data, err := json.Marshal(ra)
+	if err != nil {
+		t.Fatal(err)
+	}
+	writeFile(t, path, data)
+	return ra
+}
+
+// writeFile writes data at path, creating its directory
+func writeFile(t *testing.T, path string, data []byte) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestResourceAttestationHeader(t *testing.T) {
+	base := t.TempDir()
+	postRA := writeRA(t, filepath.Join(base, "posts", "1", resourceAttestationFile), "https://example.com/posts/1")
+	writeFile(t, filepath.Join(base, "images", "cat.png"), []byte("png"))
+	imageRA := writeRA(t, filepath.Join(base, "images", "cat.png."+resourceAttestationFile), "https://example.com/images/cat.png")
+	writeFile(t, filepath.Join(base, "broken", resourceAttestationFile), []byte(`{"fragment_url":"a","fragment_url":"b"}`))
+	writeFile(t, filepath.Join(base, "plain.txt"), []byte("no attestation"))
+
+	handler := ResourceAttestationHeader(base)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	serve := func(method, path string) http.Header {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(method, path, nil))
+		return rec.Header()
+	}
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		ra     *wire.ResourceAttestation
+		link   string
+	}{
+		{"directory", http.MethodGet, "/posts/1/", &postRA, "</posts/1/_la_resource.json>; rel=\"lap-resource-attestation\""},
+		{"directory head", http.MethodHead, "/posts/1", &postRA, "</posts/1/_la_resource.json>; rel=\"lap-resource-attestation\""},
+		{"sibling file", http.MethodGet, "/images/cat.png", &imageRA, "</images/cat.png._la_resource.json>; rel=\"lap-resource-attestation\""},
+		{"invalid RA", http.MethodGet, "/broken/", nil, ""},
+		{"no RA", http.MethodGet, "/plain.txt", nil, ""},
+		{"missing", http.MethodGet, "/posts/2/", nil, ""},
+		{"post", http.MethodPost, "/posts/1/", nil, ""},
+	}
+	for _, tt := range tests {
+		header := serve(tt.method, tt.path)
+		value := header.Get(wire.AttestationHeaderName)
+		if tt.ra == nil {
+			if value != "" || header.Get("Link") != "" {
+				t.Errorf("%s: expected no attestation headers, got %q and %q", tt.name, value, header.Get("Link"))
+			}
+			continue
+		}
+		ra, err := wire.DecodeAttestationHeader(value)
+		if err != nil || ra.FragmentURL != tt.ra.FragmentURL {
+			t.Errorf("%s: expected the RA for %s, got %+v, %v", tt.name, tt.ra.FragmentURL, ra, err)
+		}
+		if link := header.Get("Link"); link != tt.link {
+			t.Errorf("%s: expected Link %q, got %q", tt.name, tt.link, link)
+		}
+	}
+}
+
+func TestAttestationFor_StaysInBase(t *testing.T) {
+	dir := t.TempDir()
+	base := filepath.Join(dir, "base")
+	writeRA(t, filepath.Join(base, "posts", "1", resourceAttestationFile), "https://example.com/posts/1")
+
+	// A sibling directory sharing the base's name as a prefix is outside it
+	writeFile(t, filepath.Join(dir, "base-other", "secret.txt"), []byte("secret"))
+	writeRA(t, filepath.Join(dir, "base-other", "secret.txt."+resourceAttestationFile), "https://example.com/secret.txt")
+
+	for _, urlPath := range []string{"/../base-other/secret.txt", "../base-other/secret.txt", "/posts/../../base-other/secret.txt"} {
+		if _, _, ok := attestationFor(base, urlPath); ok {
+			t.Errorf("Expected %q not to reach outside the base directory", urlPath)
+		}
+	}
+	if _, _, ok := attestationFor(base, "/posts/1"); !ok {
+		t.Error("Expected an RA inside the base directory to be found")
+	}
+}
@@ -29,7 +29,9 @@ import (
 	"time"
 
 	"github.com/stonebraker/lap/apps/demo-utils/artifacts"
+	"github.com/stonebraker/lap/sdks/go/pkg/lap/canonical"
 	"github.com/stonebraker/lap/sdks/go/pkg/lap/crypto"
+	"github.com/stonebraker/lap/sdks/go/pkg/lap/wire"
 )
 
 func main() {
@@ -42,13 +44,21 @@ func main() {
 		keygenCmd(os.Args[2:])
 	case "ra-create":
 		raCreateCmd(os.Args[2:])
+	case "ra-cosign":
+		raCosignCmd(os.Args[2:])
 	case "fragment-create":
 		fragmentCreateCmd(os.Args[2:])
+	case "excerpt-create":
+		excerptCreateCmd(os.Args[2:])
 
 	case "na-create":
 		naCreateCmd(os.Args[2:])
+	case "statement-create":
+		statementCreateCmd(os.Args[2:])
 	case "reset-artifacts":
 		resetArtifactsCmd(os.Args[2:])
+	case "migrate":
+		migrateCmd(os.Args[2:])
 	case "verify-remote":
 		verifyRemoteCmd(os.Args[2:])
 	case "help", "-h", "--help":
@@ -64,11 +74,15 @@ func usage() {
 	fmt.Fprintf(os.Stderr, "Usage: %s <command> [options]\n", exe)
 	fmt.Fprintf(os.Stderr, "\nCommands:\n")
 	fmt.Fprintf(os.Stderr, "  keygen      Generate a secp256k1 keypair and print or write to file (.env or .json)\n")
-	fmt.Fprintf(os.Stderr, "  ra-create   Create a v0.2 resource attestation for an HTML file\n")
+	fmt.Fprintf(os.Stderr, "  ra-create   Create a v0.2 resource attestation for an HTML file or other media\n")
+	fmt.Fprintf(os.Stderr, "  ra-cosign   Sign a resource attestation as a co-publisher of a co-authored resource\n")
 	fmt.Fprintf(os.Stderr, "  fragment-create   Create a v0.2 HTML fragment (index.htmx) from an content.htmx\n")
+	fmt.Fprintf(os.Stderr, "  excerpt-create    Quote one block of an attested resource as a verifiable excerpt\n")
 
 	fmt.Fprintf(os.Stderr, "  na-create     Create a v0.2 namespace attestation for a namespace URL\n")
+	fmt.Fprintf(os.Stderr, "  statement-create Sign an endorsement, reshare, label or dispute about another resource\n")
 	fmt.Fprintf(os.Stderr, "  reset-artifacts Reset all LAP artifacts for alice by creating a new NA and updating all posts\n")
+	fmt.Fprintf(os.Stderr, "  migrate       Convert v0.1 fragments and attestations under a directory to v0.2\n")
 	fmt.Fprintf(os.Stderr, "  verify-remote Fetch a fragment from a URL and verify it using the verifier service\n")
 }
 
@@ -154,6 +168,14 @@ func raCreateCmd(args []string) {
 	base := fs.String("base", "", "optional base (scheme://host[:port]) to resolve -url against, e.g. http://localhost:8080")
 	publisherClaim := fs.String("publisher-claim", "", "publisher's secp256k1 X-only public key (64 hex chars) for triangulation")
 	namespaceAttestationURL := fs.String("namespace-attestation-url", "", "URL pointing to the Namespace Attestation (required)")
+	hashAlg := fs.String("hash", crypto.DefaultHashAlgorithm, "content hash algorithm: "+strings.Join(crypto.HashAlgorithmNames(), ", "))
+	canonProfile := fs.String("canon", canonical.ProfileRaw, "content canonicalization profile applied before hashing: "+strings.Join(canonical.ProfileNames(), ", "))
+	contentType := fs.String("content-type", wire.DefaultContentType, "media type of the input file, e.g. image/png or application/pdf")
+	blocks := fs.Bool("blocks", false, "commit to the top-level HTML blocks (block_root) so the content can be quoted as verifiable excerpts")
+	previous := fs.String("previous", "", "RA of the version being replaced; its hash is kept in previous_hashes and the version incremented")
+	inReplyTo := fs.String("in-reply-to", "", "RA of the post this one replies to, e.g. downloaded from the parent's site; recorded as in_reply_to")
+	exp := fs.String("exp", "", "optional unix seconds after which the RA no longer verifies")
+	maxAge := fs.Int64("max-age", 0, "optional seconds a fetched copy of the RA stays fresh before clients re-verify")
 	out := fs.String("out", "", "output file path (default: <dir>/_la_resource.json)")
 	_ = fs.Parse(args)
 
@@ -163,12 +185,51 @@ func raCreateCmd(args []string) {
 		os.Exit(2)
 	}
 
-	err := artifacts.CreateResourceAttestation(*inPath, *resURL, *base, *publisherClaim, *namespaceAttestationURL, *out)
+	err := artifacts.CreateResourceAttestationWithOptions(*inPath, *resURL, *base, *publisherClaim, *namespaceAttestationURL, *out, artifacts.ResourceAttestationOptions{
+		HashAlg:       *hashAlg,
+		CanonProfile:  *canonProfile,
+		ContentType:   *contentType,
+		CommitBlocks:  *blocks,
+		PreviousPath:  *previous,
+		InReplyToPath: *inReplyTo,
+		Exp:           *exp,
+		MaxAge:        *maxAge,
+	})
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "error: %v\n", err)
 		os.Exit(1)
 	}
 	
+	fmt.Fprintf(os.Stderr, "wrote %s\n", *out)
+	if strings.EqualFold(*hashAlg, crypto.MerkleHashAlgorithm) && *out != "" {
+		fmt.Fprintf(os.Stderr, "wrote %s\n", wire.MerkleProofsLocation(*out))
+	}
+}
+
+func excerptCreateCmd(args []string) {
+	fs := flag.NewFlagSet("excerpt-create", flag.ExitOnError)
+	inPath := fs.String("in", "", "path to the quoted resource's canonical content")
+	resURL := fs.String("url", "", "absolute fragment URL of the quoted resource")
+	block := fs.Int("block", 0, "index of the top-level block to quote, counted from 0")
+	publisherClaim := fs.String("publisher-claim", "", "quoted publisher's secp256k1 X-only public key (64 hex chars)")
+	resourceAttestationURL := fs.String("resource-attestation-url", "", "URL of the quoted resource's Resource Attestation (must have a block_root)")
+	namespaceAttestationURL := fs.String("namespace-attestation-url", "", "URL of the quoted publisher's Namespace Attestation")
+	canonProfile := fs.String("canon", canonical.ProfileRaw, "content canonicalization profile of the quoted resource's RA: "+strings.Join(canonical.ProfileNames(), ", "))
+	out := fs.String("out", "", "output excerpt HTML path (default: <dir>/excerpt.htmx)")
+	_ = fs.Parse(args)
+
+	if *inPath == "" || *resURL == "" || *publisherClaim == "" || *resourceAttestationURL == "" || *namespaceAttestationURL == "" {
+		fmt.Fprintf(os.Stderr, "excerpt-create requires -in, -url, -publisher-claim, -resource-attestation-url, and -namespace-attestation-url\n")
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	err := artifacts.CreateExcerpt(*inPath, *resURL, *publisherClaim, *resourceAttestationURL, *namespaceAttestationURL, *canonProfile, *block, *out)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		os.Exit(1)
+	}
+
 	fmt.Fprintf(os.Stderr, "wrote %s\n", *out)
 }
 
@@ -180,6 +241,13 @@ func fragmentCreateCmd(args []string) {
 	publisherClaim := fs.String("publisher-claim", "", "publisher's secp256k1 X-only public key (64 hex chars) for triangulation")
 	resourceAttestationURL := fs.String("resource-attestation-url", "", "URL pointing to the Resource Attestation (required)")
 	namespaceAttestationURL := fs.String("namespace-attestation-url", "", "URL pointing to the Namespace Attestation (required)")
+	canonProfile := fs.String("canon", canonical.ProfileRaw, "content canonicalization profile; must match the one used for ra-create: "+strings.Join(canonical.ProfileNames(), ", "))
+	contentType := fs.String("content-type", wire.DefaultContentType, "media type of the input file; must match the one used for ra-create")
+	contentURL := fs.String("content-url", "", "optional same-origin URL serving the content bytes; referenced instead of inlined")
+	stapleRA := fs.String("staple-ra", "", "optional Resource Attestation file to embed in the fragment (requires -staple-na)")
+	stapleNA := fs.String("staple-na", "", "optional Namespace Attestation file to embed in the fragment (requires -staple-ra)")
+	coPublishers := fs.String("co-publishers", "", "optional co-signed Resource Attestation whose co-publishers the fragment names (see ra-cosign)")
+	recipients := fs.String("recipients", "", "optional comma-separated X-only public keys of subscribers; the content is encrypted to them")
 	out := fs.String("out", "", "output fragment HTML path (default: <dir>/index.htmx)")
 	updateHost := fs.String("update", "", "optional path to host HTML file whose matching <article data-la-fragment-url> should be replaced with the new fragment")
 	dryRun := fs.Bool("dry-run", false, "if set, do not write changes to -update host file; just report action")
@@ -191,7 +259,22 @@ func fragmentCreateCmd(args []string) {
 		os.Exit(2)
 	}
 
-	err := artifacts.CreateFragment(*inPath, *resURL, *base, *publisherClaim, *resourceAttestationURL, *namespaceAttestationURL, *out)
+	var recipientKeys []string
+	for _, key := range strings.Split(*recipients, ",") {
+		if key = strings.TrimSpace(key); key != "" {
+			recipientKeys = append(recipientKeys, key)
+		}
+	}
+
+	err := artifacts.CreateFragmentWithOptions(*inPath, *resURL, *base, *publisherClaim, *resourceAttestationURL, *namespaceAttestationURL, *out, artifacts.FragmentOptions{
+		CanonProfile:       *canonProfile,
+		ContentType:        *contentType,
+		ContentURL:         *contentURL,
+		StapleRAPath:       *stapleRA,
+		StapleNAPath:       *stapleNA,
+		CoPublishersRAPath: *coPublishers,
+		Recipients:         recipientKeys,
+	})
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "error: %v\n", err)
 		os.Exit(1)
@@ -249,6 +332,7 @@ func naCreateCmd(args []string) {
 
 	keysDir := fs.String("keys-dir", "demo-keys", "directory to store per-namespace keys (outside static)")
 	rotate := fs.Bool("rotate", false, "force generating a new keypair even if one exists for this namespace")
+	alg := fs.String("alg", crypto.SignatureAlgorithmBIP340, "signature algorithm: "+strings.Join(crypto.SignatureAlgorithmNames(), ", "))
 	_ = fs.Parse(args)
 
 	if *namespace == "" {
@@ -257,7 +341,7 @@ func naCreateCmd(args []string) {
 		os.Exit(2)
 	}
 
-	outputPath, err := artifacts.CreateNamespaceAttestation(*namespace, *expStr, *privHexFlag, *out, *keysDir, *rotate)
+	outputPath, err := artifacts.CreateNamespaceAttestation(*namespace, *expStr, *privHexFlag, *out, *keysDir, *alg, *rotate)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "error: %v\n", err)
 		os.Exit(1)
@@ -266,6 +350,57 @@ func naCreateCmd(args []string) {
 	fmt.Fprintf(os.Stderr, "Created namespace attestation at %s\n", outputPath)
 }
 
+func raCosignCmd(args []string) {
+	fs := flag.NewFlagSet("ra-cosign", flag.ExitOnError)
+	raPath := fs.String("ra", "", "Resource Attestation of the co-authored resource; updated in place")
+	namespaceAttestationURL := fs.String("namespace-attestation-url", "", "URL of the co-publisher's own Namespace Attestation")
+	privHexFlag := fs.String("privkey", "", "(optional) hex-encoded co-publisher private key (default: the namespace key in -keys-dir)")
+	keysDir := fs.String("keys-dir", "demo-keys", "directory holding the co-publisher's namespace key")
+	alg := fs.String("alg", crypto.SignatureAlgorithmBIP340, "signature algorithm: "+strings.Join(crypto.SignatureAlgorithmNames(), ", "))
+	_ = fs.Parse(args)
+
+	if *raPath == "" || *namespaceAttestationURL == "" {
+		fmt.Fprintf(os.Stderr, "ra-cosign requires -ra and -namespace-attestation-url\n")
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	coPublisher, err := artifacts.CoSignResourceAttestation(*raPath, *namespaceAttestationURL, *privHexFlag, *keysDir, *alg)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		os.Exit(1)
+	}
+
+	fmt.Fprintf(os.Stderr, "Co-signed %s as %s\n", *raPath, coPublisher.PublisherClaim)
+}
+
+func statementCreateCmd(args []string) {
+	fs := flag.NewFlagSet("statement-create", flag.ExitOnError)
+	subject := fs.String("subject", "", "path to the Resource Attestation of the resource the statement is about")
+	statementType := fs.String("type", "", "statement type: "+strings.Join(wire.StatementTypes, ", "))
+	body := fs.String("body", "", "optional statement text, e.g. a label or the reason for a dispute")
+	namespaceAttestationURL := fs.String("namespace-attestation-url", "", "URL of the issuer's own Namespace Attestation")
+	privHexFlag := fs.String("privkey", "", "(optional) hex-encoded issuer private key (default: the namespace key in -keys-dir)")
+	keysDir := fs.String("keys-dir", "demo-keys", "directory holding the issuer's namespace key")
+	alg := fs.String("alg", crypto.SignatureAlgorithmBIP340, "signature algorithm: "+strings.Join(crypto.SignatureAlgorithmNames(), ", "))
+	out := fs.String("out", "", "statement index to add to, published next to the issuer's NA (default: ./"+wire.StatementsFileName+")")
+	_ = fs.Parse(args)
+
+	if *subject == "" || *statementType == "" || *namespaceAttestationURL == "" {
+		fmt.Fprintf(os.Stderr, "statement-create requires -subject, -type, and -namespace-attestation-url\n")
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	outputPath, err := artifacts.CreateStatement(*subject, *statementType, *body, *namespaceAttestationURL, *privHexFlag, *keysDir, *alg, *out)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		os.Exit(1)
+	}
+
+	fmt.Fprintf(os.Stderr, "Added %s statement to %s\n", *statementType, outputPath)
+}
+
 
 
 func envKey(prefix, key string) string {
@@ -300,6 +435,52 @@ func resetArtifactsCmd(args []string) {
 	}
 }
 
+// migrateCmd converts the v0.1 artifacts under a directory to v0.2 and prints a JSON report
+func migrateCmd(args []string) {
+	fs := flag.NewFlagSet("migrate", flag.ExitOnError)
+	from := fs.String("from", wire.SpecV01, "protocol version of the artifacts to convert (only v0.1)")
+	root := fs.String("root", "", "directory holding the v0.1 fragments and attestations")
+	keysDir := fs.String("keys-dir", "demo-keys", "directory containing the publisher keys of the v0.1 namespace attestations")
+	privHexFlag := fs.String("privkey", "", "(optional) hex-encoded publisher private key, used when it matches a namespace attestation")
+	reportPath := fs.String("report", "", "(optional) write the report to this file instead of stdout")
+	_ = fs.Parse(args)
+
+	if *root == "" {
+		fmt.Fprintf(os.Stderr, "migrate requires -root\n")
+		fs.Usage()
+		os.Exit(2)
+	}
+	if *from != wire.SpecV01 {
+		fmt.Fprintf(os.Stderr, "migrate: unsupported -from %s (supported: %s)\n", *from, wire.SpecV01)
+		os.Exit(2)
+	}
+
+	report, err := artifacts.MigrateV01(*root, *keysDir, *privHexFlag)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		os.Exit(1)
+	}
+
+	out, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		os.Exit(1)
+	}
+	if *reportPath != "" {
+		if err := os.WriteFile(*reportPath, append(out, '\n'), 0644); err != nil {
+			fmt.Fprintf(os.Stderr, "error: %v\n", err)
+			os.Exit(1)
+		}
+	} else {
+		fmt.Println(string(out))
+	}
+
+	fmt.Fprintf(os.Stderr, "Migrated %d namespace attestations and %d resources; %d skipped\n", len(report.Namespaces), len(report.Resources), len(report.Skipped))
+	if len(report.Skipped) > 0 {
+		os.Exit(1)
+	}
+}
+
 // verifyRemoteCmd fetches a fragment from a URL and verifies it using the verifier service
 func verifyRemoteCmd(args []string) {
 	fs := flag.NewFlagSet("verify-remote", flag.ExitOnError)
@@ -344,7 +525,20 @@ func verifyRemoteCmd(args []string) {
 	verifyEndpoint := strings.TrimSuffix(*verifierURL, "/") + "/verify"
 	fmt.Fprintf(os.Stderr, "Posting fragment to verifier service at %s...\n", verifyEndpoint)
 	
-	verifyResp, err := client.Post(verifyEndpoint, "text/html", bytes.NewReader(fragmentContent))
+	verifyReq, err := http.NewRequest(http.MethodPost, verifyEndpoint, bytes.NewReader(fragmentContent))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error creating verifier request: %v\n", err)
+		os.Exit(1)
+	}
+	verifyReq.Header.Set("Content-Type", "text/html")
+
+	// Forward the Resource Attestation header so the verifier can use it instead of fetching the RA
+	if headerValue := resp.Header.Get(wire.AttestationHeaderName); headerValue != "" {
+		fmt.Fprintf(os.Stderr, "Fragment response carries a %s header\n", wire.AttestationHeaderName)
+		verifyReq.Header.Set(wire.AttestationHeaderName, headerValue)
+	}
+
+	verifyResp, err := client.Do(verifyReq)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "error posting to verifier service: %v\n", err)
 		os.Exit(1)
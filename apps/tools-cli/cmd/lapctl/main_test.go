@@ -1,15 +1,22 @@
 package main
 
 import (
+	"bytes"
+	"encoding/base64"
+	"encoding/hex"
 	"encoding/json"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"runtime"
+	"strconv"
 	"strings"
 	"testing"
 	"time"
 
+	"github.com/stonebraker/lap/sdks/go/pkg/lap/canonical"
+	"github.com/stonebraker/lap/sdks/go/pkg/lap/crypto"
+	"github.com/stonebraker/lap/sdks/go/pkg/lap/verify"
 	"github.com/stonebraker/lap/sdks/go/pkg/lap/wire"
 )
 
@@ -338,6 +345,80 @@ func TestNaCreate_KeyRotation(t *testing.T) {
 	if firstKey == "" || secondKey == "" {
 		t.Error("Expected both keys to be valid")
 	}
+
+	// The old key announces the new one, so readers who pinned it can follow
+	f, err := os.Open(wire.KeyRotationsFileName)
+	if err != nil {
+		t.Fatalf("Expected a key rotation index: %v", err)
+	}
+	defer f.Close()
+	index, err := wire.DecodeKeyRotationIndex(f)
+	if err != nil {
+		t.Fatalf("Failed to decode key rotation index: %v", err)
+	}
+	if err := verify.VerifyKeyRotation(index, "https://example.com/people/david/", "", firstKey, "", secondKey); err != nil {
+		t.Errorf("Expected a valid rotation from the first key to the second: %v", err)
+	}
+}
+
+func TestNaCreate_SignatureAlgorithms(t *testing.T) {
+	tmpDir, cleanup := setupTestEnvironment(t)
+	defer cleanup()
+
+	originalDir, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("Failed to get current directory: %v", err)
+	}
+	defer os.Chdir(originalDir)
+
+	if err := os.Chdir(tmpDir); err != nil {
+		t.Fatalf("Failed to change to temp directory: %v", err)
+	}
+
+	for _, alg := range []string{"ed25519", "ecdsa-p256"} {
+		t.Run(alg, func(t *testing.T) {
+			outDir := filepath.Join("out", alg)
+			_, stderr, err := runLapctl(t, "na-create",
+				"-namespace", "https://example.com/people/erin/",
+				"-keys-dir", "keys",
+				"-alg", alg,
+				"-out", outDir)
+			if err != nil {
+				t.Fatalf("na-create failed: %v\nstderr: %s", err, stderr)
+			}
+
+			attestation := readNamespaceAttestation(t, filepath.Join(outDir, "_la_namespace.json"))
+			if attestation.Alg != alg {
+				t.Errorf("Expected alg %s, got %q", alg, attestation.Alg)
+			}
+
+			payloadBytes, err := canonical.MarshalNamespacePayloadCanonical(attestation.SignedPayload())
+			if err != nil {
+				t.Fatal(err)
+			}
+			ok, err := crypto.VerifySignatureHex(attestation.Alg, attestation.Key, attestation.Sig, crypto.HashSHA256(payloadBytes))
+			if err != nil || !ok {
+				t.Errorf("Expected %s signature to verify, ok=%v err=%v", alg, ok, err)
+			}
+
+			// The key is stored per algorithm so the secp256k1 namespace key is untouched
+			if _, err := os.Stat(filepath.Join("keys", "namespace_key_"+alg+".json")); err != nil {
+				t.Errorf("Expected per-algorithm key file: %v", err)
+			}
+		})
+	}
+
+	// Default remains bip340 with no alg field
+	_, stderr, err := runLapctl(t, "na-create",
+		"-namespace", "https://example.com/people/erin/",
+		"-keys-dir", "keys")
+	if err != nil {
+		t.Fatalf("na-create failed: %v\nstderr: %s", err, stderr)
+	}
+	attestation := readNamespaceAttestation(t, "_la_namespace.json")
+	if attestation.Alg != "" {
+		t.Errorf("Expected default attestation to omit alg, got %q", attestation.Alg)
+	}
 }
 
 func TestRaCreate_DefaultBehavior(t *testing.T) {
@@ -492,3 +573,992 @@ func TestFragmentCreate_DefaultBehavior(t *testing.T) {
 	}
 }
 
+
+func TestRaCreate_HashAlgorithm(t *testing.T) {
+	tmpDir, cleanup := setupTestEnvironment(t)
+	defer cleanup()
+
+	originalDir, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("Failed to get current directory: %v", err)
+	}
+	defer os.Chdir(originalDir)
+
+	if err := os.Chdir(tmpDir); err != nil {
+		t.Fatalf("Failed to change to temp directory: %v", err)
+	}
+
+	testHTML := `<article><h1>Test Post</h1><p>Test content</p></article>`
+	if err := os.WriteFile("test.html", []byte(testHTML), 0644); err != nil {
+		t.Fatalf("Failed to create test HTML file: %v", err)
+	}
+
+	_, stderr, err := runLapctl(t, "ra-create",
+		"-in", "test.html",
+		"-url", "https://example.com/people/alice/frc/posts/1",
+		"-publisher-claim", "ac20898edf97b5a24c59749ec26ea7bc95cc1d2859ef6a194ceb7eeb2c709677",
+		"-namespace-attestation-url", "https://example.com/people/alice/_la_namespace.json",
+		"-hash", "sha512")
+	if err != nil {
+		t.Fatalf("ra-create failed: %v\nstderr: %s", err, stderr)
+	}
+
+	attestation := readResourceAttestation(t, "_la_resource.json")
+	if !strings.HasPrefix(attestation.Hash, "sha512:") {
+		t.Errorf("Expected hash to start with 'sha512:', got %s", attestation.Hash)
+	}
+	if len(attestation.Hash) != len("sha512:")+128 {
+		t.Errorf("Expected 128 hex chars after prefix, got %s", attestation.Hash)
+	}
+
+	// Unknown algorithms must be rejected
+	_, _, err = runLapctl(t, "ra-create",
+		"-in", "test.html",
+		"-url", "https://example.com/people/alice/frc/posts/1",
+		"-publisher-claim", "ac20898edf97b5a24c59749ec26ea7bc95cc1d2859ef6a194ceb7eeb2c709677",
+		"-namespace-attestation-url", "https://example.com/people/alice/_la_namespace.json",
+		"-hash", "md5",
+		"-out", "md5.json")
+	if err == nil {
+		t.Error("Expected ra-create to fail for unknown hash algorithm")
+	}
+}
+
+func TestRaCreate_Freshness(t *testing.T) {
+	tmpDir, cleanup := setupTestEnvironment(t)
+	defer cleanup()
+
+	originalDir, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("Failed to get current directory: %v", err)
+	}
+	defer os.Chdir(originalDir)
+
+	if err := os.Chdir(tmpDir); err != nil {
+		t.Fatalf("Failed to change to temp directory: %v", err)
+	}
+
+	testHTML := `<article><h1>Test Post</h1><p>Test content</p></article>`
+	if err := os.WriteFile("test.html", []byte(testHTML), 0644); err != nil {
+		t.Fatalf("Failed to create test HTML file: %v", err)
+	}
+
+	exp := time.Now().Add(24 * time.Hour).Unix()
+	_, stderr, err := runLapctl(t, "ra-create",
+		"-in", "test.html",
+		"-url", "https://example.com/people/alice/frc/posts/1",
+		"-publisher-claim", "ac20898edf97b5a24c59749ec26ea7bc95cc1d2859ef6a194ceb7eeb2c709677",
+		"-namespace-attestation-url", "https://example.com/people/alice/_la_namespace.json",
+		"-exp", strconv.FormatInt(exp, 10),
+		"-max-age", "300")
+	if err != nil {
+		t.Fatalf("ra-create failed: %v\nstderr: %s", err, stderr)
+	}
+
+	attestation := readResourceAttestation(t, "_la_resource.json")
+	if attestation.Exp != exp || attestation.MaxAge != 300 {
+		t.Errorf("Expected exp %d and max_age 300, got %d and %d", exp, attestation.Exp, attestation.MaxAge)
+	}
+	if attestation.IAT == 0 || attestation.IAT > exp {
+		t.Errorf("Expected iat to be set to the time of issue, got %d", attestation.IAT)
+	}
+
+	// An RA that is already expired is refused
+	_, _, err = runLapctl(t, "ra-create",
+		"-in", "test.html",
+		"-url", "https://example.com/people/alice/frc/posts/1",
+		"-publisher-claim", "ac20898edf97b5a24c59749ec26ea7bc95cc1d2859ef6a194ceb7eeb2c709677",
+		"-namespace-attestation-url", "https://example.com/people/alice/_la_namespace.json",
+		"-exp", "1000",
+		"-out", "expired.json")
+	if err == nil {
+		t.Error("Expected ra-create to fail for an exp in the past")
+	}
+}
+
+func TestRaCreate_MerkleProofs(t *testing.T) {
+	tmpDir, cleanup := setupTestEnvironment(t)
+	defer cleanup()
+
+	originalDir, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("Failed to get current directory: %v", err)
+	}
+	defer os.Chdir(originalDir)
+
+	if err := os.Chdir(tmpDir); err != nil {
+		t.Fatalf("Failed to change to temp directory: %v", err)
+	}
+
+	content := []byte(strings.Repeat("<p>A long article paragraph.</p>\n", 5000))
+	if err := os.WriteFile("test.html", content, 0644); err != nil {
+		t.Fatalf("Failed to create test HTML file: %v", err)
+	}
+
+	_, stderr, err := runLapctl(t, "ra-create",
+		"-in", "test.html",
+		"-url", "https://example.com/people/alice/frc/posts/1",
+		"-publisher-claim", "ac20898edf97b5a24c59749ec26ea7bc95cc1d2859ef6a194ceb7eeb2c709677",
+		"-namespace-attestation-url", "https://example.com/people/alice/_la_namespace.json",
+		"-hash", "merkle-sha256")
+	if err != nil {
+		t.Fatalf("ra-create failed: %v\nstderr: %s", err, stderr)
+	}
+
+	attestation := readResourceAttestation(t, "_la_resource.json")
+	if !strings.HasPrefix(attestation.Hash, "merkle-sha256:") {
+		t.Errorf("Expected hash to start with 'merkle-sha256:', got %s", attestation.Hash)
+	}
+	if attestation.Size != int64(len(content)) {
+		t.Errorf("Expected size %d, got %d", len(content), attestation.Size)
+	}
+
+	proofsFile, err := os.Open("_la_resource.proofs.json")
+	if err != nil {
+		t.Fatalf("Expected proof sidecar to be written: %v", err)
+	}
+	defer proofsFile.Close()
+	proofs, err := wire.DecodeMerkleProofs(proofsFile)
+	if err != nil {
+		t.Fatalf("Failed to decode proof sidecar: %v", err)
+	}
+	if proofs.Hash != attestation.Hash || proofs.Size != int64(len(content)) {
+		t.Errorf("Expected proofs for %s over %d bytes, got %s over %d", attestation.Hash, len(content), proofs.Hash, proofs.Size)
+	}
+	if want := (int64(len(content)) + proofs.ChunkSize - 1) / proofs.ChunkSize; int64(len(proofs.Proofs)) != want {
+		t.Errorf("Expected %d chunk proofs, got %d", want, len(proofs.Proofs))
+	}
+}
+
+func TestRaCreate_CanonicalizationProfile(t *testing.T) {
+	tmpDir, cleanup := setupTestEnvironment(t)
+	defer cleanup()
+
+	originalDir, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("Failed to get current directory: %v", err)
+	}
+	defer os.Chdir(originalDir)
+
+	if err := os.Chdir(tmpDir); err != nil {
+		t.Fatalf("Failed to change to temp directory: %v", err)
+	}
+
+	// CRLF line endings and a trailing newline, as left behind by a Windows checkout
+	testHTML := "<article>\r\n<h1>Test Post</h1>\r\n</article>\r\n"
+	if err := os.WriteFile("content.htmx", []byte(testHTML), 0644); err != nil {
+		t.Fatalf("Failed to create test content file: %v", err)
+	}
+	normalized := "<article>\n<h1>Test Post</h1>\n</article>"
+
+	_, stderr, err := runLapctl(t, "ra-create",
+		"-in", "content.htmx",
+		"-url", "https://example.com/people/alice/frc/posts/1",
+		"-publisher-claim", "ac20898edf97b5a24c59749ec26ea7bc95cc1d2859ef6a194ceb7eeb2c709677",
+		"-namespace-attestation-url", "https://example.com/people/alice/_la_namespace.json",
+		"-canon", "html-normalized")
+	if err != nil {
+		t.Fatalf("ra-create failed: %v\nstderr: %s", err, stderr)
+	}
+
+	attestation := readResourceAttestation(t, "_la_resource.json")
+	if attestation.Canonicalization != canonical.ProfileHTMLNormalized {
+		t.Errorf("Expected canonicalization 'html-normalized', got '%s'", attestation.Canonicalization)
+	}
+	if want := crypto.ComputeContentHashField([]byte(normalized)); attestation.Hash != want {
+		t.Errorf("Expected hash of normalized content %s, got %s", want, attestation.Hash)
+	}
+
+	_, stderr, err = runLapctl(t, "fragment-create",
+		"-in", "content.htmx",
+		"-url", "https://example.com/people/alice/frc/posts/1",
+		"-publisher-claim", "ac20898edf97b5a24c59749ec26ea7bc95cc1d2859ef6a194ceb7eeb2c709677",
+		"-resource-attestation-url", "https://example.com/people/alice/frc/posts/1/_la_resource.json",
+		"-namespace-attestation-url", "https://example.com/people/alice/_la_namespace.json",
+		"-canon", "html-normalized")
+	if err != nil {
+		t.Fatalf("fragment-create failed: %v\nstderr: %s", err, stderr)
+	}
+
+	fragmentHTML, err := os.ReadFile("index.htmx")
+	if err != nil {
+		t.Fatalf("Failed to read fragment: %v", err)
+	}
+	wantHref := "data:text/html;base64," + base64.StdEncoding.EncodeToString([]byte(normalized))
+	if !strings.Contains(string(fragmentHTML), wantHref) {
+		t.Errorf("Expected fragment to embed normalized content, got:\n%s", fragmentHTML)
+	}
+
+	// The raw profile keeps today's output, with no canonicalization field
+	_, stderr, err = runLapctl(t, "ra-create",
+		"-in", "content.htmx",
+		"-url", "https://example.com/people/alice/frc/posts/1",
+		"-publisher-claim", "ac20898edf97b5a24c59749ec26ea7bc95cc1d2859ef6a194ceb7eeb2c709677",
+		"-namespace-attestation-url", "https://example.com/people/alice/_la_namespace.json",
+		"-out", "raw.json")
+	if err != nil {
+		t.Fatalf("ra-create failed: %v\nstderr: %s", err, stderr)
+	}
+	rawBytes, err := os.ReadFile("raw.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(rawBytes), "canonicalization") {
+		t.Errorf("Expected raw profile to omit canonicalization field, got %s", rawBytes)
+	}
+}
+
+func TestFragmentCreate_StapledAttestations(t *testing.T) {
+	tmpDir, cleanup := setupTestEnvironment(t)
+	defer cleanup()
+
+	originalDir, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("Failed to get current directory: %v", err)
+	}
+	defer os.Chdir(originalDir)
+
+	if err := os.Chdir(tmpDir); err != nil {
+		t.Fatalf("Failed to change to temp directory: %v", err)
+	}
+
+	testHTML := `<article><h1>Test Post</h1><p>Test content</p></article>`
+	if err := os.WriteFile("test.html", []byte(testHTML), 0644); err != nil {
+		t.Fatalf("Failed to create test HTML file: %v", err)
+	}
+	raJSON := `{"fragment_url":"https://example.com/people/alice/frc/posts/1","hash":"sha256:00"}`
+	naJSON := `{"payload":{"namespace":"https://example.com/people/alice/","exp":1},"key":"aa","sig":"bb"}`
+	if err := os.WriteFile("ra.json", []byte(raJSON), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile("na.json", []byte(naJSON), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	_, stderr, err := runLapctl(t, "fragment-create",
+		"-in", "test.html",
+		"-url", "https://example.com/people/alice/frc/posts/1",
+		"-publisher-claim", "ac20898edf97b5a24c59749ec26ea7bc95cc1d2859ef6a194ceb7eeb2c709677",
+		"-resource-attestation-url", "https://example.com/people/alice/frc/posts/1/_la_resource.json",
+		"-namespace-attestation-url", "https://example.com/people/alice/_la_namespace.json",
+		"-staple-ra", "ra.json",
+		"-staple-na", "na.json")
+	if err != nil {
+		t.Fatalf("fragment-create failed: %v\nstderr: %s", err, stderr)
+	}
+
+	fragmentBytes, err := os.ReadFile("index.htmx")
+	if err != nil {
+		t.Fatalf("Failed to read fragment file: %v", err)
+	}
+	fragmentContent := string(fragmentBytes)
+
+	// Stapled JSON must be embedded byte for byte
+	if !strings.Contains(fragmentContent, `data-la-stapled="resource-attestation"`) || !strings.Contains(fragmentContent, ">"+raJSON+"</script>") {
+		t.Errorf("Expected fragment to staple the exact RA bytes, got:\n%s", fragmentContent)
+	}
+	if !strings.Contains(fragmentContent, `data-la-stapled="namespace-attestation"`) || !strings.Contains(fragmentContent, ">"+naJSON+"</script>") {
+		t.Errorf("Expected fragment to staple the exact NA bytes, got:\n%s", fragmentContent)
+	}
+	if !strings.Contains(fragmentContent, `data-la-stapled-at="`) {
+		t.Error("Expected fragment to record the staple time")
+	}
+
+	// Stapling requires both attestations
+	_, _, err = runLapctl(t, "fragment-create",
+		"-in", "test.html",
+		"-url", "https://example.com/people/alice/frc/posts/1",
+		"-publisher-claim", "ac20898edf97b5a24c59749ec26ea7bc95cc1d2859ef6a194ceb7eeb2c709677",
+		"-resource-attestation-url", "https://example.com/people/alice/frc/posts/1/_la_resource.json",
+		"-namespace-attestation-url", "https://example.com/people/alice/_la_namespace.json",
+		"-staple-ra", "ra.json",
+		"-out", "partial.htmx")
+	if err == nil {
+		t.Error("Expected fragment-create to fail with only -staple-ra")
+	}
+}
+
+func TestFragmentCreate_Recipients(t *testing.T) {
+	tmpDir, cleanup := setupTestEnvironment(t)
+	defer cleanup()
+
+	originalDir, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("Failed to get current directory: %v", err)
+	}
+	defer os.Chdir(originalDir)
+
+	if err := os.Chdir(tmpDir); err != nil {
+		t.Fatalf("Failed to change to temp directory: %v", err)
+	}
+
+	testHTML := `<article><h1>Members Post</h1><p>Secret content</p></article>`
+	if err := os.WriteFile("test.html", []byte(testHTML), 0644); err != nil {
+		t.Fatalf("Failed to create test HTML file: %v", err)
+	}
+	reader, readerPub, err := crypto.GenerateKeyPair()
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, otherPub, err := crypto.GenerateKeyPair()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, stderr, err := runLapctl(t, "fragment-create",
+		"-in", "test.html",
+		"-url", "https://example.com/people/alice/frc/posts/1",
+		"-publisher-claim", "ac20898edf97b5a24c59749ec26ea7bc95cc1d2859ef6a194ceb7eeb2c709677",
+		"-resource-attestation-url", "https://example.com/people/alice/frc/posts/1/_la_resource.json",
+		"-namespace-attestation-url", "https://example.com/people/alice/_la_namespace.json",
+		"-recipients", readerPub+","+otherPub)
+	if err != nil {
+		t.Fatalf("fragment-create failed: %v\nstderr: %s", err, stderr)
+	}
+
+	fragmentBytes, err := os.ReadFile("index.htmx")
+	if err != nil {
+		t.Fatalf("Failed to read fragment file: %v", err)
+	}
+	fragmentContent := string(fragmentBytes)
+	if strings.Contains(fragmentContent, "Secret content") {
+		t.Errorf("Expected fragment not to reveal the content, got:\n%s", fragmentContent)
+	}
+	if !strings.Contains(fragmentContent, `type="text/html"`) {
+		t.Error("Expected the link type to keep the plaintext media type")
+	}
+
+	// The sealed content opens to the exact input bytes for a recipient
+	prefix := `href="data:` + wire.EncryptedContentType + `;base64,`
+	start := strings.Index(fragmentContent, prefix)
+	if start < 0 {
+		t.Fatalf("Expected an encrypted data URL, got:\n%s", fragmentContent)
+	}
+	encoded := fragmentContent[start+len(prefix):]
+	encoded = encoded[:strings.Index(encoded, `"`)]
+	sealedJSON, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var sealed crypto.SealedContent
+	if err := json.Unmarshal(sealedJSON, &sealed); err != nil {
+		t.Fatal(err)
+	}
+	if len(sealed.Recipients) != 2 {
+		t.Errorf("Expected two recipients, got %d", len(sealed.Recipients))
+	}
+	plaintext, err := crypto.OpenContent(sealed, hex.EncodeToString(reader.Serialize()))
+	if err != nil {
+		t.Fatalf("OpenContent: %v", err)
+	}
+	if string(plaintext) != testHTML {
+		t.Errorf("Expected %q, got %q", testHTML, plaintext)
+	}
+
+	// Encrypted content cannot be referenced by URL
+	_, _, err = runLapctl(t, "fragment-create",
+		"-in", "test.html",
+		"-url", "https://example.com/people/alice/frc/posts/1",
+		"-publisher-claim", "ac20898edf97b5a24c59749ec26ea7bc95cc1d2859ef6a194ceb7eeb2c709677",
+		"-resource-attestation-url", "https://example.com/people/alice/frc/posts/1/_la_resource.json",
+		"-namespace-attestation-url", "https://example.com/people/alice/_la_namespace.json",
+		"-content-url", "/people/alice/frc/posts/1/content.html",
+		"-recipients", readerPub,
+		"-out", "referenced.htmx")
+	if err == nil {
+		t.Error("Expected fragment-create to reject -recipients with -content-url")
+	}
+}
+
+func TestCreate_NonHTMLContent(t *testing.T) {
+	tmpDir, cleanup := setupTestEnvironment(t)
+	defer cleanup()
+
+	originalDir, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("Failed to get current directory: %v", err)
+	}
+	defer os.Chdir(originalDir)
+
+	if err := os.Chdir(tmpDir); err != nil {
+		t.Fatalf("Failed to change to temp directory: %v", err)
+	}
+
+	if err := os.WriteFile("photo.png", []byte("\x89PNG\r\n\x1a\nimage bytes"), 0644); err != nil {
+		t.Fatalf("Failed to create test image file: %v", err)
+	}
+
+	_, stderr, err := runLapctl(t, "ra-create",
+		"-in", "photo.png",
+		"-url", "https://example.com/people/alice/frc/photos/1.png",
+		"-publisher-claim", "ac20898edf97b5a24c59749ec26ea7bc95cc1d2859ef6a194ceb7eeb2c709677",
+		"-namespace-attestation-url", "https://example.com/people/alice/_la_namespace.json",
+		"-content-type", "image/png",
+		"-out", "photo.png._la_resource.json")
+	if err != nil {
+		t.Fatalf("ra-create failed: %v\nstderr: %s", err, stderr)
+	}
+	if attestation := readResourceAttestation(t, "photo.png._la_resource.json"); attestation.ContentType != "image/png" {
+		t.Errorf("Expected content_type 'image/png', got '%s'", attestation.ContentType)
+	}
+
+	_, stderr, err = runLapctl(t, "fragment-create",
+		"-in", "photo.png",
+		"-url", "https://example.com/people/alice/frc/photos/1.png",
+		"-publisher-claim", "ac20898edf97b5a24c59749ec26ea7bc95cc1d2859ef6a194ceb7eeb2c709677",
+		"-resource-attestation-url", "https://example.com/people/alice/frc/photos/1.png._la_resource.json",
+		"-namespace-attestation-url", "https://example.com/people/alice/_la_namespace.json",
+		"-content-type", "image/png",
+		"-content-url", "https://example.com/people/alice/frc/photos/1.png")
+	if err != nil {
+		t.Fatalf("fragment-create failed: %v\nstderr: %s", err, stderr)
+	}
+
+	fragmentBytes, err := os.ReadFile("index.htmx")
+	if err != nil {
+		t.Fatalf("Failed to read fragment file: %v", err)
+	}
+	fragmentContent := string(fragmentBytes)
+	if !strings.Contains(fragmentContent, `type="image/png"`) {
+		t.Errorf("Expected fragment to declare the content type, got:\n%s", fragmentContent)
+	}
+	if !strings.Contains(fragmentContent, `href="https://example.com/people/alice/frc/photos/1.png"`) {
+		t.Errorf("Expected fragment to reference the content URL, got:\n%s", fragmentContent)
+	}
+	if strings.Contains(fragmentContent, "data:image/png;base64,") {
+		t.Error("Expected content referenced by URL not to be embedded")
+	}
+}
+
+func TestExcerptCreate_BlockCommitment(t *testing.T) {
+	tmpDir, cleanup := setupTestEnvironment(t)
+	defer cleanup()
+
+	originalDir, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("Failed to get current directory: %v", err)
+	}
+	defer os.Chdir(originalDir)
+
+	if err := os.Chdir(tmpDir); err != nil {
+		t.Fatalf("Failed to change to temp directory: %v", err)
+	}
+
+	content := []byte("<h1>Title</h1>\n<p>First paragraph.</p>\n<p>Second paragraph.</p>\n")
+	if err := os.WriteFile("test.html", content, 0644); err != nil {
+		t.Fatalf("Failed to create test HTML file: %v", err)
+	}
+
+	_, stderr, err := runLapctl(t, "ra-create",
+		"-in", "test.html",
+		"-url", "https://example.com/people/alice/frc/posts/1",
+		"-publisher-claim", "ac20898edf97b5a24c59749ec26ea7bc95cc1d2859ef6a194ceb7eeb2c709677",
+		"-namespace-attestation-url", "https://example.com/people/alice/_la_namespace.json",
+		"-blocks")
+	if err != nil {
+		t.Fatalf("ra-create failed: %v\nstderr: %s", err, stderr)
+	}
+	attestation := readResourceAttestation(t, "_la_resource.json")
+	if !strings.HasPrefix(attestation.BlockRoot, "merkle-sha256:") {
+		t.Errorf("Expected block_root to start with 'merkle-sha256:', got %q", attestation.BlockRoot)
+	}
+	if attestation.BlockCount == 0 {
+		t.Error("Expected block_count to be set with block_root")
+	}
+
+	_, stderr, err = runLapctl(t, "excerpt-create",
+		"-in", "test.html",
+		"-url", "https://example.com/people/alice/frc/posts/1",
+		"-block", "2",
+		"-publisher-claim", "ac20898edf97b5a24c59749ec26ea7bc95cc1d2859ef6a194ceb7eeb2c709677",
+		"-resource-attestation-url", "https://example.com/people/alice/frc/posts/1/_la_resource.json",
+		"-namespace-attestation-url", "https://example.com/people/alice/_la_namespace.json")
+	if err != nil {
+		t.Fatalf("excerpt-create failed: %v\nstderr: %s", err, stderr)
+	}
+	excerpt, err := os.ReadFile("excerpt.htmx")
+	if err != nil {
+		t.Fatalf("Expected excerpt to be written: %v", err)
+	}
+	for _, want := range []string{`data-la-excerpt-of="https://example.com/people/alice/frc/posts/1"`, `data-la-block-index="2"`, `data-la-block-count="3"`, "Second paragraph."} {
+		if !strings.Contains(string(excerpt), want) {
+			t.Errorf("Expected excerpt to contain %s", want)
+		}
+	}
+
+	_, _, err = runLapctl(t, "excerpt-create",
+		"-in", "test.html",
+		"-url", "https://example.com/people/alice/frc/posts/1",
+		"-block", "3",
+		"-publisher-claim", "ac20898edf97b5a24c59749ec26ea7bc95cc1d2859ef6a194ceb7eeb2c709677",
+		"-resource-attestation-url", "https://example.com/people/alice/frc/posts/1/_la_resource.json",
+		"-namespace-attestation-url", "https://example.com/people/alice/_la_namespace.json")
+	if err == nil {
+		t.Error("Expected excerpt-create to fail for a block past the end")
+	}
+}
+
+func TestRaCreate_PreviousVersion(t *testing.T) {
+	tmpDir, cleanup := setupTestEnvironment(t)
+	defer cleanup()
+
+	originalDir, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("Failed to get current directory: %v", err)
+	}
+	defer os.Chdir(originalDir)
+
+	if err := os.Chdir(tmpDir); err != nil {
+		t.Fatalf("Failed to change to temp directory: %v", err)
+	}
+
+	raCreate := func(content string, extra ...string) {
+		t.Helper()
+		if err := os.WriteFile("test.html", []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to create test HTML file: %v", err)
+		}
+		args := append([]string{"ra-create",
+			"-in", "test.html",
+			"-url", "https://example.com/people/alice/frc/posts/1",
+			"-publisher-claim", "ac20898edf97b5a24c59749ec26ea7bc95cc1d2859ef6a194ceb7eeb2c709677",
+			"-namespace-attestation-url", "https://example.com/people/alice/_la_namespace.json"}, extra...)
+		if _, stderr, err := runLapctl(t, args...); err != nil {
+			t.Fatalf("ra-create failed: %v\nstderr: %s", err, stderr)
+		}
+	}
+
+	raCreate("<p>First version</p>")
+	first := readResourceAttestation(t, "_la_resource.json")
+	if first.Version != 0 || len(first.PreviousHashes) != 0 {
+		t.Errorf("Expected an unversioned RA, got version %d with %d previous hashes", first.Version, len(first.PreviousHashes))
+	}
+
+	raCreate("<p>Second version</p>", "-previous", "_la_resource.json")
+	second := readResourceAttestation(t, "_la_resource.json")
+	if second.Version != 2 || len(second.PreviousHashes) != 1 || second.PreviousHashes[0] != first.Hash {
+		t.Errorf("Expected version 2 superseding %s, got version %d with %v", first.Hash, second.Version, second.PreviousHashes)
+	}
+
+	// Re-attesting unchanged content keeps the version
+	raCreate("<p>Second version</p>", "-previous", "_la_resource.json")
+	again := readResourceAttestation(t, "_la_resource.json")
+	if again.Version != 2 || len(again.PreviousHashes) != 1 {
+		t.Errorf("Expected version 2 to be kept, got version %d with %v", again.Version, again.PreviousHashes)
+	}
+
+	raCreate("<p>Third version</p>", "-previous", "_la_resource.json")
+	third := readResourceAttestation(t, "_la_resource.json")
+	if third.Version != 3 || len(third.PreviousHashes) != 2 || third.PreviousHashes[0] != second.Hash || third.PreviousHashes[1] != first.Hash {
+		t.Errorf("Expected version 3 with history [%s %s], got version %d with %v", second.Hash, first.Hash, third.Version, third.PreviousHashes)
+	}
+}
+
+func TestRaCreate_InReplyTo(t *testing.T) {
+	tmpDir, cleanup := setupTestEnvironment(t)
+	defer cleanup()
+
+	originalDir, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("Failed to get current directory: %v", err)
+	}
+	defer os.Chdir(originalDir)
+
+	if err := os.Chdir(tmpDir); err != nil {
+		t.Fatalf("Failed to change to temp directory: %v", err)
+	}
+
+	parent := wire.ResourceAttestation{
+		FragmentURL:             "https://alice.example/people/alice/frc/posts/1",
+		Hash:                    "sha256:7b0c2a3f0e1d4c5b6a798897a6b5c4d3e2f1a0b9c8d7e6f5a4b3c2d1e0f9cafe",
+		PublisherClaim:          "f1a2d3c4e5f60718293a4b5c6d7e8f90112233445566778899aabbccddeeff00",
+		NamespaceAttestationURL: "https://alice.example/people/alice/_la_namespace.json",
+	}
+	parentJSON, err := json.Marshal(parent)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile("parent.json", parentJSON, 0644); err != nil {
+		t.Fatalf("Failed to write parent RA: %v", err)
+	}
+	if err := os.WriteFile("test.html", []byte("<p>As you wish.</p>"), 0644); err != nil {
+		t.Fatalf("Failed to create test HTML file: %v", err)
+	}
+
+	_, stderr, err := runLapctl(t, "ra-create",
+		"-in", "test.html",
+		"-url", "https://westley.example/people/westley/frc/posts/9",
+		"-publisher-claim", "ac20898edf97b5a24c59749ec26ea7bc95cc1d2859ef6a194ceb7eeb2c709677",
+		"-namespace-attestation-url", "https://westley.example/people/westley/_la_namespace.json",
+		"-in-reply-to", "parent.json")
+	if err != nil {
+		t.Fatalf("ra-create failed: %v\nstderr: %s", err, stderr)
+	}
+
+	attestation := readResourceAttestation(t, "_la_resource.json")
+	if attestation.InReplyTo == nil || attestation.InReplyTo.FragmentURL != parent.FragmentURL || attestation.InReplyTo.Hash != parent.Hash {
+		t.Errorf("Expected in_reply_to %s %s, got %+v", parent.FragmentURL, parent.Hash, attestation.InReplyTo)
+	}
+}
+
+func TestStatementCreate(t *testing.T) {
+	tmpDir, cleanup := setupTestEnvironment(t)
+	defer cleanup()
+
+	originalDir, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("Failed to get current directory: %v", err)
+	}
+	defer os.Chdir(originalDir)
+
+	if err := os.Chdir(tmpDir); err != nil {
+		t.Fatalf("Failed to change to temp directory: %v", err)
+	}
+
+	// The issuer's NA stores the namespace key in demo-keys, which statement-create then signs with
+	_, stderr, err := runLapctl(t, "na-create", "-namespace", "https://bob.example/people/bob/")
+	if err != nil {
+		t.Fatalf("na-create failed: %v\nstderr: %s", err, stderr)
+	}
+
+	subject := wire.ResourceAttestation{
+		FragmentURL:             "https://alice.example/people/alice/frc/posts/1",
+		Hash:                    "sha256:7b0c2a3f0e1d4c5b6a798897a6b5c4d3e2f1a0b9c8d7e6f5a4b3c2d1e0f9cafe",
+		PublisherClaim:          "f1a2d3c4e5f60718293a4b5c6d7e8f90112233445566778899aabbccddeeff00",
+		NamespaceAttestationURL: "https://alice.example/people/alice/_la_namespace.json",
+	}
+	subjectJSON, err := json.Marshal(subject)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile("subject.json", subjectJSON, 0644); err != nil {
+		t.Fatalf("Failed to write subject RA: %v", err)
+	}
+
+	naURL := "https://bob.example/people/bob/_la_namespace.json"
+	for _, statementType := range []string{wire.StatementEndorse, wire.StatementLabel} {
+		_, stderr, err := runLapctl(t, "statement-create",
+			"-subject", "subject.json",
+			"-type", statementType,
+			"-body", "satire",
+			"-namespace-attestation-url", naURL)
+		if err != nil {
+			t.Fatalf("statement-create failed: %v\nstderr: %s", err, stderr)
+		}
+	}
+
+	f, err := os.Open(wire.StatementsFileName)
+	if err != nil {
+		t.Fatalf("Failed to open statement index: %v", err)
+	}
+	defer f.Close()
+	index, err := wire.DecodeStatementIndex(f)
+	if err != nil {
+		t.Fatalf("Failed to decode statement index: %v", err)
+	}
+	if len(index.Statements) != 2 {
+		t.Fatalf("Expected 2 statements, got %d", len(index.Statements))
+	}
+
+	na := readNamespaceAttestation(t, "_la_namespace.json")
+	for i, st := range index.Statements {
+		if st.Payload.FragmentURL != subject.FragmentURL || st.Payload.Hash != subject.Hash {
+			t.Errorf("Statement %d: unexpected subject %s %s", i, st.Payload.FragmentURL, st.Payload.Hash)
+		}
+		if res := verify.VerifyStatement(st, subject, *na, naURL, verify.Options{}); res.Status != "pass" {
+			t.Errorf("Statement %d did not verify: %+v", i, res.Failure)
+		}
+	}
+
+	_, _, err = runLapctl(t, "statement-create",
+		"-subject", "subject.json",
+		"-type", "like",
+		"-namespace-attestation-url", naURL)
+	if err == nil {
+		t.Error("Expected statement-create to reject an unknown statement type")
+	}
+}
+
+func TestRaCosign_CoPublisher(t *testing.T) {
+	tmpDir, cleanup := setupTestEnvironment(t)
+	defer cleanup()
+
+	originalDir, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("Failed to get current directory: %v", err)
+	}
+	defer os.Chdir(originalDir)
+
+	if err := os.Chdir(tmpDir); err != nil {
+		t.Fatalf("Failed to change to temp directory: %v", err)
+	}
+
+	// Bob's namespace key ends up in demo-keys, which ra-cosign then signs with
+	_, stderr, err := runLapctl(t, "na-create", "-namespace", "https://bob.example/people/bob/")
+	if err != nil {
+		t.Fatalf("na-create failed: %v\nstderr: %s", err, stderr)
+	}
+	bobNA := readNamespaceAttestation(t, "_la_namespace.json")
+
+	if err := os.WriteFile("test.html", []byte("<p>Written together.</p>"), 0644); err != nil {
+		t.Fatalf("Failed to create test HTML file: %v", err)
+	}
+	_, stderr, err = runLapctl(t, "ra-create",
+		"-in", "test.html",
+		"-url", "https://alice.example/people/alice/frc/posts/7",
+		"-publisher-claim", "f1a2d3c4e5f60718293a4b5c6d7e8f90112233445566778899aabbccddeeff00",
+		"-namespace-attestation-url", "https://alice.example/people/alice/_la_namespace.json")
+	if err != nil {
+		t.Fatalf("ra-create failed: %v\nstderr: %s", err, stderr)
+	}
+
+	bobNAURL := "https://bob.example/people/bob/_la_namespace.json"
+	for i := 0; i < 2; i++ {
+		_, stderr, err = runLapctl(t, "ra-cosign", "-ra", "_la_resource.json", "-namespace-attestation-url", bobNAURL)
+		if err != nil {
+			t.Fatalf("ra-cosign failed: %v\nstderr: %s", err, stderr)
+		}
+	}
+
+	// Signing twice replaces the entry rather than listing Bob again
+	attestation := readResourceAttestation(t, "_la_resource.json")
+	if len(attestation.CoPublishers) != 1 {
+		t.Fatalf("Expected one co-publisher, got %+v", attestation.CoPublishers)
+	}
+	cp := attestation.CoPublishers[0]
+	if cp.PublisherClaim != bobNA.Key || cp.NamespaceAttestationURL != bobNAURL {
+		t.Errorf("Unexpected co-publisher %+v", cp)
+	}
+	payloadBytes, err := canonical.MarshalCoPublisherPayloadCanonical(attestation.CoPublisherPayload())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ok, err := crypto.VerifySignatureHex(bobNA.Alg, bobNA.Key, cp.Sig, crypto.HashSHA256(payloadBytes)); err != nil || !ok {
+		t.Errorf("Co-signature does not verify with Bob's key: %v", err)
+	}
+
+	_, stderr, err = runLapctl(t, "fragment-create",
+		"-in", "test.html",
+		"-url", attestation.FragmentURL,
+		"-publisher-claim", attestation.PublisherClaim,
+		"-resource-attestation-url", attestation.FragmentURL+"/_la_resource.json",
+		"-namespace-attestation-url", attestation.NamespaceAttestationURL,
+		"-co-publishers", "_la_resource.json",
+		"-out", "index.htmx")
+	if err != nil {
+		t.Fatalf("fragment-create failed: %v\nstderr: %s", err, stderr)
+	}
+	fragment, err := os.ReadFile("index.htmx")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `data-la-co-publishers="` + bobNA.Key + " " + bobNAURL + `"`; !strings.Contains(string(fragment), want) {
+		t.Errorf("Expected fragment to contain %s, got:\n%s", want, fragment)
+	}
+}
+
+// writeV01Message writes an archived v0.1 message page for url under dir, stapling an RA signed by a
+// new resource key over content
+func writeV01Message(t *testing.T, dir, url string, content []byte) {
+	t.Helper()
+	resourcePriv, resourceKey, err := crypto.GenerateKeyPair()
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now().Unix()
+	ra := wire.ResourceAttestationV01{
+		Payload: wire.ResourcePayloadV01{
+			URL:            url,
+			AttestationURL: url + "/_la_resource.json",
+			Hash:           crypto.ComputeContentHashField(content),
+			ETag:           `W/"1-abc"`,
+			IAT:            now,
+			Exp:            now + 600,
+			KID:            "resource-key-1",
+		},
+		ResourceKey: resourceKey,
+	}
+	payloadBytes, err := canonical.MarshalResourcePayloadV01Canonical(ra.Payload.ToCanonical())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ra.Sig, err = crypto.SignSchnorrHex(resourcePriv, crypto.HashSHA256(payloadBytes)); err != nil {
+		t.Fatal(err)
+	}
+	stapled, err := json.Marshal(ra)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	page := `<article id="m" data-lap-spec="https://lap.dev/spec/v0-1" data-lap-profile="fragment"
+    data-lap-attestation-format="script" data-lap-bytes-format="link-data" data-lap-url="` + url + `"
+    data-lap-preview="#m-preview" data-lap-attestation="#m-attestation" data-lap-bytes="#m-bytes">
+    <script id="m-attestation" type="application/lap+json" class="lap-attestation">` + string(stapled) + `</script>
+    <div id="m-preview" class="preview">` + string(content) + `</div>
+    <link id="m-bytes" rel="alternate" type="text/html; charset=utf-8" class="lap-bytes"
+        href="data:text/html;base64,` + base64.StdEncoding.EncodeToString(content) + `" />
+</article>`
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "index.html"), []byte(page), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "_la_resource.json"), append(stapled, '\n'), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestMigrate_FromV01(t *testing.T) {
+	tmpDir, cleanup := setupTestEnvironment(t)
+	defer cleanup()
+
+	originalDir, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("Failed to get current directory: %v", err)
+	}
+	defer os.Chdir(originalDir)
+
+	if err := os.Chdir(tmpDir); err != nil {
+		t.Fatalf("Failed to change to temp directory: %v", err)
+	}
+
+	// The publisher's key is in the keys directory
+	publisherPriv, publisherKey, err := crypto.GenerateKeyPair()
+	if err != nil {
+		t.Fatal(err)
+	}
+	stored, _ := json.Marshal(map[string]interface{}{
+		"privkey_hex":      hex.EncodeToString(publisherPriv.Serialize()),
+		"pubkey_xonly_hex": publisherKey,
+		"created_at":       time.Now().Unix(),
+	})
+	if err := os.WriteFile(filepath.Join("demo-keys", "alice_publisher_key.json"), stored, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	// A v0.1 site: an NA, one message and one whose bytes were edited after attestation
+	na := wire.NamespaceAttestationV01{
+		Payload: wire.NamespacePayloadV01{
+			Namespace:       []string{"https://example.com/people/alice/"},
+			AttestationPath: "/people/alice/_la_namespace.json",
+			IAT:             time.Now().Unix(),
+			Exp:             time.Now().Add(time.Hour).Unix(),
+		},
+		PublisherKey: publisherKey,
+	}
+	payloadBytes, err := canonical.MarshalNamespacePayloadV01Canonical(na.Payload.ToCanonical())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if na.Sig, err = crypto.SignSchnorrHex(publisherPriv, crypto.HashSHA256(payloadBytes)); err != nil {
+		t.Fatal(err)
+	}
+	naJSON, _ := json.Marshal(na)
+	if err := os.MkdirAll(filepath.Join("site", "people", "alice"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join("site", "people", "alice", "_la_namespace.json"), naJSON, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	content := []byte(`<span id="msg-1">Hello, my name is Alice.</span>`)
+	writeV01Message(t, filepath.Join("site", "people", "alice", "messages", "1"), "https://example.com/people/alice/messages/1", content)
+	editedDir := filepath.Join("site", "people", "alice", "messages", "2")
+	writeV01Message(t, editedDir, "https://example.com/people/alice/messages/2", []byte(`<span>Original</span>`))
+	page, _ := os.ReadFile(filepath.Join(editedDir, "index.html"))
+	page = []byte(strings.Replace(string(page), base64.StdEncoding.EncodeToString([]byte(`<span>Original</span>`)), base64.StdEncoding.EncodeToString([]byte(`<span>Edited</span>`)), 1))
+	if err := os.WriteFile(filepath.Join(editedDir, "index.html"), page, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// The edited message is reported, so the command exits non-zero
+	_, stderr, err := runLapctl(t, "migrate", "-from", "v0.1", "-root", "site", "-report", "report.json")
+	if err == nil {
+		t.Fatalf("Expected migrate to report the unconvertible message\nstderr: %s", stderr)
+	}
+
+	reportJSON, err := os.ReadFile("report.json")
+	if err != nil {
+		t.Fatalf("Failed to read report: %v\nstderr: %s", err, stderr)
+	}
+	var report struct {
+		Namespaces []struct{ URL string }         `json:"namespaces"`
+		Resources  []struct{ URL string }         `json:"resources"`
+		Skipped    []struct{ URL, Reason string } `json:"skipped"`
+	}
+	if err := json.Unmarshal(reportJSON, &report); err != nil {
+		t.Fatal(err)
+	}
+	if len(report.Namespaces) != 1 || len(report.Resources) != 1 || report.Resources[0].URL != "https://example.com/people/alice/messages/1" {
+		t.Fatalf("Unexpected report: %s", reportJSON)
+	}
+	if len(report.Skipped) != 1 || report.Skipped[0].URL != "https://example.com/people/alice/messages/2" {
+		t.Fatalf("Expected the edited message to be skipped, got %s", reportJSON)
+	}
+
+	// The v0.2 artifacts are signed by the same publisher and verify together
+	v02NA := readNamespaceAttestation(t, filepath.Join("site", "people", "alice", "_la_namespace.json"))
+	if v02NA.Key != publisherKey || v02NA.Payload.Namespace != "https://example.com/people/alice/" {
+		t.Errorf("Unexpected v0.2 namespace attestation: %+v", v02NA)
+	}
+	messageDir := filepath.Join("site", "people", "alice", "messages", "1")
+	ra := readResourceAttestation(t, filepath.Join(messageDir, "_la_resource.json"))
+	fragmentHTML, err := os.ReadFile(filepath.Join(messageDir, "index.htmx"))
+	if err != nil {
+		t.Fatalf("Failed to read migrated fragment: %v", err)
+	}
+	if !strings.Contains(string(fragmentHTML), `data-la-spec="v0.2"`) {
+		t.Errorf("Expected a v0.2 fragment, got:\n%s", fragmentHTML)
+	}
+	fragment := wire.Fragment{
+		Spec:                    "v0.2",
+		FragmentURL:             "https://example.com/people/alice/messages/1",
+		CanonicalContent:        content,
+		PublisherClaim:          publisherKey,
+		ResourceAttestationURL:  "https://example.com/people/alice/messages/1/_la_resource.json",
+		NamespaceAttestationURL: "https://example.com/people/alice/_la_namespace.json",
+	}
+	if result := verify.VerifyFragment(fragment, *ra, *v02NA); !result.Verified {
+		t.Errorf("Expected migrated artifacts to verify, got %+v", result.Failure)
+	}
+
+	// The replaced v0.1 attestations are kept
+	for _, path := range []string{filepath.Join("site", "people", "alice", "_la_namespace.json.bak"), filepath.Join(messageDir, "_la_resource.json.bak")} {
+		if _, err := os.Stat(path); err != nil {
+			t.Errorf("Expected backup %s: %v", path, err)
+		}
+	}
+
+	// A second run finds the v0.2 NA and re-attests the v0.1 message under it, keeping the v0.1 backups
+	if err := os.RemoveAll(editedDir); err != nil {
+		t.Fatal(err)
+	}
+	if _, stderr, err := runLapctl(t, "migrate", "-from", "v0.1", "-root", "site", "-report", "report.json"); err != nil {
+		t.Fatalf("Expected a second migration to succeed: %v\nstderr: %s", err, stderr)
+	}
+	reportJSON, err = os.ReadFile("report.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	report.Namespaces, report.Resources, report.Skipped = nil, nil, nil
+	if err := json.Unmarshal(reportJSON, &report); err != nil {
+		t.Fatal(err)
+	}
+	if len(report.Namespaces) != 0 || len(report.Resources) != 1 || len(report.Skipped) != 0 {
+		t.Fatalf("Unexpected report for the second run: %s", reportJSON)
+	}
+	if rerunNA := readNamespaceAttestation(t, filepath.Join("site", "people", "alice", "_la_namespace.json")); rerunNA.Sig != v02NA.Sig {
+		t.Errorf("Expected the v0.2 namespace attestation to be left alone, got %+v", rerunNA)
+	}
+	backup, err := os.ReadFile(filepath.Join(messageDir, "_la_resource.json.bak"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := wire.DecodeResourceAttestationV01(bytes.NewReader(backup)); err != nil {
+		t.Errorf("Expected the backup to still hold the v0.1 attestation: %v", err)
+	}
+	ra = readResourceAttestation(t, filepath.Join(messageDir, "_la_resource.json"))
+	if result := verify.VerifyFragment(fragment, *ra, *v02NA); !result.Verified {
+		t.Errorf("Expected re-migrated artifacts to verify, got %+v", result.Failure)
+	}
+}
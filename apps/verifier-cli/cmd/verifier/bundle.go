@@ -0,0 +1,345 @@
+// Copyright 2025 Jason Stonebraker
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/stonebraker/lap/sdks/go/pkg/lap/crypto"
+	"github.com/stonebraker/lap/sdks/go/pkg/lap/fetch"
+	"github.com/stonebraker/lap/sdks/go/pkg/lap/verify"
+	"github.com/stonebraker/lap/sdks/go/pkg/lap/wire"
+)
+
+// bundleFormat identifies the evidence bundle layout
+const bundleFormat = "lap-evidence-bundle/v1"
+
+// maxBundleBodySize bounds each captured response body
+const maxBundleBodySize = 10 << 20
+
+// EvidenceBundle is a signed record of everything a verifier fetched and concluded.
+// Payload holds the exact EvidencePayload JSON bytes that were signed (base64 in JSON),
+// so reformatting the bundle file cannot invalidate the signature.
+type EvidenceBundle struct {
+	Format  string `json:"format"`
+	Payload []byte `json:"payload"`
+	Alg     string `json:"alg"` // Signature algorithm of the verifier key
+	Key     string `json:"key"` // Verifier public key hex
+	Sig     string `json:"sig"` // Signature over SHA256(payload)
+}
+
+// EvidencePayload is the signed content of an EvidenceBundle
+type EvidencePayload struct {
+	ResourceURL string                     `json:"resource_url"`
+	FetchedAt   int64                      `json:"fetched_at"`
+	Exchanges   []HTTPExchange             `json:"exchanges"`
+	Result      *verify.VerificationResult `json:"result"`
+}
+
+// HTTPExchange is one HTTP response captured during verification
+type HTTPExchange struct {
+	URL        string      `json:"url"`
+	OriginURL  string      `json:"origin_url"` // URL first requested when this response ended a redirect chain
+	StatusCode int         `json:"status_code"`
+	Headers    http.Header `json:"headers"`
+	Body       []byte      `json:"body"` // Exact response bytes (base64 in JSON)
+	FetchedAt  int64       `json:"fetched_at"`
+}
+
+// BundleCheck is the outcome of re-checking an evidence bundle
+type BundleCheck struct {
+	SignatureValid bool                       `json:"signature_valid"`
+	VerifierKey    string                     `json:"verifier_key"`
+	ResourceURL    string                     `json:"resource_url"`
+	FetchedAt      int64                      `json:"fetched_at"`
+	Original       *verify.VerificationResult `json:"original"`
+	Result         *verify.VerificationResult `json:"result"`
+}
+
+// exchangeRecorder is an http.RoundTripper that captures every response it relays
+type exchangeRecorder struct {
+	base      http.RoundTripper
+	mu        sync.Mutex
+	exchanges []HTTPExchange
+}
+
+// newExchangeRecorder wraps base, or http.DefaultTransport when base is nil
+func newExchangeRecorder(base http.RoundTripper) *exchangeRecorder {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &exchangeRecorder{base: base}
+}
+
+// RoundTrip performs the request and records the response bytes and headers
+func (r *exchangeRecorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := r.base.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxBundleBodySize))
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+
+	// Redirected requests carry the response that caused them; walk back to the first request
+	origin := req
+	for origin.Response != nil && origin.Response.Request != nil {
+		origin = origin.Response.Request
+	}
+
+	r.mu.Lock()
+	r.exchanges = append(r.exchanges, HTTPExchange{
+		URL:        req.URL.String(),
+		OriginURL:  origin.URL.String(),
+		StatusCode: resp.StatusCode,
+		Headers:    resp.Header.Clone(),
+		Body:       body,
+		FetchedAt:  time.Now().Unix(),
+	})
+	r.mu.Unlock()
+	return resp, nil
+}
+
+// Exchanges returns the responses recorded so far
+func (r *exchangeRecorder) Exchanges() []HTTPExchange {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]HTTPExchange(nil), r.exchanges...)
+}
+
+// findExchange returns the final response of the redirect chain that started at target
+func findExchange(exchanges []HTTPExchange, target string) (*HTTPExchange, bool) {
+	for i := len(exchanges) - 1; i >= 0; i-- {
+		if sameURL(exchanges[i].OriginURL, target) {
+			return &exchanges[i], true
+		}
+	}
+	return nil, false
+}
+
+// sameURL compares two URLs after parsing, so equivalent encodings match
+func sameURL(a, b string) bool {
+	ua, errA := url.Parse(a)
+	ub, errB := url.Parse(b)
+	if errA != nil || errB != nil {
+		return a == b
+	}
+	return ua.String() == ub.String()
+}
+
+// sameOrigin reports whether two URLs share scheme and host
+func sameOrigin(a, b string) bool {
+	ua, errA := url.Parse(a)
+	ub, errB := url.Parse(b)
+	return errA == nil && errB == nil && fetch.SameOrigin(ua, ub)
+}
+
+// CreateEvidenceBundle signs the captured exchanges and verification result with the verifier key
+func CreateEvidenceBundle(resourceURL string, exchanges []HTTPExchange, result *verify.VerificationResult, signer crypto.Signer) (*EvidenceBundle, error) {
+	payload := EvidencePayload{
+		ResourceURL: resourceURL,
+		FetchedAt:   time.Now().Unix(),
+		Exchanges:   exchanges,
+		Result:      result,
+	}
+	if len(exchanges) > 0 {
+		payload.FetchedAt = exchanges[0].FetchedAt
+	}
+
+	payloadBytes, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("marshal payload: %w", err)
+	}
+	sig, err := signer.SignHex(crypto.HashSHA256(payloadBytes))
+	if err != nil {
+		return nil, fmt.Errorf("sign payload: %w", err)
+	}
+
+	return &EvidenceBundle{
+		Format:  bundleFormat,
+		Payload: payloadBytes,
+		Alg:     signer.Algorithm(),
+		Key:     signer.PublicKeyHex(),
+		Sig:     sig,
+	}, nil
+}
+
+// CheckEvidenceBundle verifies the bundle signature and re-runs integrity and signature checks
+// from the captured bytes. Time-dependent checks are evaluated at the original fetch time.
+func CheckEvidenceBundle(data []byte, opts VerificationOptions) (*BundleCheck, error) {
+	var bundle EvidenceBundle
+	if err := json.Unmarshal(data, &bundle); err != nil {
+		return nil, fmt.Errorf("invalid bundle: %w", err)
+	}
+	if bundle.Format != bundleFormat {
+		return nil, fmt.Errorf("unsupported bundle format: %q", bundle.Format)
+	}
+
+	var payload EvidencePayload
+	if err := json.Unmarshal(bundle.Payload, &payload); err != nil {
+		return nil, fmt.Errorf("invalid bundle payload: %w", err)
+	}
+
+	check := &BundleCheck{
+		VerifierKey: bundle.Key,
+		ResourceURL: payload.ResourceURL,
+		FetchedAt:   payload.FetchedAt,
+		Original:    payload.Result,
+	}
+	ok, err := crypto.VerifySignatureHex(bundle.Alg, bundle.Key, bundle.Sig, crypto.HashSHA256(bundle.Payload))
+	check.SignatureValid = ok && err == nil
+
+	check.Result = recheckExchanges(payload, opts)
+	return check, nil
+}
+
+// recheckExchanges verifies the fragment from the captured page against the captured attestations
+func recheckExchanges(payload EvidencePayload, opts VerificationOptions) *verify.VerificationResult {
+	page, ok := findExchange(payload.Exchanges, payload.ResourceURL)
+	if !ok {
+		return verify.FailedResult(nil, "resource_presence", "fetch_failed", "bundle does not contain the resource response", nil)
+	}
+	fragment, err := wire.ParseFragmentHTML(string(page.Body))
+	if err != nil {
+		return verify.FailedResult(nil, "resource_presence", "malformed", fmt.Sprintf("failed to parse fragment: %v", err), nil)
+	}
+
+	// Content referenced by URL was captured as its own exchange
+	if fragment.ContentURL != "" {
+		content, ok := findExchange(payload.Exchanges, fragment.ContentURL)
+		if !ok {
+			return verify.FailedResult(fragment, "resource_presence", "fetch_failed", "bundle does not contain the referenced content", nil)
+		}
+		fragment.CanonicalContent = content.Body
+	}
+
+	// The RA was either fetched from its URL or attached to a page response from the fragment's origin
+	var ra wire.ResourceAttestation
+	if raExchange, ok := findExchange(payload.Exchanges, fragment.ResourceAttestationURL); ok {
+		ra, err = wire.DecodeResourceAttestation(bytes.NewReader(raExchange.Body))
+	} else if headerValue := page.Headers.Get(wire.AttestationHeaderName); headerValue != "" && sameOrigin(page.OriginURL, fragment.FragmentURL) {
+		ra, err = wire.DecodeAttestationHeader(headerValue)
+	} else {
+		return verify.FailedResult(fragment, "resource_presence", "fetch_failed", "bundle does not contain the resource attestation", nil)
+	}
+	if err != nil {
+		reason := wire.MalformedReason(err)
+		if reason == "" {
+			reason = "malformed"
+		}
+		return verify.FailedResult(fragment, "resource_presence", reason, fmt.Sprintf("failed to parse resource attestation: %v", err), nil)
+	}
+
+	naExchange, ok := findExchange(payload.Exchanges, fragment.NamespaceAttestationURL)
+	if !ok {
+		return verify.FailedResult(fragment, "publisher_association", "fetch_failed", "bundle does not contain the namespace attestation", nil)
+	}
+	na, err := wire.DecodeNamespaceAttestation(bytes.NewReader(naExchange.Body))
+	if err != nil {
+		return verify.FailedResult(fragment, "publisher_association", wire.MalformedReason(err), fmt.Sprintf("failed to parse namespace attestation: %v", err), nil)
+	}
+
+	// Co-publishers' NAs were recorded when verification fetched them
+	coAttestations := make(map[string]wire.NamespaceAttestation)
+	for _, cp := range ra.CoPublishers {
+		if exchange, ok := findExchange(payload.Exchanges, cp.NamespaceAttestationURL); ok {
+			if coNA, err := wire.DecodeNamespaceAttestation(bytes.NewReader(exchange.Body)); err == nil {
+				coAttestations[cp.NamespaceAttestationURL] = coNA
+			}
+		}
+	}
+
+	result := verify.VerifyFragmentWithCoPublishers(*fragment, ra, na, coAttestations, verify.Options{
+		AllowedHashAlgorithms: opts.AllowedHashAlgorithms,
+		Offline:               true,
+		At:                    time.Unix(naExchange.FetchedAt, 0),
+		ReaderKey:             opts.ReaderKey,
+	})
+	return &result
+}
+
+// WriteEvidenceBundle writes the bundle as indented JSON
+func WriteEvidenceBundle(path string, bundle *EvidenceBundle) error {
+	data, err := json.MarshalIndent(bundle, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0644)
+}
+
+// verifierKeyFile is the on-disk form of the verifier's signing key
+type verifierKeyFile struct {
+	Alg        string `json:"alg"`
+	PrivKeyHex string `json:"priv_key_hex"`
+	PubKeyHex  string `json:"pub_key_hex"`
+}
+
+// defaultVerifierKeyPath returns the verifier key location under the user config directory
+func defaultVerifierKeyPath() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "verifier_key.json"
+	}
+	return filepath.Join(dir, "lap", "verifier_key.json")
+}
+
+// loadOrCreateVerifierKey loads the verifier signing key, generating a BIP-340 key on first use
+func loadOrCreateVerifierKey(path string) (crypto.Signer, error) {
+	data, err := os.ReadFile(path)
+	if err == nil {
+		var stored verifierKeyFile
+		if err := json.Unmarshal(data, &stored); err != nil {
+			return nil, fmt.Errorf("invalid verifier key file %s: %w", path, err)
+		}
+		return crypto.ParseSignerHex(stored.Alg, stored.PrivKeyHex)
+	}
+	if !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+
+	signer, err := crypto.GenerateSigner(crypto.SignatureAlgorithmBIP340)
+	if err != nil {
+		return nil, err
+	}
+	stored := verifierKeyFile{
+		Alg:        signer.Algorithm(),
+		PrivKeyHex: signer.PrivateKeyHex(),
+		PubKeyHex:  signer.PublicKeyHex(),
+	}
+	data, err = json.MarshalIndent(stored, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return nil, err
+	}
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		return nil, err
+	}
+	return signer, nil
+}
@@ -0,0 +1,150 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stonebraker/lap/sdks/go/pkg/lap/crypto"
+)
+
+// newPublisherServer serves a signed page and its attestations, returning the page URL
+func newPublisherServer(t *testing.T) (*httptest.Server, string) {
+	t.Helper()
+	mux := http.NewServeMux()
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+
+	html, raJSON, naJSON := createSignedDocument(t, srv.URL)
+	mux.HandleFunc("/people/alice/frc/posts/1", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		w.Write([]byte(html))
+	})
+	mux.HandleFunc("/people/alice/frc/posts/1/_la_resource.json", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(raJSON)
+	})
+	mux.HandleFunc("/people/alice/_la_namespace.json", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(naJSON)
+	})
+	return srv, srv.URL + "/people/alice/frc/posts/1"
+}
+
+func createTestBundle(t *testing.T) (*EvidenceBundle, crypto.Signer) {
+	t.Helper()
+	_, pageURL := newPublisherServer(t)
+
+	recorder := newExchangeRecorder(nil)
+	result, err := VerifyResource(pageURL, VerificationOptions{Timeout: 5 * time.Second, Transport: recorder})
+	if err != nil {
+		t.Fatalf("VerifyResource: %v", err)
+	}
+	if !result.Verified {
+		t.Fatalf("Expected live verification to pass, got %+v", result.Failure)
+	}
+	if got := len(recorder.Exchanges()); got != 3 {
+		t.Fatalf("Expected 3 recorded exchanges, got %d", got)
+	}
+
+	signer, err := loadOrCreateVerifierKey(filepath.Join(t.TempDir(), "verifier_key.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	bundle, err := CreateEvidenceBundle(pageURL, recorder.Exchanges(), result, signer)
+	if err != nil {
+		t.Fatalf("CreateEvidenceBundle: %v", err)
+	}
+	return bundle, signer
+}
+
+func TestEvidenceBundle_RoundTrip(t *testing.T) {
+	bundle, signer := createTestBundle(t)
+
+	path := filepath.Join(t.TempDir(), "out.lapb")
+	if err := WriteEvidenceBundle(path, bundle); err != nil {
+		t.Fatal(err)
+	}
+	data, err := readInput(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	check, err := CheckEvidenceBundle(data, VerificationOptions{})
+	if err != nil {
+		t.Fatalf("CheckEvidenceBundle: %v", err)
+	}
+	if !check.SignatureValid {
+		t.Error("Expected bundle signature to be valid")
+	}
+	if check.VerifierKey != signer.PublicKeyHex() {
+		t.Errorf("Expected verifier key %s, got %s", signer.PublicKeyHex(), check.VerifierKey)
+	}
+	if check.Original == nil || !check.Original.Verified {
+		t.Errorf("Expected original result to be recorded as verified, got %+v", check.Original)
+	}
+	if !check.Result.Verified {
+		t.Fatalf("Expected re-check to pass, got %+v", check.Result.Failure)
+	}
+	if check.Result.ResourcePresence != "skip (offline)" {
+		t.Errorf("Expected resource presence 'skip (offline)', got '%s'", check.Result.ResourcePresence)
+	}
+}
+
+func TestEvidenceBundle_CapturesHeaders(t *testing.T) {
+	bundle, _ := createTestBundle(t)
+
+	var payload EvidencePayload
+	if err := json.Unmarshal(bundle.Payload, &payload); err != nil {
+		t.Fatal(err)
+	}
+	for _, exchange := range payload.Exchanges {
+		if exchange.StatusCode != http.StatusOK || exchange.Headers.Get("Content-Type") == "" || len(exchange.Body) == 0 {
+			t.Errorf("Incomplete exchange captured for %s: %+v", exchange.URL, exchange)
+		}
+	}
+}
+
+func TestEvidenceBundle_TamperedPayload(t *testing.T) {
+	bundle, _ := createTestBundle(t)
+
+	// Swap the captured content while leaving the verifier signature in place
+	bundle.Payload = []byte(strings.Replace(string(bundle.Payload), `"verified":true`, `"verified":false`, 1))
+	data, err := json.Marshal(bundle)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	check, err := CheckEvidenceBundle(data, VerificationOptions{})
+	if err != nil {
+		t.Fatalf("CheckEvidenceBundle: %v", err)
+	}
+	if check.SignatureValid {
+		t.Error("Expected tampered bundle signature to be invalid")
+	}
+}
+
+func TestEvidenceBundle_UnsupportedFormat(t *testing.T) {
+	if _, err := CheckEvidenceBundle([]byte(`{"format":"zip"}`), VerificationOptions{}); err == nil {
+		t.Error("Expected error for unsupported bundle format")
+	}
+}
+
+func TestLoadOrCreateVerifierKey_Persists(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "lap", "verifier_key.json")
+	first, err := loadOrCreateVerifierKey(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := loadOrCreateVerifierKey(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first.PublicKeyHex() != second.PublicKeyHex() {
+		t.Error("Expected the verifier key to be reused on second load")
+	}
+}
@@ -0,0 +1,178 @@
+// Copyright 2025 Jason Stonebraker
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/stonebraker/lap/sdks/go/pkg/lap/fetch"
+	"github.com/stonebraker/lap/sdks/go/pkg/lap/verify"
+	"github.com/stonebraker/lap/sdks/go/pkg/lap/wire"
+)
+
+// defaultContactsPath returns the address book location under the user config directory
+func defaultContactsPath() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "contacts.json"
+	}
+	return filepath.Join(dir, "lap", "contacts.json")
+}
+
+// contactsCmd manages the address book that names publisher keys in verification results
+func contactsCmd(args []string) {
+	fs := flag.NewFlagSet("contacts", flag.ExitOnError)
+	contactsPath := fs.String("contacts", defaultContactsPath(), "address book of publisher petnames")
+	jsonOutput := fs.Bool("json", false, "list: output the address book as JSON")
+	note := fs.String("note", "", "add: a note about the contact")
+	via := fs.String("via", "", "import: name of the contact whose address book is imported (required)")
+	timeout := fs.Duration("timeout", 10*time.Second, "add-profile: HTTP timeout")
+	fs.Usage = func() {
+		exe := filepath.Base(os.Args[0])
+		fmt.Fprintf(os.Stderr, "Usage:\n")
+		fmt.Fprintf(os.Stderr, "  %s contacts [options] list\n", exe)
+		fmt.Fprintf(os.Stderr, "  %s contacts [options] add <key> <name>\n", exe)
+		fmt.Fprintf(os.Stderr, "  %s contacts [options] add-profile <profile-url>\n", exe)
+		fmt.Fprintf(os.Stderr, "  %s contacts [options] remove <key>\n", exe)
+		fmt.Fprintf(os.Stderr, "  %s contacts -via <name> import <file>\n", exe)
+		fmt.Fprintf(os.Stderr, "  %s contacts [options] export [file]\n", exe)
+		fs.PrintDefaults()
+	}
+	_ = fs.Parse(args)
+
+	book, err := verify.LoadAddressBook(*contactsPath)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	switch action := fs.Arg(0); {
+	case action == "list" && fs.NArg() == 1:
+		if *jsonOutput {
+			_ = book.Encode(os.Stdout)
+			return
+		}
+		for _, contact := range book.Contacts {
+			fmt.Printf("%s\n  Key: %s\n  Source: %s\n", contact.Name, contact.Key, contact.SourceLabel())
+			if contact.Note != "" {
+				fmt.Printf("  Note: %s\n", contact.Note)
+			}
+		}
+		return
+	case action == "export" && fs.NArg() <= 2:
+		out := io.Writer(os.Stdout)
+		if fs.NArg() == 2 {
+			f, err := os.Create(fs.Arg(1))
+			if err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				os.Exit(1)
+			}
+			defer f.Close()
+			out = f
+		}
+		if err := book.Encode(out); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		return
+	case action == "add" && fs.NArg() == 3:
+		err = book.Add(verify.Contact{Key: fs.Arg(1), Name: fs.Arg(2), Note: *note, Source: verify.SourceSelf})
+	case action == "add-profile" && fs.NArg() == 2:
+		var contact verify.Contact
+		if contact, err = fetchProfileContact(fs.Arg(1), VerificationOptions{Timeout: *timeout}); err == nil {
+			if err = book.Add(contact); err == nil {
+				fmt.Printf("%s is %s (%s)\n", contact.Key, contact.Name, contact.SourceLabel())
+			}
+		}
+	case action == "remove" && fs.NArg() == 2:
+		if !book.Remove(fs.Arg(1)) {
+			err = fmt.Errorf("no contact for %s", fs.Arg(1))
+		}
+	case action == "import" && fs.NArg() == 2 && *via != "":
+		var other *verify.AddressBook
+		if other, err = readAddressBook(fs.Arg(1)); err == nil {
+			fmt.Printf("imported %d contacts via %s\n", book.Import(other, *via), *via)
+		}
+	default:
+		fs.Usage()
+		os.Exit(2)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	if err := book.Save(*contactsPath); err != nil {
+		fmt.Fprintf(os.Stderr, "write %s: %v\n", *contactsPath, err)
+		os.Exit(1)
+	}
+}
+
+// readAddressBook reads an exported address book from a file, or stdin for -
+func readAddressBook(path string) (*verify.AddressBook, error) {
+	data, err := readInput(path)
+	if err != nil {
+		return nil, err
+	}
+	book, err := verify.DecodeAddressBook(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return book, nil
+}
+
+// fetchProfileContact fetches and verifies a publisher's profile fragment and returns the contact it
+// declares. The name is read from the verified canonical content, never from the preview.
+func fetchProfileContact(profileURL string, opts VerificationOptions) (verify.Contact, error) {
+	resp, err := fetch.NewClient(opts.Timeout).Get(profileURL)
+	if err != nil {
+		return verify.Contact{}, fmt.Errorf("fetch failed: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != 200 {
+		return verify.Contact{}, fmt.Errorf("fetch failed with status %d", resp.StatusCode)
+	}
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return verify.Contact{}, err
+	}
+	return profileContact(string(body), profileURL, opts)
+}
+
+// profileContact verifies a profile fragment's HTML and returns the contact it declares
+func profileContact(profileHTML, profileURL string, opts VerificationOptions) (verify.Contact, error) {
+	result, err := VerifyDocument(profileHTML, nil, nil, opts)
+	if err != nil {
+		return verify.Contact{}, err
+	}
+	if !result.Verified {
+		if result.Failure != nil {
+			return verify.Contact{}, fmt.Errorf("profile fragment did not verify: %s", result.Failure.Reason)
+		}
+		return verify.Contact{}, errors.New("profile fragment did not verify")
+	}
+	fragment, err := wire.ParseFragmentHTML(profileHTML)
+	if err != nil {
+		return verify.Contact{}, err
+	}
+	return verify.ProfileContact(*result, fragment.FragmentURL, fragment.CanonicalContent)
+}
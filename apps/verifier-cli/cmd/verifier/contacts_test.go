@@ -0,0 +1,92 @@
+// Copyright 2025 Jason Stonebraker
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stonebraker/lap/sdks/go/pkg/lap/verify"
+	"github.com/stonebraker/lap/sdks/go/pkg/lap/wire"
+)
+
+func TestProfileContact(t *testing.T) {
+	var html string
+	var raJSON, naJSON []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/people/alice/profile/index.htmx":
+			_, _ = w.Write([]byte(html))
+		case "/people/alice/profile/index.htmx/_la_resource.json":
+			_, _ = w.Write(raJSON)
+		case "/people/alice/_la_namespace.json":
+			_, _ = w.Write(naJSON)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	profile := []byte(`<header><h1 data-profile-display-name="Alice Cooks">Alice Cooks</h1><p data-profile-name="Alice">@alice</p></header>`)
+	html, raJSON, naJSON = createSignedDocumentAt(t, server.URL, "/people/alice/profile/index.htmx", profile)
+	var na wire.NamespaceAttestation
+	_ = json.Unmarshal(naJSON, &na)
+
+	profileURL := server.URL + "/people/alice/profile/index.htmx"
+	contact, err := fetchProfileContact(profileURL, VerificationOptions{Timeout: time.Second})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if contact.Key != na.Key || contact.Name != "Alice" || contact.Source != verify.SourceProfile || contact.Via != profileURL {
+		t.Errorf("Unexpected profile contact %+v", contact)
+	}
+
+	// A resource fetched live from the same key is then attributed to the profile's name
+	book := &verify.AddressBook{}
+	if err := book.Add(contact); err != nil {
+		t.Fatal(err)
+	}
+	result, err := VerifyResource(profileURL, VerificationOptions{Timeout: time.Second})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := book.Describe(*result); got != "verified as Alice (from their profile)" {
+		t.Errorf("Unexpected description %q", got)
+	}
+	if got := contactSuffix(book, result.ResourcePresence, na.Key); got != " - Alice (from their profile)" {
+		t.Errorf("Unexpected co-publisher suffix %q", got)
+	}
+	if got := contactSuffix(book, verify.StatusSkipStapled, na.Key); got != "" {
+		t.Errorf("Expected no name for a stapled resource, got %q", got)
+	}
+
+	// The same fragment verified from local copies could have come from anywhere
+	offline, err := VerifyDocument(html, raJSON, naJSON, VerificationOptions{Timeout: time.Second})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := book.Describe(*offline); got != "verified, publisher not named (resource not fetched live)" {
+		t.Errorf("Expected an offline result not to be attributed, got %q", got)
+	}
+
+	// A profile whose signature does not cover the served content is not trusted for a name
+	html, _, _ = createSignedDocumentAt(t, server.URL, "/people/alice/profile/index.htmx", profile)
+	if _, err := fetchProfileContact(profileURL, VerificationOptions{Timeout: time.Second}); err == nil {
+		t.Error("Expected an unverified profile to be rejected")
+	}
+}
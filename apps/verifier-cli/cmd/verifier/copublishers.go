@@ -0,0 +1,33 @@
+// Copyright 2025 Jason Stonebraker
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+
+	"github.com/stonebraker/lap/sdks/go/pkg/lap/verify"
+)
+
+// publisherSummary describes one author's association in one line
+func publisherSummary(p verify.PublisherResult) string {
+	summary := fmt.Sprintf("%s (%s): %s", p.PublisherClaim, p.Namespace, p.Status)
+	if p.Namespace == "" {
+		summary = fmt.Sprintf("%s (%s): %s", p.PublisherClaim, p.NamespaceAttestationURL, p.Status)
+	}
+	if p.Failure != nil {
+		summary += " - " + p.Failure.Reason
+	}
+	return summary
+}
@@ -0,0 +1,124 @@
+// Copyright 2025 Jason Stonebraker
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stonebraker/lap/apps/demo-utils/artifacts"
+	"github.com/stonebraker/lap/sdks/go/pkg/lap/wire"
+)
+
+// newCoAuthoredServers publishes a post by Alice co-signed by Bob, whose NA is on a second site, and
+// returns the post URL. Bob's NA is served only when serveBob is set.
+func newCoAuthoredServers(t *testing.T, serveBob bool) string {
+	t.Helper()
+	aliceMux, bobMux := http.NewServeMux(), http.NewServeMux()
+	alice, bob := httptest.NewServer(aliceMux), httptest.NewServer(bobMux)
+	t.Cleanup(alice.Close)
+	t.Cleanup(bob.Close)
+
+	dir := t.TempDir()
+	aliceNAPath, err := artifacts.CreateNamespaceAttestation(alice.URL+"/people/alice/", "", "", filepath.Join(dir, "alice"), filepath.Join(dir, "alice-keys"), "", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	bobNAPath, err := artifacts.CreateNamespaceAttestation(bob.URL+"/people/bob/", "", "", filepath.Join(dir, "bob"), filepath.Join(dir, "bob-keys"), "", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	aliceNAJSON, err := os.ReadFile(aliceNAPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var aliceNA wire.NamespaceAttestation
+	if err := json.Unmarshal(aliceNAJSON, &aliceNA); err != nil {
+		t.Fatal(err)
+	}
+
+	postURL := alice.URL + "/people/alice/frc/posts/7"
+	aliceNAURL := alice.URL + "/people/alice/_la_namespace.json"
+	bobNAURL := bob.URL + "/people/bob/_la_namespace.json"
+	contentPath := filepath.Join(dir, "content.htmx")
+	raPath := filepath.Join(dir, "_la_resource.json")
+	fragmentPath := filepath.Join(dir, "index.htmx")
+	if err := os.WriteFile(contentPath, []byte("<p>Written together.</p>"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := artifacts.CreateResourceAttestation(contentPath, postURL, "", aliceNA.Key, aliceNAURL, raPath); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := artifacts.CoSignResourceAttestation(raPath, bobNAURL, "", filepath.Join(dir, "bob-keys"), ""); err != nil {
+		t.Fatal(err)
+	}
+	if err := artifacts.CreateFragmentWithOptions(contentPath, postURL, "", aliceNA.Key, postURL+"/_la_resource.json", aliceNAURL, fragmentPath, artifacts.FragmentOptions{CoPublishersRAPath: raPath}); err != nil {
+		t.Fatal(err)
+	}
+
+	aliceMux.HandleFunc("/people/alice/frc/posts/7", func(w http.ResponseWriter, r *http.Request) {
+		http.ServeFile(w, r, fragmentPath)
+	})
+	aliceMux.HandleFunc("/people/alice/frc/posts/7/_la_resource.json", func(w http.ResponseWriter, r *http.Request) {
+		http.ServeFile(w, r, raPath)
+	})
+	aliceMux.HandleFunc("/people/alice/_la_namespace.json", func(w http.ResponseWriter, r *http.Request) {
+		http.ServeFile(w, r, aliceNAPath)
+	})
+	if serveBob {
+		bobMux.HandleFunc("/people/bob/_la_namespace.json", func(w http.ResponseWriter, r *http.Request) {
+			http.ServeFile(w, r, bobNAPath)
+		})
+	}
+	return postURL
+}
+
+func TestVerifyResource_CoPublishers(t *testing.T) {
+	postURL := newCoAuthoredServers(t, true)
+
+	result, err := VerifyResource(postURL, VerificationOptions{Timeout: 5 * time.Second})
+	if err != nil {
+		t.Fatalf("VerifyResource failed: %v", err)
+	}
+	if !result.Verified {
+		t.Fatalf("Expected co-authored post to verify, got %+v", result.Failure)
+	}
+	if len(result.Publishers) != 2 || result.Publishers[0].Status != "pass" || result.Publishers[1].Status != "pass" {
+		t.Errorf("Expected both authors to pass, got %+v", result.Publishers)
+	}
+}
+
+func TestVerifyResource_CoPublisherUnavailable(t *testing.T) {
+	postURL := newCoAuthoredServers(t, false)
+
+	result, err := VerifyResource(postURL, VerificationOptions{Timeout: 5 * time.Second})
+	if err != nil {
+		t.Fatalf("VerifyResource failed: %v", err)
+	}
+	if result.Verified {
+		t.Fatal("Expected verification to fail without the co-publisher's NA")
+	}
+	if result.Failure.Check != "publisher_association" || result.Failure.Reason != "namespace_attestation_unavailable" {
+		t.Errorf("Expected publisher_association/namespace_attestation_unavailable, got %s/%s", result.Failure.Check, result.Failure.Reason)
+	}
+	if len(result.Publishers) != 2 || result.Publishers[0].Status != "pass" || result.Publishers[1].Status != "fail" {
+		t.Errorf("Expected only the co-publisher to fail, got %+v", result.Publishers)
+	}
+}
@@ -0,0 +1,106 @@
+// Copyright 2025 Jason Stonebraker
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stonebraker/lap/apps/demo-utils/artifacts"
+	"github.com/stonebraker/lap/sdks/go/pkg/lap/crypto"
+	"github.com/stonebraker/lap/sdks/go/pkg/lap/wire"
+)
+
+// newEncryptedServer publishes a subscriber-only post sealed to a new reader, and returns the post URL
+// and the reader's private key
+func newEncryptedServer(t *testing.T) (string, string) {
+	t.Helper()
+	mux := http.NewServeMux()
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	dir := t.TempDir()
+	naPath, err := artifacts.CreateNamespaceAttestation(server.URL+"/people/alice/", "", "", dir, filepath.Join(dir, "keys"), "", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	naJSON, err := os.ReadFile(naPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var na wire.NamespaceAttestation
+	if err := json.Unmarshal(naJSON, &na); err != nil {
+		t.Fatal(err)
+	}
+	reader, readerPub, err := crypto.GenerateKeyPair()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	postURL := server.URL + "/people/alice/frc/posts/9"
+	naURL := server.URL + "/people/alice/_la_namespace.json"
+	contentPath := filepath.Join(dir, "content.htmx")
+	raPath := filepath.Join(dir, "_la_resource.json")
+	fragmentPath := filepath.Join(dir, "index.htmx")
+	if err := os.WriteFile(contentPath, []byte("<p>For subscribers.</p>"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := artifacts.CreateResourceAttestation(contentPath, postURL, "", na.Key, naURL, raPath); err != nil {
+		t.Fatal(err)
+	}
+	if err := artifacts.CreateFragmentWithOptions(contentPath, postURL, "", na.Key, postURL+"/_la_resource.json", naURL, fragmentPath, artifacts.FragmentOptions{Recipients: []string{readerPub}}); err != nil {
+		t.Fatal(err)
+	}
+
+	mux.HandleFunc("/people/alice/frc/posts/9", func(w http.ResponseWriter, r *http.Request) {
+		http.ServeFile(w, r, fragmentPath)
+	})
+	mux.HandleFunc("/people/alice/frc/posts/9/_la_resource.json", func(w http.ResponseWriter, r *http.Request) {
+		http.ServeFile(w, r, raPath)
+	})
+	mux.HandleFunc("/people/alice/_la_namespace.json", func(w http.ResponseWriter, r *http.Request) {
+		http.ServeFile(w, r, naPath)
+	})
+	return postURL, hex.EncodeToString(reader.Serialize())
+}
+
+func TestVerifyResource_Encrypted(t *testing.T) {
+	postURL, readerKey := newEncryptedServer(t)
+
+	result, err := VerifyResource(postURL, VerificationOptions{Timeout: 5 * time.Second, ReaderKey: readerKey})
+	if err != nil {
+		t.Fatalf("VerifyResource failed: %v", err)
+	}
+	if !result.Verified {
+		t.Fatalf("Expected subscriber-only post to verify with the reader key, got %+v", result.Failure)
+	}
+	if !result.Context.Encrypted {
+		t.Error("Expected context to record the decryption")
+	}
+
+	result, err = VerifyResource(postURL, VerificationOptions{Timeout: 5 * time.Second})
+	if err != nil {
+		t.Fatalf("VerifyResource failed: %v", err)
+	}
+	if result.Verified || result.Failure.Reason != "reader_key_required" {
+		t.Errorf("Expected reader_key_required without a reader key, got %+v", result.Failure)
+	}
+}
@@ -0,0 +1,93 @@
+// Copyright 2025 Jason Stonebraker
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/stonebraker/lap/sdks/go/pkg/lap/fetch"
+	"github.com/stonebraker/lap/sdks/go/pkg/lap/verify"
+	"github.com/stonebraker/lap/sdks/go/pkg/lap/wire"
+)
+
+// verifyLegacyFragment verifies an archived v0.1 fragment with the v0.1 rules, under the same verifier
+// options as v0.2 fragments. raJSON and naJSON hold locally saved attestations; when nil, the RA is
+// fetched from its canonical location and the NA from the nearest ancestor directory serving one.
+// etag is the resource response's ETag, if any.
+func verifyLegacyFragment(client *http.Client, articleHTML string, raJSON, naJSON []byte, etag string, opts VerificationOptions) *verify.VerificationResult {
+	fragment, err := wire.ParseFragmentV01(articleHTML)
+	if err != nil {
+		return verify.FailedResult(nil, "resource_presence", "malformed", fmt.Sprintf("failed to parse v0.1 fragment: %v", err), nil)
+	}
+
+	// Load the Resource Attestation from the local copy, or fetch it
+	raURL := wire.ResourceAttestationURLV01(fragment.URL)
+	var ra wire.ResourceAttestationV01
+	if raJSON != nil {
+		ra, err = wire.DecodeResourceAttestationV01(bytes.NewReader(raJSON))
+	} else {
+		ra, err = fetch.ResourceAttestationV01(client, raURL)
+	}
+	if err != nil {
+		return verify.FailedResult(nil, "resource_presence", fetch.FailureReason(err), fmt.Sprintf("failed to load resource attestation: %v", err), map[string]interface{}{
+			"resource_attestation_url": raURL,
+		})
+	}
+
+	// Load the Namespace Attestation from the local copy, or discover it
+	var na wire.NamespaceAttestationV01
+	var naURL string
+	if naJSON != nil {
+		na, err = wire.DecodeNamespaceAttestationV01(bytes.NewReader(naJSON))
+		if err == nil {
+			naURL, err = legacyAttestationURL(fragment.URL, na.Payload.AttestationPath)
+		}
+	} else {
+		na, naURL, err = fetch.NamespaceAttestationV01(client, fragment.URL)
+	}
+	if err != nil {
+		result := verify.FailedResult(nil, "publisher_association", fetch.FailureReason(err), fmt.Sprintf("failed to load namespace attestation: %v", err), nil)
+		result.ResourcePresence = "skip"
+		result.ResourceIntegrity = "skip"
+		result.Context.ResourceAttestationURL = raURL
+		result.Context.Spec = wire.SpecV01
+		return result
+	}
+
+	result := verify.VerifyFragmentV01(fragment, ra, na, naURL, etag, verify.Options{
+		AllowedHashAlgorithms: opts.AllowedHashAlgorithms,
+		Offline:               raJSON != nil,
+		RejectStale:           opts.RejectStale,
+		Policy:                opts.Policy,
+		Pipeline:              opts.pipeline(),
+	})
+	return &result
+}
+
+// legacyAttestationURL resolves a saved NA's attestation_path against the fragment's origin
+func legacyAttestationURL(fragmentURL, attestationPath string) (string, error) {
+	base, err := url.Parse(fragmentURL)
+	if err != nil {
+		return "", err
+	}
+	ref, err := url.Parse(attestationPath)
+	if err != nil {
+		return "", err
+	}
+	return base.ResolveReference(ref).String(), nil
+}
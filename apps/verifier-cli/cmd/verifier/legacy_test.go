@@ -0,0 +1,190 @@
+// Copyright 2025 Jason Stonebraker
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stonebraker/lap/sdks/go/pkg/lap/canonical"
+	"github.com/stonebraker/lap/sdks/go/pkg/lap/crypto"
+	"github.com/stonebraker/lap/sdks/go/pkg/lap/verify"
+	"github.com/stonebraker/lap/sdks/go/pkg/lap/wire"
+)
+
+// newLegacyServer serves an archived v0.1 message page with its Resource Attestation and a Namespace
+// Attestation two directories up, and returns the message URL
+func newLegacyServer(t *testing.T) string {
+	t.Helper()
+	mux := http.NewServeMux()
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	resourcePriv, resourceKey, err := crypto.GenerateKeyPair()
+	if err != nil {
+		t.Fatal(err)
+	}
+	publisherPriv, publisherKey, err := crypto.GenerateKeyPair()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	messageURL := server.URL + "/people/alice/messages/123"
+	content := []byte(`<span id="msg-123">Hello, my name is Alice.</span>`)
+	now := time.Now().Unix()
+	ra := wire.ResourceAttestationV01{
+		Payload: wire.ResourcePayloadV01{
+			URL:            messageURL,
+			AttestationURL: messageURL + "/_la_resource.json",
+			Hash:           crypto.ComputeContentHashField(content),
+			ETag:           `W/"123-abcde"`,
+			IAT:            now,
+			Exp:            now + 600,
+			KID:            "resource-key-2025-08-12",
+		},
+		ResourceKey: resourceKey,
+	}
+	payloadBytes, err := canonical.MarshalResourcePayloadV01Canonical(ra.Payload.ToCanonical())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ra.Sig, err = crypto.SignSchnorrHex(resourcePriv, crypto.HashSHA256(payloadBytes)); err != nil {
+		t.Fatal(err)
+	}
+
+	na := wire.NamespaceAttestationV01{
+		Payload: wire.NamespacePayloadV01{
+			Namespace:       []string{server.URL + "/people/alice/"},
+			AttestationPath: "/people/alice/_la_namespace.json",
+			IAT:             now,
+			Exp:             now + 3600,
+		},
+		PublisherKey: publisherKey,
+	}
+	payloadBytes, err = canonical.MarshalNamespacePayloadV01Canonical(na.Payload.ToCanonical())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if na.Sig, err = crypto.SignSchnorrHex(publisherPriv, crypto.HashSHA256(payloadBytes)); err != nil {
+		t.Fatal(err)
+	}
+
+	stapled, err := json.Marshal(ra)
+	if err != nil {
+		t.Fatal(err)
+	}
+	page := fmt.Sprintf(`<html><body>
+<article id="msg-123" data-lap-spec="https://lap.dev/spec/v0-1" data-lap-profile="fragment"
+    data-lap-attestation-format="script" data-lap-bytes-format="link-data" data-lap-url="%s"
+    data-lap-preview="#msg-123-preview" data-lap-attestation="#msg-123-attestation" data-lap-bytes="#msg-123-bytes">
+    <script id="msg-123-attestation" type="application/lap+json" class="lap-attestation">%s</script>
+    <div id="msg-123-preview" class="preview">Hello, my name is Alice.</div>
+    <link id="msg-123-bytes" rel="alternate" type="text/html; charset=utf-8" class="lap-bytes"
+        data-hash="%s" href="data:text/html;base64,%s" />
+</article>
+</body></html>`, messageURL, stapled, ra.Payload.Hash, base64.StdEncoding.EncodeToString(content))
+
+	mux.HandleFunc("/people/alice/messages/123", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("ETag", ra.Payload.ETag)
+		w.Write([]byte(page))
+	})
+	mux.HandleFunc("/people/alice/messages/123/_la_resource.json", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(ra)
+	})
+	mux.HandleFunc("/people/alice/_la_namespace.json", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(na)
+	})
+	return messageURL
+}
+
+func TestVerifyResource_LegacyV01(t *testing.T) {
+	messageURL := newLegacyServer(t)
+
+	result, err := VerifyResource(messageURL, VerificationOptions{Timeout: 5 * time.Second})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !result.Verified {
+		t.Fatalf("Expected archived v0.1 fragment to verify, got %+v", result.Failure)
+	}
+	if result.Context.Spec != wire.SpecV01 {
+		t.Errorf("Expected context spec %s, got %q", wire.SpecV01, result.Context.Spec)
+	}
+	if result.Context.NamespaceAttestationURL != messageURL[:len(messageURL)-len("messages/123")]+"_la_namespace.json" {
+		t.Errorf("Expected the NA to be discovered in the alice directory, got %s", result.Context.NamespaceAttestationURL)
+	}
+}
+
+func TestVerifyResource_LegacyV01Options(t *testing.T) {
+	messageURL := newLegacyServer(t)
+
+	result, err := VerifyResource(messageURL, VerificationOptions{Timeout: 5 * time.Second, AllowedHashAlgorithms: []string{"sha512"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Verified || result.Failure.Reason != "hash_algorithm_not_allowed" {
+		t.Fatalf("Expected the hash allowlist to apply to v0.1 fragments, got %+v", result.Failure)
+	}
+}
+
+func TestVerifyResource_LegacyV01Policy(t *testing.T) {
+	messageURL := newLegacyServer(t)
+
+	result, err := VerifyResource(messageURL, VerificationOptions{Timeout: 5 * time.Second, Policy: &verify.Policy{RequireHTTPS: true}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Verified || result.Failure.Check != verify.CheckPolicy || result.Failure.Reason != "insecure_scheme" {
+		t.Fatalf("Expected the policy to apply to v0.1 fragments, got %+v", result.Failure)
+	}
+}
+
+func TestVerifyDocument_MentionsLegacySpec(t *testing.T) {
+	html, raJSON, naJSON := createSignedDocument(t, "https://example.com")
+	document := html + `<article><p>Archived posts carry data-lap-spec="https://lap.dev/spec/v0-1".</p></article>`
+
+	result, err := VerifyDocument(document, raJSON, naJSON, VerificationOptions{Timeout: time.Second})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !result.Verified || result.Context.Spec != "" {
+		t.Fatalf("Expected the v0.2 fragment to be verified as v0.2, got %+v (spec %q)", result.Failure, result.Context.Spec)
+	}
+}
+
+func TestVerifyDocument_UnsupportedSpec(t *testing.T) {
+	document := `<article data-la-spec="v0.3" data-la-fragment-url="https://example.com/people/alice/posts/1"
+    data-la-publisher-claim="aa11bb22cc33dd44ee55ff6600112233445566778899aabbccddeeff00112233"
+    data-la-resource-attestation-url="https://example.com/people/alice/posts/1/_la_resource.json"
+    data-la-namespace-attestation-url="https://example.com/people/alice/_la_namespace.json">
+    <link rel="canonical" type="text/html" href="data:text/html;base64,PHA+SGk8L3A+" />
+</article>`
+
+	result, err := VerifyDocument(document, nil, nil, VerificationOptions{Timeout: time.Second})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Verified || result.Failure.Reason != "unsupported_spec" {
+		t.Fatalf("Expected unsupported_spec, got %+v", result.Failure)
+	}
+	if result.Failure.Details["spec"] != "v0.3" {
+		t.Errorf("Expected the declared spec in details, got %v", result.Failure.Details["spec"])
+	}
+}
@@ -18,9 +18,16 @@ import (
 	"encoding/json"
 	"flag"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
+	"sort"
+	"strings"
 	"time"
+
+	"github.com/stonebraker/lap/sdks/go/pkg/lap/crypto"
+	"github.com/stonebraker/lap/sdks/go/pkg/lap/fetch"
+	"github.com/stonebraker/lap/sdks/go/pkg/lap/verify"
 )
 
 func main() {
@@ -33,6 +40,16 @@ func main() {
 		usage()
 	case "verify":
 		verifyCmd(os.Args[2:])
+	case "verify-bundle":
+		verifyBundleCmd(os.Args[2:])
+	case "verify-range":
+		verifyRangeCmd(os.Args[2:])
+	case "thread":
+		threadCmd(os.Args[2:])
+	case "pins":
+		pinsCmd(os.Args[2:])
+	case "contacts":
+		contactsCmd(os.Args[2:])
 	default:
 		usage()
 		os.Exit(2)
@@ -43,11 +60,19 @@ func usage() {
 	exe := filepath.Base(os.Args[0])
 	fmt.Fprintf(os.Stderr, "Usage: %s <command> [options]\n", exe)
 	fmt.Fprintf(os.Stderr, "\nCommands:\n")
-	fmt.Fprintf(os.Stderr, "  verify      Verify a LAP v0.2 fragment located at the specified URL\n")
+	fmt.Fprintf(os.Stderr, "  verify         Verify a LAP v0.2 fragment located at the specified URL or in a local file\n")
+	fmt.Fprintf(os.Stderr, "  verify-bundle  Re-check a signed evidence bundle written by verify -save-bundle\n")
+	fmt.Fprintf(os.Stderr, "  verify-range   Verify a byte range of a resource against a Merkle root RA and its proof sidecar\n")
+	fmt.Fprintf(os.Stderr, "  thread         Verify replies and the in_reply_to chain up to each thread's root\n")
+	fmt.Fprintf(os.Stderr, "  pins           List, forget or trust the namespace keys pinned on first use\n")
+	fmt.Fprintf(os.Stderr, "  contacts       Manage the address book that names publishers in results\n")
 	fmt.Fprintf(os.Stderr, "\nVerification follows the v0.2 three-step process:\n")
 	fmt.Fprintf(os.Stderr, "  1. Resource Presence - Check attestation accessibility and same-origin validation\n")
 	fmt.Fprintf(os.Stderr, "  2. Resource Integrity - Verify content hash matches attestation\n")
 	fmt.Fprintf(os.Stderr, "  3. Publisher Association - Validate namespace control and signature\n")
+	fmt.Fprintf(os.Stderr, "\nOffline verification:\n")
+	fmt.Fprintf(os.Stderr, "  %s verify -file page.html -ra-file _la_resource.json -na-file _la_namespace.json\n", exe)
+	fmt.Fprintf(os.Stderr, "  Use - for any one file to read it from stdin. Resource Presence is reported as \"skip (offline)\".\n")
 }
 
 func verifyCmd(args []string) {
@@ -56,24 +81,117 @@ func verifyCmd(args []string) {
 	timeout := fs.Duration("timeout", 10*time.Second, "HTTP timeout")
 	verbose := fs.Bool("v", false, "verbose output")
 	jsonOutput := fs.Bool("json", false, "output structured JSON result matching v0.2 specification")
+	allowHash := fs.String("allow-hash", "", "comma-separated list of accepted RA hash algorithms (default: all supported)")
+	filePath := fs.String("file", "", "verify the first fragment in a local HTML file (- for stdin) instead of -url")
+	raFile := fs.String("ra-file", "", "local Resource Attestation JSON (- for stdin); verifies offline instead of fetching")
+	naFile := fs.String("na-file", "", "local Namespace Attestation JSON (- for stdin) instead of fetching")
+	saveBundle := fs.String("save-bundle", "", "write a signed evidence bundle of the fetched bytes and result to this path (requires -url)")
+	keyPath := fs.String("key", defaultVerifierKeyPath(), "verifier signing key for evidence bundles (created on first use)")
+	freshness := fs.String("freshness", verify.DefaultFreshnessPolicy().Mode, "whether to confirm stapled attestations live: always, max-age to use a stapled NA younger than -staple-max-age instead of fetching it, or never to check the staples alone (never verifies)")
+	stapleMaxAge := fs.Duration("staple-max-age", verify.DefaultStapleMaxAge, "with -freshness max-age, how old a staple may be for its NA to be used without a fetch")
+	crossCheck := fs.Bool("ra-cross-check", false, "also cross-check an RA header from the fragment's origin against the RA URL (headers from other origins always are)")
+	maxContentSize := fs.Int64("max-content-size", fetch.DefaultMaxContentBytes, "maximum bytes of canonical content fetched from a fragment's content URL")
+	rejectStale := fs.Bool("reject-stale", false, "fail fragments that embed a superseded version of the content instead of reporting them stale")
+	trustIssuers := fs.String("trust-issuer", "", "comma-separated Namespace Attestation URLs of issuers whose statements (endorsements, labels, disputes) are collected")
+	readerKey := fs.String("reader-key", "", "hex private key of a recipient, to decrypt subscriber-only fragments")
+	policyPath := fs.String("policy", "", "JSON verifier policy file (require_https, max_na_lifetime, allowed/denied origins and keys, ...)")
+	pinMode := fs.String("pins", pinModeWarn, "namespace key pinning: warn or enforce on a key change without a rotation, or off")
+	pinsPath := fs.String("pins-file", defaultPinsPath(), "key pin store")
+	contactsPath := fs.String("contacts", defaultContactsPath(), "address book naming publisher keys in results")
 	_ = fs.Parse(args)
 	
-	if *urlFlag == "" {
-		fmt.Fprintln(os.Stderr, "verify requires -url")
+	if (*urlFlag == "") == (*filePath == "") {
+		fmt.Fprintln(os.Stderr, "verify requires exactly one of -url or -file")
+		fs.Usage()
+		os.Exit(2)
+	}
+	if *urlFlag != "" && (*raFile != "" || *naFile != "") {
+		fmt.Fprintln(os.Stderr, "-ra-file and -na-file require -file")
 		fs.Usage()
 		os.Exit(2)
 	}
+	stdinUsers := 0
+	for _, path := range []string{*filePath, *raFile, *naFile} {
+		if path == "-" {
+			stdinUsers++
+		}
+	}
+	if stdinUsers > 1 {
+		fmt.Fprintln(os.Stderr, "only one of -file, -ra-file and -na-file may read from stdin")
+		os.Exit(2)
+	}
+	if *saveBundle != "" && *urlFlag == "" {
+		fmt.Fprintln(os.Stderr, "-save-bundle requires -url")
+		os.Exit(2)
+	}
+	freshnessMode, err := verify.ParseFreshnessMode(*freshness)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+	policy := loadPolicyFlag(*policyPath)
+
+	contacts, err := verify.LoadAddressBook(*contactsPath)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+	pins := loadPinsFlag(*pinMode, *pinsPath)
 
 	opts := VerificationOptions{
-		Timeout: *timeout,
-		Verbose: *verbose,
+		Timeout:               *timeout,
+		Verbose:               *verbose,
+		AllowedHashAlgorithms: splitList(*allowHash),
+		Freshness:             verify.FreshnessPolicy{Mode: freshnessMode, MaxAge: *stapleMaxAge},
+		CrossCheckHeaderRA:    *crossCheck,
+		MaxContentBytes:       *maxContentSize,
+		RejectStale:           *rejectStale,
+		TrustedIssuers:        splitList(*trustIssuers),
+		ReaderKey:             *readerKey,
+		Policy:                policy,
+		Pins:                  pins,
+		PinMode:               *pinMode,
 	}
 
-	result, err := VerifyResource(*urlFlag, opts)
+	var result *verify.VerificationResult
+	var raJSON []byte
+	if *filePath != "" {
+		var htmlContent, naJSON []byte
+		htmlContent, err = readInput(*filePath)
+		if err == nil && *raFile != "" {
+			raJSON, err = readInput(*raFile)
+		}
+		if err == nil && *naFile != "" {
+			naJSON, err = readInput(*naFile)
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "read error: %v\n", err)
+			os.Exit(1)
+		}
+		result, err = VerifyDocument(string(htmlContent), raJSON, naJSON, opts)
+	} else {
+		var recorder *exchangeRecorder
+		if *saveBundle != "" {
+			recorder = newExchangeRecorder(nil)
+			opts.Transport = recorder
+		}
+		result, err = VerifyResource(*urlFlag, opts)
+		if err == nil && recorder != nil {
+			if err := saveEvidenceBundle(*saveBundle, *keyPath, *urlFlag, recorder.Exchanges(), result); err != nil {
+				fmt.Fprintf(os.Stderr, "bundle error: %v\n", err)
+				os.Exit(1)
+			}
+			fmt.Fprintf(os.Stderr, "wrote %s\n", *saveBundle)
+		}
+	}
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "verification error: %v\n", err)
 		os.Exit(1)
 	}
+	if err := CollectStatements(result, opts); err != nil {
+		fmt.Fprintf(os.Stderr, "statements: %v\n", err)
+	}
+	savePins(pins)
 
 	if *jsonOutput {
 		// Output structured JSON matching v0.2 normative specification
@@ -87,9 +205,31 @@ func verifyCmd(args []string) {
 		// Human-readable output following v0.2 simplified approach
 		if result.Verified {
 			fmt.Printf("✅ Verification successful\n")
+			fmt.Printf("  Publisher Identity: %s\n", contacts.Describe(*result))
 			fmt.Printf("  Resource Presence: %s\n", result.ResourcePresence)
 			fmt.Printf("  Resource Integrity: %s\n", result.ResourceIntegrity)
+			if result.Context != nil && result.Context.Stale {
+				fmt.Printf("  Stale: embeds version %d, current version is %d\n", result.Context.EmbeddedVersion, result.Context.Version)
+			}
+			if result.Context != nil && result.Context.Spec != "" {
+				fmt.Printf("  Spec: %s (legacy rules)\n", result.Context.Spec)
+			}
+			if result.Context != nil && result.Context.Encrypted {
+				fmt.Printf("  Encrypted: content decrypted with the reader key\n")
+			}
+			if result.ExcerptInclusion != "" {
+				fmt.Printf("  Excerpt Inclusion: %s\n", result.ExcerptInclusion)
+			}
 			fmt.Printf("  Publisher Association: %s\n", result.PublisherAssociation)
+			for _, p := range result.Publishers {
+				fmt.Printf("  Publisher: %s%s\n", publisherSummary(p), contactSuffix(contacts, result.ResourcePresence, p.PublisherClaim))
+			}
+			for _, name := range checkNames(result.Checks) {
+				fmt.Printf("  Check %s: %s\n", name, result.Checks[name])
+			}
+			for _, st := range result.Statements {
+				fmt.Printf("  Statement: %s\n", statementSummary(st))
+			}
 		} else {
 			fmt.Printf("❌ Verification failed\n")
 			if result.Failure != nil {
@@ -97,6 +237,9 @@ func verifyCmd(args []string) {
 				fmt.Printf("  Reason: %s\n", result.Failure.Reason)
 				fmt.Printf("  Message: %s\n", result.Failure.Message)
 			}
+			for _, p := range result.Publishers {
+				fmt.Printf("  Publisher: %s\n", publisherSummary(p))
+			}
 		}
 		
 		if *verbose && result.Context != nil {
@@ -104,6 +247,29 @@ func verifyCmd(args []string) {
 			fmt.Printf("  Resource Attestation URL: %s\n", result.Context.ResourceAttestationURL)
 			fmt.Printf("  Namespace Attestation URL: %s\n", result.Context.NamespaceAttestationURL)
 			fmt.Printf("  Verified At: %d\n", result.Context.VerifiedAt)
+			if result.Context.Policy != nil {
+				policyJSON, _ := json.Marshal(result.Context.Policy)
+				fmt.Printf("  Policy: %s\n", policyJSON)
+			}
+			if result.Context.FreshUntil > 0 {
+				fmt.Printf("  Fresh Until: %d (re-verify after this)\n", result.Context.FreshUntil)
+			}
+			if result.Context.Offline {
+				fmt.Printf("  Offline: attestations loaded from local files\n")
+			}
+			if result.Context.ResourceAttestationSource != "" {
+				fmt.Printf("  Resource Attestation Source: %s\n", result.Context.ResourceAttestationSource)
+			}
+			if result.Context.Version > 0 {
+				fmt.Printf("  Current Version: %d\n", result.Context.Version)
+			}
+			if staple := result.Context.Stapled; staple != nil {
+				fmt.Printf("  Stapled At: %d\n", staple.StapledAt)
+				fmt.Printf("  Freshness: %s (live checked: %t)\n", staple.Freshness, staple.LiveChecked)
+				if staple.StapledNA {
+					fmt.Printf("  Namespace Attestation: stapled copy used\n")
+				}
+			}
 		}
 	}
 
@@ -114,3 +280,312 @@ func verifyCmd(args []string) {
 		os.Exit(1)
 	}
 }
+
+// saveEvidenceBundle signs the recorded exchanges and result with the verifier key and writes the bundle
+func saveEvidenceBundle(path, keyPath, resourceURL string, exchanges []HTTPExchange, result *verify.VerificationResult) error {
+	signer, err := loadOrCreateVerifierKey(keyPath)
+	if err != nil {
+		return fmt.Errorf("verifier key: %w", err)
+	}
+	bundle, err := CreateEvidenceBundle(resourceURL, exchanges, result, signer)
+	if err != nil {
+		return err
+	}
+	return WriteEvidenceBundle(path, bundle)
+}
+
+func verifyBundleCmd(args []string) {
+	fs := flag.NewFlagSet("verify-bundle", flag.ExitOnError)
+	bundlePath := fs.String("bundle", "", "path to an evidence bundle (- for stdin)")
+	expectKey := fs.String("expect-key", "", "require the bundle to be signed by this verifier public key (hex)")
+	jsonOutput := fs.Bool("json", false, "output structured JSON")
+	allowHash := fs.String("allow-hash", "", "comma-separated list of accepted RA hash algorithms (default: all supported)")
+	readerKey := fs.String("reader-key", "", "hex private key of a recipient, to decrypt subscriber-only fragments")
+	_ = fs.Parse(args)
+
+	if *bundlePath == "" {
+		fmt.Fprintln(os.Stderr, "verify-bundle requires -bundle")
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	data, err := readInput(*bundlePath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "read error: %v\n", err)
+		os.Exit(1)
+	}
+
+	check, err := CheckEvidenceBundle(data, VerificationOptions{AllowedHashAlgorithms: splitList(*allowHash), ReaderKey: *readerKey})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "bundle error: %v\n", err)
+		os.Exit(1)
+	}
+	keyTrusted := *expectKey == "" || strings.EqualFold(*expectKey, check.VerifierKey)
+
+	if *jsonOutput {
+		output, err := json.MarshalIndent(check, "", "  ")
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "json marshal error: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Println(string(output))
+	} else {
+		switch {
+		case !check.SignatureValid:
+			fmt.Printf("❌ Bundle signature invalid\n")
+		case !keyTrusted:
+			fmt.Printf("❌ Bundle signed by unexpected verifier key %s\n", check.VerifierKey)
+		default:
+			fmt.Printf("✅ Bundle signature valid\n")
+		}
+		fmt.Printf("  Verifier Key: %s\n", check.VerifierKey)
+		fmt.Printf("  Resource: %s\n", check.ResourceURL)
+		fmt.Printf("  Fetched At: %s\n", time.Unix(check.FetchedAt, 0).UTC().Format(time.RFC3339))
+		if check.Original != nil {
+			fmt.Printf("  Original Result: verified=%v\n", check.Original.Verified)
+		}
+
+		result := check.Result
+		if result.Verified {
+			fmt.Printf("✅ Re-check successful\n")
+		} else {
+			fmt.Printf("❌ Re-check failed\n")
+		}
+		fmt.Printf("  Resource Presence: %s\n", result.ResourcePresence)
+		fmt.Printf("  Resource Integrity: %s\n", result.ResourceIntegrity)
+		fmt.Printf("  Publisher Association: %s\n", result.PublisherAssociation)
+		if result.Failure != nil {
+			fmt.Printf("  Failed at: %s\n", result.Failure.Check)
+			fmt.Printf("  Reason: %s\n", result.Failure.Reason)
+			fmt.Printf("  Message: %s\n", result.Failure.Message)
+		}
+	}
+
+	if check.SignatureValid && keyTrusted && check.Result.Verified {
+		os.Exit(0)
+	}
+	os.Exit(1)
+}
+
+func verifyRangeCmd(args []string) {
+	fs := flag.NewFlagSet("verify-range", flag.ExitOnError)
+	urlFlag := fs.String("url", "", "absolute URL serving the attested content")
+	raLocation := fs.String("ra", "", "Resource Attestation URL or file with a "+crypto.MerkleHashAlgorithm+" hash")
+	proofsLocation := fs.String("proofs", "", "proof sidecar URL or file (default: derived from -ra, e.g. _la_resource.proofs.json)")
+	offset := fs.Int64("offset", 0, "byte offset of the range; must be a multiple of the chunk size")
+	length := fs.Int64("length", 0, "byte length of the range (default: one chunk)")
+	timeout := fs.Duration("timeout", 10*time.Second, "HTTP timeout")
+	jsonOutput := fs.Bool("json", false, "output structured JSON")
+	allowHash := fs.String("allow-hash", "", "comma-separated list of accepted RA hash algorithms (default: all supported)")
+	policyPath := fs.String("policy", "", "JSON verifier policy file (require_https, max_na_lifetime, allowed/denied origins and keys, ...)")
+	pinMode := fs.String("pins", pinModeWarn, "namespace key pinning: warn or enforce on a key change without a rotation, or off")
+	pinsPath := fs.String("pins-file", defaultPinsPath(), "key pin store")
+	_ = fs.Parse(args)
+
+	if *urlFlag == "" || *raLocation == "" {
+		fmt.Fprintln(os.Stderr, "verify-range requires -url and -ra")
+		fs.Usage()
+		os.Exit(2)
+	}
+	pins := loadPinsFlag(*pinMode, *pinsPath)
+
+	result, err := VerifyRange(*urlFlag, *raLocation, *proofsLocation, *offset, *length, VerificationOptions{
+		Timeout:               *timeout,
+		AllowedHashAlgorithms: splitList(*allowHash),
+		Policy:                loadPolicyFlag(*policyPath),
+		Pins:                  pins,
+		PinMode:               *pinMode,
+	})
+	savePins(pins)
+	if *jsonOutput {
+		output := map[string]interface{}{"verified": err == nil}
+		if err != nil {
+			output["error"] = err.Error()
+		} else {
+			output["range"] = result
+		}
+		data, _ := json.MarshalIndent(output, "", "  ")
+		fmt.Println(string(data))
+	} else if err != nil {
+		fmt.Printf("❌ Range verification failed\n")
+		fmt.Printf("  Message: %v\n", err)
+	} else {
+		fmt.Printf("✅ Range verification successful\n")
+		fmt.Printf("  Bytes: %d-%d of %d\n", result.Offset, result.Offset+result.Length, result.Size)
+	}
+
+	if err != nil {
+		os.Exit(1)
+	}
+}
+
+func threadCmd(args []string) {
+	fs := flag.NewFlagSet("thread", flag.ExitOnError)
+	timeout := fs.Duration("timeout", 10*time.Second, "HTTP timeout")
+	jsonOutput := fs.Bool("json", false, "output the thread provenance tree as JSON")
+	allowHash := fs.String("allow-hash", "", "comma-separated list of accepted RA hash algorithms (default: all supported)")
+	maxDepth := fs.Int("max-depth", defaultMaxThreadDepth, "maximum in_reply_to hops followed from each URL")
+	maxContentSize := fs.Int64("max-content-size", fetch.DefaultMaxContentBytes, "maximum bytes of canonical content fetched from a fragment's content URL")
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: %s thread [options] <url>...\n", filepath.Base(os.Args[0]))
+		fs.PrintDefaults()
+	}
+	_ = fs.Parse(args)
+
+	if fs.NArg() == 0 {
+		fmt.Fprintln(os.Stderr, "thread requires at least one post URL")
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	roots := VerifyThread(fs.Args(), *maxDepth, VerificationOptions{
+		Timeout:               *timeout,
+		AllowedHashAlgorithms: splitList(*allowHash),
+		MaxContentBytes:       *maxContentSize,
+	})
+	if *jsonOutput {
+		output, err := json.MarshalIndent(map[string]interface{}{"threads": roots}, "", "  ")
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "json marshal error: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Println(string(output))
+	} else {
+		for _, root := range roots {
+			printThreadNode(root, 0)
+		}
+	}
+
+	if !threadVerified(roots) {
+		os.Exit(1)
+	}
+}
+
+// printThreadNode prints a post and its replies, indented by depth
+func printThreadNode(node *ThreadNode, depth int) {
+	indent := strings.Repeat("    ", depth)
+	mark := "❌"
+	if node.Result != nil && node.Result.Verified && node.Error == "" {
+		mark = "✅"
+	}
+	fmt.Printf("%s%s %s\n", indent, mark, node.FragmentURL)
+	if node.PublisherClaim != "" {
+		fmt.Printf("%s   Publisher: %s\n", indent, node.PublisherClaim)
+	}
+	if node.Error != "" {
+		fmt.Printf("%s   Error: %s\n", indent, node.Error)
+	} else if node.Result != nil && node.Result.Failure != nil {
+		fmt.Printf("%s   Failed at: %s (%s)\n", indent, node.Result.Failure.Check, node.Result.Failure.Reason)
+	}
+	if node.ReplyLink != "" {
+		fmt.Printf("%s   Reply Link: %s\n", indent, node.ReplyLink)
+	}
+	if node.ReplyFailure != nil {
+		fmt.Printf("%s   Reply Failure: %s\n", indent, node.ReplyFailure.Message)
+	}
+	for _, reply := range node.Replies {
+		printThreadNode(reply, depth+1)
+	}
+}
+
+// threadVerified reports whether every post verified and every reply link passed (stale links included)
+func threadVerified(nodes []*ThreadNode) bool {
+	for _, node := range nodes {
+		if node.Error != "" || node.Result == nil || !node.Result.Verified {
+			return false
+		}
+		if node.ReplyLink == "fail" || node.ReplyLink == "skip" {
+			return false
+		}
+		if !threadVerified(node.Replies) {
+			return false
+		}
+	}
+	return true
+}
+
+// readInput reads a file, or stdin when path is "-"
+func readInput(path string) ([]byte, error) {
+	if path == "-" {
+		return io.ReadAll(os.Stdin)
+	}
+	return os.ReadFile(path)
+}
+
+// contactSuffix names a publisher key from the address book, or returns "" for unknown keys and
+// for resources that were not fetched live
+func contactSuffix(contacts *verify.AddressBook, presence, key string) string {
+	if presence != "pass" {
+		return ""
+	}
+	if contact, ok := contacts.Lookup(key); ok {
+		return fmt.Sprintf(" - %s (%s)", contact.Name, contact.SourceLabel())
+	}
+	return ""
+}
+
+// loadPolicyFlag loads the -policy file, if one was given, exiting on an invalid policy
+func loadPolicyFlag(path string) *verify.Policy {
+	if path == "" {
+		return nil
+	}
+	policy, err := verify.LoadPolicy(path)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+	return policy
+}
+
+// loadPinsFlag loads the pin store for a -pins mode; it is nil when pinning is off
+func loadPinsFlag(mode, path string) *PinStore {
+	switch mode {
+	case pinModeWarn, pinModeEnforce:
+		pins, err := LoadPinStore(path)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(2)
+		}
+		return pins
+	case pinModeOff:
+		return nil
+	}
+	fmt.Fprintf(os.Stderr, "invalid -pins %q: want warn, enforce or off\n", mode)
+	os.Exit(2)
+	return nil
+}
+
+// savePins warns about key changes seen during the run and writes the pin store
+func savePins(pins *PinStore) {
+	if pins == nil {
+		return
+	}
+	for _, change := range pins.Changes {
+		fmt.Fprintf(os.Stderr, "warning: key for %s changed from %s to %s without a rotation statement\n", change.Namespace, change.PinnedKey, change.Key)
+		fmt.Fprintf(os.Stderr, "  if the change is expected: %s pins trust %s %s\n", filepath.Base(os.Args[0]), change.Namespace, change.Key)
+	}
+	if err := pins.Save(); err != nil {
+		fmt.Fprintf(os.Stderr, "pins: %v\n", err)
+	}
+}
+
+// splitList splits a comma-separated flag value, dropping empty entries
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// checkNames returns the names of the custom checks in a result, sorted
+func checkNames(checks map[string]string) []string {
+	names := make([]string, 0, len(checks))
+	for name := range checks {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
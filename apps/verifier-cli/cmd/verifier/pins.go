@@ -0,0 +1,337 @@
+// Copyright 2025 Jason Stonebraker
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/stonebraker/lap/sdks/go/pkg/lap/crypto"
+	"github.com/stonebraker/lap/sdks/go/pkg/lap/fetch"
+	"github.com/stonebraker/lap/sdks/go/pkg/lap/verify"
+	"github.com/stonebraker/lap/sdks/go/pkg/lap/wire"
+)
+
+// Key pin modes: what a namespace key that differs from its pin does to verification
+const (
+	pinModeWarn    = "warn"    // Report the change in the key_pin status and on stderr
+	pinModeEnforce = "enforce" // Fail verification with key_pin/key_changed
+	pinModeOff     = "off"     // Neither check nor record pins
+)
+
+// checkKeyPin is the name of the pipeline check comparing namespace keys with their pins
+const checkKeyPin = "key_pin"
+
+// PinStore is the local trust-on-first-use database of namespace keys, keyed by namespace
+type PinStore struct {
+	Pins map[string]Pin `json:"pins"`
+
+	path    string
+	dirty   bool
+	Changes []PinChange `json:"-"` // Key changes seen during this run that were not covered by a rotation
+}
+
+// Pin is the key a namespace is trusted with: the first one seen, or one accepted since
+type Pin struct {
+	Key       string `json:"key"`
+	Alg       string `json:"alg,omitempty"` // Signature algorithm; empty means "bip340"
+	FirstSeen int64  `json:"first_seen"`
+	LastSeen  int64  `json:"last_seen"`
+	Rotated   int64  `json:"rotated,omitempty"` // When the pin last followed a key rotation
+}
+
+// PinChange is a namespace seen with a key other than its pin
+type PinChange struct {
+	Namespace string
+	PinnedKey string
+	Key       string
+}
+
+// defaultPinsPath returns the pin store location under the user config directory
+func defaultPinsPath() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "pins.json"
+	}
+	return filepath.Join(dir, "lap", "pins.json")
+}
+
+// LoadPinStore reads the pin store at path; a missing file is an empty store
+func LoadPinStore(path string) (*PinStore, error) {
+	store := &PinStore{Pins: map[string]Pin{}, path: path}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return store, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, store); err != nil {
+		return nil, fmt.Errorf("invalid pin store %s: %w", path, err)
+	}
+	if store.Pins == nil {
+		store.Pins = map[string]Pin{}
+	}
+	return store, nil
+}
+
+// Save writes the pin store if it changed since it was loaded
+func (s *PinStore) Save() error {
+	if !s.dirty {
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(s.path), 0700); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(s.path, append(data, '\n'), 0600); err != nil {
+		return err
+	}
+	s.dirty = false
+	return nil
+}
+
+// check returns the pipeline check that pins each author's namespace key on first use and compares
+// it on later runs. A changed key is accepted, and the pin moved, when the namespace publishes a valid
+// rotation from the pinned key; otherwise mode decides between a warning and a failure. Attestations
+// read from local files or stapled in the fragment are compared with existing pins but never pinned
+// themselves. Rotation indexes are fetched with client.
+func (s *PinStore) check(mode string, client *http.Client) verify.Check {
+	return verify.NewCheck(checkKeyPin, func(in *verify.CheckInput, _ *verify.VerificationResult) (string, *verify.FailureDetails) {
+		nas := map[string]wire.NamespaceAttestation{in.Fragment.NamespaceAttestationURL: in.NamespaceAttestation}
+		for url, na := range in.CoAttestations {
+			nas[url] = na
+		}
+
+		live := !in.Options.Offline && !in.Options.Stapled
+		status := "pass"
+		for naURL, na := range nas {
+			primary := naURL == in.Fragment.NamespaceAttestationURL
+			result, namespace := s.compare(in.Fragment.FragmentURL, naURL, na, primary, live, client)
+			switch result {
+			case "first use":
+				if status == "pass" {
+					status = "pass (first use)"
+				}
+			case "unpinned":
+				if status == "pass" {
+					status = "skip (unpinned)"
+				}
+			case "rotated":
+				status = "pass (rotated)"
+			case "changed":
+				pin := s.Pins[namespace]
+				if mode == pinModeEnforce {
+					return "fail", &verify.FailureDetails{
+						Check:   checkKeyPin,
+						Reason:  "key_changed",
+						Message: fmt.Sprintf("namespace %s is signed by %s, but %s was pinned", namespace, na.Key, pin.Key),
+						Details: map[string]interface{}{
+							"namespace":  namespace,
+							"pinned_key": pin.Key,
+							"key":        na.Key,
+							"first_seen": pin.FirstSeen,
+						},
+					}
+				}
+				status = "warn (key changed)"
+			}
+		}
+		return status, nil
+	})
+}
+
+// compare checks one Namespace Attestation against its pin, recording it on first use when it was
+// fetched live and following a published rotation. The primary NA's namespace is self-declared, so
+// when it has no pin it is held to the most specific pinned namespace covering fragmentURL instead;
+// a co-publisher's NA lives in its own namespace and is only compared with that namespace's pin. It
+// returns "first use", "unpinned", "same", "rotated" or "changed", and the namespace whose pin was
+// compared.
+func (s *PinStore) compare(fragmentURL, naURL string, na wire.NamespaceAttestation, primary, live bool, client *http.Client) (string, string) {
+	now := time.Now().Unix()
+	namespace := na.Payload.Namespace
+	alg := crypto.NormalizeSignatureAlgorithm(na.Alg)
+	pin, ok := s.Pins[namespace]
+	if !ok {
+		covering, found := "", false
+		if primary {
+			covering, found = s.coveringPin(fragmentURL)
+		}
+		if !found {
+			if !live {
+				return "unpinned", namespace
+			}
+			s.Pins[namespace] = Pin{Key: na.Key, Alg: na.Alg, FirstSeen: now, LastSeen: now}
+			s.dirty = true
+			return "first use", namespace
+		}
+		namespace, pin = covering, s.Pins[covering]
+	}
+	if pin.Key == na.Key && crypto.NormalizeSignatureAlgorithm(pin.Alg) == alg {
+		pin.LastSeen = now
+		s.Pins[namespace] = pin
+		s.dirty = true
+		return "same", namespace
+	}
+
+	// The namespace may announce its new key, signed by the pinned one
+	if index, err := fetchKeyRotations(client, wire.KeyRotationsLocation(naURL)); err == nil {
+		if verify.VerifyKeyRotation(index, namespace, pin.Alg, pin.Key, na.Alg, na.Key) == nil {
+			pin.Key, pin.Alg = na.Key, na.Alg
+			pin.LastSeen, pin.Rotated = now, now
+			s.Pins[namespace] = pin
+			s.dirty = true
+			return "rotated", namespace
+		}
+	}
+	s.Changes = append(s.Changes, PinChange{Namespace: namespace, PinnedKey: pin.Key, Key: na.Key})
+	return "changed", namespace
+}
+
+// coveringPin returns the most specific pinned namespace that covers url
+func (s *PinStore) coveringPin(url string) (string, bool) {
+	covering := ""
+	for namespace := range s.Pins {
+		if strings.HasSuffix(namespace, "/") && strings.HasPrefix(url, namespace) && len(namespace) > len(covering) {
+			covering = namespace
+		}
+	}
+	return covering, covering != ""
+}
+
+// fetchKeyRotations fetches a namespace's key rotation index
+func fetchKeyRotations(client *http.Client, url string) (wire.KeyRotationIndex, error) {
+	resp, err := client.Get(url)
+	if err != nil {
+		return wire.KeyRotationIndex{}, fmt.Errorf("fetch failed: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != 200 {
+		return wire.KeyRotationIndex{}, fmt.Errorf("fetch failed with status %d", resp.StatusCode)
+	}
+	return wire.DecodeKeyRotationIndex(resp.Body)
+}
+
+// pinsCmd lists and edits the pin store: pins list|forget|trust
+func pinsCmd(args []string) {
+	fs := flag.NewFlagSet("pins", flag.ExitOnError)
+	pinsPath := fs.String("pins-file", defaultPinsPath(), "key pin store")
+	jsonOutput := fs.Bool("json", false, "list: output the pins as JSON")
+	alg := fs.String("alg", "", "trust: signature algorithm of the key (default bip340)")
+	fs.Usage = func() {
+		exe := filepath.Base(os.Args[0])
+		fmt.Fprintf(os.Stderr, "Usage:\n")
+		fmt.Fprintf(os.Stderr, "  %s pins [options] list\n", exe)
+		fmt.Fprintf(os.Stderr, "  %s pins [options] forget <namespace>\n", exe)
+		fmt.Fprintf(os.Stderr, "  %s pins [options] trust <namespace> <key>\n", exe)
+		fs.PrintDefaults()
+	}
+	_ = fs.Parse(args)
+
+	store, err := LoadPinStore(*pinsPath)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	switch action := fs.Arg(0); {
+	case action == "list" && fs.NArg() == 1:
+		if *jsonOutput {
+			output, _ := json.MarshalIndent(store.Pins, "", "  ")
+			fmt.Println(string(output))
+			return
+		}
+		namespaces := make([]string, 0, len(store.Pins))
+		for namespace := range store.Pins {
+			namespaces = append(namespaces, namespace)
+		}
+		sort.Strings(namespaces)
+		for _, namespace := range namespaces {
+			pin := store.Pins[namespace]
+			fmt.Printf("%s\n  Key: %s\n  First Seen: %s\n  Last Seen: %s\n", namespace, pinKeySummary(pin),
+				time.Unix(pin.FirstSeen, 0).UTC().Format(time.RFC3339), time.Unix(pin.LastSeen, 0).UTC().Format(time.RFC3339))
+			if pin.Rotated > 0 {
+				fmt.Printf("  Rotated: %s\n", time.Unix(pin.Rotated, 0).UTC().Format(time.RFC3339))
+			}
+		}
+	case action == "forget" && fs.NArg() == 2:
+		namespace := fs.Arg(1)
+		if _, ok := store.Pins[namespace]; !ok {
+			fmt.Fprintf(os.Stderr, "no pin for %s\n", namespace)
+			os.Exit(1)
+		}
+		delete(store.Pins, namespace)
+		store.dirty = true
+	case action == "trust" && fs.NArg() == 3:
+		namespace, key := fs.Arg(1), fs.Arg(2)
+		if _, err := hex.DecodeString(key); err != nil || key == "" {
+			fmt.Fprintf(os.Stderr, "invalid key %q: not a hex public key\n", key)
+			os.Exit(2)
+		}
+		now := time.Now().Unix()
+		pin, ok := store.Pins[namespace]
+		if !ok {
+			pin.FirstSeen = now
+		}
+		pin.Key, pin.LastSeen = key, now
+		pin.Alg = ""
+		if normalized := crypto.NormalizeSignatureAlgorithm(*alg); normalized != crypto.SignatureAlgorithmBIP340 {
+			pin.Alg = normalized
+		}
+		store.Pins[namespace] = pin
+		store.dirty = true
+	default:
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	if err := store.Save(); err != nil {
+		fmt.Fprintf(os.Stderr, "write %s: %v\n", *pinsPath, err)
+		os.Exit(1)
+	}
+}
+
+// pipeline returns the checks run for the options: nil for the default ones, or the default ones
+// followed by the key pin check when a pin store is in use
+func (o VerificationOptions) pipeline() verify.Pipeline {
+	if o.Pins == nil || o.PinMode == pinModeOff {
+		return nil
+	}
+	client := fetch.NewClient(o.Timeout)
+	if o.Transport != nil {
+		client.Transport = o.Transport
+	}
+	return append(verify.DefaultPipeline(), o.Pins.check(o.PinMode, client))
+}
+
+// pinKeySummary formats a pinned key with its algorithm when it is not the bip340 default
+func pinKeySummary(pin Pin) string {
+	if pin.Alg == "" {
+		return pin.Key
+	}
+	return fmt.Sprintf("%s (%s)", pin.Key, pin.Alg)
+}
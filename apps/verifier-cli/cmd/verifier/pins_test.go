@@ -0,0 +1,254 @@
+// Copyright 2025 Jason Stonebraker
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stonebraker/lap/sdks/go/pkg/lap/canonical"
+	"github.com/stonebraker/lap/sdks/go/pkg/lap/crypto"
+	"github.com/stonebraker/lap/sdks/go/pkg/lap/wire"
+)
+
+// attestationServer serves the RA and NA of a document created with createSignedDocument for its URL
+type attestationServer struct {
+	*httptest.Server
+	raJSON, naJSON []byte
+}
+
+func newAttestationServer(t *testing.T) *attestationServer {
+	t.Helper()
+	srv := &attestationServer{}
+	srv.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/people/alice/frc/posts/1/_la_resource.json":
+			_, _ = w.Write(srv.raJSON)
+		case "/people/alice/_la_namespace.json":
+			_, _ = w.Write(srv.naJSON)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+// verifyPinned verifies a signed document against the pin store at path, fetching its attestations
+// from srv, or offline from raJSON and naJSON when srv is nil
+func verifyPinned(t *testing.T, path, mode string, srv *attestationServer, html string, raJSON, naJSON []byte) (*PinStore, string, string) {
+	t.Helper()
+	pins, err := LoadPinStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if srv != nil {
+		srv.raJSON, srv.naJSON = raJSON, naJSON
+		raJSON, naJSON = nil, nil
+	}
+	result, err := VerifyDocument(html, raJSON, naJSON, VerificationOptions{Timeout: time.Second, Pins: pins, PinMode: mode})
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if err := pins.Save(); err != nil {
+		t.Fatal(err)
+	}
+	reason := ""
+	if result.Failure != nil {
+		reason = result.Failure.Check + "/" + result.Failure.Reason
+	}
+	return pins, result.Checks[checkKeyPin], reason
+}
+
+func TestVerifyDocument_KeyPinning(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "lap", "pins.json")
+	srv := newAttestationServer(t)
+	html, raJSON, naJSON := createSignedDocument(t, srv.URL)
+
+	pins, status, _ := verifyPinned(t, path, pinModeEnforce, srv, html, raJSON, naJSON)
+	if status != "pass (first use)" {
+		t.Fatalf("Expected the key to be pinned on first use, got %q", status)
+	}
+	var na wire.NamespaceAttestation
+	_ = json.Unmarshal(naJSON, &na)
+	if pin := pins.Pins[srv.URL+"/people/alice/"]; pin.Key != na.Key || pin.FirstSeen == 0 {
+		t.Fatalf("Expected the namespace key pinned, got %+v", pin)
+	}
+
+	if _, status, _ = verifyPinned(t, path, pinModeEnforce, srv, html, raJSON, naJSON); status != "pass" {
+		t.Errorf("Expected the pinned key to pass, got %q", status)
+	}
+
+	// The same namespace signed by a new key, with no rotation statement to follow
+	html, raJSON, naJSON = createSignedDocument(t, srv.URL)
+	pins, status, reason := verifyPinned(t, path, pinModeWarn, srv, html, raJSON, naJSON)
+	if status != "warn (key changed)" || reason != "" || len(pins.Changes) != 1 {
+		t.Errorf("Expected a warning for the changed key, got %q %q %+v", status, reason, pins.Changes)
+	}
+	if _, _, reason = verifyPinned(t, path, pinModeEnforce, srv, html, raJSON, naJSON); reason != "key_pin/key_changed" {
+		t.Errorf("Expected key_pin/key_changed when enforcing, got %q", reason)
+	}
+	if pins, _ := LoadPinStore(path); pins.Pins[srv.URL+"/people/alice/"].Key != na.Key {
+		t.Error("Expected a changed key to leave the pin alone")
+	}
+}
+
+func TestVerifyDocument_KeyPinOffline(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "pins.json")
+	srv := newAttestationServer(t)
+	html, raJSON, naJSON := createSignedDocument(t, srv.URL)
+
+	// Local attestations are not evidence of the key a namespace uses, so they are never pinned
+	pins, status, reason := verifyPinned(t, path, pinModeEnforce, nil, html, raJSON, naJSON)
+	if status != "skip (unpinned)" || reason != "" || len(pins.Pins) != 0 {
+		t.Fatalf("Expected no pin recorded offline, got %q %q %+v", status, reason, pins.Pins)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("Expected the pin store not to be written, got %v", err)
+	}
+
+	// Once pinned live, offline verifications are still compared with the pin
+	if _, status, _ = verifyPinned(t, path, pinModeEnforce, srv, html, raJSON, naJSON); status != "pass (first use)" {
+		t.Fatalf("Expected the key to be pinned live, got %q", status)
+	}
+	if _, status, _ = verifyPinned(t, path, pinModeEnforce, nil, html, raJSON, naJSON); status != "pass" {
+		t.Errorf("Expected the pinned key to pass offline, got %q", status)
+	}
+	html, raJSON, naJSON = createSignedDocument(t, srv.URL)
+	if _, _, reason = verifyPinned(t, path, pinModeEnforce, nil, html, raJSON, naJSON); reason != "key_pin/key_changed" {
+		t.Errorf("Expected a changed key to fail offline, got %q", reason)
+	}
+}
+
+func TestVerifyDocument_KeyPinCoveringNamespace(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "pins.json")
+	srv := newAttestationServer(t)
+	html, raJSON, naJSON := createSignedDocument(t, srv.URL)
+	if _, status, _ := verifyPinned(t, path, pinModeEnforce, srv, html, raJSON, naJSON); status != "pass (first use)" {
+		t.Fatalf("Expected the key to be pinned on first use, got %q", status)
+	}
+
+	// Another key declares a wider namespace, which has no pin of its own but covers the pinned one
+	var na wire.NamespaceAttestation
+	_ = json.Unmarshal(naJSON, &na)
+	priv, pubKey, err := crypto.GenerateKeyPair()
+	if err != nil {
+		t.Fatal(err)
+	}
+	payload := wire.NamespacePayload{Namespace: srv.URL + "/people/", Exp: na.Payload.Exp}
+	payloadBytes, err := canonical.MarshalNamespacePayloadCanonical(payload.ToCanonical())
+	if err != nil {
+		t.Fatal(err)
+	}
+	sig, err := crypto.SignSchnorrHex(priv, crypto.HashSHA256(payloadBytes))
+	if err != nil {
+		t.Fatal(err)
+	}
+	wider, _ := json.Marshal(wire.NamespaceAttestation{Payload: payload, Key: pubKey, Sig: sig})
+	html = strings.ReplaceAll(html, na.Key, pubKey)
+	raJSON = []byte(strings.ReplaceAll(string(raJSON), na.Key, pubKey))
+
+	pins, _, reason := verifyPinned(t, path, pinModeEnforce, srv, html, raJSON, wider)
+	if reason != "key_pin/key_changed" {
+		t.Fatalf("Expected the covering pin to apply, got %q", reason)
+	}
+	if _, ok := pins.Pins[payload.Namespace]; ok {
+		t.Error("Expected no pin recorded for the wider namespace")
+	}
+}
+
+func TestVerifyResource_KeyPinCoPublisher(t *testing.T) {
+	postURL := newCoAuthoredServers(t, true)
+	pins, err := LoadPinStore(filepath.Join(t.TempDir(), "pins.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	opts := VerificationOptions{Timeout: 5 * time.Second, Pins: pins, PinMode: pinModeEnforce}
+
+	result, err := VerifyResource(postURL, opts)
+	if err != nil || !result.Verified || len(pins.Pins) != 2 {
+		t.Fatalf("Expected both authors pinned on first use, got %+v %+v (%v)", result.Failure, pins.Pins, err)
+	}
+
+	// The co-author's namespace has its own pin; the publisher's pin covering the post never applies to it
+	for namespace := range pins.Pins {
+		if !strings.HasPrefix(postURL, namespace) {
+			delete(pins.Pins, namespace)
+		}
+	}
+	result, err = VerifyResource(postURL, opts)
+	if err != nil || !result.Verified || result.Checks[checkKeyPin] != "pass (first use)" {
+		t.Fatalf("Expected the co-author to be pinned again, got %q %+v (%v)", result.Checks[checkKeyPin], result.Failure, err)
+	}
+	if len(pins.Pins) != 2 {
+		t.Errorf("Expected both authors pinned, got %+v", pins.Pins)
+	}
+}
+
+func TestVerifyDocument_KeyPinFollowsRotation(t *testing.T) {
+	var rotations []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/people/alice/"+wire.KeyRotationsFileName {
+			http.NotFound(w, r)
+			return
+		}
+		_, _ = w.Write(rotations)
+	}))
+	defer server.Close()
+
+	html, raJSON, naJSON := createSignedDocument(t, server.URL)
+	var na wire.NamespaceAttestation
+	_ = json.Unmarshal(naJSON, &na)
+
+	// The reader pinned an older key, which announced the current one
+	old, err := crypto.GenerateSigner(crypto.SignatureAlgorithmBIP340)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rotation := wire.KeyRotation{
+		Payload: wire.KeyRotationPayload{Namespace: na.Payload.Namespace, NewKey: na.Key, Iat: time.Now().Unix()},
+		Key:     old.PublicKeyHex(),
+	}
+	payloadBytes, err := canonical.MarshalKeyRotationPayloadCanonical(rotation.SignedPayload())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rotation.Sig, err = old.SignHex(crypto.HashSHA256(payloadBytes)); err != nil {
+		t.Fatal(err)
+	}
+	rotations, _ = json.Marshal(wire.KeyRotationIndex{Rotations: []wire.KeyRotation{rotation}})
+
+	path := filepath.Join(t.TempDir(), "pins.json")
+	pins, _ := LoadPinStore(path)
+	pins.Pins[na.Payload.Namespace] = Pin{Key: old.PublicKeyHex(), FirstSeen: 1700000000, LastSeen: 1700000000}
+	pins.dirty = true
+	if err := pins.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	pins, status, reason := verifyPinned(t, path, pinModeEnforce, nil, html, raJSON, naJSON)
+	if status != "pass (rotated)" || reason != "" {
+		t.Fatalf("Expected the pin to follow the rotation, got %q %q", status, reason)
+	}
+	if pin := pins.Pins[na.Payload.Namespace]; pin.Key != na.Key || pin.FirstSeen != 1700000000 || pin.Rotated == 0 {
+		t.Errorf("Expected the pin moved to the new key, got %+v", pin)
+	}
+}
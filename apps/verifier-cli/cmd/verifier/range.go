@@ -0,0 +1,178 @@
+// Copyright 2025 Jason Stonebraker
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/stonebraker/lap/sdks/go/pkg/lap/crypto"
+	"github.com/stonebraker/lap/sdks/go/pkg/lap/fetch"
+	"github.com/stonebraker/lap/sdks/go/pkg/lap/verify"
+	"github.com/stonebraker/lap/sdks/go/pkg/lap/wire"
+)
+
+// RangeResult describes a verified byte range
+type RangeResult struct {
+	Offset int64 `json:"offset"`
+	Length int64 `json:"length"`
+	Size   int64 `json:"size"` // Length of the whole attested content
+}
+
+// VerifyRange fetches length bytes of contentURL at offset with an HTTP Range request and checks
+// them against the Merkle root in the Resource Attestation, using its proof sidecar. raLocation and
+// proofsLocation are URLs or local files; an empty proofsLocation uses wire.MerkleProofsLocation.
+// A zero length selects one chunk, and the range is clipped to the end of the content. Resource
+// Presence and Publisher Association run first, with contentURL as the fragment URL and the publisher
+// claim and NA URL taken from the RA; a local RA is checked offline.
+func VerifyRange(contentURL, raLocation, proofsLocation string, offset, length int64, opts VerificationOptions) (*RangeResult, error) {
+	client := fetch.NewClient(opts.Timeout)
+	if opts.Transport != nil {
+		client.Transport = opts.Transport
+	}
+	if proofsLocation == "" {
+		proofsLocation = wire.MerkleProofsLocation(raLocation)
+	}
+
+	raBytes, err := readLocation(client, raLocation)
+	if err != nil {
+		return nil, fmt.Errorf("resource attestation: %w", err)
+	}
+	ra, err := wire.DecodeResourceAttestation(bytes.NewReader(raBytes))
+	if err != nil {
+		return nil, fmt.Errorf("resource attestation: %w", err)
+	}
+	if err := fetch.ValidateResourceAttestation(ra); err != nil {
+		return nil, fmt.Errorf("resource attestation: %w", err)
+	}
+	na, err := fetch.NamespaceAttestation(client, ra.NamespaceAttestationURL)
+	if err != nil {
+		return nil, fmt.Errorf("namespace attestation: %w", err)
+	}
+	proofsBytes, err := readLocation(client, proofsLocation)
+	if err != nil {
+		return nil, fmt.Errorf("proofs: %w", err)
+	}
+	proofs, err := wire.DecodeMerkleProofs(bytes.NewReader(proofsBytes))
+	if err != nil {
+		return nil, fmt.Errorf("proofs: %w", err)
+	}
+
+	if length <= 0 {
+		length = crypto.MerkleChunkSize
+	}
+	if offset+length > proofs.Size {
+		length = proofs.Size - offset
+	}
+	if offset < 0 || length < 0 {
+		return nil, fmt.Errorf("offset %d is beyond the %d byte content", offset, proofs.Size)
+	}
+
+	data, err := fetchRange(client, contentURL, offset, length)
+	if err != nil {
+		return nil, fmt.Errorf("content: %w", err)
+	}
+
+	// The content is its own resource, as for a direct resource without a fragment
+	offline := !isURL(raLocation)
+	fragment := wire.Fragment{
+		Spec:                    wire.SpecV02,
+		FragmentURL:             contentURL,
+		ResourceAttestationURL:  raLocation,
+		PublisherClaim:          ra.PublisherClaim,
+		NamespaceAttestationURL: ra.NamespaceAttestationURL,
+		CoPublishers:            wire.ListedCoPublishers(ra),
+	}
+	if offline {
+		fragment.ResourceAttestationURL = contentURL
+	}
+	bundle := verify.FragmentBundle{
+		Fragment:                fragment,
+		ResourceAttestation:     ra,
+		NamespaceAttestation:    *na,
+		CoPublisherAttestations: fetch.CoPublisherAttestations(client, ra),
+	}
+	result := verify.VerifyRange(bundle, proofs, offset, data, verify.Options{
+		AllowedHashAlgorithms: opts.AllowedHashAlgorithms,
+		Offline:               offline,
+		Policy:                opts.Policy,
+		Pipeline:              opts.pipeline(),
+	})
+	if !result.Verified {
+		return nil, fmt.Errorf("%s failed (%s): %s", result.Failure.Check, result.Failure.Reason, result.Failure.Message)
+	}
+	return &RangeResult{Offset: offset, Length: length, Size: proofs.Size}, nil
+}
+
+// fetchRange requests bytes [offset, offset+length) of url. Servers that ignore the Range header
+// answer with the whole body, which is sliced locally.
+func fetchRange(client *http.Client, url string, offset, length int64) ([]byte, error) {
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	if length > 0 {
+		req.Header.Set("Range", fmt.Sprintf("bytes=%d-%d", offset, offset+length-1))
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusPartialContent:
+		return readExactly(resp.Body, length)
+	case http.StatusOK:
+		if _, err := io.CopyN(io.Discard, resp.Body, offset); err != nil {
+			return nil, err
+		}
+		return readExactly(resp.Body, length)
+	default:
+		return nil, fmt.Errorf("fetch failed with status %d", resp.StatusCode)
+	}
+}
+
+func readExactly(r io.Reader, n int64) ([]byte, error) {
+	data := make([]byte, n)
+	if _, err := io.ReadFull(r, data); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+// readLocation fetches an http(s) URL or reads a local file (- for stdin)
+func readLocation(client *http.Client, location string) ([]byte, error) {
+	if !isURL(location) {
+		return readInput(location)
+	}
+	resp, err := client.Get(location)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("fetch failed with status %d", resp.StatusCode)
+	}
+	return io.ReadAll(resp.Body)
+}
+
+// isURL reports whether location is an http(s) URL rather than a local file
+func isURL(location string) bool {
+	return strings.HasPrefix(location, "http://") || strings.HasPrefix(location, "https://")
+}
@@ -0,0 +1,131 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stonebraker/lap/apps/demo-utils/artifacts"
+	"github.com/stonebraker/lap/sdks/go/pkg/lap/crypto"
+	"github.com/stonebraker/lap/sdks/go/pkg/lap/wire"
+)
+
+// newRangeServer serves Alice's content at /people/alice/video.bin with Range support, plus a signed
+// RA with a Merkle root, its proofs and her NA. The content URL serves served instead, when set.
+func newRangeServer(t *testing.T, content, served []byte) string {
+	t.Helper()
+	mux := http.NewServeMux()
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+
+	_, raJSON, naJSON := createSignedDocumentAt(t, srv.URL, "/people/alice/video.bin", content)
+	var ra wire.ResourceAttestation
+	if err := json.Unmarshal(raJSON, &ra); err != nil {
+		t.Fatal(err)
+	}
+	hash, err := crypto.ComputeContentHashFieldWithAlgorithm(crypto.MerkleHashAlgorithm, content)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ra.Hash, ra.Size = hash, int64(len(content))
+	if raJSON, err = json.Marshal(ra); err != nil {
+		t.Fatal(err)
+	}
+	proofsJSON, err := json.Marshal(artifacts.CreateMerkleProofs(hash, content))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if served == nil {
+		served = content
+	}
+
+	mux.HandleFunc("/people/alice/video.bin", func(w http.ResponseWriter, r *http.Request) {
+		http.ServeContent(w, r, "video.bin", time.Time{}, bytes.NewReader(served))
+	})
+	mux.HandleFunc("/people/alice/video.bin._la_resource.json", func(w http.ResponseWriter, r *http.Request) {
+		w.Write(raJSON)
+	})
+	mux.HandleFunc("/people/alice/video.bin._la_resource.proofs.json", func(w http.ResponseWriter, r *http.Request) {
+		w.Write(proofsJSON)
+	})
+	mux.HandleFunc("/people/alice/_la_namespace.json", func(w http.ResponseWriter, r *http.Request) {
+		w.Write(naJSON)
+	})
+	return srv.URL + "/people/alice"
+}
+
+func TestVerifyRange(t *testing.T) {
+	content := make([]byte, 3*crypto.MerkleChunkSize+500)
+	for i := range content {
+		content[i] = byte(i*7 + i/1000)
+	}
+	base := newRangeServer(t, content, nil)
+	opts := VerificationOptions{Timeout: 5 * time.Second}
+
+	result, err := VerifyRange(base+"/video.bin", base+"/video.bin._la_resource.json", "", crypto.MerkleChunkSize, 0, opts)
+	if err != nil {
+		t.Fatalf("Expected the second chunk to verify, got %v", err)
+	}
+	if result.Offset != crypto.MerkleChunkSize || result.Length != crypto.MerkleChunkSize || result.Size != int64(len(content)) {
+		t.Errorf("Unexpected range result %+v", result)
+	}
+
+	// The last chunk is clipped to the end of the content
+	result, err = VerifyRange(base+"/video.bin", base+"/video.bin._la_resource.json", "", 3*crypto.MerkleChunkSize, 0, opts)
+	if err != nil {
+		t.Fatalf("Expected the last chunk to verify, got %v", err)
+	}
+	if result.Length != 500 {
+		t.Errorf("Expected a 500 byte last chunk, got %d", result.Length)
+	}
+
+	if _, err := VerifyRange(base+"/video.bin", base+"/video.bin._la_resource.json", "", 100, 0, opts); err == nil {
+		t.Error("Expected an unaligned range to fail")
+	}
+}
+
+func TestVerifyRange_ModifiedContent(t *testing.T) {
+	content := bytes.Repeat([]byte("chunk data "), crypto.MerkleChunkSize/5)
+	opts := VerificationOptions{Timeout: 5 * time.Second}
+
+	// The publisher serves different bytes than it attested
+	base := newRangeServer(t, content, bytes.ToUpper(content))
+	if _, err := VerifyRange(base+"/video.bin", base+"/video.bin._la_resource.json", "", 0, 0, opts); err == nil || !strings.Contains(err.Error(), "hash_mismatch") {
+		t.Errorf("Expected modified content to fail with hash_mismatch, got %v", err)
+	}
+
+	// Bytes on another origin are not covered by the RA, even when they match it
+	mirror := newRangeServer(t, content, nil)
+	if _, err := VerifyRange(mirror+"/video.bin", base+"/video.bin._la_resource.json", "", 0, 0, opts); err == nil || !strings.Contains(err.Error(), "resource_presence") {
+		t.Errorf("Expected content from another origin to fail resource presence, got %v", err)
+	}
+}
+
+func TestVerifyRange_KeyPin(t *testing.T) {
+	content := bytes.Repeat([]byte("chunk data "), crypto.MerkleChunkSize/5)
+	base := newRangeServer(t, content, nil)
+	pins, err := LoadPinStore(filepath.Join(t.TempDir(), "pins.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	opts := VerificationOptions{Timeout: 5 * time.Second, Pins: pins, PinMode: pinModeEnforce}
+
+	if _, err := VerifyRange(base+"/video.bin", base+"/video.bin._la_resource.json", "", 0, 0, opts); err != nil {
+		t.Fatalf("Expected the range to verify on first use, got %v", err)
+	}
+	pin, ok := pins.Pins[base+"/"]
+	if !ok {
+		t.Fatalf("Expected the namespace key pinned, got %+v", pins.Pins)
+	}
+
+	pin.Key = strings.Repeat("ab", 32)
+	pins.Pins[base+"/"] = pin
+	if _, err := VerifyRange(base+"/video.bin", base+"/video.bin._la_resource.json", "", 0, 0, opts); err == nil || !strings.Contains(err.Error(), "key_pin") {
+		t.Errorf("Expected a changed key to fail the key pin, got %v", err)
+	}
+}
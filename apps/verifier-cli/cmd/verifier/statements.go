@@ -0,0 +1,49 @@
+// Copyright 2025 Jason Stonebraker
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/stonebraker/lap/sdks/go/pkg/lap/fetch"
+	"github.com/stonebraker/lap/sdks/go/pkg/lap/verify"
+)
+
+// CollectStatements attaches to a verified result the statements about its resource published by
+// opts.TrustedIssuers, checked under the same options as the verification itself. Issuers that cannot
+// be reached are reported in the returned error.
+func CollectStatements(result *verify.VerificationResult, opts VerificationOptions) error {
+	if len(opts.TrustedIssuers) == 0 {
+		return nil
+	}
+	client := fetch.NewClient(opts.Timeout)
+	if opts.Transport != nil {
+		client.Transport = opts.Transport
+	}
+	return fetch.Statements(client, result, opts.TrustedIssuers, opts.verifyOptions())
+}
+
+// statementSummary describes a statement result in one line
+func statementSummary(st verify.StatementResult) string {
+	summary := fmt.Sprintf("%s by %s (%s)", st.Type, st.IssuerNamespace, st.Status)
+	if st.Body != "" {
+		summary += ": " + strings.TrimSpace(st.Body)
+	}
+	if st.Failure != nil {
+		summary += " - " + st.Failure.Reason
+	}
+	return summary
+}
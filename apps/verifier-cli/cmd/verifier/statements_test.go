@@ -0,0 +1,97 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stonebraker/lap/apps/demo-utils/artifacts"
+	"github.com/stonebraker/lap/sdks/go/pkg/lap/fetch"
+)
+
+// newIssuerServer serves Bob's Namespace Attestation and a statement index with one statement of
+// the given type about the resource whose RA is at subjectRAURL
+func newIssuerServer(t *testing.T, subjectRAURL, statementType, body string) string {
+	t.Helper()
+	mux := http.NewServeMux()
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+
+	dir := t.TempDir()
+	keysDir := filepath.Join(dir, "keys")
+	naPath, err := artifacts.CreateNamespaceAttestation(srv.URL+"/people/bob/", "", "", dir, keysDir, "", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	raJSON, err := readLocation(fetch.NewClient(5*time.Second), subjectRAURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	raPath := filepath.Join(dir, "subject.json")
+	if err := os.WriteFile(raPath, raJSON, 0644); err != nil {
+		t.Fatal(err)
+	}
+	naURL := srv.URL + "/people/bob/_la_namespace.json"
+	statementsPath, err := artifacts.CreateStatement(raPath, statementType, body, naURL, "", keysDir, "", filepath.Join(dir, "_la_statements.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	mux.HandleFunc("/people/bob/_la_namespace.json", func(w http.ResponseWriter, r *http.Request) {
+		http.ServeFile(w, r, naPath)
+	})
+	mux.HandleFunc("/people/bob/_la_statements.json", func(w http.ResponseWriter, r *http.Request) {
+		http.ServeFile(w, r, statementsPath)
+	})
+	return naURL
+}
+
+func TestCollectStatements(t *testing.T) {
+	_, pageURL := newPublisherServer(t)
+	issuerURL := newIssuerServer(t, pageURL+"/_la_resource.json", "label", "satire")
+
+	opts := VerificationOptions{Timeout: 5 * time.Second, TrustedIssuers: []string{issuerURL}}
+	result, err := VerifyResource(pageURL, opts)
+	if err != nil {
+		t.Fatalf("VerifyResource failed: %v", err)
+	}
+	if err := CollectStatements(result, opts); err != nil {
+		t.Fatalf("CollectStatements failed: %v", err)
+	}
+	if len(result.Statements) != 1 {
+		t.Fatalf("Expected one statement, got %+v", result.Statements)
+	}
+	st := result.Statements[0]
+	if st.Status != "pass" || st.Type != "label" || st.Body != "satire" || !strings.HasSuffix(st.IssuerNamespace, "/people/bob/") {
+		t.Errorf("Unexpected statement: %+v", st)
+	}
+
+	// Statements from issuers the caller does not list are not collected
+	result, err = VerifyResource(pageURL, VerificationOptions{Timeout: 5 * time.Second})
+	if err != nil {
+		t.Fatalf("VerifyResource failed: %v", err)
+	}
+	if err := CollectStatements(result, VerificationOptions{Timeout: 5 * time.Second}); err != nil || len(result.Statements) != 0 {
+		t.Errorf("Expected no statements without trusted issuers, got %+v (%v)", result.Statements, err)
+	}
+}
+
+func TestCollectStatements_UnreachableIssuer(t *testing.T) {
+	_, pageURL := newPublisherServer(t)
+	opts := VerificationOptions{Timeout: 5 * time.Second, TrustedIssuers: []string{pageURL + "/missing/_la_namespace.json"}}
+	result, err := VerifyResource(pageURL, opts)
+	if err != nil {
+		t.Fatalf("VerifyResource failed: %v", err)
+	}
+	if err := CollectStatements(result, opts); err == nil {
+		t.Error("Expected an error for an unreachable issuer")
+	}
+	if !result.Verified || len(result.Statements) != 0 {
+		t.Errorf("Expected the verified result to be unchanged, got %+v", result)
+	}
+}
@@ -0,0 +1,135 @@
+// Copyright 2025 Jason Stonebraker
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+
+	"github.com/stonebraker/lap/sdks/go/pkg/lap/verify"
+	"github.com/stonebraker/lap/sdks/go/pkg/lap/wire"
+)
+
+// defaultMaxThreadDepth bounds how many in_reply_to hops are followed from each starting URL
+const defaultMaxThreadDepth = 16
+
+// ThreadNode is one post in a thread provenance tree. Every post is verified on its own, against
+// its own namespace; ReplyLink records whether its in_reply_to reference matches the parent's RA.
+type ThreadNode struct {
+	FragmentURL    string                     `json:"fragment_url"`
+	PublisherClaim string                     `json:"publisher_claim,omitempty"`
+	Result         *verify.VerificationResult `json:"result,omitempty"`
+	Error          string                     `json:"error,omitempty"`      // Set when the post could not be verified at all
+	ReplyLink      string                     `json:"reply_link,omitempty"` // Link to the parent: "pass", "pass (stale)", "fail", "skip"
+	ReplyFailure   *verify.FailureDetails     `json:"reply_failure,omitempty"`
+	Replies        []*ThreadNode              `json:"replies,omitempty"`
+}
+
+// threadWalk verifies each post once, however many starting URLs lead to it
+type threadWalk struct {
+	opts  VerificationOptions
+	nodes map[string]*ThreadNode
+	ras   map[string]*wire.ResourceAttestation
+	order []*ThreadNode // Nodes in the order they were first visited
+}
+
+// VerifyThread follows the in_reply_to chain from each URL up to its root, verifying every hop, and
+// returns the roots of the resulting tree. A chain stops at a post that fails verification, since
+// its reference cannot be trusted, and after maxDepth hops (zero selects defaultMaxThreadDepth).
+func VerifyThread(urls []string, maxDepth int, opts VerificationOptions) []*ThreadNode {
+	if maxDepth <= 0 {
+		maxDepth = defaultMaxThreadDepth
+	}
+	walk := &threadWalk{
+		opts:  opts,
+		nodes: make(map[string]*ThreadNode),
+		ras:   make(map[string]*wire.ResourceAttestation),
+	}
+
+	hasParent := make(map[*ThreadNode]bool)
+	for _, start := range urls {
+		node, ra, seen := walk.visit(start)
+		chain := map[*ThreadNode]bool{node: true}
+		for depth := 0; !seen && ra != nil && ra.InReplyTo != nil; depth++ {
+			if depth == maxDepth {
+				node.ReplyLink = "fail"
+				node.ReplyFailure = &verify.FailureDetails{
+					Check:   "reply_link",
+					Reason:  "max_depth",
+					Message: fmt.Sprintf("stopped after %d replies", maxDepth),
+					Details: map[string]interface{}{"parent_url": ra.InReplyTo.FragmentURL},
+				}
+				break
+			}
+
+			var parent *ThreadNode
+			var parentRA *wire.ResourceAttestation
+			parent, parentRA, seen = walk.visit(ra.InReplyTo.FragmentURL)
+			if chain[parent] {
+				node.ReplyLink = "fail"
+				node.ReplyFailure = &verify.FailureDetails{
+					Check:   "reply_link",
+					Reason:  "reply_cycle",
+					Message: fmt.Sprintf("%s is already in this thread", parent.FragmentURL),
+					Details: map[string]interface{}{"parent_url": parent.FragmentURL},
+				}
+				break
+			}
+			chain[parent] = true
+
+			if parentRA == nil {
+				node.ReplyLink = "skip"
+			} else {
+				node.ReplyLink, node.ReplyFailure = verify.VerifyReplyLink(*ra, *parentRA)
+			}
+			parent.Replies = append(parent.Replies, node)
+			hasParent[node] = true
+			node, ra = parent, parentRA
+		}
+	}
+
+	var roots []*ThreadNode
+	for _, node := range walk.order {
+		if !hasParent[node] {
+			roots = append(roots, node)
+		}
+	}
+	return roots
+}
+
+// visit verifies the post at url unless it was already visited. The RA the post was verified against
+// is returned only when it verified, so that its in_reply_to reference can be followed.
+func (w *threadWalk) visit(url string) (*ThreadNode, *wire.ResourceAttestation, bool) {
+	key := verify.NormalizeURL(url)
+	if node, ok := w.nodes[key]; ok {
+		return node, w.ras[key], true
+	}
+
+	node := &ThreadNode{FragmentURL: url}
+	w.nodes[key] = node
+	w.order = append(w.order, node)
+	result, err := VerifyResource(url, w.opts)
+	if err != nil {
+		node.Error = err.Error()
+		return node, nil, false
+	}
+	node.Result = result
+	ra := result.ResourceAttestation
+	if !result.Verified || ra == nil {
+		return node, nil, false
+	}
+	node.PublisherClaim = ra.PublisherClaim
+	w.ras[key] = ra
+	return node, ra, false
+}
@@ -0,0 +1,127 @@
+package main
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stonebraker/lap/sdks/go/pkg/lap/canonical"
+	"github.com/stonebraker/lap/sdks/go/pkg/lap/crypto"
+	"github.com/stonebraker/lap/sdks/go/pkg/lap/fetch"
+	"github.com/stonebraker/lap/sdks/go/pkg/lap/wire"
+)
+
+// newReplyServer serves Westley's post replying to parentURL under his own key and namespace
+func newReplyServer(t *testing.T, parentURL, parentHash string) string {
+	t.Helper()
+	mux := http.NewServeMux()
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+
+	priv, pubKey, err := crypto.GenerateKeyPair()
+	if err != nil {
+		t.Fatal(err)
+	}
+	content := []byte("<p>As you wish.</p>")
+	fragmentURL := srv.URL + "/people/westley/frc/posts/9"
+	naURL := srv.URL + "/people/westley/_la_namespace.json"
+
+	html := fmt.Sprintf(`<html><body>
+<article data-la-spec="v0.2" data-la-fragment-url="%s">
+  <link rel="canonical" type="text/html"
+    data-la-publisher-claim="%s"
+    data-la-resource-attestation-url="%s/_la_resource.json"
+    data-la-namespace-attestation-url="%s"
+    href="data:text/html;base64,%s" hidden />
+</article>
+</body></html>`, fragmentURL, pubKey, fragmentURL, naURL, base64.StdEncoding.EncodeToString(content))
+
+	raJSON, err := json.Marshal(wire.ResourceAttestation{
+		FragmentURL:             fragmentURL,
+		Hash:                    crypto.ComputeContentHashField(content),
+		InReplyTo:               &wire.ReplyReference{FragmentURL: parentURL, Hash: parentHash},
+		PublisherClaim:          pubKey,
+		NamespaceAttestationURL: naURL,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	payload := wire.NamespacePayload{Namespace: srv.URL + "/people/westley/", Exp: time.Now().Add(time.Hour).Unix()}
+	payloadBytes, err := canonical.MarshalNamespacePayloadCanonical(payload.ToCanonical())
+	if err != nil {
+		t.Fatal(err)
+	}
+	sig, err := crypto.SignSchnorrHex(priv, crypto.HashSHA256(payloadBytes))
+	if err != nil {
+		t.Fatal(err)
+	}
+	naJSON, err := json.Marshal(wire.NamespaceAttestation{Payload: payload, Key: pubKey, Sig: sig})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	mux.HandleFunc("/people/westley/frc/posts/9", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(html))
+	})
+	mux.HandleFunc("/people/westley/frc/posts/9/_la_resource.json", func(w http.ResponseWriter, r *http.Request) {
+		w.Write(raJSON)
+	})
+	mux.HandleFunc("/people/westley/_la_namespace.json", func(w http.ResponseWriter, r *http.Request) {
+		w.Write(naJSON)
+	})
+	return fragmentURL
+}
+
+func TestVerifyThread_CrossSiteReply(t *testing.T) {
+	_, parentURL := newPublisherServer(t)
+	parentRA, err := fetch.ResourceAttestation(fetch.NewClient(5*time.Second), parentURL+"/_la_resource.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	replyURL := newReplyServer(t, parentURL, parentRA.Hash)
+
+	// Starting from the reply and the parent yields a single thread
+	roots := VerifyThread([]string{replyURL, parentURL}, 0, VerificationOptions{Timeout: 5 * time.Second})
+	if len(roots) != 1 {
+		t.Fatalf("Expected one thread, got %d roots", len(roots))
+	}
+	root := roots[0]
+	if root.FragmentURL != parentURL || root.Result == nil || !root.Result.Verified {
+		t.Fatalf("Expected verified root %s, got %+v", parentURL, root)
+	}
+	if len(root.Replies) != 1 {
+		t.Fatalf("Expected one reply, got %d", len(root.Replies))
+	}
+	reply := root.Replies[0]
+	if reply.FragmentURL != replyURL || !reply.Result.Verified || reply.ReplyLink != "pass" {
+		t.Errorf("Expected verified reply with passing link, got %+v", reply)
+	}
+	if reply.PublisherClaim == root.PublisherClaim {
+		t.Error("Expected the reply to be verified under a different publisher key")
+	}
+	if !threadVerified(roots) {
+		t.Error("Expected the thread to verify")
+	}
+}
+
+func TestVerifyThread_ReplyHashMismatch(t *testing.T) {
+	_, parentURL := newPublisherServer(t)
+	replyURL := newReplyServer(t, parentURL, crypto.ComputeContentHashField([]byte("<p>Something Alice never wrote</p>")))
+
+	roots := VerifyThread([]string{replyURL}, 0, VerificationOptions{Timeout: 5 * time.Second})
+	if len(roots) != 1 || len(roots[0].Replies) != 1 {
+		t.Fatalf("Expected the reply to be attached to its parent, got %+v", roots)
+	}
+	reply := roots[0].Replies[0]
+	if reply.ReplyLink != "fail" || reply.ReplyFailure == nil || reply.ReplyFailure.Reason != "reply_hash_mismatch" {
+		t.Errorf("Expected reply_hash_mismatch, got %s %+v", reply.ReplyLink, reply.ReplyFailure)
+	}
+	if threadVerified(roots) {
+		t.Error("Expected the thread not to verify")
+	}
+}
@@ -15,23 +15,44 @@
 package main
 
 import (
-	"encoding/base64"
-	"encoding/json"
+	"bytes"
 	"fmt"
 	"io"
 	"net/http"
 	"net/url"
-	"strings"
 	"time"
 
+	"github.com/stonebraker/lap/sdks/go/pkg/lap/fetch"
 	"github.com/stonebraker/lap/sdks/go/pkg/lap/verify"
 	"github.com/stonebraker/lap/sdks/go/pkg/lap/wire"
 )
 
 // VerificationOptions contains configuration for verification
 type VerificationOptions struct {
-	Timeout time.Duration // HTTP timeout
-	Verbose bool          // Debug output
+	Timeout               time.Duration // HTTP timeout
+	Verbose               bool          // Debug output
+	AllowedHashAlgorithms []string      // Accepted RA hash algorithms (empty allows all registered)
+	Transport             http.RoundTripper // Optional HTTP transport, e.g. to record exchanges for an evidence bundle
+	Freshness             verify.FreshnessPolicy // Whether stapled attestations are confirmed live (zero value: always)
+	CrossCheckHeaderRA    bool                   // Also fetch the RA URL when a same-origin response carries an RA header, and require both to match
+	MaxContentBytes       int64                  // Size limit for content fetched from a fragment's content URL (zero: fetch.DefaultMaxContentBytes)
+	RejectStale           bool                   // Fail fragments carrying a superseded version of the content instead of reporting them stale
+	TrustedIssuers        []string               // Namespace Attestation URLs of issuers whose statements are collected
+	ReaderKey             string                 // Hex private key that opens subscriber-only fragments sealed to it
+	Policy                *verify.Policy         // Verifier policy enforced after the three checks (nil: none)
+	Pins                  *PinStore              // Trust-on-first-use namespace key pins (nil: not checked)
+	PinMode               string                 // What a key that differs from its pin does: warn or enforce
+}
+
+// verifyOptions returns the verify package options for a live verification under these options
+func (o VerificationOptions) verifyOptions() verify.Options {
+	return verify.Options{
+		AllowedHashAlgorithms: o.AllowedHashAlgorithms,
+		RejectStale:           o.RejectStale,
+		ReaderKey:             o.ReaderKey,
+		Policy:                o.Policy,
+		Pipeline:              o.pipeline(),
+	}
 }
 
 // VerifyResource performs v0.2 LAP verification using the three-step process
@@ -56,21 +77,9 @@ func VerifyResource(resourceURL string, opts VerificationOptions) (*verify.Verif
 		}, nil
 	}
 
-	client := &http.Client{
-		Timeout: opts.Timeout,
-		CheckRedirect: func(req *http.Request, via []*http.Request) error {
-			if len(via) == 0 {
-				return nil
-			}
-			prev := via[len(via)-1]
-			if !sameOrigin(prev.URL, req.URL) {
-				return fmt.Errorf("cross-origin redirect not allowed")
-			}
-			if len(via) > 10 {
-				return fmt.Errorf("too many redirects")
-			}
-			return nil
-		},
+	client := fetch.NewClient(opts.Timeout)
+	if opts.Transport != nil {
+		client.Transport = opts.Transport
 	}
 
 	// Step 1: Fetch the resource content to extract the fragment
@@ -134,8 +143,26 @@ func VerifyResource(resourceURL string, opts VerificationOptions) (*verify.Verif
 		}, nil
 	}
 
+	// The publisher may attach the RA to the response itself
+	headerValue := resp.Header.Get(wire.AttestationHeaderName)
+	raLink := wire.ResourceAttestationLink(resp.Header.Values("Link"))
+
 	// Step 2: Parse the fragment from the HTML content
-	fragment, err := parseFragmentFromHTML(string(body), resourceURL)
+	fragment, err := wire.ParseFragmentHTML(string(body))
+	if err != nil {
+		// Archived v0.1 fragments are verified with the v0.1 rules
+		if article, ok := wire.FindFragmentV01(string(body)); ok {
+			return verifyLegacyFragment(client, article, nil, nil, resp.Header.Get("ETag"), opts), nil
+		}
+		// A page quoting another resource carries an excerpt instead of a fragment
+		if excerpt, excerptErr := wire.ParseExcerptHTML(string(body)); excerptErr == nil {
+			return verifyExcerpt(client, excerpt, nil, nil, opts), nil
+		}
+	}
+	if err != nil && (raLink != "" || headerValue != "") {
+		// Resources without a fragment, such as images, are attested through their response headers
+		return verifyDirectResource(client, resourceURL, resp, body, raLink, headerValue, opts), nil
+	}
 	if err != nil {
 		return &verify.VerificationResult{
 			Verified:         false,
@@ -154,15 +181,67 @@ func VerifyResource(resourceURL string, opts VerificationOptions) (*verify.Verif
 		}, nil
 	}
 
-	// Step 3: Fetch the Resource Attestation
-	resourceAttestation, err := fetchResourceAttestation(client, fragment.ResourceAttestationURL)
+	if spec, ok := wire.NormalizeSpec(fragment.Spec); !ok || spec != wire.SpecV02 {
+		return unsupportedSpec(fragment), nil
+	}
+
+	// Content referenced by URL rather than inlined is fetched before any check runs
+	if failed := fetch.LoadContent(client, fragment, opts.MaxContentBytes); failed != nil {
+		return failed, nil
+	}
+
+	// Stapled attestations are checked first; fetching the live ones is a freshness step
+	return verifyWithStaples(fragment, opts, func(stapledNA *wire.NamespaceAttestation) *verify.VerificationResult {
+		return verifyFetched(client, fragment, headerValue, resp.Request.URL, stapledNA, opts)
+	}), nil
+}
+
+// verifyWithStaples verifies a fragment's stapled attestations immediately and, unless the freshness
+// policy is never, confirms them by running live(). Staples alone never verify the fragment: with no
+// live check the result fails with verify.ReasonStapledOnly. Under the max-age policy a fresh stapled
+// NA is passed to live() to use instead of fetching the NA. Fragments without staples go straight to live(nil).
+func verifyWithStaples(fragment *wire.Fragment, opts VerificationOptions, live func(stapledNA *wire.NamespaceAttestation) *verify.VerificationResult) *verify.VerificationResult {
+	// Staples carry only the publisher's NA, so co-authored fragments are always checked live
+	if !verify.HasStapledAttestations(*fragment) || len(fragment.CoPublishers) > 0 {
+		return live(nil)
+	}
+
+	stapled := verify.VerifyStapled(*fragment, opts.verifyOptions())
+	staple := stapled.Context.Stapled
+	staple.Freshness = opts.Freshness.Mode
+	if staple.Freshness == "" {
+		staple.Freshness = verify.FreshnessAlways
+	}
+	if !verify.IsStapledOnly(stapled) || !opts.Freshness.NeedsLiveCheck() {
+		return &stapled
+	}
+
+	var stapledNA *wire.NamespaceAttestation
+	if opts.Freshness.UseStapledNA(staple, time.Now()) {
+		if na, err := verify.StapledNamespaceAttestation(*fragment); err == nil {
+			stapledNA = &na
+		}
+	}
+	result := live(stapledNA)
+	staple.LiveChecked = true
+	staple.StapledNA = stapledNA != nil
+	result.Context.Stapled = staple
+	return result
+}
+
+// verifyFetched fetches the fragment's attestations and performs v0.2 verification.
+// headerValue is the RA header of the resource response served from responseURL (after redirects),
+// or "" when there was none. A non-nil stapledNA is used instead of fetching the NA.
+func verifyFetched(client *http.Client, fragment *wire.Fragment, headerValue string, responseURL *url.URL, stapledNA *wire.NamespaceAttestation, opts VerificationOptions) *verify.VerificationResult {
+	// Step 3: Fetch the Resource Attestation, unless a response from the fragment's origin carried it
+	resourceAttestation, source, err := fetch.ResolveResourceAttestation(client, fragment, headerValue, responseURL, opts.CrossCheckHeaderRA)
 	if err != nil {
 		return &verify.VerificationResult{
 			Verified:         false,
 			ResourcePresence: "fail",
 			Failure: &verify.FailureDetails{
 				Check:   "resource_presence",
-				Reason:  "fetch_failed",
+				Reason:  fetch.FailureReason(err),
 				Message: fmt.Sprintf("failed to fetch resource attestation: %v", err),
 				Details: map[string]interface{}{
 					"resource_attestation_url": fragment.ResourceAttestationURL,
@@ -173,12 +252,50 @@ func VerifyResource(resourceURL string, opts VerificationOptions) (*verify.Verif
 				NamespaceAttestationURL: fragment.NamespaceAttestationURL,
 				VerifiedAt:             time.Now().Unix(),
 			},
-		}, nil
+		}
 	}
 
-	// Ensure Resource Attestation has required fields
-	resourceAttestation, err = validateRequiredResourceAttestationFields(*resourceAttestation) 
+	return verifyWithResourceAttestation(client, fragment, resourceAttestation, source, stapledNA, opts)
+}
+
+// verifyDirectResource verifies a resource that is its own content, such as an image or a PDF.
+// Its RA is named by the response's Link header or carried in its RA header; with no fragment to
+// declare them, the publisher claim and Namespace Attestation URL are taken from the RA.
+func verifyDirectResource(client *http.Client, resourceURL string, resp *http.Response, body []byte, raLink, headerValue string, opts VerificationOptions) *verify.VerificationResult {
+	fragment := &wire.Fragment{
+		Spec:             "v0.2",
+		FragmentURL:      resourceURL,
+		CanonicalContent: body,
+		ContentType:      resp.Header.Get("Content-Type"),
+		// An RA carried only in the header came with the resource itself
+		ResourceAttestationURL: resourceURL,
+	}
+	if raLink != "" {
+		ref, err := url.Parse(raLink)
+		if err != nil {
+			return verify.FailedResult(fragment, "resource_presence", "malformed", fmt.Sprintf("invalid Link header target: %v", err), nil)
+		}
+		fragment.ResourceAttestationURL = resp.Request.URL.ResolveReference(ref).String()
+	}
+
+	resourceAttestation, source, err := fetch.ResolveResourceAttestation(client, fragment, headerValue, resp.Request.URL, opts.CrossCheckHeaderRA)
 	if err != nil {
+		return verify.FailedResult(fragment, "resource_presence", fetch.FailureReason(err), fmt.Sprintf("failed to fetch resource attestation: %v", err), map[string]interface{}{
+			"resource_attestation_url": fragment.ResourceAttestationURL,
+		})
+	}
+	fragment.PublisherClaim = resourceAttestation.PublisherClaim
+	fragment.NamespaceAttestationURL = resourceAttestation.NamespaceAttestationURL
+	fragment.CoPublishers = wire.ListedCoPublishers(*resourceAttestation)
+
+	return verifyWithResourceAttestation(client, fragment, resourceAttestation, source, nil, opts)
+}
+
+// verifyWithResourceAttestation validates the RA, fetches the Namespace Attestation unless a stapled one
+// is given, and performs v0.2 verification
+func verifyWithResourceAttestation(client *http.Client, fragment *wire.Fragment, resourceAttestation *wire.ResourceAttestation, source string, stapledNA *wire.NamespaceAttestation, opts VerificationOptions) *verify.VerificationResult {
+	// Ensure Resource Attestation has required fields
+	if err := fetch.ValidateResourceAttestation(*resourceAttestation); err != nil {
 		return &verify.VerificationResult{
 			Verified:         false,
 			ResourcePresence: "fail",
@@ -195,12 +312,16 @@ func VerifyResource(resourceURL string, opts VerificationOptions) (*verify.Verif
 				NamespaceAttestationURL: fragment.NamespaceAttestationURL,
 				VerifiedAt:             time.Now().Unix(),
 			},
-		}, nil
+		}
 	}
 	
 
-	// Step 4: Fetch the Namespace Attestation
-	namespaceAttestation, err := fetchNamespaceAttestation(client, fragment.NamespaceAttestationURL)
+	// Step 4: Fetch the Namespace Attestation, unless a fresh stapled one stands in for it
+	namespaceAttestation := stapledNA
+	var err error
+	if namespaceAttestation == nil {
+		namespaceAttestation, err = fetch.NamespaceAttestation(client, fragment.NamespaceAttestationURL)
+	}
 	if err != nil {
 		return &verify.VerificationResult{
 			Verified:             false,
@@ -209,7 +330,7 @@ func VerifyResource(resourceURL string, opts VerificationOptions) (*verify.Verif
 			PublisherAssociation: "fail",
 			Failure: &verify.FailureDetails{
 				Check:   "publisher_association",
-				Reason:  "fetch_failed",
+				Reason:  fetch.FailureReason(err),
 				Message: fmt.Sprintf("failed to fetch namespace attestation: %v", err),
 				Details: map[string]interface{}{
 					"namespace_attestation_url": fragment.NamespaceAttestationURL,
@@ -220,214 +341,169 @@ func VerifyResource(resourceURL string, opts VerificationOptions) (*verify.Verif
 				NamespaceAttestationURL: fragment.NamespaceAttestationURL,
 				VerifiedAt:             time.Now().Unix(),
 			},
-		}, nil
+		}
 	}
 
-	// Step 5: Perform v0.2 verification using the verify package
-	result := verify.VerifyFragment(*fragment, *resourceAttestation, *namespaceAttestation)
+	// Step 5: Perform v0.2 verification using the verify package, with each co-publisher's NA
+	coAttestations := fetch.CoPublisherAttestations(client, *resourceAttestation)
+	result := verify.VerifyFragmentWithCoPublishers(*fragment, *resourceAttestation, *namespaceAttestation, coAttestations, opts.verifyOptions())
 	
 	// Update context with URLs
 	result.Context.ResourceAttestationURL = fragment.ResourceAttestationURL
 	result.Context.NamespaceAttestationURL = fragment.NamespaceAttestationURL
+	result.Context.ResourceAttestationSource = source
 
-	return &result, nil
-}
-
-// parseFragmentFromHTML extracts a LAP fragment from HTML content
-func parseFragmentFromHTML(htmlContent, resourceURL string) (*wire.Fragment, error) {
-	// Look for any fragment in the HTML (we'll use the first one found)
-	needle := `data-la-fragment-url="`
-	idx := strings.Index(htmlContent, needle)
-	if idx < 0 {
-		return nil, fmt.Errorf("no fragment found with data-la-fragment-url attribute")
-	}
-
-	// Extract the actual fragment URL from the HTML
-	fragmentURLStart := idx + len(needle)
-	fragmentURLEnd := strings.Index(htmlContent[fragmentURLStart:], `"`)
-	if fragmentURLEnd < 0 {
-		return nil, fmt.Errorf("fragment structure malformed: incomplete data-la-fragment-url attribute")
-	}
-	fragmentURL := htmlContent[fragmentURLStart : fragmentURLStart+fragmentURLEnd]
-
-	// Find the start of the article element
-	start := strings.LastIndex(htmlContent[:idx], "<article")
-	if start < 0 {
-		return nil, fmt.Errorf("fragment structure malformed: no <article> tag found")
-	}
-
-	// Find the end of the article element
-	rest := htmlContent[start:]
-	depth := 0
-	i := 0
-	for i < len(rest) {
-		if rest[i] == '<' {
-			if strings.HasPrefix(rest[i:], "<article") {
-				depth++
-			} else if strings.HasPrefix(rest[i:], "</article") {
-				depth--
-				endTag := strings.Index(rest[i:], ">")
-				if endTag >= 0 {
-					i += endTag + 1
-				} else {
-					break
-				}
-				if depth == 0 {
-					endAbs := start + i
-					articleHTML := htmlContent[start:endAbs]
-					return parseFragmentFromArticle(articleHTML, fragmentURL)
-				}
-				continue
-			}
-			end := strings.Index(rest[i:], ">")
-			if end >= 0 {
-				i += end + 1
-				continue
-			}
-			break
-		}
-		i++
-	}
-
-	return nil, fmt.Errorf("fragment structure malformed: incomplete <article> tag")
+	return &result
 }
 
-// parseFragmentFromArticle parses a fragment from an article HTML element
-func parseFragmentFromArticle(articleHTML, resourceURL string) (*wire.Fragment, error) {
-	fragment := &wire.Fragment{
-		Spec:        "v0.2",
-		FragmentURL: resourceURL,
+// VerifyDocument verifies the first fragment in an HTML document read from a file or stdin.
+// raJSON and naJSON hold locally saved attestations; when nil, the attestation is fetched from the
+// URL in the fragment. With a local RA the check runs offline and Resource Presence is reported as
+// "skip (offline)", since a saved copy cannot show the publisher is still distributing the resource.
+func VerifyDocument(htmlContent string, raJSON, naJSON []byte, opts VerificationOptions) (*verify.VerificationResult, error) {
+	client := fetch.NewClient(opts.Timeout)
+	if opts.Transport != nil {
+		client.Transport = opts.Transport
 	}
 
-	// Extract publisher claim
-	if idx := strings.Index(articleHTML, `data-la-publisher-claim="`); idx >= 0 {
-		start := idx + len(`data-la-publisher-claim="`)
-		end := strings.Index(articleHTML[start:], `"`)
-		if end >= 0 {
-			fragment.PublisherClaim = articleHTML[start : start+end]
+	fragment, err := wire.ParseFragmentHTML(htmlContent)
+	if err != nil {
+		if article, ok := wire.FindFragmentV01(htmlContent); ok {
+			return verifyLegacyFragment(client, article, raJSON, naJSON, "", opts), nil
 		}
-	}
-
-	// Extract resource attestation URL
-	if idx := strings.Index(articleHTML, `data-la-resource-attestation-url="`); idx >= 0 {
-		start := idx + len(`data-la-resource-attestation-url="`)
-		end := strings.Index(articleHTML[start:], `"`)
-		if end >= 0 {
-			fragment.ResourceAttestationURL = articleHTML[start : start+end]
+		if excerpt, excerptErr := wire.ParseExcerptHTML(htmlContent); excerptErr == nil {
+			return verifyExcerpt(client, excerpt, raJSON, naJSON, opts), nil
 		}
+		return verify.FailedResult(nil, "resource_presence", "malformed", fmt.Sprintf("failed to parse fragment: %v", err), nil), nil
 	}
-
-	// Extract namespace attestation URL
-	if idx := strings.Index(articleHTML, `data-la-namespace-attestation-url="`); idx >= 0 {
-		start := idx + len(`data-la-namespace-attestation-url="`)
-		end := strings.Index(articleHTML[start:], `"`)
-		if end >= 0 {
-			fragment.NamespaceAttestationURL = articleHTML[start : start+end]
-		}
+	if spec, ok := wire.NormalizeSpec(fragment.Spec); !ok || spec != wire.SpecV02 {
+		return unsupportedSpec(fragment), nil
 	}
 
-	// Extract canonical content from href
-	if idx := strings.Index(articleHTML, `href="data:text/html;base64,`); idx >= 0 {
-		start := idx + len(`href="data:text/html;base64,`)
-		end := strings.Index(articleHTML[start:], `"`)
-		if end >= 0 {
-			base64Content := articleHTML[start : start+end]
-			canonicalBytes, err := base64.StdEncoding.DecodeString(base64Content)
-			if err != nil {
-				return nil, fmt.Errorf("failed to decode base64 content: %v", err)
-			}
-			fragment.CanonicalContent = canonicalBytes
-			fragment.PreviewContent = string(canonicalBytes)
-		}
+	// Content referenced by URL is fetched even when the attestations are local
+	if failed := fetch.LoadContent(client, fragment, opts.MaxContentBytes); failed != nil {
+		return failed, nil
 	}
 
-	// Validate required fields
-	if fragment.PublisherClaim == "" {
-		return nil, fmt.Errorf("missing data-la-publisher-claim")
-	}
-	if fragment.ResourceAttestationURL == "" {
-		return nil, fmt.Errorf("missing data-la-resource-attestation-url")
-	}
-	if fragment.NamespaceAttestationURL == "" {
-		return nil, fmt.Errorf("missing data-la-namespace-attestation-url")
-	}
-	if len(fragment.CanonicalContent) == 0 {
-		return nil, fmt.Errorf("missing canonical content in href")
+	// Without local attestations, a saved page's staples can still be checked offline
+	if raJSON == nil && naJSON == nil {
+		return verifyWithStaples(fragment, opts, func(stapledNA *wire.NamespaceAttestation) *verify.VerificationResult {
+			return verifyFetched(client, fragment, "", nil, stapledNA, opts)
+		}), nil
 	}
 
-	return fragment, nil
-}
-
-// fetchResourceAttestation fetches and parses a Resource Attestation
-func fetchResourceAttestation(client *http.Client, url string) (*wire.ResourceAttestation, error) {
-	resp, err := client.Get(url)
-	if err != nil {
-		return nil, fmt.Errorf("fetch failed: %v", err)
+	// Load the Resource Attestation from the local copy, or fetch it
+	var resourceAttestation *wire.ResourceAttestation
+	if raJSON != nil {
+		ra, err := wire.DecodeResourceAttestation(bytes.NewReader(raJSON))
+		if err != nil {
+			return verify.FailedResult(fragment, "resource_presence", wire.MalformedReason(err), fmt.Sprintf("failed to parse resource attestation: %v", err), nil), nil
+		}
+		resourceAttestation = &ra
+	} else {
+		resourceAttestation, err = fetch.ResourceAttestation(client, fragment.ResourceAttestationURL)
+		if err != nil {
+			return verify.FailedResult(fragment, "resource_presence", fetch.FailureReason(err), fmt.Sprintf("failed to fetch resource attestation: %v", err), map[string]interface{}{
+				"resource_attestation_url": fragment.ResourceAttestationURL,
+			}), nil
+		}
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode != 200 {
-		return nil, fmt.Errorf("fetch failed with status %d", resp.StatusCode)
+	if err := fetch.ValidateResourceAttestation(*resourceAttestation); err != nil {
+		return verify.FailedResult(fragment, "resource_presence", "malformed", fmt.Sprintf("failed to validate resource attestation fields: %v", err), nil), nil
 	}
 
-	var attestation wire.ResourceAttestation
-	if err := json.NewDecoder(resp.Body).Decode(&attestation); err != nil {
-		return nil, fmt.Errorf("invalid JSON in attestation: %v", err)
+	// Load the Namespace Attestation from the local copy, or fetch it
+	var namespaceAttestation *wire.NamespaceAttestation
+	if naJSON != nil {
+		na, err := wire.DecodeNamespaceAttestation(bytes.NewReader(naJSON))
+		if err == nil {
+			err = fetch.ValidateNamespaceAttestation(na)
+		}
+		if err != nil {
+			reason := wire.MalformedReason(err)
+			if reason == "" {
+				reason = "malformed"
+			}
+			result := verify.FailedResult(fragment, "publisher_association", reason, fmt.Sprintf("failed to parse namespace attestation: %v", err), nil)
+			result.ResourcePresence = "pass"
+			result.ResourceIntegrity = "pass"
+			return result, nil
+		}
+		namespaceAttestation = &na
+	} else {
+		namespaceAttestation, err = fetch.NamespaceAttestation(client, fragment.NamespaceAttestationURL)
+		if err != nil {
+			result := verify.FailedResult(fragment, "publisher_association", fetch.FailureReason(err), fmt.Sprintf("failed to fetch namespace attestation: %v", err), map[string]interface{}{
+				"namespace_attestation_url": fragment.NamespaceAttestationURL,
+			})
+			result.ResourcePresence = "pass"
+			result.ResourceIntegrity = "pass"
+			return result, nil
+		}
 	}
 
-	return &attestation, nil
+	// Co-publishers' Namespace Attestations are always fetched
+	coAttestations := fetch.CoPublisherAttestations(client, *resourceAttestation)
+	verifyOpts := opts.verifyOptions()
+	verifyOpts.Offline = raJSON != nil
+	result := verify.VerifyFragmentWithCoPublishers(*fragment, *resourceAttestation, *namespaceAttestation, coAttestations, verifyOpts)
+	return &result, nil
 }
 
-func validateRequiredResourceAttestationFields(attestation wire.ResourceAttestation) (*wire.ResourceAttestation, error) {
-	// Validate required fields
-	if attestation.FragmentURL == "" {
-		return nil, fmt.Errorf("malformed attestation: missing fragment_url field")
-	}
-	if attestation.Hash == "" {
-		return nil, fmt.Errorf("malformed attestation: missing hash field")
-	}
-	if attestation.PublisherClaim == "" {
-		return nil, fmt.Errorf("malformed attestation: missing publisher_claim field")
-	}
-	if attestation.NamespaceAttestationURL == "" {
-		return nil, fmt.Errorf("malformed attestation: missing namespace_attestation_url field")
-	}
-
-	return &attestation, nil
+// unsupportedSpec reports a fragment declaring a version this verifier cannot dispatch to
+func unsupportedSpec(fragment *wire.Fragment) *verify.VerificationResult {
+	result := verify.UnsupportedSpecResult(fragment.FragmentURL, fragment.Spec)
+	result.Context.ResourceAttestationURL = fragment.ResourceAttestationURL
+	result.Context.NamespaceAttestationURL = fragment.NamespaceAttestationURL
+	return &result
 }
 
-// fetchNamespaceAttestation fetches and parses a Namespace Attestation
-func fetchNamespaceAttestation(client *http.Client, url string) (*wire.NamespaceAttestation, error) {
-	resp, err := client.Get(url)
-	if err != nil {
-		return nil, fmt.Errorf("fetch failed: %v", err)
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != 200 {
-		return nil, fmt.Errorf("fetch failed with status %d", resp.StatusCode)
-	}
-
-	var attestation wire.NamespaceAttestation
-	if err := json.NewDecoder(resp.Body).Decode(&attestation); err != nil {
-		return nil, fmt.Errorf("invalid JSON in attestation: %v", err)
-	}
-
-	// Validate required fields
-	if attestation.Payload.Namespace == "" {
-		return nil, fmt.Errorf("malformed attestation: missing payload.namespace field")
-	}
-	if attestation.Key == "" {
-		return nil, fmt.Errorf("malformed attestation: missing key field")
+// verifyExcerpt loads the quoted resource's attestations, from the local copies when given, and
+// verifies the excerpt against them
+func verifyExcerpt(client *http.Client, excerpt *wire.Excerpt, raJSON, naJSON []byte, opts VerificationOptions) *verify.VerificationResult {
+	fragment := &wire.Fragment{
+		FragmentURL:             excerpt.FragmentURL,
+		ResourceAttestationURL:  excerpt.ResourceAttestationURL,
+		NamespaceAttestationURL: excerpt.NamespaceAttestationURL,
+	}
+
+	var ra wire.ResourceAttestation
+	var err error
+	if raJSON != nil {
+		ra, err = wire.DecodeResourceAttestation(bytes.NewReader(raJSON))
+	} else {
+		var fetched *wire.ResourceAttestation
+		if fetched, err = fetch.ResourceAttestation(client, excerpt.ResourceAttestationURL); err == nil {
+			ra = *fetched
+		}
 	}
-	if attestation.Sig == "" {
-		return nil, fmt.Errorf("malformed attestation: missing sig field")
+	if err != nil {
+		return verify.FailedResult(fragment, "resource_presence", fetch.FailureReason(err), fmt.Sprintf("failed to load resource attestation: %v", err), map[string]interface{}{
+			"resource_attestation_url": excerpt.ResourceAttestationURL,
+		})
+	}
+
+	var na wire.NamespaceAttestation
+	if naJSON != nil {
+		na, err = wire.DecodeNamespaceAttestation(bytes.NewReader(naJSON))
+	} else {
+		var fetched *wire.NamespaceAttestation
+		if fetched, err = fetch.NamespaceAttestation(client, excerpt.NamespaceAttestationURL); err == nil {
+			na = *fetched
+		}
 	}
-
-	return &attestation, nil
-}
-
-// Helper functions
-func sameOrigin(a, b *url.URL) bool {
-	return strings.EqualFold(a.Scheme, b.Scheme) && strings.EqualFold(a.Host, b.Host)
+	if err != nil {
+		result := verify.FailedResult(fragment, "publisher_association", fetch.FailureReason(err), fmt.Sprintf("failed to load namespace attestation: %v", err), map[string]interface{}{
+			"namespace_attestation_url": excerpt.NamespaceAttestationURL,
+		})
+		result.ResourcePresence = "pass"
+		result.ExcerptInclusion = "skip"
+		return result
+	}
+
+	verifyOpts := opts.verifyOptions()
+	verifyOpts.Offline = raJSON != nil
+	result := verify.VerifyExcerpt(*excerpt, ra, na, verifyOpts)
+	return &result
 }
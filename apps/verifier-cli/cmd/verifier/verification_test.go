@@ -15,10 +15,22 @@
 package main
 
 import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 	"time"
 
+	"github.com/stonebraker/lap/apps/demo-utils/artifacts"
+	"github.com/stonebraker/lap/sdks/go/pkg/lap/canonical"
+	"github.com/stonebraker/lap/sdks/go/pkg/lap/crypto"
+	"github.com/stonebraker/lap/sdks/go/pkg/lap/verify"
 	"github.com/stonebraker/lap/sdks/go/pkg/lap/wire"
 )
 
@@ -111,9 +123,9 @@ hidden />
 </body>
 </html>`
 
-	fragment, err := parseFragmentFromHTML(html, "https://example.com/people/alice/frc/posts/1")
+	fragment, err := wire.ParseFragmentHTML(html)
 	if err != nil {
-		t.Fatalf("parseFragmentFromHTML failed: %v", err)
+		t.Fatalf("ParseFragmentHTML failed: %v", err)
 	}
 
 	// Verify fragment fields
@@ -158,7 +170,7 @@ func TestParseFragmentFromHTML_NoFragment(t *testing.T) {
 </body>
 </html>`
 
-	_, err := parseFragmentFromHTML(html, "https://example.com/people/alice/frc/posts/1")
+	_, err := wire.ParseFragmentHTML(html)
 	if err == nil {
 		t.Error("Expected error when no fragment found")
 	}
@@ -182,7 +194,7 @@ func TestParseFragmentFromHTML_MalformedFragment(t *testing.T) {
 </body>
 </html>`
 
-	_, err := parseFragmentFromHTML(html, "https://example.com/people/alice/frc/posts/1")
+	_, err := wire.ParseFragmentHTML(html)
 	if err == nil {
 		t.Error("Expected error when fragment is malformed")
 	}
@@ -206,3 +218,706 @@ func TestVerificationOptions(t *testing.T) {
 		t.Error("Expected verbose to be true")
 	}
 }
+
+// createSignedDocument returns a page under base containing a signed fragment, plus its RA and NA JSON
+func createSignedDocument(t *testing.T, base string) (string, []byte, []byte) {
+	t.Helper()
+
+	priv, pubKey, err := crypto.GenerateKeyPair()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	content := []byte("<h2>Saved Post</h2><p>Read offline.</p>")
+	fragmentURL := base + "/people/alice/frc/posts/1"
+	raURL := fragmentURL + "/_la_resource.json"
+	naURL := base + "/people/alice/_la_namespace.json"
+
+	html := fmt.Sprintf(`<html><body>
+<article data-la-spec="v0.2" data-la-fragment-url="%s">
+  <link rel="canonical" type="text/html"
+    data-la-publisher-claim="%s"
+    data-la-resource-attestation-url="%s"
+    data-la-namespace-attestation-url="%s"
+    href="data:text/html;base64,%s" hidden />
+</article>
+</body></html>`, fragmentURL, pubKey, raURL, naURL, base64.StdEncoding.EncodeToString(content))
+
+	raJSON, err := json.Marshal(wire.ResourceAttestation{
+		FragmentURL:             fragmentURL,
+		Hash:                    crypto.ComputeContentHashField(content),
+		PublisherClaim:          pubKey,
+		NamespaceAttestationURL: naURL,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	payload := wire.NamespacePayload{Namespace: base + "/people/alice/", Exp: time.Now().Add(time.Hour).Unix()}
+	payloadBytes, err := canonical.MarshalNamespacePayloadCanonical(payload.ToCanonical())
+	if err != nil {
+		t.Fatal(err)
+	}
+	sig, err := crypto.SignSchnorrHex(priv, crypto.HashSHA256(payloadBytes))
+	if err != nil {
+		t.Fatal(err)
+	}
+	naJSON, err := json.Marshal(wire.NamespaceAttestation{Payload: payload, Key: pubKey, Sig: sig})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return html, raJSON, naJSON
+}
+
+// createSignedDocumentAt signs content as Alice's fragment at path under base
+func createSignedDocumentAt(t *testing.T, base, path string, content []byte) (string, []byte, []byte) {
+	t.Helper()
+
+	priv, pubKey, err := crypto.GenerateKeyPair()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fragmentURL := base + path
+	raURL := fragmentURL + "/_la_resource.json"
+	naURL := base + "/people/alice/_la_namespace.json"
+
+	html := fmt.Sprintf(`<html><body>
+<article data-la-spec="v0.2" data-la-fragment-url="%s">
+  <link rel="canonical" type="text/html"
+    data-la-publisher-claim="%s"
+    data-la-resource-attestation-url="%s"
+    data-la-namespace-attestation-url="%s"
+    href="data:text/html;base64,%s" hidden />
+</article>
+</body></html>`, fragmentURL, pubKey, raURL, naURL, base64.StdEncoding.EncodeToString(content))
+
+	raJSON, err := json.Marshal(wire.ResourceAttestation{
+		FragmentURL:             fragmentURL,
+		Hash:                    crypto.ComputeContentHashField(content),
+		PublisherClaim:          pubKey,
+		NamespaceAttestationURL: naURL,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	payload := wire.NamespacePayload{Namespace: base + "/people/alice/", Exp: time.Now().Add(time.Hour).Unix()}
+	payloadBytes, err := canonical.MarshalNamespacePayloadCanonical(payload.ToCanonical())
+	if err != nil {
+		t.Fatal(err)
+	}
+	sig, err := crypto.SignSchnorrHex(priv, crypto.HashSHA256(payloadBytes))
+	if err != nil {
+		t.Fatal(err)
+	}
+	naJSON, err := json.Marshal(wire.NamespaceAttestation{Payload: payload, Key: pubKey, Sig: sig})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return html, raJSON, naJSON
+}
+
+func TestVerifyDocument_Offline(t *testing.T) {
+	html, raJSON, naJSON := createSignedDocument(t, "https://example.com")
+
+	result, err := VerifyDocument(html, raJSON, naJSON, VerificationOptions{Timeout: time.Second})
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if !result.Verified {
+		t.Fatalf("Expected offline verification to pass, got %+v", result.Failure)
+	}
+	if result.ResourcePresence != verify.StatusSkipOffline {
+		t.Errorf("Expected resource presence '%s', got '%s'", verify.StatusSkipOffline, result.ResourcePresence)
+	}
+	if result.ResourceIntegrity != "pass" || result.PublisherAssociation != "pass" {
+		t.Errorf("Expected integrity and association to pass, got %s/%s", result.ResourceIntegrity, result.PublisherAssociation)
+	}
+}
+
+func TestVerifyDocument_OfflineTamperedContent(t *testing.T) {
+	html, raJSON, naJSON := createSignedDocument(t, "https://example.com")
+	tampered := strings.Replace(html, "base64,", "base64,AAAA", 1)
+
+	result, err := VerifyDocument(tampered, raJSON, naJSON, VerificationOptions{Timeout: time.Second})
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if result.Verified || result.ResourceIntegrity != "fail" {
+		t.Errorf("Expected resource integrity failure, got %+v", result)
+	}
+}
+
+func TestVerifyDocument_Policy(t *testing.T) {
+	html, raJSON, naJSON := createSignedDocument(t, "http://example.com")
+
+	policyPath := filepath.Join(t.TempDir(), "policy.json")
+	if err := os.WriteFile(policyPath, []byte(`{"require_https": true, "max_na_lifetime": 86400}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	policy, err := verify.LoadPolicy(policyPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := VerifyDocument(html, raJSON, naJSON, VerificationOptions{Timeout: time.Second, Policy: policy})
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if result.Verified || result.Failure.Check != "policy" || result.Failure.Reason != "insecure_scheme" {
+		t.Fatalf("Expected policy/insecure_scheme, got %+v", result.Failure)
+	}
+	if result.PublisherAssociation != "pass" {
+		t.Errorf("Expected the three checks to pass, got %s", result.PublisherAssociation)
+	}
+	if result.Context.Policy == nil || result.Context.Policy.MaxNALifetime != 86400 {
+		t.Errorf("Expected the policy in effect in the context, got %+v", result.Context.Policy)
+	}
+}
+
+func TestVerifyDocument_MalformedLocalAttestation(t *testing.T) {
+	html, raJSON, naJSON := createSignedDocument(t, "https://example.com")
+
+	result, err := VerifyDocument(html, append(raJSON, []byte("{}")...), naJSON, VerificationOptions{Timeout: time.Second})
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if result.Failure == nil || result.Failure.Reason != "malformed_trailing_data" {
+		t.Errorf("Expected failure reason 'malformed_trailing_data', got %+v", result.Failure)
+	}
+
+	result, err = VerifyDocument(html, raJSON, []byte(`{"payload":{"namespace":"https://example.com/","exp":1}}`), VerificationOptions{Timeout: time.Second})
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if result.PublisherAssociation != "fail" || result.Failure == nil || result.Failure.Reason != "malformed" {
+		t.Errorf("Expected publisher association 'malformed' failure, got %+v", result.Failure)
+	}
+}
+
+// stapleDocument embeds raJSON and naJSON in the page's fragment as stapled attestations
+func stapleDocument(html string, raJSON, naJSON []byte, stapledAt int64) string {
+	scripts := fmt.Sprintf(`<script type="application/lap+json" data-la-stapled="resource-attestation" data-la-stapled-at="%d">%s</script>
+<script type="application/lap+json" data-la-stapled="namespace-attestation" data-la-stapled-at="%d">%s</script>
+</article>`, stapledAt, raJSON, stapledAt, naJSON)
+	return strings.Replace(html, "</article>", scripts, 1)
+}
+
+func TestParseFragmentFromHTML_Stapled(t *testing.T) {
+	html, raJSON, naJSON := createSignedDocument(t, "https://example.com")
+
+	fragment, err := wire.ParseFragmentHTML(stapleDocument(html, raJSON, naJSON, 1700000000))
+	if err != nil {
+		t.Fatalf("ParseFragmentHTML failed: %v", err)
+	}
+	if string(fragment.StapledResourceAttestation) != string(raJSON) {
+		t.Errorf("Expected exact stapled RA bytes, got %s", fragment.StapledResourceAttestation)
+	}
+	if string(fragment.StapledNamespaceAttestation) != string(naJSON) {
+		t.Errorf("Expected exact stapled NA bytes, got %s", fragment.StapledNamespaceAttestation)
+	}
+	if fragment.StapledAt != 1700000000 {
+		t.Errorf("Expected stapled at 1700000000, got %d", fragment.StapledAt)
+	}
+
+	duplicated := stapleDocument(stapleDocument(html, raJSON, naJSON, 1), raJSON, naJSON, 2)
+	if _, err := wire.ParseFragmentHTML(duplicated); err == nil {
+		t.Error("Expected duplicate stapled attestations to be rejected")
+	}
+}
+
+func TestVerifyDocument_StapledWithoutNetwork(t *testing.T) {
+	// The .invalid host cannot resolve, so any live fetch would fail
+	html, raJSON, naJSON := createSignedDocument(t, "https://publisher.invalid")
+	stapled := stapleDocument(html, raJSON, naJSON, time.Now().Unix())
+
+	result, err := VerifyDocument(stapled, nil, nil, VerificationOptions{
+		Timeout:   time.Second,
+		Freshness: verify.FreshnessPolicy{Mode: verify.FreshnessNever},
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	// Staples pass every check but were never fetched from the publisher, so they do not verify
+	if result.Verified || !verify.IsStapledOnly(*result) {
+		t.Fatalf("Expected unverified stapled-only result, got %+v", result.Failure)
+	}
+	if result.ResourcePresence != verify.StatusSkipStapled || result.ResourceIntegrity != "pass" || result.PublisherAssociation != "pass" {
+		t.Errorf("Expected stapled checks to pass with presence '%s', got %+v", verify.StatusSkipStapled, result)
+	}
+	if result.Context.Stapled == nil || result.Context.Stapled.LiveChecked {
+		t.Errorf("Expected staple context without live check, got %+v", result.Context.Stapled)
+	}
+}
+
+func TestVerifyDocument_StapledDefaultChecksLive(t *testing.T) {
+	// A just-declared staple time does not exempt the staple from the live check
+	html, raJSON, naJSON := createSignedDocument(t, "https://publisher.invalid")
+	stapled := stapleDocument(html, raJSON, naJSON, time.Now().Unix())
+
+	result, err := VerifyDocument(stapled, nil, nil, VerificationOptions{Timeout: time.Second})
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if result.Verified || result.ResourcePresence != "fail" {
+		t.Errorf("Expected the live check to fail without network, got %+v", result)
+	}
+	if result.Context.Stapled == nil || !result.Context.Stapled.LiveChecked {
+		t.Errorf("Expected staple context with live check, got %+v", result.Context.Stapled)
+	}
+}
+
+func TestVerifyDocument_StapledAlwaysChecksLive(t *testing.T) {
+	mux := http.NewServeMux()
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	html, raJSON, naJSON := createSignedDocument(t, srv.URL)
+	fetches := 0
+	mux.HandleFunc("/people/alice/frc/posts/1/_la_resource.json", func(w http.ResponseWriter, r *http.Request) {
+		fetches++
+		w.Write(raJSON)
+	})
+	mux.HandleFunc("/people/alice/_la_namespace.json", func(w http.ResponseWriter, r *http.Request) {
+		fetches++
+		w.Write(naJSON)
+	})
+
+	result, err := VerifyDocument(stapleDocument(html, raJSON, naJSON, time.Now().Unix()), nil, nil, VerificationOptions{
+		Timeout:   5 * time.Second,
+		Freshness: verify.FreshnessPolicy{Mode: verify.FreshnessAlways},
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if !result.Verified || result.ResourcePresence != "pass" {
+		t.Fatalf("Expected live verification to pass, got %+v", result)
+	}
+	if fetches != 2 {
+		t.Errorf("Expected both attestations fetched live, got %d fetches", fetches)
+	}
+	if result.Context.Stapled == nil || !result.Context.Stapled.LiveChecked || result.Context.Stapled.Freshness != verify.FreshnessAlways {
+		t.Errorf("Expected staple context with live check, got %+v", result.Context.Stapled)
+	}
+}
+
+func TestVerifyDocument_StapledMaxAgeSkipsNAFetch(t *testing.T) {
+	mux := http.NewServeMux()
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	html, raJSON, naJSON := createSignedDocument(t, srv.URL)
+	raFetches, naFetches := 0, 0
+	mux.HandleFunc("/people/alice/frc/posts/1/_la_resource.json", func(w http.ResponseWriter, r *http.Request) {
+		raFetches++
+		w.Write(raJSON)
+	})
+	mux.HandleFunc("/people/alice/_la_namespace.json", func(w http.ResponseWriter, r *http.Request) {
+		naFetches++
+		w.Write(naJSON)
+	})
+	opts := VerificationOptions{
+		Timeout:   5 * time.Second,
+		Freshness: verify.FreshnessPolicy{Mode: verify.FreshnessMaxAge, MaxAge: time.Hour},
+	}
+
+	// A fresh staple stands in for the NA; the unsigned RA is still confirmed live
+	result, err := VerifyDocument(stapleDocument(html, raJSON, naJSON, time.Now().Unix()), nil, nil, opts)
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if !result.Verified || result.ResourcePresence != "pass" {
+		t.Fatalf("Expected verification with the stapled NA to pass, got %+v", result)
+	}
+	if raFetches != 1 || naFetches != 0 {
+		t.Errorf("Expected only the RA fetched, got %d RA and %d NA fetches", raFetches, naFetches)
+	}
+	if staple := result.Context.Stapled; staple == nil || !staple.LiveChecked || !staple.StapledNA {
+		t.Errorf("Expected staple context recording the stapled NA, got %+v", staple)
+	}
+
+	// An old staple is refetched
+	result, err = VerifyDocument(stapleDocument(html, raJSON, naJSON, time.Now().Add(-2*time.Hour).Unix()), nil, nil, opts)
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if !result.Verified || naFetches != 1 || result.Context.Stapled.StapledNA {
+		t.Errorf("Expected an old staple to fetch the live NA, got %d NA fetches and %+v", naFetches, result.Context.Stapled)
+	}
+}
+
+func TestVerifyDocument_StapledMalformed(t *testing.T) {
+	html, raJSON, naJSON := createSignedDocument(t, "https://publisher.invalid")
+	stapled := stapleDocument(html, raJSON, append(naJSON, []byte("{}")...), time.Now().Unix())
+
+	result, err := VerifyDocument(stapled, nil, nil, VerificationOptions{
+		Timeout:   time.Second,
+		Freshness: verify.FreshnessPolicy{Mode: verify.FreshnessNever},
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if result.Verified || result.Failure == nil || result.Failure.Reason != "malformed_trailing_data" {
+		t.Errorf("Expected failure reason 'malformed_trailing_data', got %+v", result.Failure)
+	}
+}
+
+// newHeaderPublisherServer serves a signed page whose response carries its RA as a header, after
+// applying mutate to it. The RA URL serves the unmodified RA only when serveRA is set.
+func newHeaderPublisherServer(t *testing.T, serveRA bool, mutate func(*wire.ResourceAttestation)) string {
+	t.Helper()
+	mux := http.NewServeMux()
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+
+	html, raJSON, naJSON := createSignedDocument(t, srv.URL)
+	var headerRA wire.ResourceAttestation
+	if err := json.Unmarshal(raJSON, &headerRA); err != nil {
+		t.Fatal(err)
+	}
+	if mutate != nil {
+		mutate(&headerRA)
+	}
+	headerValue, err := wire.EncodeAttestationHeader(headerRA)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	mux.HandleFunc("/people/alice/frc/posts/1", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set(wire.AttestationHeaderName, headerValue)
+		w.Write([]byte(html))
+	})
+	mux.HandleFunc("/people/alice/frc/posts/1/_la_resource.json", func(w http.ResponseWriter, r *http.Request) {
+		if !serveRA {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write(raJSON)
+	})
+	mux.HandleFunc("/people/alice/_la_namespace.json", func(w http.ResponseWriter, r *http.Request) {
+		w.Write(naJSON)
+	})
+	return srv.URL + "/people/alice/frc/posts/1"
+}
+
+func TestVerifyResource_HeaderAttestation(t *testing.T) {
+	pageURL := newHeaderPublisherServer(t, false, nil)
+
+	result, err := VerifyResource(pageURL, VerificationOptions{Timeout: 5 * time.Second})
+	if err != nil {
+		t.Fatalf("VerifyResource failed: %v", err)
+	}
+	if !result.Verified {
+		t.Fatalf("Expected verification with the header RA to pass, got %+v", result.Failure)
+	}
+	if result.Context.ResourceAttestationSource != verify.RASourceHeader {
+		t.Errorf("Expected RA source '%s', got '%s'", verify.RASourceHeader, result.Context.ResourceAttestationSource)
+	}
+
+	// Cross-checking needs the RA URL, which this publisher does not serve
+	result, err = VerifyResource(pageURL, VerificationOptions{Timeout: 5 * time.Second, CrossCheckHeaderRA: true})
+	if err != nil {
+		t.Fatalf("VerifyResource failed: %v", err)
+	}
+	if result.Verified || result.Failure == nil || result.Failure.Reason != "fetch_failed" {
+		t.Errorf("Expected cross-check to fail with 'fetch_failed', got %+v", result.Failure)
+	}
+}
+
+func TestVerifyResource_HeaderAttestationCrossCheck(t *testing.T) {
+	pageURL := newHeaderPublisherServer(t, true, nil)
+
+	result, err := VerifyResource(pageURL, VerificationOptions{Timeout: 5 * time.Second, CrossCheckHeaderRA: true})
+	if err != nil {
+		t.Fatalf("VerifyResource failed: %v", err)
+	}
+	if !result.Verified {
+		t.Fatalf("Expected cross-checked verification to pass, got %+v", result.Failure)
+	}
+	if result.Context.ResourceAttestationSource != verify.RASourceHeaderChecked {
+		t.Errorf("Expected RA source '%s', got '%s'", verify.RASourceHeaderChecked, result.Context.ResourceAttestationSource)
+	}
+
+	// A header that disagrees with the RA URL is rejected
+	pageURL = newHeaderPublisherServer(t, true, func(ra *wire.ResourceAttestation) {
+		ra.Hash = crypto.ComputeContentHashField([]byte("other content"))
+	})
+	result, err = VerifyResource(pageURL, VerificationOptions{Timeout: 5 * time.Second, CrossCheckHeaderRA: true})
+	if err != nil {
+		t.Fatalf("VerifyResource failed: %v", err)
+	}
+	if result.Verified || result.Failure == nil || result.Failure.Reason != "header_mismatch" {
+		t.Errorf("Expected failure reason 'header_mismatch', got %+v", result.Failure)
+	}
+}
+
+func TestVerifyResource_HeaderAttestationFromOtherOrigin(t *testing.T) {
+	pageURL := newHeaderPublisherServer(t, true, nil)
+	resp, err := http.Get(pageURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	html, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	headerRA, err := wire.DecodeAttestationHeader(resp.Header.Get(wire.AttestationHeaderName))
+	if err != nil {
+		t.Fatal(err)
+	}
+	headerRA.Hash = crypto.ComputeContentHashField([]byte("other content"))
+	forged, _ := wire.EncodeAttestationHeader(headerRA)
+
+	// A mirror on another origin cannot vouch for the publisher's RA with its own header
+	mirror := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set(wire.AttestationHeaderName, forged)
+		w.Write(html)
+	}))
+	defer mirror.Close()
+
+	result, err := VerifyResource(mirror.URL+"/people/alice/frc/posts/1", VerificationOptions{Timeout: 5 * time.Second})
+	if err != nil {
+		t.Fatalf("VerifyResource failed: %v", err)
+	}
+	if result.Verified || result.Failure == nil || result.Failure.Reason != "header_mismatch" {
+		t.Errorf("Expected failure reason 'header_mismatch', got %+v", result.Failure)
+	}
+}
+
+// newImagePublisherServer serves a signed PNG with a sibling RA advertised by a Link header, and a
+// page whose fragment references the image by URL rather than embedding it
+func newImagePublisherServer(t *testing.T, contentType string) string {
+	t.Helper()
+	mux := http.NewServeMux()
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+
+	_, _, naJSON := createSignedDocument(t, srv.URL)
+	var na wire.NamespaceAttestation
+	if err := json.Unmarshal(naJSON, &na); err != nil {
+		t.Fatal(err)
+	}
+
+	image := []byte("\x89PNG\r\n\x1a\nnot really an image")
+	imageURL := srv.URL + "/people/alice/frc/photos/1.png"
+	raURL := imageURL + "._la_resource.json"
+	naURL := srv.URL + "/people/alice/_la_namespace.json"
+	raJSON, err := json.Marshal(wire.ResourceAttestation{
+		FragmentURL:             imageURL,
+		Hash:                    crypto.ComputeContentHashField(image),
+		PublisherClaim:          na.Key,
+		NamespaceAttestationURL: naURL,
+		ContentType:             contentType,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	html := fmt.Sprintf(`<html><body>
+<article data-la-spec="v0.2" data-la-fragment-url="%s">
+  <link rel="canonical" type="image/png"
+    data-la-publisher-claim="%s"
+    data-la-resource-attestation-url="%s"
+    data-la-namespace-attestation-url="%s"
+    href="/people/alice/frc/photos/1.png" hidden />
+  <img src="/people/alice/frc/photos/1.png" />
+</article>
+</body></html>`, imageURL, na.Key, raURL, naURL)
+
+	mux.HandleFunc("/people/alice/frc/photos/", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(html))
+	})
+	mux.HandleFunc("/people/alice/frc/photos/1.png", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "image/png")
+		w.Header().Set("Link", fmt.Sprintf("<%s>; rel=\"%s\"", "1.png._la_resource.json", wire.LinkRelResourceAttestation))
+		w.Write(image)
+	})
+	mux.HandleFunc("/people/alice/frc/photos/1.png._la_resource.json", func(w http.ResponseWriter, r *http.Request) {
+		w.Write(raJSON)
+	})
+	mux.HandleFunc("/people/alice/_la_namespace.json", func(w http.ResponseWriter, r *http.Request) {
+		w.Write(naJSON)
+	})
+	return srv.URL + "/people/alice/frc/photos"
+}
+
+func TestVerifyResource_ContentURLFragment(t *testing.T) {
+	baseURL := newImagePublisherServer(t, "image/png")
+
+	result, err := VerifyResource(baseURL+"/", VerificationOptions{Timeout: 5 * time.Second})
+	if err != nil {
+		t.Fatalf("VerifyResource failed: %v", err)
+	}
+	if !result.Verified {
+		t.Fatalf("Expected fragment with a content URL to verify, got %+v", result.Failure)
+	}
+}
+
+func TestVerifyResource_DirectImageWithLinkHeader(t *testing.T) {
+	baseURL := newImagePublisherServer(t, "image/png")
+
+	result, err := VerifyResource(baseURL+"/1.png", VerificationOptions{Timeout: 5 * time.Second})
+	if err != nil {
+		t.Fatalf("VerifyResource failed: %v", err)
+	}
+	if !result.Verified {
+		t.Fatalf("Expected direct image verification to pass, got %+v", result.Failure)
+	}
+	if !strings.HasSuffix(result.Context.ResourceAttestationURL, "/1.png._la_resource.json") {
+		t.Errorf("Expected RA URL from the Link header, got '%s'", result.Context.ResourceAttestationURL)
+	}
+
+	// An RA attesting a different media type does not cover the image
+	baseURL = newImagePublisherServer(t, "image/jpeg")
+	result, err = VerifyResource(baseURL+"/1.png", VerificationOptions{Timeout: 5 * time.Second})
+	if err != nil {
+		t.Fatalf("VerifyResource failed: %v", err)
+	}
+	if result.Verified || result.Failure == nil || result.Failure.Reason != "content_type_mismatch" {
+		t.Errorf("Expected failure reason 'content_type_mismatch', got %+v", result.Failure)
+	}
+}
+
+// newExternalContentServer serves a signed page whose fragment references its HTML content by a
+// same-origin URL instead of embedding it
+func newExternalContentServer(t *testing.T) string {
+	t.Helper()
+	mux := http.NewServeMux()
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+
+	html, raJSON, naJSON := createSignedDocument(t, srv.URL)
+	content := "<h2>Saved Post</h2><p>Read offline.</p>"
+	html = strings.Replace(html, "data:text/html;base64,"+base64.StdEncoding.EncodeToString([]byte(content)), "/people/alice/frc/posts/1/content", 1)
+
+	mux.HandleFunc("/people/alice/frc/posts/1", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(html))
+	})
+	mux.HandleFunc("/people/alice/frc/posts/1/content", func(w http.ResponseWriter, r *http.Request) {
+		// Referenced content follows same-origin redirects
+		http.Redirect(w, r, "/people/alice/frc/posts/1/content.html", http.StatusFound)
+	})
+	mux.HandleFunc("/people/alice/frc/posts/1/content.html", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(content))
+	})
+	mux.HandleFunc("/people/alice/frc/posts/1/_la_resource.json", func(w http.ResponseWriter, r *http.Request) {
+		w.Write(raJSON)
+	})
+	mux.HandleFunc("/people/alice/_la_namespace.json", func(w http.ResponseWriter, r *http.Request) {
+		w.Write(naJSON)
+	})
+	return srv.URL + "/people/alice/frc/posts/1"
+}
+
+func TestVerifyResource_ExternalCanonicalContent(t *testing.T) {
+	pageURL := newExternalContentServer(t)
+
+	result, err := VerifyResource(pageURL, VerificationOptions{Timeout: 5 * time.Second})
+	if err != nil {
+		t.Fatalf("VerifyResource failed: %v", err)
+	}
+	if !result.Verified {
+		t.Fatalf("Expected verification of externally referenced content to pass, got %+v", result.Failure)
+	}
+
+	result, err = VerifyResource(pageURL, VerificationOptions{Timeout: 5 * time.Second, MaxContentBytes: 16})
+	if err != nil {
+		t.Fatalf("VerifyResource failed: %v", err)
+	}
+	if result.Verified || result.Failure == nil || result.Failure.Reason != "content_too_large" {
+		t.Errorf("Expected failure reason 'content_too_large', got %+v", result.Failure)
+	}
+}
+
+// newExcerptServer serves an RA with a block commitment for the post from createSignedDocument,
+// and a page on the same server quoting one of its blocks through artifacts.CreateExcerpt
+func newExcerptServer(t *testing.T, edit func(string) string) string {
+	t.Helper()
+	mux := http.NewServeMux()
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+
+	_, raJSON, naJSON := createSignedDocument(t, srv.URL)
+	var ra wire.ResourceAttestation
+	if err := json.Unmarshal(raJSON, &ra); err != nil {
+		t.Fatal(err)
+	}
+	content := []byte("<h2>Saved Post</h2><p>Read offline.</p>")
+	blocks := canonical.SplitBlocks(content)
+	ra.BlockRoot, ra.BlockCount = crypto.MerkleRootField(blocks), len(blocks)
+	raJSON, err := json.Marshal(ra)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dir := t.TempDir()
+	inPath := filepath.Join(dir, "content.html")
+	if err := os.WriteFile(inPath, content, 0644); err != nil {
+		t.Fatal(err)
+	}
+	outPath := filepath.Join(dir, "excerpt.htmx")
+	if err := artifacts.CreateExcerpt(inPath, ra.FragmentURL, ra.PublisherClaim, ra.FragmentURL+"/_la_resource.json", ra.NamespaceAttestationURL, "", 1, outPath); err != nil {
+		t.Fatal(err)
+	}
+	excerptHTML, err := os.ReadFile(outPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	page := "<html><body><p>Alice wrote:</p>" + edit(string(excerptHTML)) + "</body></html>"
+
+	mux.HandleFunc("/people/westley/frc/posts/9", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(page))
+	})
+	mux.HandleFunc("/people/alice/frc/posts/1/_la_resource.json", func(w http.ResponseWriter, r *http.Request) {
+		w.Write(raJSON)
+	})
+	mux.HandleFunc("/people/alice/_la_namespace.json", func(w http.ResponseWriter, r *http.Request) {
+		w.Write(naJSON)
+	})
+	return srv.URL + "/people/westley/frc/posts/9"
+}
+
+func TestVerifyResource_Excerpt(t *testing.T) {
+	pageURL := newExcerptServer(t, func(html string) string { return html })
+
+	result, err := VerifyResource(pageURL, VerificationOptions{Timeout: 5 * time.Second})
+	if err != nil {
+		t.Fatalf("VerifyResource failed: %v", err)
+	}
+	if !result.Verified {
+		t.Fatalf("Expected excerpt verification to pass, got %+v", result.Failure)
+	}
+	if result.ExcerptInclusion != "pass" || result.ResourceIntegrity != "skip" {
+		t.Errorf("Expected excerpt inclusion pass and integrity skip, got %s/%s", result.ExcerptInclusion, result.ResourceIntegrity)
+	}
+
+	// An edited quote no longer matches the committed block
+	edited := base64.StdEncoding.EncodeToString([]byte("<p>Read online.</p>"))
+	pageURL = newExcerptServer(t, func(html string) string {
+		return strings.Replace(html, base64.StdEncoding.EncodeToString([]byte("<p>Read offline.</p>")), edited, 1)
+	})
+	result, err = VerifyResource(pageURL, VerificationOptions{Timeout: 5 * time.Second})
+	if err != nil {
+		t.Fatalf("VerifyResource failed: %v", err)
+	}
+	if result.Verified || result.Failure == nil || result.Failure.Reason != "excerpt_mismatch" {
+		t.Errorf("Expected failure reason 'excerpt_mismatch', got %+v", result.Failure)
+	}
+}
+
+func TestVerifyResource_ExcerptPolicy(t *testing.T) {
+	pageURL := newExcerptServer(t, func(html string) string { return html })
+
+	// The verifier policy applies to excerpts as to fragments
+	result, err := VerifyResource(pageURL, VerificationOptions{Timeout: 5 * time.Second, Policy: &verify.Policy{RequireHTTPS: true}})
+	if err != nil {
+		t.Fatalf("VerifyResource failed: %v", err)
+	}
+	if result.Verified || result.Failure == nil || result.Failure.Check != verify.CheckPolicy {
+		t.Errorf("Expected the policy to reject an http excerpt, got %+v", result.Failure)
+	}
+	if result.ExcerptInclusion != "pass" {
+		t.Errorf("Expected excerpt inclusion to pass before the policy, got %s", result.ExcerptInclusion)
+	}
+}
@@ -16,22 +16,59 @@ package main
 
 import (
 	"encoding/json"
+	"errors"
 	"flag"
 	"fmt"
 	"io"
 	"log"
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
+	"github.com/stonebraker/lap/sdks/go/pkg/lap/verify"
+	"github.com/stonebraker/lap/sdks/go/pkg/lap/wire"
 )
 
 func main() {
 	var port string
+	var allowHash string
+	var policyPath string
+	var trustIssuers string
 	flag.StringVar(&port, "port", "8082", "port to listen on")
+	flag.StringVar(&allowHash, "allow-hash", "", "comma-separated list of accepted RA hash algorithms (default: all supported)")
+	flag.StringVar(&freshnessPolicy.Mode, "freshness", freshnessPolicy.Mode, "whether to confirm stapled attestations live: always, max-age to use a stapled NA younger than -staple-max-age instead of fetching it, or never to check the staples alone (never verifies)")
+	flag.DurationVar(&freshnessPolicy.MaxAge, "staple-max-age", verify.DefaultStapleMaxAge, "with -freshness max-age, how old a staple may be for its NA to be used without a fetch")
+	flag.BoolVar(&verifyOptions.RejectStale, "reject-stale", false, "fail fragments that embed a superseded version of the content instead of reporting them stale")
+	flag.StringVar(&verifyOptions.ReaderKey, "reader-key", "", "hex private key of a recipient, to decrypt subscriber-only fragments")
+	flag.Int64Var(&maxContentBytes, "max-content-size", maxContentBytes, "maximum bytes of canonical content fetched from a fragment's content URL")
+	flag.StringVar(&trustIssuers, "trust-issuer", "", "comma-separated Namespace Attestation URLs of issuers whose statements (endorsements, labels, disputes) are attached to results")
+	flag.StringVar(&policyPath, "policy", "", "JSON verifier policy file enforced after the three checks and echoed in each result's context")
 	flag.Parse()
 
+	if _, err := verify.ParseFreshnessMode(freshnessPolicy.Mode); err != nil {
+		log.Fatal(err)
+	}
+	if policyPath != "" {
+		policy, err := verify.LoadPolicy(policyPath)
+		if err != nil {
+			log.Fatal(err)
+		}
+		verifyOptions.Policy = policy
+	}
+
+	for _, alg := range strings.Split(allowHash, ",") {
+		if alg = strings.TrimSpace(alg); alg != "" {
+			verifyOptions.AllowedHashAlgorithms = append(verifyOptions.AllowedHashAlgorithms, alg)
+		}
+	}
+	for _, issuer := range strings.Split(trustIssuers, ",") {
+		if issuer = strings.TrimSpace(issuer); issuer != "" {
+			trustedIssuers = append(trustedIssuers, issuer)
+		}
+	}
+
 	r := chi.NewRouter()
 
 	// Middleware
@@ -44,7 +81,7 @@ func main() {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			w.Header().Set("Access-Control-Allow-Origin", "*")
 			w.Header().Set("Access-Control-Allow-Methods", "GET, POST, OPTIONS")
-			w.Header().Set("Access-Control-Allow-Headers", "Content-Type")
+			w.Header().Set("Access-Control-Allow-Headers", "Content-Type, "+wire.AttestationHeaderName)
 			
 			if r.Method == "OPTIONS" {
 				w.WriteHeader(http.StatusOK)
@@ -58,6 +95,7 @@ func main() {
 	// Routes
 	r.Get("/health", healthHandler)
 	r.Post("/verify", verifyHandler)
+	r.Post("/verify-batch", verifyBatchHandler)
 
 	addr := ":" + port
 	fmt.Printf("Verifier Service starting on %s\n", addr)
@@ -97,8 +135,11 @@ func verifyHandler(w http.ResponseWriter, r *http.Request) {
 		actualFetchURL = r.Header.Get("X-Fetch-URL")
 	}
 
+	// The RA header of the fetched response, if the client forwarded it; it is cross-checked before use
+	headerValue := r.Header.Get(wire.AttestationHeaderName)
+
 	// Process the fragment and perform verification
-	result, err := processFragmentVerification(string(body), actualFetchURL)
+	result, err := processFragmentVerification(string(body), actualFetchURL, headerValue)
 	if err != nil {
 		// Return error as JSON response instead of plain text HTTP error
 		errorResponse := map[string]interface{}{
@@ -114,4 +155,46 @@ func verifyHandler(w http.ResponseWriter, r *http.Request) {
 	// Return verification result as JSON
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(result)
-}
\ No newline at end of file
+}
+
+// batchVerifyRequest is one fragment in a /verify-batch request body
+type batchVerifyRequest struct {
+	HTML                      string `json:"html"`
+	FetchURL                  string `json:"fetch_url"`
+	ResourceAttestationHeader string `json:"resource_attestation_header,omitempty"` // RA header of the fetched response, if any; cross-checked before use
+}
+
+// Limits on one /verify-batch request; every entry can cost several remote fetches
+const (
+	maxBatchSize      = 100      // Fragments per batch
+	maxBatchBodyBytes = 10 << 20 // Request body size
+)
+
+// verifyBatchHandler verifies a JSON array of fragments and returns one result per entry, in order
+func verifyBatchHandler(w http.ResponseWriter, r *http.Request) {
+	defer r.Body.Close()
+
+	var requests []batchVerifyRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxBatchBodyBytes)).Decode(&requests); err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			http.Error(w, fmt.Sprintf("Request body exceeds %d bytes", maxBatchBodyBytes), http.StatusRequestEntityTooLarge)
+			return
+		}
+		http.Error(w, "Invalid JSON request body", http.StatusBadRequest)
+		return
+	}
+	if len(requests) == 0 {
+		http.Error(w, "Empty batch", http.StatusBadRequest)
+		return
+	}
+	if len(requests) > maxBatchSize {
+		http.Error(w, fmt.Sprintf("Batch of %d fragments exceeds the limit of %d", len(requests), maxBatchSize), http.StatusRequestEntityTooLarge)
+		return
+	}
+
+	results := processBatchVerification(requests)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(results)
+}
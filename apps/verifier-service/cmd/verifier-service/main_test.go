@@ -0,0 +1,79 @@
+// Copyright 2025 Jason Stonebraker
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stonebraker/lap/sdks/go/pkg/lap/verify"
+)
+
+// postBatch sends body to the batch handler and returns the response
+func postBatch(t *testing.T, body string) *httptest.ResponseRecorder {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodPost, "/verify-batch", strings.NewReader(body))
+	rec := httptest.NewRecorder()
+	verifyBatchHandler(rec, req)
+	return rec
+}
+
+// batchOf returns a batch body with n entries that fail to parse without fetching anything
+func batchOf(t *testing.T, n int) string {
+	t.Helper()
+	body, err := json.Marshal(make([]batchVerifyRequest, n))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(body)
+}
+
+func TestVerifyBatchHandler(t *testing.T) {
+	rec := postBatch(t, batchOf(t, 2))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d: %s", rec.Code, rec.Body)
+	}
+	var results []verify.VerificationResult
+	if err := json.Unmarshal(rec.Body.Bytes(), &results); err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 2 || results[0].Verified || results[0].Failure == nil || results[0].Failure.Reason != "malformed" {
+		t.Errorf("Expected two malformed results, got %+v", results)
+	}
+
+	if rec := postBatch(t, "[]"); rec.Code != http.StatusBadRequest {
+		t.Errorf("Expected an empty batch to be rejected with 400, got %d", rec.Code)
+	}
+	if rec := postBatch(t, "{"); rec.Code != http.StatusBadRequest {
+		t.Errorf("Expected invalid JSON to be rejected with 400, got %d", rec.Code)
+	}
+}
+
+func TestVerifyBatchHandler_Limits(t *testing.T) {
+	if rec := postBatch(t, batchOf(t, maxBatchSize)); rec.Code != http.StatusOK {
+		t.Errorf("Expected a full batch to be accepted, got %d", rec.Code)
+	}
+	if rec := postBatch(t, batchOf(t, maxBatchSize+1)); rec.Code != http.StatusRequestEntityTooLarge {
+		t.Errorf("Expected %d entries to be rejected with 413, got %d", maxBatchSize+1, rec.Code)
+	}
+
+	oversized := `[{"html":"` + strings.Repeat("a", maxBatchBodyBytes) + `"}]`
+	if rec := postBatch(t, oversized); rec.Code != http.StatusRequestEntityTooLarge {
+		t.Errorf("Expected an oversized body to be rejected with 413, got %d", rec.Code)
+	}
+}
@@ -15,64 +15,240 @@
 package main
 
 import (
-	"encoding/base64"
-	"encoding/json"
 	"fmt"
+	"log"
 	"net/http"
-	"net/url"
-	"strings"
 	"time"
 
+	"github.com/stonebraker/lap/sdks/go/pkg/lap/fetch"
 	"github.com/stonebraker/lap/sdks/go/pkg/lap/verify"
 	"github.com/stonebraker/lap/sdks/go/pkg/lap/wire"
 )
 
-// processFragmentVerification processes a complete HTML fragment and performs LAP v0.2 verification
-func processFragmentVerification(htmlContent string, actualFetchURL string) (*verify.VerificationResult, error) {
-	// Parse the fragment from the HTML content
+// verifyOptions holds the verifier policy configured from command-line flags
+var verifyOptions = verify.DefaultOptions()
+
+// freshnessPolicy decides whether stapled attestations are confirmed against the live ones
+var freshnessPolicy = verify.DefaultFreshnessPolicy()
+
+// maxContentBytes bounds canonical content fetched from a fragment's content URL
+var maxContentBytes int64 = fetch.DefaultMaxContentBytes
+
+// trustedIssuers lists the Namespace Attestation URLs of issuers whose statements are attached to results
+var trustedIssuers []string
+
+// processFragmentVerification processes a complete HTML fragment and performs LAP v0.2 verification.
+// headerValue is the RA header of the response the fragment was fetched from, or "" when it had none.
+func processFragmentVerification(htmlContent string, actualFetchURL string, headerValue string) (*verify.VerificationResult, error) {
+	if legacy := checkLegacyFragment(htmlContent, actualFetchURL); legacy != nil {
+		return legacy, nil
+	}
+
+	client := fetch.NewClient(10 * time.Second)
+	fragment, failed := loadFragment(client, htmlContent, actualFetchURL)
+	if failed != nil {
+		return failed, nil
+	}
+
+	stapled, staple, stapledNA := checkStapledAttestations(fragment)
+	if stapled != nil {
+		return stapled, nil
+	}
+
+	bundle, source, failed := prepareFragmentVerification(client, fragment, headerValue, stapledNA)
+	if failed != nil {
+		failed.Context.Stapled = staple
+		return failed, nil
+	}
+
+	// Perform v0.2 verification using the verify package
+	result := verify.VerifyFragmentWithCoPublishers(bundle.Fragment, bundle.ResourceAttestation, bundle.NamespaceAttestation, bundle.CoPublisherAttestations, verifyOptions)
+	setAttestationURLs(&result, bundle.Fragment)
+	result.Context.Stapled = staple
+	result.Context.ResourceAttestationSource = source
+	collectStatements(client, &result)
+
+	return &result, nil
+}
+
+// collectStatements attaches the statements of the trusted issuers to a verified result. Issuers that
+// cannot be reached are logged; they never affect the result.
+func collectStatements(client *http.Client, result *verify.VerificationResult) {
+	if len(trustedIssuers) == 0 {
+		return
+	}
+	if err := fetch.Statements(client, result, trustedIssuers, verifyOptions); err != nil {
+		log.Printf("statements: %v", err)
+	}
+}
+
+// loadFragment parses the v0.2 fragment and loads the content it references by URL, once for both the
+// stapled and the live checks. It returns the failed result when the fragment cannot be verified.
+func loadFragment(client *http.Client, htmlContent string, actualFetchURL string) (*wire.Fragment, *verify.VerificationResult) {
 	fragment, err := parseFragmentFromHTML(htmlContent, actualFetchURL)
 	if err != nil {
-		return &verify.VerificationResult{
-			Verified:         false,
-			ResourcePresence: "fail",
-			Failure: &verify.FailureDetails{
-				Check:   "resource_presence",
-				Reason:  "malformed",
-				Message: fmt.Sprintf("failed to parse fragment: %v", err),
-			},
-			Context: &verify.VerificationContext{
-				VerifiedAt: time.Now().Unix(),
-			},
-		}, nil
+		return nil, verify.FailedResult(nil, verify.CheckResourcePresence, "malformed", fmt.Sprintf("failed to parse fragment: %v", err), nil)
+	}
+
+	// Fragments declaring a version this service cannot verify are rejected before any fetch
+	if spec, ok := wire.NormalizeSpec(fragment.Spec); !ok || spec != wire.SpecV02 {
+		result := verify.UnsupportedSpecResult(fragment.FragmentURL, fragment.Spec)
+		setAttestationURLs(&result, *fragment)
+		return nil, &result
+	}
+
+	// Fetch content the fragment references by URL rather than inlining it
+	if failed := fetch.LoadContent(client, fragment, maxContentBytes); failed != nil {
+		return nil, failed
+	}
+	return fragment, nil
+}
+
+// checkStapledAttestations verifies the fragment's stapled attestations, if any. It returns the final
+// result when the staples fail or no live check is configured, in which case the result is unverified;
+// otherwise it returns the staple context (nil without staples) to attach to the live result and, under
+// the max-age freshness policy, the fresh stapled NA to use instead of fetching it.
+func checkStapledAttestations(fragment *wire.Fragment) (*verify.VerificationResult, *verify.StapleContext, *wire.NamespaceAttestation) {
+	// Staples carry only the publisher's NA, so co-authored fragments are always checked live
+	if !verify.HasStapledAttestations(*fragment) || len(fragment.CoPublishers) > 0 {
+		return nil, nil, nil
 	}
 
-	// Create HTTP client for fetching attestations
-	client := &http.Client{
-		Timeout: 10 * time.Second,
-		CheckRedirect: func(req *http.Request, via []*http.Request) error {
-			if len(via) == 0 {
-				return nil
-			}
-			prev := via[len(via)-1]
-			if !sameOrigin(prev.URL, req.URL) {
-				return fmt.Errorf("cross-origin redirect not allowed")
-			}
-			if len(via) > 10 {
-				return fmt.Errorf("too many redirects")
-			}
-			return nil
-		},
+	stapled := verify.VerifyStapled(*fragment, verifyOptions)
+	staple := stapled.Context.Stapled
+	staple.Freshness = freshnessPolicy.Mode
+	if !verify.IsStapledOnly(stapled) || !freshnessPolicy.NeedsLiveCheck() {
+		return &stapled, nil, nil
 	}
 
-	// Fetch the Resource Attestation
-	resourceAttestation, err := fetchResourceAttestation(client, fragment.ResourceAttestationURL)
+	staple.LiveChecked = true
+	if freshnessPolicy.UseStapledNA(staple, time.Now()) {
+		if na, err := verify.StapledNamespaceAttestation(*fragment); err == nil {
+			staple.StapledNA = true
+			return nil, staple, &na
+		}
+	}
+	return nil, staple, nil
+}
+
+// checkLegacyFragment verifies an archived v0.1 fragment with the v0.1 rules when the document has no
+// v0.2 fragment but carries a v0.1 one, and returns nil otherwise. The service only sees the fragment,
+// not the response it came in, so the v0.1 ETag comparison is skipped.
+func checkLegacyFragment(htmlContent string, actualFetchURL string) *verify.VerificationResult {
+	if _, err := wire.ParseFragmentHTML(htmlContent); err == nil {
+		return nil
+	}
+	article, ok := wire.FindFragmentV01(htmlContent)
+	if !ok {
+		return nil
+	}
+
+	fragment, err := wire.ParseFragmentV01(article)
 	if err != nil {
-		return &verify.VerificationResult{
+		return verify.FailedResult(nil, "resource_presence", "malformed", fmt.Sprintf("failed to parse v0.1 fragment: %v", err), nil)
+	}
+	if actualFetchURL != "" && verify.NormalizeURL(fragment.URL) != verify.NormalizeURL(actualFetchURL) {
+		return verify.FailedResult(nil, "resource_presence", "malformed", fmt.Sprintf("failed to parse v0.1 fragment: URL mismatch: fragment claims URL %s but was fetched from %s", fragment.URL, actualFetchURL), nil)
+	}
+
+	client := fetch.NewClient(10 * time.Second)
+	raURL := wire.ResourceAttestationURLV01(fragment.URL)
+	ra, err := fetch.ResourceAttestationV01(client, raURL)
+	if err != nil {
+		return verify.FailedResult(nil, "resource_presence", fetch.FailureReason(err), fmt.Sprintf("failed to fetch resource attestation: %v", err), map[string]interface{}{
+			"resource_attestation_url": raURL,
+		})
+	}
+
+	na, naURL, err := fetch.NamespaceAttestationV01(client, fragment.URL)
+	if err != nil {
+		result := verify.FailedResult(nil, "publisher_association", fetch.FailureReason(err), fmt.Sprintf("failed to fetch namespace attestation: %v", err), nil)
+		result.ResourcePresence = "skip"
+		result.ResourceIntegrity = "skip"
+		result.Context.ResourceAttestationURL = raURL
+		result.Context.Spec = wire.SpecV01
+		return result
+	}
+
+	result := verify.VerifyFragmentV01(fragment, ra, na, naURL, "", verifyOptions)
+	return &result
+}
+
+// processBatchVerification verifies many HTML fragments, checking their Namespace Attestation
+// signatures together so repeated publishers are only verified once
+func processBatchVerification(requests []batchVerifyRequest) []*verify.VerificationResult {
+	results := make([]*verify.VerificationResult, len(requests))
+	bundles := make([]verify.FragmentBundle, 0, len(requests))
+	slots := make([]int, 0, len(requests))
+
+	staples := make([]*verify.StapleContext, len(requests))
+	sources := make([]string, len(requests))
+	clients := make([]*http.Client, len(requests))
+
+	for i, req := range requests {
+		if legacy := checkLegacyFragment(req.HTML, req.FetchURL); legacy != nil {
+			results[i] = legacy
+			continue
+		}
+
+		client := fetch.NewClient(10 * time.Second)
+		fragment, failed := loadFragment(client, req.HTML, req.FetchURL)
+		if failed != nil {
+			results[i] = failed
+			continue
+		}
+
+		stapled, staple, stapledNA := checkStapledAttestations(fragment)
+		if stapled != nil {
+			results[i] = stapled
+			continue
+		}
+		staples[i] = staple
+
+		bundle, source, failed := prepareFragmentVerification(client, fragment, req.ResourceAttestationHeader, stapledNA)
+		if failed != nil {
+			failed.Context.Stapled = staple
+			results[i] = failed
+			continue
+		}
+		sources[i] = source
+		clients[i] = client
+		bundles = append(bundles, *bundle)
+		slots = append(slots, i)
+	}
+
+	for j, result := range verify.VerifyFragments(bundles, verifyOptions) {
+		setAttestationURLs(&result, bundles[j].Fragment)
+		result.Context.Stapled = staples[slots[j]]
+		result.Context.ResourceAttestationSource = sources[slots[j]]
+		collectStatements(clients[slots[j]], &result)
+		results[slots[j]] = &result
+	}
+
+	return results
+}
+
+// setAttestationURLs records the fragment's attestation URLs in the result context
+func setAttestationURLs(result *verify.VerificationResult, fragment wire.Fragment) {
+	result.Context.ResourceAttestationURL = fragment.ResourceAttestationURL
+	result.Context.NamespaceAttestationURL = fragment.NamespaceAttestationURL
+}
+
+// prepareFragmentVerification fetches the loaded fragment's attestations, cross-checking the RA from
+// headerValue when set, and using stapledNA instead of fetching the NA when it is not nil. It returns
+// either the bundle to verify and the RA source for the result context, or a failed result describing
+// why a bundle could not be built.
+func prepareFragmentVerification(client *http.Client, fragment *wire.Fragment, headerValue string, stapledNA *wire.NamespaceAttestation) (*verify.FragmentBundle, string, *verify.VerificationResult) {
+	// Fetch the Resource Attestation. A forwarded RA header comes from the caller, not the publisher,
+	// so it only counts when it matches the RA served at its URL.
+	resourceAttestation, source, err := fetch.ResolveResourceAttestation(client, fragment, headerValue, nil, true)
+	if err != nil {
+		return nil, "", &verify.VerificationResult{
 			Verified:         false,
 			ResourcePresence: "fail",
 			Failure: &verify.FailureDetails{
 				Check:   "resource_presence",
-				Reason:  "fetch_failed",
+				Reason:  fetch.FailureReason(err),
 				Message: fmt.Sprintf("failed to fetch resource attestation: %v", err),
 				Details: map[string]interface{}{
 					"resource_attestation_url": fragment.ResourceAttestationURL,
@@ -83,13 +259,12 @@ func processFragmentVerification(htmlContent string, actualFetchURL string) (*ve
 				NamespaceAttestationURL: fragment.NamespaceAttestationURL,
 				VerifiedAt:             time.Now().Unix(),
 			},
-		}, nil
+		}
 	}
 
 	// Validate Resource Attestation has required fields
-	resourceAttestation, err = validateRequiredResourceAttestationFields(*resourceAttestation)
-	if err != nil {
-		return &verify.VerificationResult{
+	if err := fetch.ValidateResourceAttestation(*resourceAttestation); err != nil {
+		return nil, "", &verify.VerificationResult{
 			Verified:         false,
 			ResourcePresence: "fail",
 			Failure: &verify.FailureDetails{
@@ -105,20 +280,23 @@ func processFragmentVerification(htmlContent string, actualFetchURL string) (*ve
 				NamespaceAttestationURL: fragment.NamespaceAttestationURL,
 				VerifiedAt:             time.Now().Unix(),
 			},
-		}, nil
+		}
 	}
 
-	// Fetch the Namespace Attestation
-	namespaceAttestation, err := fetchNamespaceAttestation(client, fragment.NamespaceAttestationURL)
+	// Fetch the Namespace Attestation, unless a fresh stapled one stands in for it
+	namespaceAttestation := stapledNA
+	if namespaceAttestation == nil {
+		namespaceAttestation, err = fetch.NamespaceAttestation(client, fragment.NamespaceAttestationURL)
+	}
 	if err != nil {
-		return &verify.VerificationResult{
+		return nil, "", &verify.VerificationResult{
 			Verified:             false,
 			ResourcePresence:     "pass",
 			ResourceIntegrity:    "pass",
 			PublisherAssociation: "fail",
 			Failure: &verify.FailureDetails{
 				Check:   "publisher_association",
-				Reason:  "fetch_failed",
+				Reason:  fetch.FailureReason(err),
 				Message: fmt.Sprintf("failed to fetch namespace attestation: %v", err),
 				Details: map[string]interface{}{
 					"namespace_attestation_url": fragment.NamespaceAttestationURL,
@@ -129,236 +307,28 @@ func processFragmentVerification(htmlContent string, actualFetchURL string) (*ve
 				NamespaceAttestationURL: fragment.NamespaceAttestationURL,
 				VerifiedAt:             time.Now().Unix(),
 			},
-		}, nil
-	}
-
-	// Perform v0.2 verification using the verify package
-	result := verify.VerifyFragment(*fragment, *resourceAttestation, *namespaceAttestation)
-
-	// Update context with URLs
-	result.Context.ResourceAttestationURL = fragment.ResourceAttestationURL
-	result.Context.NamespaceAttestationURL = fragment.NamespaceAttestationURL
-
-	return &result, nil
-}
-
-// parseFragmentFromHTML extracts a LAP fragment from HTML content
-// This is adapted from the verifier CLI implementation
-func parseFragmentFromHTML(htmlContent string, actualFetchURL string) (*wire.Fragment, error) {
-	// Extract the claimed fragment URL from the HTML
-	needle := `data-la-fragment-url="`
-	idx := strings.Index(htmlContent, needle)
-	if idx < 0 {
-		return nil, fmt.Errorf("no fragment found with data-la-fragment-url attribute")
-	}
-
-	// Extract the claimed fragment URL from the HTML
-	fragmentURLStart := idx + len(needle)
-	fragmentURLEnd := strings.Index(htmlContent[fragmentURLStart:], `"`)
-	if fragmentURLEnd < 0 {
-		return nil, fmt.Errorf("fragment structure malformed: incomplete data-la-fragment-url attribute")
-	}
-	claimedFragmentURL := htmlContent[fragmentURLStart : fragmentURLStart+fragmentURLEnd]
-
-	// Validate that the claimed URL matches the actual fetch URL
-	if actualFetchURL != "" {
-		// Normalize URLs by removing trailing slashes for comparison
-		normalizedClaimed := strings.TrimSuffix(claimedFragmentURL, "/")
-		normalizedActual := strings.TrimSuffix(actualFetchURL, "/")
-		if normalizedClaimed != normalizedActual {
-			return nil, fmt.Errorf("URL mismatch: fragment claims URL %s but was fetched from %s", claimedFragmentURL, actualFetchURL)
 		}
 	}
 
-	// Use the claimed URL (which should match actualFetchURL if provided)
-	fragmentURL := claimedFragmentURL
-
-	// Find the start of the article element
-	// Look for any fragment in the HTML (we'll use the first one found)
-	idx = strings.Index(htmlContent, needle)
-	if idx < 0 {
-		return nil, fmt.Errorf("no fragment found with data-la-fragment-url attribute")
-	}
-
-	// Find the start of the article element
-	start := strings.LastIndex(htmlContent[:idx], "<article")
-	if start < 0 {
-		return nil, fmt.Errorf("fragment structure malformed: no <article> tag found")
-	}
-
-	// Find the end of the article element
-	rest := htmlContent[start:]
-	depth := 0
-	i := 0
-	for i < len(rest) {
-		if rest[i] == '<' {
-			if strings.HasPrefix(rest[i:], "<article") {
-				depth++
-			} else if strings.HasPrefix(rest[i:], "</article") {
-				depth--
-				endTag := strings.Index(rest[i:], ">")
-				if endTag >= 0 {
-					i += endTag + 1
-				} else {
-					break
-				}
-				if depth == 0 {
-					endAbs := start + i
-					articleHTML := htmlContent[start:endAbs]
-					return parseFragmentFromArticle(articleHTML, fragmentURL)
-				}
-				continue
-			}
-			end := strings.Index(rest[i:], ">")
-			if end >= 0 {
-				i += end + 1
-				continue
-			}
-			break
-		}
-		i++
-	}
-
-	return nil, fmt.Errorf("fragment structure malformed: incomplete <article> tag")
+	return &verify.FragmentBundle{
+		Fragment:                *fragment,
+		ResourceAttestation:     *resourceAttestation,
+		NamespaceAttestation:    *namespaceAttestation,
+		CoPublisherAttestations: fetch.CoPublisherAttestations(client, *resourceAttestation),
+	}, source, nil
 }
 
-// parseFragmentFromArticle parses a fragment from an article HTML element
-func parseFragmentFromArticle(articleHTML, resourceURL string) (*wire.Fragment, error) {
-	fragment := &wire.Fragment{
-		Spec:        "v0.2",
-		FragmentURL: resourceURL,
-	}
-
-	// Extract publisher claim
-	if idx := strings.Index(articleHTML, `data-la-publisher-claim="`); idx >= 0 {
-		start := idx + len(`data-la-publisher-claim="`)
-		end := strings.Index(articleHTML[start:], `"`)
-		if end >= 0 {
-			fragment.PublisherClaim = articleHTML[start : start+end]
-		}
-	}
-
-	// Extract resource attestation URL
-	if idx := strings.Index(articleHTML, `data-la-resource-attestation-url="`); idx >= 0 {
-		start := idx + len(`data-la-resource-attestation-url="`)
-		end := strings.Index(articleHTML[start:], `"`)
-		if end >= 0 {
-			fragment.ResourceAttestationURL = articleHTML[start : start+end]
-		}
-	}
-
-	// Extract namespace attestation URL
-	if idx := strings.Index(articleHTML, `data-la-namespace-attestation-url="`); idx >= 0 {
-		start := idx + len(`data-la-namespace-attestation-url="`)
-		end := strings.Index(articleHTML[start:], `"`)
-		if end >= 0 {
-			fragment.NamespaceAttestationURL = articleHTML[start : start+end]
-		}
-	}
-
-	// Extract canonical content from href
-	if idx := strings.Index(articleHTML, `href="data:text/html;base64,`); idx >= 0 {
-		start := idx + len(`href="data:text/html;base64,`)
-		end := strings.Index(articleHTML[start:], `"`)
-		if end >= 0 {
-			base64Content := articleHTML[start : start+end]
-			canonicalBytes, err := base64.StdEncoding.DecodeString(base64Content)
-			if err != nil {
-				return nil, fmt.Errorf("failed to decode base64 content: %v", err)
-			}
-			fragment.CanonicalContent = canonicalBytes
-			fragment.PreviewContent = string(canonicalBytes)
-		}
-	}
-
-	// Validate required fields
-	if fragment.PublisherClaim == "" {
-		return nil, fmt.Errorf("missing data-la-publisher-claim")
-	}
-	if fragment.ResourceAttestationURL == "" {
-		return nil, fmt.Errorf("missing data-la-resource-attestation-url")
-	}
-	if fragment.NamespaceAttestationURL == "" {
-		return nil, fmt.Errorf("missing data-la-namespace-attestation-url")
-	}
-	if len(fragment.CanonicalContent) == 0 {
-		return nil, fmt.Errorf("missing canonical content in href")
-	}
-
-	return fragment, nil
-}
-
-// fetchResourceAttestation fetches and parses a Resource Attestation
-func fetchResourceAttestation(client *http.Client, url string) (*wire.ResourceAttestation, error) {
-	resp, err := client.Get(url)
-	if err != nil {
-		return nil, fmt.Errorf("fetch failed: %v", err)
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != 200 {
-		return nil, fmt.Errorf("fetch failed with status %d", resp.StatusCode)
-	}
-
-	var attestation wire.ResourceAttestation
-	if err := json.NewDecoder(resp.Body).Decode(&attestation); err != nil {
-		return nil, fmt.Errorf("invalid JSON in attestation: %v", err)
-	}
-
-	return &attestation, nil
-}
-
-// validateRequiredResourceAttestationFields validates that a Resource Attestation has all required fields
-func validateRequiredResourceAttestationFields(attestation wire.ResourceAttestation) (*wire.ResourceAttestation, error) {
-	// Validate required fields
-	if attestation.FragmentURL == "" {
-		return nil, fmt.Errorf("malformed attestation: missing fragment_url field")
-	}
-	if attestation.Hash == "" {
-		return nil, fmt.Errorf("malformed attestation: missing hash field")
-	}
-	if attestation.PublisherClaim == "" {
-		return nil, fmt.Errorf("malformed attestation: missing publisher_claim field")
-	}
-	if attestation.NamespaceAttestationURL == "" {
-		return nil, fmt.Errorf("malformed attestation: missing namespace_attestation_url field")
-	}
-
-	return &attestation, nil
-}
-
-// fetchNamespaceAttestation fetches and parses a Namespace Attestation
-func fetchNamespaceAttestation(client *http.Client, url string) (*wire.NamespaceAttestation, error) {
-	resp, err := client.Get(url)
+// parseFragmentFromHTML extracts a LAP fragment from HTML content and checks that it claims the URL
+// it was fetched from
+func parseFragmentFromHTML(htmlContent string, actualFetchURL string) (*wire.Fragment, error) {
+	fragment, err := wire.ParseFragmentHTML(htmlContent)
 	if err != nil {
-		return nil, fmt.Errorf("fetch failed: %v", err)
+		return nil, err
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode != 200 {
-		return nil, fmt.Errorf("fetch failed with status %d", resp.StatusCode)
+	// Normalize URLs by removing trailing slashes for comparison
+	if actualFetchURL != "" && verify.NormalizeURL(fragment.FragmentURL) != verify.NormalizeURL(actualFetchURL) {
+		return nil, fmt.Errorf("URL mismatch: fragment claims URL %s but was fetched from %s", fragment.FragmentURL, actualFetchURL)
 	}
-
-	var attestation wire.NamespaceAttestation
-	if err := json.NewDecoder(resp.Body).Decode(&attestation); err != nil {
-		return nil, fmt.Errorf("invalid JSON in attestation: %v", err)
-	}
-
-	// Validate required fields
-	if attestation.Payload.Namespace == "" {
-		return nil, fmt.Errorf("malformed attestation: missing payload.namespace field")
-	}
-	if attestation.Key == "" {
-		return nil, fmt.Errorf("malformed attestation: missing key field")
-	}
-	if attestation.Sig == "" {
-		return nil, fmt.Errorf("malformed attestation: missing sig field")
-	}
-
-	return &attestation, nil
+	return fragment, nil
 }
-
-// sameOrigin checks if two URLs have the same origin (scheme + host)
-func sameOrigin(a, b *url.URL) bool {
-	return strings.EqualFold(a.Scheme, b.Scheme) && strings.EqualFold(a.Host, b.Host)
-}
\ No newline at end of file
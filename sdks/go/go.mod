@@ -2,7 +2,13 @@ module github.com/stonebraker/lap/sdks/go
 
 go 1.22
 
-require github.com/btcsuite/btcd/btcec/v2 v2.3.3
+require (
+	github.com/btcsuite/btcd/btcec/v2 v2.3.3
+	github.com/microcosm-cc/bluemonday v1.0.27
+	golang.org/x/crypto v0.24.0
+	golang.org/x/net v0.26.0
+	lukechampine.com/blake3 v1.3.0
+)
 
 require (
 	github.com/aymerick/douceur v0.2.0 // indirect
@@ -10,6 +16,6 @@ require (
 	github.com/decred/dcrd/crypto/blake256 v1.0.0 // indirect
 	github.com/decred/dcrd/dcrec/secp256k1/v4 v4.0.1 // indirect
 	github.com/gorilla/css v1.0.1 // indirect
-	github.com/microcosm-cc/bluemonday v1.0.27 // indirect
-	golang.org/x/net v0.26.0 // indirect
+	github.com/klauspost/cpuid/v2 v2.0.9 // indirect
+	golang.org/x/sys v0.21.0 // indirect
 )
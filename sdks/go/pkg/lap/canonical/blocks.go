@@ -0,0 +1,67 @@
+package canonical
+
+import (
+	"bytes"
+	"io"
+
+	"golang.org/x/net/html"
+)
+
+// voidElements never have content or an end tag, so they do not open a nesting level
+var voidElements = map[string]bool{
+	"area": true, "base": true, "br": true, "col": true, "embed": true, "hr": true, "img": true,
+	"input": true, "link": true, "meta": true, "source": true, "track": true, "wbr": true,
+}
+
+// SplitBlocks splits canonical HTML content into its top-level blocks, the units a block commitment
+// covers. Each top-level element, from its start tag to the matching end tag, is one block, as is
+// each run of top-level text with surrounding whitespace trimmed. Whitespace-only text, comments and
+// doctypes between blocks are not part of any block. Nesting follows the tags as written: an element
+// left unclosed extends to the end of the content. Every block is a subslice of content.
+func SplitBlocks(content []byte) [][]byte {
+	var blocks [][]byte
+	z := html.NewTokenizer(bytes.NewReader(content))
+	offset, start, depth := 0, 0, 0
+	for {
+		tt := z.Next()
+		if tt == html.ErrorToken {
+			if z.Err() == io.EOF && depth > 0 {
+				blocks = append(blocks, content[start:])
+			}
+			return blocks
+		}
+		tokenStart := offset
+		offset += len(z.Raw())
+
+		switch tt {
+		case html.StartTagToken:
+			name, _ := z.TagName()
+			if voidElements[string(name)] {
+				if depth == 0 {
+					blocks = append(blocks, content[tokenStart:offset])
+				}
+				continue
+			}
+			if depth == 0 {
+				start = tokenStart
+			}
+			depth++
+		case html.SelfClosingTagToken:
+			if depth == 0 {
+				blocks = append(blocks, content[tokenStart:offset])
+			}
+		case html.EndTagToken:
+			if depth == 0 {
+				continue
+			}
+			depth--
+			if depth == 0 {
+				blocks = append(blocks, content[start:offset])
+			}
+		case html.TextToken:
+			if text := bytes.TrimSpace(content[tokenStart:offset]); depth == 0 && len(text) > 0 {
+				blocks = append(blocks, text)
+			}
+		}
+	}
+}
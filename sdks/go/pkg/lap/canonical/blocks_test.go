@@ -0,0 +1,41 @@
+package canonical
+
+import "testing"
+
+func TestSplitBlocks(t *testing.T) {
+	content := []byte("<!-- lead -->\n<h2>Title</h2>\n<p>One <em>two</em></p>\n<img src=\"a.png\">\n<br/>\nTrailing text \n<div><div>nested</div></div>")
+	want := []string{
+		"<h2>Title</h2>",
+		"<p>One <em>two</em></p>",
+		"<img src=\"a.png\">",
+		"<br/>",
+		"Trailing text",
+		"<div><div>nested</div></div>",
+	}
+
+	blocks := SplitBlocks(content)
+	if len(blocks) != len(want) {
+		t.Fatalf("got %d blocks %q, want %d", len(blocks), blocks, len(want))
+	}
+	for i, block := range blocks {
+		if string(block) != want[i] {
+			t.Errorf("block %d: got %q, want %q", i, block, want[i])
+		}
+	}
+}
+
+func TestSplitBlocks_RawTextAndUnclosed(t *testing.T) {
+	blocks := SplitBlocks([]byte("<script>if (a < b) { x = '</p>' }</script><p>open"))
+	if len(blocks) != 2 {
+		t.Fatalf("got %d blocks %q, want 2", len(blocks), blocks)
+	}
+	if string(blocks[1]) != "<p>open" {
+		t.Errorf("expected an unclosed element to extend to the end, got %q", blocks[1])
+	}
+}
+
+func TestSplitBlocks_Empty(t *testing.T) {
+	if blocks := SplitBlocks([]byte("  \n ")); len(blocks) != 0 {
+		t.Errorf("expected no blocks for whitespace, got %q", blocks)
+	}
+}
@@ -4,27 +4,84 @@ import (
 	"encoding/json"
 )
 
-// ResourceAttestationCanonical for v0.2 maintains key order: fragment_url, hash, publisher_claim, namespace_attestation_url
+// ResourceAttestationCanonical for v0.2 maintains key order: fragment_url, hash, canonicalization, content_type,
+// size, block_root, block_count, version, previous_hashes, in_reply_to, iat, exp and max_age (each omitted when empty), publisher_claim,
+// namespace_attestation_url, co_publishers (omitted when empty)
 type ResourceAttestationCanonical struct {
-	FragmentURL             string `json:"fragment_url"`
-	Hash                    string `json:"hash"`
+	FragmentURL             string                   `json:"fragment_url"`
+	Hash                    string                   `json:"hash"`
+	Canonicalization        string                   `json:"canonicalization,omitempty"`
+	ContentType             string                   `json:"content_type,omitempty"`
+	Size                    int64                    `json:"size,omitempty"`
+	BlockRoot               string                   `json:"block_root,omitempty"`
+	BlockCount              int                      `json:"block_count,omitempty"`
+	Version                 int                      `json:"version,omitempty"`
+	PreviousHashes          []string                 `json:"previous_hashes,omitempty"`
+	InReplyTo               *ReplyReferenceCanonical `json:"in_reply_to,omitempty"`
+	IAT                     int64                    `json:"iat,omitempty"`
+	Exp                     int64                    `json:"exp,omitempty"`
+	MaxAge                  int64                    `json:"max_age,omitempty"`
+	PublisherClaim          string                   `json:"publisher_claim"`
+	NamespaceAttestationURL string                   `json:"namespace_attestation_url"`
+	CoPublishers            []CoPublisherCanonical   `json:"co_publishers,omitempty"`
+}
+
+// CoPublisherCanonical maintains key order: publisher_claim, namespace_attestation_url, sig
+type CoPublisherCanonical struct {
 	PublisherClaim          string `json:"publisher_claim"`
 	NamespaceAttestationURL string `json:"namespace_attestation_url"`
+	Sig                     string `json:"sig"`
+}
+
+// CoPublisherPayloadCanonical is what each co-publisher signs; it maintains key order: fragment_url, hash
+type CoPublisherPayloadCanonical struct {
+	FragmentURL string `json:"fragment_url"`
+	Hash        string `json:"hash"`
+}
+
+// KeyRotationPayloadCanonical is what the replaced key signs when a namespace changes keys; it
+// maintains key order: namespace, new_alg (omitted when empty), new_key, iat, alg (omitted when empty)
+type KeyRotationPayloadCanonical struct {
+	Namespace string `json:"namespace"`
+	NewAlg    string `json:"new_alg,omitempty"`
+	NewKey    string `json:"new_key"`
+	Iat       int64  `json:"iat"`
+	Alg       string `json:"alg,omitempty"`
 }
 
-// NamespacePayloadCanonical for v0.2 maintains key order: namespace, exp
+// ReplyReferenceCanonical maintains key order: fragment_url, hash
+type ReplyReferenceCanonical struct {
+	FragmentURL string `json:"fragment_url"`
+	Hash        string `json:"hash"`
+}
+
+// NamespacePayloadCanonical for v0.2 maintains key order: namespace, exp, alg (omitted when empty)
 type NamespacePayloadCanonical struct {
 	Namespace string `json:"namespace"`
 	Exp       int64  `json:"exp"`
+	Alg       string `json:"alg,omitempty"`
 }
 
-// NamespaceAttestationCanonical for v0.2 maintains key order: payload, key, sig
+// NamespaceAttestationCanonical for v0.2 maintains key order: payload, alg (omitted when empty), key, sig
 type NamespaceAttestationCanonical struct {
 	Payload NamespacePayloadCanonical `json:"payload"`
+	Alg     string                    `json:"alg,omitempty"`
 	Key     string                    `json:"key"`
 	Sig     string                    `json:"sig"`
 }
 
+// StatementPayloadCanonical maintains key order: fragment_url, hash, type, body (omitted when empty),
+// namespace_attestation_url, iat, alg (omitted when empty)
+type StatementPayloadCanonical struct {
+	FragmentURL             string `json:"fragment_url"`
+	Hash                    string `json:"hash"`
+	Type                    string `json:"type"`
+	Body                    string `json:"body,omitempty"`
+	NamespaceAttestationURL string `json:"namespace_attestation_url"`
+	Iat                     int64  `json:"iat"`
+	Alg                     string `json:"alg,omitempty"`
+}
+
 // MarshalResourceAttestationCanonical returns compact JSON for v0.2 ResourceAttestation with deterministic key order.
 func MarshalResourceAttestationCanonical(ra ResourceAttestationCanonical) ([]byte, error) {
 	return json.Marshal(ra)
@@ -35,6 +92,53 @@ func MarshalNamespacePayloadCanonical(p NamespacePayloadCanonical) ([]byte, erro
 	return json.Marshal(p)
 }
 
+// MarshalStatementPayloadCanonical returns compact JSON for a statement payload with deterministic key order.
+func MarshalStatementPayloadCanonical(p StatementPayloadCanonical) ([]byte, error) {
+	return json.Marshal(p)
+}
+
+// MarshalCoPublisherPayloadCanonical returns compact JSON for a co-publisher payload with deterministic key order.
+func MarshalCoPublisherPayloadCanonical(p CoPublisherPayloadCanonical) ([]byte, error) {
+	return json.Marshal(p)
+}
+
+// MarshalKeyRotationPayloadCanonical returns compact JSON for a key rotation payload with deterministic key order.
+func MarshalKeyRotationPayloadCanonical(p KeyRotationPayloadCanonical) ([]byte, error) {
+	return json.Marshal(p)
+}
+
+// ResourcePayloadV01Canonical is the signed payload of a v0.1 Resource Attestation; it maintains
+// key order: url, attestation_url, hash, etag, iat, exp, kid
+type ResourcePayloadV01Canonical struct {
+	URL            string `json:"url"`
+	AttestationURL string `json:"attestation_url"`
+	Hash           string `json:"hash"`
+	ETag           string `json:"etag"`
+	IAT            int64  `json:"iat"`
+	Exp            int64  `json:"exp"`
+	KID            string `json:"kid"`
+}
+
+// NamespacePayloadV01Canonical is the signed payload of a v0.1 Namespace Attestation; it maintains
+// key order: namespace, attestation_path, iat, exp, kid
+type NamespacePayloadV01Canonical struct {
+	Namespace       []string `json:"namespace"`
+	AttestationPath string   `json:"attestation_path"`
+	IAT             int64    `json:"iat"`
+	Exp             int64    `json:"exp"`
+	KID             string   `json:"kid"`
+}
+
+// MarshalResourcePayloadV01Canonical returns compact JSON for a v0.1 resource payload with deterministic key order.
+func MarshalResourcePayloadV01Canonical(p ResourcePayloadV01Canonical) ([]byte, error) {
+	return json.Marshal(p)
+}
+
+// MarshalNamespacePayloadV01Canonical returns compact JSON for a v0.1 namespace payload with deterministic key order.
+func MarshalNamespacePayloadV01Canonical(p NamespacePayloadV01Canonical) ([]byte, error) {
+	return json.Marshal(p)
+}
+
 // MarshalNamespaceAttestationCanonical returns compact JSON for v0.2 NamespaceAttestation with deterministic key order.
 func MarshalNamespaceAttestationCanonical(na NamespaceAttestationCanonical) ([]byte, error) {
 	return json.Marshal(na)
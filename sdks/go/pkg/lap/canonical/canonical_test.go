@@ -65,6 +65,29 @@ func TestNamespaceAttestationCanonical_FieldOrder(t *testing.T) {
 	}
 }
 
+func TestNamespaceAttestationCanonical_AlgFieldOrder(t *testing.T) {
+	na := NamespaceAttestationCanonical{
+		Payload: NamespacePayloadCanonical{
+			Namespace: "https://example.com/people/alice/",
+			Exp:       1754909100,
+		},
+		Alg: "ed25519",
+		Key: "aa",
+		Sig: "bb",
+	}
+
+	bytes, err := MarshalNamespaceAttestationCanonical(na)
+	if err != nil {
+		t.Fatalf("Failed to marshal: %v", err)
+	}
+
+	// alg sits between payload and key when present
+	expected := `{"payload":{"namespace":"https://example.com/people/alice/","exp":1754909100},"alg":"ed25519","key":"aa","sig":"bb"}`
+	if string(bytes) != expected {
+		t.Errorf("Field order mismatch:\ngot:  %s\nwant: %s", string(bytes), expected)
+	}
+}
+
 func TestCanonicalSerialization_Deterministic(t *testing.T) {
 	// Test that multiple serializations produce identical output
 	ra := ResourceAttestationCanonical{
@@ -132,3 +155,45 @@ func TestCanonicalSerialization_UTF8Encoding(t *testing.T) {
 func contains(s, substr string) bool {
 	return len(s) >= len(substr) && s[:len(substr)] == substr
 }
+
+func TestResourceAttestationCanonical_CanonicalizationFieldOrder(t *testing.T) {
+	ra := ResourceAttestationCanonical{
+		FragmentURL:             "https://example.com/resource",
+		Hash:                    "sha256:abc123",
+		Canonicalization:        ProfileHTMLNormalized,
+		PublisherClaim:          "def456",
+		NamespaceAttestationURL: "https://example.com/namespace.json",
+	}
+
+	bytes, err := MarshalResourceAttestationCanonical(ra)
+	if err != nil {
+		t.Fatalf("Failed to marshal: %v", err)
+	}
+
+	expected := `{"fragment_url":"https://example.com/resource","hash":"sha256:abc123","canonicalization":"html-normalized","publisher_claim":"def456","namespace_attestation_url":"https://example.com/namespace.json"}`
+	if string(bytes) != expected {
+		t.Errorf("Field order mismatch:\ngot:  %s\nwant: %s", string(bytes), expected)
+	}
+}
+
+func TestResourceAttestationCanonical_TimeFieldOrder(t *testing.T) {
+	ra := ResourceAttestationCanonical{
+		FragmentURL:             "https://example.com/resource",
+		Hash:                    "sha256:abc123",
+		IAT:                     1700000000,
+		Exp:                     1700086400,
+		MaxAge:                  300,
+		PublisherClaim:          "def456",
+		NamespaceAttestationURL: "https://example.com/namespace.json",
+	}
+
+	bytes, err := MarshalResourceAttestationCanonical(ra)
+	if err != nil {
+		t.Fatalf("Failed to marshal: %v", err)
+	}
+
+	expected := `{"fragment_url":"https://example.com/resource","hash":"sha256:abc123","iat":1700000000,"exp":1700086400,"max_age":300,"publisher_claim":"def456","namespace_attestation_url":"https://example.com/namespace.json"}`
+	if string(bytes) != expected {
+		t.Errorf("Field order mismatch:\ngot:  %s\nwant: %s", string(bytes), expected)
+	}
+}
@@ -0,0 +1,65 @@
+package canonical
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// Content canonicalization profiles named by the Resource Attestation "canonicalization" field.
+const (
+	// ProfileRaw hashes the content bytes exactly as published (the default).
+	ProfileRaw = "raw"
+	// ProfileHTMLNormalized requires UTF-8, strips a leading BOM, converts CRLF and CR to LF,
+	// trims trailing spaces and tabs from every line and trims trailing whitespace from the document.
+	ProfileHTMLNormalized = "html-normalized"
+)
+
+// ErrUnknownProfile is returned for canonicalization profiles that are not supported.
+var ErrUnknownProfile = errors.New("unknown canonicalization profile")
+
+var utf8BOM = []byte{0xEF, 0xBB, 0xBF}
+
+// ProfileNames returns the supported canonicalization profile names, default first.
+func ProfileNames() []string {
+	return []string{ProfileRaw, ProfileHTMLNormalized}
+}
+
+// NormalizeProfile maps an empty profile name to ProfileRaw and lowercases the rest.
+func NormalizeProfile(profile string) string {
+	if profile == "" {
+		return ProfileRaw
+	}
+	return strings.ToLower(profile)
+}
+
+// CanonicalizeContent applies the named profile to content and returns the bytes to hash.
+// Every profile is idempotent, so applying it to already canonical content is a no-op.
+func CanonicalizeContent(profile string, content []byte) ([]byte, error) {
+	switch NormalizeProfile(profile) {
+	case ProfileRaw:
+		return content, nil
+	case ProfileHTMLNormalized:
+		return normalizeHTML(content)
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnknownProfile, profile)
+	}
+}
+
+func normalizeHTML(content []byte) ([]byte, error) {
+	if !utf8.Valid(content) {
+		return nil, errors.New("html-normalized content must be valid UTF-8")
+	}
+	s := string(bytes.TrimPrefix(content, utf8BOM))
+	s = strings.ReplaceAll(s, "\r\n", "\n")
+	s = strings.ReplaceAll(s, "\r", "\n")
+
+	lines := strings.Split(s, "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimRight(line, " \t")
+	}
+	s = strings.TrimRight(strings.Join(lines, "\n"), " \t\n")
+	return []byte(s), nil
+}
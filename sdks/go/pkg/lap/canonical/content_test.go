@@ -0,0 +1,66 @@
+package canonical
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestCanonicalizeContent_Raw(t *testing.T) {
+	input := []byte("\xEF\xBB\xBF<p>hi</p>\r\n")
+	for _, profile := range []string{"", ProfileRaw} {
+		out, err := CanonicalizeContent(profile, input)
+		if err != nil {
+			t.Fatalf("profile %q: %v", profile, err)
+		}
+		if string(out) != string(input) {
+			t.Errorf("profile %q changed content: %q", profile, out)
+		}
+	}
+}
+
+func TestCanonicalizeContent_HTMLNormalized(t *testing.T) {
+	want := "<h1>Title</h1>\n<p>Body</p>"
+	inputs := []string{
+		"<h1>Title</h1>\n<p>Body</p>",
+		"<h1>Title</h1>\n<p>Body</p>\n",
+		"<h1>Title</h1>\r\n<p>Body</p>\r\n",
+		"<h1>Title</h1>\r<p>Body</p>",
+		"\xEF\xBB\xBF<h1>Title</h1>\n<p>Body</p>\n\n",
+		"<h1>Title</h1>  \n<p>Body</p>\t\n",
+	}
+	for _, input := range inputs {
+		out, err := CanonicalizeContent(ProfileHTMLNormalized, []byte(input))
+		if err != nil {
+			t.Fatalf("input %q: %v", input, err)
+		}
+		if string(out) != want {
+			t.Errorf("input %q: got %q, want %q", input, out, want)
+		}
+		again, _ := CanonicalizeContent(ProfileHTMLNormalized, out)
+		if string(again) != string(out) {
+			t.Errorf("input %q: profile is not idempotent: %q", input, again)
+		}
+	}
+}
+
+func TestCanonicalizeContent_HTMLNormalizedPreservesLeadingWhitespace(t *testing.T) {
+	out, err := CanonicalizeContent(ProfileHTMLNormalized, []byte("<pre>\n  indented\n</pre>"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(out) != "<pre>\n  indented\n</pre>" {
+		t.Errorf("leading whitespace changed: %q", out)
+	}
+}
+
+func TestCanonicalizeContent_InvalidUTF8(t *testing.T) {
+	if _, err := CanonicalizeContent(ProfileHTMLNormalized, []byte{0xff, 0xfe}); err == nil {
+		t.Error("expected error for invalid UTF-8")
+	}
+}
+
+func TestCanonicalizeContent_UnknownProfile(t *testing.T) {
+	if _, err := CanonicalizeContent("xml-c14n", []byte("x")); !errors.Is(err, ErrUnknownProfile) {
+		t.Errorf("expected ErrUnknownProfile, got %v", err)
+	}
+}
@@ -0,0 +1,169 @@
+// Copyright 2025 Jason Stonebraker
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package crypto
+
+import (
+	"crypto/cipher"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/btcec/v2/schnorr"
+	"golang.org/x/crypto/chacha20poly1305"
+	"golang.org/x/crypto/hkdf"
+)
+
+// ContentEncryptionAlgorithm identifies the encryption of subscriber-only content: a random content
+// key encrypts the content with ChaCha20-Poly1305, and is wrapped for each recipient with a key
+// derived by HKDF-SHA256 from the secp256k1 ECDH secret between an ephemeral key and the recipient's
+// x-only key.
+const ContentEncryptionAlgorithm = "secp256k1-ecdh-chacha20poly1305"
+
+// contentKeyWrapInfo is the HKDF info string for recipient key-wrapping keys
+const contentKeyWrapInfo = "lap content key wrap"
+
+var (
+	// ErrNotRecipient is returned when the reader key is not among the sealed content's recipients.
+	ErrNotRecipient = errors.New("reader key is not a recipient")
+	// ErrDecryptionFailed is returned when the wrapped key or the content fails authentication.
+	ErrDecryptionFailed = errors.New("decryption failed")
+)
+
+// SealedContent is content encrypted to a list of recipients.
+type SealedContent struct {
+	Alg          string      `json:"alg"`           // ContentEncryptionAlgorithm
+	EphemeralKey string      `json:"ephemeral_key"` // x-only ephemeral public key (64 hex chars)
+	Recipients   []SealedKey `json:"recipients"`
+	Nonce        []byte      `json:"nonce"`
+	Ciphertext   []byte      `json:"ciphertext"`
+}
+
+// SealedKey is the content key wrapped for one recipient.
+type SealedKey struct {
+	Key        string `json:"key"`         // Recipient x-only public key (64 hex chars)
+	WrappedKey []byte `json:"wrapped_key"` // Content key sealed under the recipient's wrapping key
+}
+
+// SealContent encrypts plaintext so that only the holders of the private keys for the given x-only
+// public keys can decrypt it.
+func SealContent(plaintext []byte, recipients []string) (SealedContent, error) {
+	if len(recipients) == 0 {
+		return SealedContent{}, errors.New("at least one recipient is required")
+	}
+	ephemeral, ephemeralKey, err := GenerateKeyPair()
+	if err != nil {
+		return SealedContent{}, err
+	}
+	contentKey, err := RandomBytes(chacha20poly1305.KeySize)
+	if err != nil {
+		return SealedContent{}, err
+	}
+
+	sealed := SealedContent{Alg: ContentEncryptionAlgorithm, EphemeralKey: ephemeralKey}
+	seen := make(map[string]bool, len(recipients))
+	for _, recipient := range recipients {
+		pub, err := ParseXOnlyPubKeyHex(recipient)
+		if err != nil {
+			return SealedContent{}, fmt.Errorf("invalid recipient key %q: %w", recipient, err)
+		}
+		recipient = hex.EncodeToString(schnorr.SerializePubKey(pub))
+		if seen[recipient] {
+			return SealedContent{}, fmt.Errorf("duplicate recipient key: %s", recipient)
+		}
+		seen[recipient] = true
+
+		wrap, err := keyWrapAEAD(btcec.GenerateSharedSecret(ephemeral, pub), ephemeralKey, recipient)
+		if err != nil {
+			return SealedContent{}, err
+		}
+		// Each wrapping key is derived for a single ephemeral key and recipient, so a zero nonce is safe
+		wrapped := wrap.Seal(nil, make([]byte, wrap.NonceSize()), contentKey, nil)
+		sealed.Recipients = append(sealed.Recipients, SealedKey{Key: recipient, WrappedKey: wrapped})
+	}
+
+	aead, err := chacha20poly1305.New(contentKey)
+	if err != nil {
+		return SealedContent{}, err
+	}
+	if sealed.Nonce, err = RandomBytes(aead.NonceSize()); err != nil {
+		return SealedContent{}, err
+	}
+	sealed.Ciphertext = aead.Seal(nil, sealed.Nonce, plaintext, []byte(sealed.Alg))
+	return sealed, nil
+}
+
+// OpenContent decrypts sealed content with a recipient's hex-encoded private key.
+func OpenContent(sealed SealedContent, readerPrivHex string) ([]byte, error) {
+	if sealed.Alg != ContentEncryptionAlgorithm {
+		return nil, fmt.Errorf("unsupported content encryption algorithm: %s", sealed.Alg)
+	}
+	reader, err := ParsePrivateKeyHex(readerPrivHex)
+	if err != nil {
+		return nil, fmt.Errorf("invalid reader key: %w", err)
+	}
+	readerKey := hex.EncodeToString(schnorr.SerializePubKey(reader.PubKey()))
+
+	var wrapped []byte
+	for _, recipient := range sealed.Recipients {
+		if recipient.Key == readerKey {
+			wrapped = recipient.WrappedKey
+			break
+		}
+	}
+	if wrapped == nil {
+		return nil, ErrNotRecipient
+	}
+
+	ephemeral, err := ParseXOnlyPubKeyHex(sealed.EphemeralKey)
+	if err != nil {
+		return nil, fmt.Errorf("invalid ephemeral key: %w", err)
+	}
+	// ECDH yields only the X coordinate, which is the same for either Y of the x-only keys
+	wrap, err := keyWrapAEAD(btcec.GenerateSharedSecret(reader, ephemeral), sealed.EphemeralKey, readerKey)
+	if err != nil {
+		return nil, err
+	}
+	contentKey, err := wrap.Open(nil, make([]byte, wrap.NonceSize()), wrapped, nil)
+	if err != nil {
+		return nil, ErrDecryptionFailed
+	}
+
+	aead, err := chacha20poly1305.New(contentKey)
+	if err != nil || len(sealed.Nonce) != aead.NonceSize() {
+		return nil, ErrDecryptionFailed
+	}
+	plaintext, err := aead.Open(nil, sealed.Nonce, sealed.Ciphertext, []byte(sealed.Alg))
+	if err != nil {
+		return nil, ErrDecryptionFailed
+	}
+	return plaintext, nil
+}
+
+// keyWrapAEAD derives the key-wrapping cipher for one recipient from the ECDH shared secret, salted
+// with the ephemeral and recipient keys
+func keyWrapAEAD(shared []byte, ephemeralKey, recipientKey string) (cipher.AEAD, error) {
+	salt, err := hex.DecodeString(ephemeralKey + recipientKey)
+	if err != nil {
+		return nil, err
+	}
+	key := make([]byte, chacha20poly1305.KeySize)
+	if _, err := io.ReadFull(hkdf.New(sha256.New, shared, salt, []byte(contentKeyWrapInfo)), key); err != nil {
+		return nil, err
+	}
+	return chacha20poly1305.New(key)
+}
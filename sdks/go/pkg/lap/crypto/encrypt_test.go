@@ -0,0 +1,70 @@
+package crypto
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"testing"
+)
+
+func TestSealOpenContent(t *testing.T) {
+	plaintext := []byte("<p>Subscribers only</p>")
+
+	// Enough recipients that some keys have an odd Y coordinate
+	var privs []string
+	var recipients []string
+	for i := 0; i < 8; i++ {
+		priv, pub, err := GenerateKeyPair()
+		if err != nil {
+			t.Fatal(err)
+		}
+		privs = append(privs, hex.EncodeToString(priv.Serialize()))
+		recipients = append(recipients, pub)
+	}
+
+	sealed, err := SealContent(plaintext, recipients)
+	if err != nil {
+		t.Fatalf("SealContent: %v", err)
+	}
+	if bytes.Contains(sealed.Ciphertext, plaintext) {
+		t.Fatal("ciphertext contains the plaintext")
+	}
+	for i, priv := range privs {
+		opened, err := OpenContent(sealed, priv)
+		if err != nil {
+			t.Fatalf("recipient %d: OpenContent: %v", i, err)
+		}
+		if !bytes.Equal(opened, plaintext) {
+			t.Errorf("recipient %d: got %q, want %q", i, opened, plaintext)
+		}
+	}
+
+	outsider, _, err := GenerateKeyPair()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := OpenContent(sealed, hex.EncodeToString(outsider.Serialize())); !errors.Is(err, ErrNotRecipient) {
+		t.Errorf("expected ErrNotRecipient for an outsider, got %v", err)
+	}
+
+	sealed.Ciphertext[0] ^= 1
+	if _, err := OpenContent(sealed, privs[0]); !errors.Is(err, ErrDecryptionFailed) {
+		t.Errorf("expected ErrDecryptionFailed for tampered ciphertext, got %v", err)
+	}
+}
+
+func TestSealContent_InvalidRecipients(t *testing.T) {
+	_, pub, err := GenerateKeyPair()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, recipients := range map[string][]string{
+		"none":      nil,
+		"malformed": {"not-a-key"},
+		"duplicate": {pub, pub},
+	} {
+		if _, err := SealContent([]byte("x"), recipients); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
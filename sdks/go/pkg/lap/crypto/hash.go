@@ -0,0 +1,133 @@
+// Copyright 2025 Jason Stonebraker
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package crypto
+
+import (
+	"crypto/sha256"
+	"crypto/sha512"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"sort"
+	"strings"
+
+	"golang.org/x/crypto/blake2b"
+	"lukechampine.com/blake3"
+)
+
+// DefaultHashAlgorithm is the algorithm used for resource hashes when none is specified.
+const DefaultHashAlgorithm = "sha256"
+
+// ErrUnknownHashAlgorithm is returned when a hash field names an algorithm that is not registered.
+var ErrUnknownHashAlgorithm = errors.New("unknown hash algorithm")
+
+// HashAlgorithm describes a content hash algorithm usable in Resource Attestation hash fields.
+type HashAlgorithm struct {
+	Name string           // prefix used in hash fields, e.g. "sha256"
+	Size int              // digest size in bytes
+	New  func() hash.Hash // constructor for a fresh hash state
+}
+
+// hashAlgorithms is the registry of supported algorithms keyed by hash field prefix.
+var hashAlgorithms = map[string]HashAlgorithm{
+	"sha256": {Name: "sha256", Size: sha256.Size, New: sha256.New},
+	"sha384": {Name: "sha384", Size: sha512.Size384, New: sha512.New384},
+	"sha512": {Name: "sha512", Size: sha512.Size, New: sha512.New},
+	"blake2b-256": {Name: "blake2b-256", Size: blake2b.Size256, New: func() hash.Hash {
+		h, _ := blake2b.New256(nil) // only fails for keys longer than 64 bytes
+		return h
+	}},
+	"blake3": {Name: "blake3", Size: 32, New: func() hash.Hash {
+		return blake3.New(32, nil)
+	}},
+	MerkleHashAlgorithm: {Name: MerkleHashAlgorithm, Size: sha256.Size, New: newMerkleHash},
+}
+
+// LookupHashAlgorithm returns the registered algorithm for the given hash field prefix.
+func LookupHashAlgorithm(name string) (HashAlgorithm, bool) {
+	alg, ok := hashAlgorithms[strings.ToLower(name)]
+	return alg, ok
+}
+
+// HashAlgorithmNames returns the names of all registered hash algorithms in sorted order.
+func HashAlgorithmNames() []string {
+	names := make([]string, 0, len(hashAlgorithms))
+	for name := range hashAlgorithms {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// HashWithAlgorithm computes the digest of data using the named algorithm.
+func HashWithAlgorithm(name string, data []byte) ([]byte, error) {
+	alg, ok := LookupHashAlgorithm(name)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownHashAlgorithm, name)
+	}
+	h := alg.New()
+	h.Write(data)
+	return h.Sum(nil), nil
+}
+
+// ComputeContentHashFieldWithAlgorithm returns the payload Hash field "<alg>:<hex>" using the named algorithm.
+// An empty name selects DefaultHashAlgorithm.
+func ComputeContentHashFieldWithAlgorithm(name string, data []byte) (string, error) {
+	if name == "" {
+		name = DefaultHashAlgorithm
+	}
+	digest, err := HashWithAlgorithm(name, data)
+	if err != nil {
+		return "", err
+	}
+	return strings.ToLower(name) + ":" + hex.EncodeToString(digest), nil
+}
+
+// ParseContentHashField splits a "<alg>:<hex>" hash field into its algorithm name and decoded digest.
+// It fails with ErrUnknownHashAlgorithm when the prefix is not registered.
+func ParseContentHashField(field string) (string, []byte, error) {
+	name, hexDigest, ok := strings.Cut(field, ":")
+	if !ok || name == "" {
+		return "", nil, fmt.Errorf("malformed hash field: missing algorithm prefix")
+	}
+	alg, found := LookupHashAlgorithm(name)
+	if !found {
+		return name, nil, fmt.Errorf("%w: %s", ErrUnknownHashAlgorithm, name)
+	}
+	digest, err := hex.DecodeString(hexDigest)
+	if err != nil {
+		return alg.Name, nil, fmt.Errorf("malformed hash field: %v", err)
+	}
+	if len(digest) != alg.Size {
+		return alg.Name, nil, fmt.Errorf("malformed hash field: %s digest must be %d bytes, got %d", alg.Name, alg.Size, len(digest))
+	}
+	return alg.Name, digest, nil
+}
+
+// VerifyContentHashField recomputes the digest of data with the algorithm named by the field's prefix
+// and reports whether it matches the field's digest.
+func VerifyContentHashField(field string, data []byte) (bool, error) {
+	name, expected, err := ParseContentHashField(field)
+	if err != nil {
+		return false, err
+	}
+	actual, err := HashWithAlgorithm(name, data)
+	if err != nil {
+		return false, err
+	}
+	return subtle.ConstantTimeCompare(expected, actual) == 1, nil
+}
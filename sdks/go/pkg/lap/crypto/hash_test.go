@@ -0,0 +1,103 @@
+package crypto
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestComputeContentHashFieldWithAlgorithm_KnownVectors(t *testing.T) {
+	testCases := []struct {
+		alg      string
+		input    string
+		expected string
+	}{
+		{"sha256", "abc", "sha256:ba7816bf8f01cfea414140de5dae2223b00361a396177a9cb410ff61f20015ad"},
+		{"sha384", "abc", "sha384:cb00753f45a35e8bb5a03d699ac65007272c32ab0eded1631a8b605a43ff5bed8086072ba1e7cc2358baeca134c825a7"},
+		{"sha512", "abc", "sha512:ddaf35a193617abacc417349ae20413112e6fa4e89a97ea20a9eeee64b55d39a2192992a274fc1a836ba3c23a3feebbd454d4423643ce80e2a9ac94fa54ca49f"},
+		{"blake2b-256", "abc", "blake2b-256:bddd813c634239723171ef3fee98579b94964e3bb1cb3e427262c8c068d52319"},
+		{"blake3", "", "blake3:af1349b9f5f9a1a6a0404dea36dcc9499bcb25c9adc112b7cc9a93cae41f3262"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.alg, func(t *testing.T) {
+			field, err := ComputeContentHashFieldWithAlgorithm(tc.alg, []byte(tc.input))
+			if err != nil {
+				t.Fatalf("ComputeContentHashFieldWithAlgorithm(%q): %v", tc.alg, err)
+			}
+			if field != tc.expected {
+				t.Errorf("got %s, want %s", field, tc.expected)
+			}
+		})
+	}
+}
+
+func TestComputeContentHashFieldWithAlgorithm_DefaultMatchesSHA256(t *testing.T) {
+	data := []byte("test content")
+	field, err := ComputeContentHashFieldWithAlgorithm("", data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if field != ComputeContentHashField(data) {
+		t.Errorf("default algorithm field %s does not match ComputeContentHashField %s", field, ComputeContentHashField(data))
+	}
+}
+
+func TestComputeContentHashFieldWithAlgorithm_Unknown(t *testing.T) {
+	_, err := ComputeContentHashFieldWithAlgorithm("md5", []byte("x"))
+	if !errors.Is(err, ErrUnknownHashAlgorithm) {
+		t.Errorf("expected ErrUnknownHashAlgorithm, got %v", err)
+	}
+}
+
+func TestParseContentHashField(t *testing.T) {
+	field := ComputeContentHashField([]byte("abc"))
+	name, digest, err := ParseContentHashField(field)
+	if err != nil {
+		t.Fatalf("ParseContentHashField: %v", err)
+	}
+	if name != "sha256" || len(digest) != 32 {
+		t.Errorf("got name=%s len=%d, want sha256 len=32", name, len(digest))
+	}
+
+	invalid := []struct {
+		name  string
+		field string
+	}{
+		{"no prefix", strings.TrimPrefix(field, "sha256:")},
+		{"empty prefix", ":abcd"},
+		{"bad hex", "sha256:zz"},
+		{"wrong length", "sha256:abcd"},
+	}
+	for _, tc := range invalid {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, _, err := ParseContentHashField(tc.field); err == nil {
+				t.Errorf("expected error for %q", tc.field)
+			}
+		})
+	}
+
+	if _, _, err := ParseContentHashField("md5:abcd"); !errors.Is(err, ErrUnknownHashAlgorithm) {
+		t.Errorf("expected ErrUnknownHashAlgorithm for md5 prefix, got %v", err)
+	}
+}
+
+func TestVerifyContentHashField_AllAlgorithms(t *testing.T) {
+	data := []byte("<h1>Test Post</h1>")
+	for _, name := range HashAlgorithmNames() {
+		t.Run(name, func(t *testing.T) {
+			field, err := ComputeContentHashFieldWithAlgorithm(name, data)
+			if err != nil {
+				t.Fatal(err)
+			}
+			ok, err := VerifyContentHashField(field, data)
+			if err != nil || !ok {
+				t.Errorf("expected match, got ok=%v err=%v", ok, err)
+			}
+			ok, err = VerifyContentHashField(field, []byte("tampered"))
+			if err != nil || ok {
+				t.Errorf("expected mismatch for tampered data, got ok=%v err=%v", ok, err)
+			}
+		})
+	}
+}
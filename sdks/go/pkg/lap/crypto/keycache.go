@@ -0,0 +1,173 @@
+// Copyright 2025 Jason Stonebraker
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package crypto
+
+import (
+	"encoding/hex"
+	"runtime"
+	"sync"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/btcec/v2/schnorr"
+)
+
+// DefaultKeyCacheSize is the number of parsed keys held by DefaultKeyCache.
+const DefaultKeyCacheSize = 4096
+
+// DefaultKeyCache is the shared parsed-key cache used by VerifySchnorrHexCached and VerifySchnorrParallel
+// when no cache is supplied.
+var DefaultKeyCache = NewKeyCache(DefaultKeyCacheSize)
+
+// KeyCache memoizes parsed x-only public keys so repeated verifications under the same
+// Namespace Attestation key skip hex decoding and point decompression.
+// It is safe for concurrent use.
+type KeyCache struct {
+	mu   sync.RWMutex
+	max  int
+	keys map[string]*btcec.PublicKey
+}
+
+// NewKeyCache returns a cache holding at most max parsed keys. When full, the cache is reset
+// rather than tracking recency; verifiers see a small working set of publisher keys.
+func NewKeyCache(max int) *KeyCache {
+	if max <= 0 {
+		max = DefaultKeyCacheSize
+	}
+	return &KeyCache{max: max, keys: make(map[string]*btcec.PublicKey)}
+}
+
+// ParseXOnlyPubKeyHex returns the parsed key for hexKey, parsing and caching it on first use.
+// Parse failures are not cached.
+func (c *KeyCache) ParseXOnlyPubKeyHex(hexKey string) (*btcec.PublicKey, error) {
+	c.mu.RLock()
+	pk, ok := c.keys[hexKey]
+	c.mu.RUnlock()
+	if ok {
+		return pk, nil
+	}
+
+	pk, err := ParseXOnlyPubKeyHex(hexKey)
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	if len(c.keys) >= c.max {
+		c.keys = make(map[string]*btcec.PublicKey)
+	}
+	c.keys[hexKey] = pk
+	c.mu.Unlock()
+	return pk, nil
+}
+
+// Len returns the number of keys currently cached.
+func (c *KeyCache) Len() int {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return len(c.keys)
+}
+
+// VerifySchnorrHexCached is VerifySchnorrHex using cache for key parsing; a nil cache uses DefaultKeyCache.
+func VerifySchnorrHexCached(cache *KeyCache, pubHex string, sigHex string, digest32 [32]byte) (bool, error) {
+	if cache == nil {
+		cache = DefaultKeyCache
+	}
+	pk, err := cache.ParseXOnlyPubKeyHex(pubHex)
+	if err != nil {
+		return false, err
+	}
+	sigBytes, err := hex.DecodeString(sigHex)
+	if err != nil {
+		return false, err
+	}
+	sig, err := schnorr.ParseSignature(sigBytes)
+	if err != nil {
+		return false, err
+	}
+	return sig.Verify(digest32[:], pk), nil
+}
+
+// SchnorrCheck is one (key, signature, digest) tuple for VerifySchnorrParallel.
+type SchnorrCheck struct {
+	PubKeyHex string
+	SigHex    string
+	Digest    [32]byte
+}
+
+// SchnorrCheckResult is the outcome for the SchnorrCheck at the same index.
+// Err is set when the key or signature could not be parsed; Valid is false in that case.
+type SchnorrCheckResult struct {
+	Valid bool
+	Err   error
+}
+
+// VerifySchnorrParallel verifies many BIP-340 signatures and returns one result per item. It is a
+// deduplicating worker pool, not batch verification: identical tuples are verified only once, keys
+// are parsed through cache (nil uses DefaultKeyCache), and each distinct tuple is verified on its own
+// across GOMAXPROCS workers. Distinct signatures therefore cost the same CPU time as verifying them
+// one by one; the saving comes from repeated tuples and from the parallelism.
+func VerifySchnorrParallel(items []SchnorrCheck, cache *KeyCache) []SchnorrCheckResult {
+	if cache == nil {
+		cache = DefaultKeyCache
+	}
+	results := make([]SchnorrCheckResult, len(items))
+	if len(items) == 0 {
+		return results
+	}
+
+	// Collapse duplicate tuples so repeated checks of the same NA cost one verification
+	unique := make([]SchnorrCheck, 0, len(items))
+	indexOf := make(map[SchnorrCheck]int, len(items))
+	slot := make([]int, len(items))
+	for i, item := range items {
+		j, seen := indexOf[item]
+		if !seen {
+			j = len(unique)
+			indexOf[item] = j
+			unique = append(unique, item)
+		}
+		slot[i] = j
+	}
+
+	uniqueResults := make([]SchnorrCheckResult, len(unique))
+	workers := runtime.GOMAXPROCS(0)
+	if workers > len(unique) {
+		workers = len(unique)
+	}
+
+	var wg sync.WaitGroup
+	next := make(chan int)
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := range next {
+				item := unique[j]
+				ok, err := VerifySchnorrHexCached(cache, item.PubKeyHex, item.SigHex, item.Digest)
+				uniqueResults[j] = SchnorrCheckResult{Valid: ok && err == nil, Err: err}
+			}
+		}()
+	}
+	for j := range unique {
+		next <- j
+	}
+	close(next)
+	wg.Wait()
+
+	for i := range items {
+		results[i] = uniqueResults[slot[i]]
+	}
+	return results
+}
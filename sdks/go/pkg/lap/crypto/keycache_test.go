@@ -0,0 +1,160 @@
+package crypto
+
+import (
+	"fmt"
+	"testing"
+)
+
+// batchFixture returns n signed items over distinct keys and digests
+func batchFixture(tb testing.TB, n int) []SchnorrCheck {
+	tb.Helper()
+	items := make([]SchnorrCheck, n)
+	for i := range items {
+		priv, pubHex, err := GenerateKeyPair()
+		if err != nil {
+			tb.Fatal(err)
+		}
+		digest := HashSHA256([]byte(fmt.Sprintf(`{"namespace":"https://example.com/people/%d/","exp":1754909400}`, i)))
+		sigHex, err := SignSchnorrHex(priv, digest)
+		if err != nil {
+			tb.Fatal(err)
+		}
+		items[i] = SchnorrCheck{PubKeyHex: pubHex, SigHex: sigHex, Digest: digest}
+	}
+	return items
+}
+
+func TestVerifySchnorrParallel_MixedResults(t *testing.T) {
+	items := batchFixture(t, 4)
+
+	// Duplicate a valid item, tamper with a digest, and corrupt a key
+	items = append(items, items[0])
+	items[1].Digest = HashSHA256([]byte("tampered"))
+	items[2].PubKeyHex = "zz"
+
+	results := VerifySchnorrParallel(items, NewKeyCache(16))
+	if len(results) != len(items) {
+		t.Fatalf("got %d results, want %d", len(results), len(items))
+	}
+
+	want := []bool{true, false, false, true, true}
+	for i, r := range results {
+		if r.Valid != want[i] {
+			t.Errorf("item %d: Valid=%v, want %v (err=%v)", i, r.Valid, want[i], r.Err)
+		}
+	}
+	if results[1].Err != nil {
+		t.Errorf("tampered digest should be invalid without error, got %v", results[1].Err)
+	}
+	if results[2].Err == nil {
+		t.Error("corrupt key should report a parse error")
+	}
+}
+
+func TestVerifySchnorrParallel_Empty(t *testing.T) {
+	if results := VerifySchnorrParallel(nil, nil); len(results) != 0 {
+		t.Errorf("expected no results, got %d", len(results))
+	}
+}
+
+func TestKeyCache_ReusesParsedKeys(t *testing.T) {
+	_, pubHex, err := GenerateKeyPair()
+	if err != nil {
+		t.Fatal(err)
+	}
+	cache := NewKeyCache(2)
+
+	first, err := cache.ParseXOnlyPubKeyHex(pubHex)
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := cache.ParseXOnlyPubKeyHex(pubHex)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first != second {
+		t.Error("expected cached key to be returned on second lookup")
+	}
+	if cache.Len() != 1 {
+		t.Errorf("cache length = %d, want 1", cache.Len())
+	}
+
+	if _, err := cache.ParseXOnlyPubKeyHex("not-hex"); err == nil {
+		t.Error("expected parse error for invalid key")
+	}
+	if cache.Len() != 1 {
+		t.Errorf("parse failures must not be cached, length = %d", cache.Len())
+	}
+
+	// Filling past capacity resets the cache instead of growing without bound
+	for _, item := range batchFixture(t, 3) {
+		if _, err := cache.ParseXOnlyPubKeyHex(item.PubKeyHex); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if cache.Len() > 2 {
+		t.Errorf("cache length = %d, want at most 2", cache.Len())
+	}
+}
+
+// Repeated: the verifier-service case of checking the same few NAs many times
+func BenchmarkVerifySchnorrHex_Repeated(b *testing.B) {
+	items := repeatedItems(b)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for _, item := range items {
+			if ok, err := VerifySchnorrHex(item.PubKeyHex, item.SigHex, item.Digest); err != nil || !ok {
+				b.Fatal("verification failed")
+			}
+		}
+	}
+}
+
+func BenchmarkVerifySchnorrParallel_Repeated(b *testing.B) {
+	items := repeatedItems(b)
+	cache := NewKeyCache(0)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for _, r := range VerifySchnorrParallel(items, cache) {
+			if !r.Valid {
+				b.Fatal("verification failed")
+			}
+		}
+	}
+}
+
+// Distinct: the crawl case of checking many different NAs once each
+func BenchmarkVerifySchnorrHex_Distinct(b *testing.B) {
+	items := batchFixture(b, 256)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for _, item := range items {
+			if ok, err := VerifySchnorrHex(item.PubKeyHex, item.SigHex, item.Digest); err != nil || !ok {
+				b.Fatal("verification failed")
+			}
+		}
+	}
+}
+
+func BenchmarkVerifySchnorrParallel_Distinct(b *testing.B) {
+	items := batchFixture(b, 256)
+	cache := NewKeyCache(0)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for _, r := range VerifySchnorrParallel(items, cache) {
+			if !r.Valid {
+				b.Fatal("verification failed")
+			}
+		}
+	}
+}
+
+// repeatedItems returns 256 checks spread over 4 distinct NAs
+func repeatedItems(b *testing.B) []SchnorrCheck {
+	distinct := batchFixture(b, 4)
+	items := make([]SchnorrCheck, 256)
+	for i := range items {
+		items[i] = distinct[i%len(distinct)]
+	}
+	return items
+}
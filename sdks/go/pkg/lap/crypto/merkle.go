@@ -0,0 +1,212 @@
+// Copyright 2025 Jason Stonebraker
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package crypto
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"hash"
+)
+
+// MerkleHashAlgorithm names the Merkle root content hash in Resource Attestation hash fields.
+const MerkleHashAlgorithm = "merkle-sha256"
+
+// MerkleChunkSize is the size in bytes of each leaf chunk; only the last chunk may be shorter.
+const MerkleChunkSize = 64 << 10
+
+// The tree follows RFC 6962: leaves and interior nodes are domain separated by a prefix byte,
+// and a tree of n leaves splits at the largest power of two below n.
+const (
+	merkleLeafPrefix = 0x00
+	merkleNodePrefix = 0x01
+)
+
+// MerkleChunkCount returns the number of leaf chunks for content of the given size.
+// Empty content has a single empty chunk.
+func MerkleChunkCount(size int64) int {
+	if size <= 0 {
+		return 1
+	}
+	return int((size + MerkleChunkSize - 1) / MerkleChunkSize)
+}
+
+// MerkleLeafHash returns the leaf hash of one chunk.
+func MerkleLeafHash(chunk []byte) [32]byte {
+	h := sha256.New()
+	h.Write([]byte{merkleLeafPrefix})
+	h.Write(chunk)
+	var out [32]byte
+	h.Sum(out[:0])
+	return out
+}
+
+// MerkleLeafHashes splits data into MerkleChunkSize chunks and returns their leaf hashes.
+func MerkleLeafHashes(data []byte) [][32]byte {
+	leaves := make([][32]byte, 0, MerkleChunkCount(int64(len(data))))
+	for off := 0; off < len(data); off += MerkleChunkSize {
+		end := off + MerkleChunkSize
+		if end > len(data) {
+			end = len(data)
+		}
+		leaves = append(leaves, MerkleLeafHash(data[off:end]))
+	}
+	if len(leaves) == 0 {
+		leaves = append(leaves, MerkleLeafHash(nil))
+	}
+	return leaves
+}
+
+// MerkleRootField returns the hash field "merkle-sha256:<hex>" for a tree with one leaf per item,
+// such as the blocks of a document. No items hash like a single empty item.
+func MerkleRootField(items [][]byte) string {
+	leaves := make([][32]byte, 0, len(items))
+	for _, item := range items {
+		leaves = append(leaves, MerkleLeafHash(item))
+	}
+	if len(leaves) == 0 {
+		leaves = append(leaves, MerkleLeafHash(nil))
+	}
+	root := MerkleRoot(leaves)
+	return MerkleHashAlgorithm + ":" + hex.EncodeToString(root[:])
+}
+
+// MerkleRoot returns the root of the tree over the given leaf hashes, which must not be empty.
+func MerkleRoot(leaves [][32]byte) [32]byte {
+	if len(leaves) == 1 {
+		return leaves[0]
+	}
+	k := merkleSplit(len(leaves))
+	return merkleNode(MerkleRoot(leaves[:k]), MerkleRoot(leaves[k:]))
+}
+
+// MerkleInclusionProof returns the sibling hashes proving the leaf at index, ordered from the
+// leaf up to the root.
+func MerkleInclusionProof(leaves [][32]byte, index int) ([][32]byte, error) {
+	if index < 0 || index >= len(leaves) {
+		return nil, fmt.Errorf("merkle leaf index %d out of range [0, %d)", index, len(leaves))
+	}
+	var proof [][32]byte
+	for len(leaves) > 1 {
+		k := merkleSplit(len(leaves))
+		if index < k {
+			proof = append(proof, MerkleRoot(leaves[k:]))
+			leaves = leaves[:k]
+		} else {
+			proof = append(proof, MerkleRoot(leaves[:k]))
+			leaves = leaves[k:]
+			index -= k
+		}
+	}
+	// Collected root-first while descending; proofs are verified leaf-first
+	for i, j := 0, len(proof)-1; i < j; i, j = i+1, j-1 {
+		proof[i], proof[j] = proof[j], proof[i]
+	}
+	return proof, nil
+}
+
+// VerifyMerkleInclusion reports whether chunk is the leaf at index in a tree of count leaves
+// with the given root, using a proof from MerkleInclusionProof.
+func VerifyMerkleInclusion(root [32]byte, index, count int, chunk []byte, proof [][32]byte) bool {
+	if index < 0 || index >= count {
+		return false
+	}
+	computed, ok := merkleRootFromProof(MerkleLeafHash(chunk), index, count, proof)
+	return ok && computed == root
+}
+
+// merkleRootFromProof recomputes the root by descending the same splits the proof was built from
+func merkleRootFromProof(leaf [32]byte, index, count int, proof [][32]byte) ([32]byte, bool) {
+	if count == 1 {
+		return leaf, len(proof) == 0
+	}
+	if len(proof) == 0 {
+		return [32]byte{}, false
+	}
+	sibling := proof[len(proof)-1]
+	rest := proof[:len(proof)-1]
+	k := merkleSplit(count)
+	if index < k {
+		sub, ok := merkleRootFromProof(leaf, index, k, rest)
+		return merkleNode(sub, sibling), ok
+	}
+	sub, ok := merkleRootFromProof(leaf, index-k, count-k, rest)
+	return merkleNode(sibling, sub), ok
+}
+
+// merkleSplit returns the largest power of two smaller than n, for n > 1
+func merkleSplit(n int) int {
+	k := 1
+	for k<<1 < n {
+		k <<= 1
+	}
+	return k
+}
+
+func merkleNode(left, right [32]byte) [32]byte {
+	h := sha256.New()
+	h.Write([]byte{merkleNodePrefix})
+	h.Write(left[:])
+	h.Write(right[:])
+	var out [32]byte
+	h.Sum(out[:0])
+	return out
+}
+
+// merkleHash computes a Merkle root incrementally so the algorithm fits the hash registry
+type merkleHash struct {
+	leaves [][32]byte
+	chunk  []byte
+}
+
+func newMerkleHash() hash.Hash {
+	return &merkleHash{chunk: make([]byte, 0, MerkleChunkSize)}
+}
+
+func (m *merkleHash) Write(p []byte) (int, error) {
+	n := len(p)
+	for len(p) > 0 {
+		if len(m.chunk) == MerkleChunkSize {
+			m.leaves = append(m.leaves, MerkleLeafHash(m.chunk))
+			m.chunk = m.chunk[:0]
+		}
+		take := MerkleChunkSize - len(m.chunk)
+		if take > len(p) {
+			take = len(p)
+		}
+		m.chunk = append(m.chunk, p[:take]...)
+		p = p[take:]
+	}
+	return n, nil
+}
+
+// Sum appends the root without changing the state. The pending chunk is always a leaf,
+// so a write that ends exactly on a chunk boundary does not add an empty leaf.
+func (m *merkleHash) Sum(b []byte) []byte {
+	leaves := m.leaves
+	if len(m.chunk) > 0 || len(leaves) == 0 {
+		leaves = append(leaves[:len(leaves):len(leaves)], MerkleLeafHash(m.chunk))
+	}
+	root := MerkleRoot(leaves)
+	return append(b, root[:]...)
+}
+
+func (m *merkleHash) Reset() {
+	m.leaves = nil
+	m.chunk = m.chunk[:0]
+}
+
+func (m *merkleHash) Size() int      { return sha256.Size }
+func (m *merkleHash) BlockSize() int { return MerkleChunkSize }
@@ -0,0 +1,91 @@
+package crypto
+
+import (
+	"bytes"
+	"encoding/hex"
+	"testing"
+)
+
+func merkleTestData(size int) []byte {
+	data := make([]byte, size)
+	for i := range data {
+		data[i] = byte(i*31 + i/997) // varies between chunks
+	}
+	return data
+}
+
+func TestMerkleHash_MatchesLeafRoot(t *testing.T) {
+	for _, size := range []int{0, 1, MerkleChunkSize, MerkleChunkSize + 1, 3*MerkleChunkSize - 7, 5 * MerkleChunkSize} {
+		data := merkleTestData(size)
+		root := MerkleRoot(MerkleLeafHashes(data))
+
+		field, err := ComputeContentHashFieldWithAlgorithm(MerkleHashAlgorithm, data)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := MerkleHashAlgorithm + ":" + hex.EncodeToString(root[:]); field != want {
+			t.Errorf("size %d: got %s, want %s", size, field, want)
+		}
+
+		// Streaming in odd-sized writes gives the same root
+		h := newMerkleHash()
+		for r := bytes.NewReader(data); r.Len() > 0; {
+			buf := make([]byte, 1000)
+			n, _ := r.Read(buf)
+			h.Write(buf[:n])
+		}
+		if !bytes.Equal(h.Sum(nil), root[:]) {
+			t.Errorf("size %d: streamed root differs", size)
+		}
+	}
+}
+
+func TestMerkleHash_SingleChunkIsLeafHash(t *testing.T) {
+	data := []byte("abc")
+	root := MerkleRoot(MerkleLeafHashes(data))
+	if root != MerkleLeafHash(data) {
+		t.Error("expected the root of a single chunk to be its leaf hash")
+	}
+	if root == HashSHA256(data) {
+		t.Error("expected leaf hashes to be domain separated from plain SHA-256")
+	}
+}
+
+func TestMerkleInclusionProof_VerifiesEveryChunk(t *testing.T) {
+	for _, chunks := range []int{1, 2, 3, 5, 8} {
+		data := merkleTestData(chunks*MerkleChunkSize - 100)
+		leaves := MerkleLeafHashes(data)
+		root := MerkleRoot(leaves)
+		for i := range leaves {
+			proof, err := MerkleInclusionProof(leaves, i)
+			if err != nil {
+				t.Fatal(err)
+			}
+			start := i * MerkleChunkSize
+			end := start + MerkleChunkSize
+			if end > len(data) {
+				end = len(data)
+			}
+			chunk := data[start:end]
+			if !VerifyMerkleInclusion(root, i, len(leaves), chunk, proof) {
+				t.Errorf("%d chunks: proof for chunk %d rejected", chunks, i)
+			}
+
+			tampered := append([]byte{}, chunk...)
+			tampered[0] ^= 0xff
+			if VerifyMerkleInclusion(root, i, len(leaves), tampered, proof) {
+				t.Errorf("%d chunks: tampered chunk %d accepted", chunks, i)
+			}
+			if len(leaves) > 1 && VerifyMerkleInclusion(root, (i+1)%len(leaves), len(leaves), chunk, proof) {
+				t.Errorf("%d chunks: chunk %d accepted at the wrong index", chunks, i)
+			}
+		}
+	}
+}
+
+func TestMerkleInclusionProof_OutOfRange(t *testing.T) {
+	leaves := MerkleLeafHashes(merkleTestData(10))
+	if _, err := MerkleInclusionProof(leaves, 1); err == nil {
+		t.Error("expected an error for an index past the last leaf")
+	}
+}
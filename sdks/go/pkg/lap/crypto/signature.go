@@ -0,0 +1,253 @@
+// Copyright 2025 Jason Stonebraker
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package crypto
+
+import (
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/btcec/v2/schnorr"
+)
+
+// Signature algorithm identifiers used in the Namespace Attestation "alg" field.
+const (
+	// SignatureAlgorithmBIP340 is BIP-340 Schnorr over secp256k1 with 32-byte x-only keys (the default).
+	SignatureAlgorithmBIP340 = "bip340"
+	// SignatureAlgorithmEd25519 is Ed25519 with 32-byte public keys, signing the 32-byte digest as the message.
+	SignatureAlgorithmEd25519 = "ed25519"
+	// SignatureAlgorithmP256 is ECDSA over NIST P-256 with 65-byte uncompressed public keys and
+	// 64-byte r||s signatures, matching the raw formats used by WebCrypto.
+	SignatureAlgorithmP256 = "ecdsa-p256"
+)
+
+// ErrUnknownSignatureAlgorithm is returned for algorithm identifiers that are not supported.
+var ErrUnknownSignatureAlgorithm = errors.New("unknown signature algorithm")
+
+// SignatureAlgorithmNames returns the supported signature algorithm identifiers, default first.
+func SignatureAlgorithmNames() []string {
+	return []string{SignatureAlgorithmBIP340, SignatureAlgorithmEd25519, SignatureAlgorithmP256}
+}
+
+// NormalizeSignatureAlgorithm maps an empty identifier to the BIP-340 default and lowercases the rest.
+func NormalizeSignatureAlgorithm(alg string) string {
+	if alg == "" {
+		return SignatureAlgorithmBIP340
+	}
+	return strings.ToLower(alg)
+}
+
+// Signer signs 32-byte digests with a private key of a specific signature algorithm.
+type Signer interface {
+	// Algorithm returns the signature algorithm identifier.
+	Algorithm() string
+	// PublicKeyHex returns the hex-encoded public key in the algorithm's wire format.
+	PublicKeyHex() string
+	// PrivateKeyHex returns the hex-encoded private key (32 bytes for every supported algorithm).
+	PrivateKeyHex() string
+	// SignHex signs the digest and returns the hex-encoded signature.
+	SignHex(digest32 [32]byte) (string, error)
+}
+
+// GenerateSigner creates a new key pair for the given signature algorithm.
+func GenerateSigner(alg string) (Signer, error) {
+	switch NormalizeSignatureAlgorithm(alg) {
+	case SignatureAlgorithmBIP340:
+		priv, _, err := GenerateKeyPair()
+		if err != nil {
+			return nil, err
+		}
+		return bip340Signer{priv: priv}, nil
+	case SignatureAlgorithmEd25519:
+		_, priv, err := ed25519.GenerateKey(rand.Reader)
+		if err != nil {
+			return nil, err
+		}
+		return ed25519Signer{priv: priv}, nil
+	case SignatureAlgorithmP256:
+		priv, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		if err != nil {
+			return nil, err
+		}
+		return p256Signer{priv: priv}, nil
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnknownSignatureAlgorithm, alg)
+	}
+}
+
+// ParseSignerHex parses a 32-byte hex-encoded private key for the given signature algorithm.
+func ParseSignerHex(alg, privHex string) (Signer, error) {
+	b, err := hex.DecodeString(privHex)
+	if err != nil {
+		return nil, err
+	}
+	if len(b) != 32 {
+		return nil, errors.New("private key must be 32 bytes")
+	}
+	switch NormalizeSignatureAlgorithm(alg) {
+	case SignatureAlgorithmBIP340:
+		priv, _ := btcec.PrivKeyFromBytes(b)
+		return bip340Signer{priv: priv}, nil
+	case SignatureAlgorithmEd25519:
+		return ed25519Signer{priv: ed25519.NewKeyFromSeed(b)}, nil
+	case SignatureAlgorithmP256:
+		key, err := ecdh.P256().NewPrivateKey(b)
+		if err != nil {
+			return nil, errors.New("invalid P-256 private key scalar")
+		}
+		pub, err := ParseP256PubKeyHex(hex.EncodeToString(key.PublicKey().Bytes()))
+		if err != nil {
+			return nil, err
+		}
+		return p256Signer{priv: &ecdsa.PrivateKey{PublicKey: *pub, D: new(big.Int).SetBytes(b)}}, nil
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnknownSignatureAlgorithm, alg)
+	}
+}
+
+// VerifySignatureHex verifies a hex-encoded signature over a 32-byte digest using the named algorithm.
+// An empty algorithm selects BIP-340 Schnorr, whose keys are parsed through DefaultKeyCache.
+func VerifySignatureHex(alg, pubHex, sigHex string, digest32 [32]byte) (bool, error) {
+	switch NormalizeSignatureAlgorithm(alg) {
+	case SignatureAlgorithmBIP340:
+		return VerifySchnorrHexCached(DefaultKeyCache, pubHex, sigHex, digest32)
+	case SignatureAlgorithmEd25519:
+		pub, err := hex.DecodeString(pubHex)
+		if err != nil {
+			return false, err
+		}
+		if len(pub) != ed25519.PublicKeySize {
+			return false, errors.New("ed25519 pubkey must be 32 bytes")
+		}
+		sig, err := hex.DecodeString(sigHex)
+		if err != nil {
+			return false, err
+		}
+		if len(sig) != ed25519.SignatureSize {
+			return false, errors.New("ed25519 signature must be 64 bytes")
+		}
+		return ed25519.Verify(ed25519.PublicKey(pub), digest32[:], sig), nil
+	case SignatureAlgorithmP256:
+		pub, err := ParseP256PubKeyHex(pubHex)
+		if err != nil {
+			return false, err
+		}
+		sig, err := hex.DecodeString(sigHex)
+		if err != nil {
+			return false, err
+		}
+		if len(sig) != 64 {
+			return false, errors.New("ecdsa-p256 signature must be 64 bytes (r||s)")
+		}
+		r := new(big.Int).SetBytes(sig[:32])
+		s := new(big.Int).SetBytes(sig[32:])
+		return ecdsa.Verify(pub, digest32[:], r, s), nil
+	default:
+		return false, fmt.Errorf("%w: %s", ErrUnknownSignatureAlgorithm, alg)
+	}
+}
+
+// ParseP256PubKeyHex parses a 65-byte uncompressed hex-encoded P-256 public key.
+func ParseP256PubKeyHex(pubHex string) (*ecdsa.PublicKey, error) {
+	b, err := hex.DecodeString(pubHex)
+	if err != nil {
+		return nil, err
+	}
+	if len(b) != 65 || b[0] != 0x04 {
+		return nil, errors.New("ecdsa-p256 pubkey must be 65 bytes uncompressed")
+	}
+	if _, err := ecdh.P256().NewPublicKey(b); err != nil { // raw uncompressed point, as imported by WebCrypto
+		return nil, errors.New("ecdsa-p256 pubkey is not on the curve")
+	}
+	return &ecdsa.PublicKey{
+		Curve: elliptic.P256(),
+		X:     new(big.Int).SetBytes(b[1:33]),
+		Y:     new(big.Int).SetBytes(b[33:]),
+	}, nil
+}
+
+// bip340Signer signs with BIP-340 Schnorr over secp256k1.
+type bip340Signer struct {
+	priv *btcec.PrivateKey
+}
+
+func (s bip340Signer) Algorithm() string { return SignatureAlgorithmBIP340 }
+
+func (s bip340Signer) PublicKeyHex() string {
+	return hex.EncodeToString(schnorr.SerializePubKey(s.priv.PubKey()))
+}
+
+func (s bip340Signer) PrivateKeyHex() string { return hex.EncodeToString(s.priv.Serialize()) }
+
+func (s bip340Signer) SignHex(digest32 [32]byte) (string, error) {
+	return SignSchnorrHex(s.priv, digest32)
+}
+
+// ed25519Signer signs the digest bytes with Ed25519.
+type ed25519Signer struct {
+	priv ed25519.PrivateKey
+}
+
+func (s ed25519Signer) Algorithm() string { return SignatureAlgorithmEd25519 }
+
+func (s ed25519Signer) PublicKeyHex() string {
+	return hex.EncodeToString(s.priv.Public().(ed25519.PublicKey))
+}
+
+func (s ed25519Signer) PrivateKeyHex() string { return hex.EncodeToString(s.priv.Seed()) }
+
+func (s ed25519Signer) SignHex(digest32 [32]byte) (string, error) {
+	return hex.EncodeToString(ed25519.Sign(s.priv, digest32[:])), nil
+}
+
+// p256Signer signs the digest with ECDSA over P-256 and encodes signatures as fixed-width r||s.
+type p256Signer struct {
+	priv *ecdsa.PrivateKey
+}
+
+func (s p256Signer) Algorithm() string { return SignatureAlgorithmP256 }
+
+func (s p256Signer) PublicKeyHex() string {
+	pub, err := s.priv.PublicKey.ECDH()
+	if err != nil {
+		return ""
+	}
+	return hex.EncodeToString(pub.Bytes()) // raw uncompressed point, as imported by WebCrypto
+}
+
+func (s p256Signer) PrivateKeyHex() string {
+	b := make([]byte, 32)
+	s.priv.D.FillBytes(b)
+	return hex.EncodeToString(b)
+}
+
+func (s p256Signer) SignHex(digest32 [32]byte) (string, error) {
+	r, sv, err := ecdsa.Sign(rand.Reader, s.priv, digest32[:])
+	if err != nil {
+		return "", err
+	}
+	sig := make([]byte, 64)
+	r.FillBytes(sig[:32])
+	sv.FillBytes(sig[32:])
+	return hex.EncodeToString(sig), nil
+}
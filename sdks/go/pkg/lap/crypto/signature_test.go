@@ -0,0 +1,117 @@
+package crypto
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestSigner_SignVerifyAllAlgorithms(t *testing.T) {
+	digest := HashSHA256([]byte(`{"namespace":"https://example.com/people/alice/","exp":1754909400}`))
+
+	for _, alg := range SignatureAlgorithmNames() {
+		t.Run(alg, func(t *testing.T) {
+			signer, err := GenerateSigner(alg)
+			if err != nil {
+				t.Fatalf("GenerateSigner: %v", err)
+			}
+			if signer.Algorithm() != alg {
+				t.Errorf("Algorithm() = %s, want %s", signer.Algorithm(), alg)
+			}
+
+			sigHex, err := signer.SignHex(digest)
+			if err != nil {
+				t.Fatalf("SignHex: %v", err)
+			}
+			if len(sigHex) != 128 {
+				t.Errorf("signature length = %d hex chars, want 128", len(sigHex))
+			}
+
+			ok, err := VerifySignatureHex(alg, signer.PublicKeyHex(), sigHex, digest)
+			if err != nil || !ok {
+				t.Fatalf("verify failed: ok=%v err=%v", ok, err)
+			}
+
+			wrong := HashSHA256([]byte("other payload"))
+			ok, err = VerifySignatureHex(alg, signer.PublicKeyHex(), sigHex, wrong)
+			if err != nil || ok {
+				t.Errorf("expected verification of wrong digest to fail, ok=%v err=%v", ok, err)
+			}
+		})
+	}
+}
+
+func TestParseSignerHex_RoundTrip(t *testing.T) {
+	for _, alg := range SignatureAlgorithmNames() {
+		t.Run(alg, func(t *testing.T) {
+			signer, err := GenerateSigner(alg)
+			if err != nil {
+				t.Fatal(err)
+			}
+			parsed, err := ParseSignerHex(alg, signer.PrivateKeyHex())
+			if err != nil {
+				t.Fatalf("ParseSignerHex: %v", err)
+			}
+			if parsed.PublicKeyHex() != signer.PublicKeyHex() {
+				t.Errorf("public key mismatch after round trip: got %s, want %s", parsed.PublicKeyHex(), signer.PublicKeyHex())
+			}
+		})
+	}
+}
+
+func TestPublicKeyFormats(t *testing.T) {
+	expected := map[string]int{
+		SignatureAlgorithmBIP340:  64,
+		SignatureAlgorithmEd25519: 64,
+		SignatureAlgorithmP256:    130,
+	}
+	for alg, length := range expected {
+		signer, err := GenerateSigner(alg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := len(signer.PublicKeyHex()); got != length {
+			t.Errorf("%s public key length = %d hex chars, want %d", alg, got, length)
+		}
+	}
+}
+
+func TestVerifySignatureHex_DefaultIsBIP340(t *testing.T) {
+	priv, pubHex, err := GenerateKeyPair()
+	if err != nil {
+		t.Fatal(err)
+	}
+	digest := HashSHA256([]byte("hello world"))
+	sigHex, err := SignSchnorrHex(priv, digest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ok, err := VerifySignatureHex("", pubHex, sigHex, digest)
+	if err != nil || !ok {
+		t.Fatalf("expected empty algorithm to verify as bip340, ok=%v err=%v", ok, err)
+	}
+}
+
+func TestVerifySignatureHex_CrossAlgorithmRejected(t *testing.T) {
+	signer, err := GenerateSigner(SignatureAlgorithmEd25519)
+	if err != nil {
+		t.Fatal(err)
+	}
+	digest := HashSHA256([]byte("hello world"))
+	sigHex, err := signer.SignHex(digest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Ed25519 keys and signatures have the same sizes as BIP-340 ones, so they must not verify as Schnorr
+	if ok, _ := VerifySignatureHex(SignatureAlgorithmBIP340, signer.PublicKeyHex(), sigHex, digest); ok {
+		t.Error("expected ed25519 signature to be rejected as bip340")
+	}
+}
+
+func TestUnknownSignatureAlgorithm(t *testing.T) {
+	if _, err := GenerateSigner("rsa"); !errors.Is(err, ErrUnknownSignatureAlgorithm) {
+		t.Errorf("GenerateSigner: expected ErrUnknownSignatureAlgorithm, got %v", err)
+	}
+	if _, err := VerifySignatureHex("rsa", "00", "00", [32]byte{}); !errors.Is(err, ErrUnknownSignatureAlgorithm) {
+		t.Errorf("VerifySignatureHex: expected ErrUnknownSignatureAlgorithm, got %v", err)
+	}
+}
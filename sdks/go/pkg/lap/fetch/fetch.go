@@ -0,0 +1,353 @@
+package fetch
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/stonebraker/lap/sdks/go/pkg/lap/verify"
+	"github.com/stonebraker/lap/sdks/go/pkg/lap/wire"
+)
+
+// DefaultMaxContentBytes bounds canonical content fetched by URL when no limit is configured
+const DefaultMaxContentBytes = 16 << 20
+
+// ErrHeaderMismatch reports an RA header that differs from the RA served at the attestation URL
+var ErrHeaderMismatch = errors.New("resource attestation header does not match the attestation URL")
+
+// ErrInvalidHeader reports an RA header that could not be decoded
+var ErrInvalidHeader = errors.New("invalid " + wire.AttestationHeaderName + " header")
+
+// ContentTooLargeError reports referenced content larger than the configured limit
+type ContentTooLargeError struct {
+	MaxBytes int64
+}
+
+func (e *ContentTooLargeError) Error() string {
+	return fmt.Sprintf("content exceeds %d bytes", e.MaxBytes)
+}
+
+// NewClient returns a client that only follows same-origin redirects
+func NewClient(timeout time.Duration) *http.Client {
+	return &http.Client{
+		Timeout: timeout,
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if len(via) == 0 {
+				return nil
+			}
+			prev := via[len(via)-1]
+			if !SameOrigin(prev.URL, req.URL) {
+				return fmt.Errorf("cross-origin redirect not allowed")
+			}
+			if len(via) > 10 {
+				return fmt.Errorf("too many redirects")
+			}
+			return nil
+		},
+	}
+}
+
+// SameOrigin checks if two URLs have the same origin (scheme + host)
+func SameOrigin(a, b *url.URL) bool {
+	return strings.EqualFold(a.Scheme, b.Scheme) && strings.EqualFold(a.Host, b.Host)
+}
+
+// ResourceAttestation fetches and strictly decodes a Resource Attestation
+func ResourceAttestation(client *http.Client, url string) (*wire.ResourceAttestation, error) {
+	resp, err := client.Get(url)
+	if err != nil {
+		return nil, fmt.Errorf("fetch failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != 200 {
+		return nil, fmt.Errorf("fetch failed with status %d", resp.StatusCode)
+	}
+
+	attestation, err := wire.DecodeResourceAttestation(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("invalid JSON in attestation: %w", err)
+	}
+
+	return &attestation, nil
+}
+
+// ResolveResourceAttestation returns the fragment's RA and its source for the result context. An RA
+// header is used as is only when responseURL, the final URL of the response that carried it, has the
+// fragment URL's origin; any other header RA, including one supplied by a caller with no response
+// (nil responseURL), counts only when it matches the RA served at the RA URL. crossCheck requires
+// that match for every header RA. Without a header the RA is fetched from its URL.
+func ResolveResourceAttestation(client *http.Client, fragment *wire.Fragment, headerValue string, responseURL *url.URL, crossCheck bool) (*wire.ResourceAttestation, string, error) {
+	if headerValue == "" {
+		ra, err := ResourceAttestation(client, fragment.ResourceAttestationURL)
+		return ra, "", err
+	}
+
+	headerRA, err := wire.DecodeAttestationHeader(headerValue)
+	if err != nil {
+		return nil, verify.RASourceHeader, fmt.Errorf("%w: %w", ErrInvalidHeader, err)
+	}
+	if !crossCheck && headerFromFragmentOrigin(fragment, responseURL) {
+		return &headerRA, verify.RASourceHeader, nil
+	}
+
+	fetched, err := ResourceAttestation(client, fragment.ResourceAttestationURL)
+	if err != nil {
+		return nil, verify.RASourceHeaderChecked, err
+	}
+	if !SameResourceAttestation(headerRA, *fetched) {
+		return nil, verify.RASourceHeaderChecked, ErrHeaderMismatch
+	}
+	return &headerRA, verify.RASourceHeaderChecked, nil
+}
+
+// headerFromFragmentOrigin reports whether a response served from responseURL speaks for the fragment
+func headerFromFragmentOrigin(fragment *wire.Fragment, responseURL *url.URL) bool {
+	if responseURL == nil {
+		return false
+	}
+	fragmentURL, err := url.Parse(fragment.FragmentURL)
+	return err == nil && SameOrigin(responseURL, fragmentURL)
+}
+
+// SameResourceAttestation compares two RAs by their canonical encoding
+func SameResourceAttestation(a, b wire.ResourceAttestation) bool {
+	encA, errA := wire.EncodeAttestationHeader(a)
+	encB, errB := wire.EncodeAttestationHeader(b)
+	return errA == nil && errB == nil && encA == encB
+}
+
+// ValidateResourceAttestation checks that a Resource Attestation has all required fields
+func ValidateResourceAttestation(attestation wire.ResourceAttestation) error {
+	if attestation.FragmentURL == "" {
+		return fmt.Errorf("malformed attestation: missing fragment_url field")
+	}
+	if attestation.Hash == "" {
+		return fmt.Errorf("malformed attestation: missing hash field")
+	}
+	if attestation.PublisherClaim == "" {
+		return fmt.Errorf("malformed attestation: missing publisher_claim field")
+	}
+	if attestation.NamespaceAttestationURL == "" {
+		return fmt.Errorf("malformed attestation: missing namespace_attestation_url field")
+	}
+	return nil
+}
+
+// NamespaceAttestation fetches, strictly decodes and validates a Namespace Attestation
+func NamespaceAttestation(client *http.Client, url string) (*wire.NamespaceAttestation, error) {
+	resp, err := client.Get(url)
+	if err != nil {
+		return nil, fmt.Errorf("fetch failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != 200 {
+		return nil, fmt.Errorf("fetch failed with status %d", resp.StatusCode)
+	}
+
+	attestation, err := wire.DecodeNamespaceAttestation(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("invalid JSON in attestation: %w", err)
+	}
+
+	if err := ValidateNamespaceAttestation(attestation); err != nil {
+		return nil, err
+	}
+
+	return &attestation, nil
+}
+
+// ValidateNamespaceAttestation checks that a Namespace Attestation has all required fields
+func ValidateNamespaceAttestation(attestation wire.NamespaceAttestation) error {
+	if attestation.Payload.Namespace == "" {
+		return fmt.Errorf("malformed attestation: missing payload.namespace field")
+	}
+	if attestation.Key == "" {
+		return fmt.Errorf("malformed attestation: missing key field")
+	}
+	if attestation.Sig == "" {
+		return fmt.Errorf("malformed attestation: missing sig field")
+	}
+	return nil
+}
+
+// CoPublisherAttestations fetches the Namespace Attestation of each co-publisher the RA lists,
+// keyed by URL. An attestation that cannot be fetched is left out; verification then reports that
+// co-publisher's association as failed.
+func CoPublisherAttestations(client *http.Client, ra wire.ResourceAttestation) map[string]wire.NamespaceAttestation {
+	if len(ra.CoPublishers) == 0 {
+		return nil
+	}
+	attestations := make(map[string]wire.NamespaceAttestation, len(ra.CoPublishers))
+	for _, cp := range ra.CoPublishers {
+		if _, ok := attestations[cp.NamespaceAttestationURL]; ok {
+			continue
+		}
+		if na, err := NamespaceAttestation(client, cp.NamespaceAttestationURL); err == nil {
+			attestations[cp.NamespaceAttestationURL] = *na
+		}
+	}
+	return attestations
+}
+
+// StatementIndex fetches an issuer's Namespace Attestation and the statement index published next to
+// it at wire.StatementsLocation
+func StatementIndex(client *http.Client, issuerURL string) (*wire.NamespaceAttestation, *wire.StatementIndex, error) {
+	na, err := NamespaceAttestation(client, issuerURL)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	resp, err := client.Get(wire.StatementsLocation(issuerURL))
+	if err != nil {
+		return nil, nil, fmt.Errorf("fetch failed: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, nil, fmt.Errorf("fetch failed with status %d", resp.StatusCode)
+	}
+	index, err := wire.DecodeStatementIndex(resp.Body)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid statement index: %w", err)
+	}
+	return na, &index, nil
+}
+
+// Statements attaches to a verified result the statements about its resource published by the
+// issuers the caller trusts, each identified by the URL of its Namespace Attestation. Statements are
+// checked against the RA the result was verified with, under the options used for that verification.
+// Issuers that cannot be reached are skipped and reported in the returned error; statements that
+// fail their checks are attached as failed.
+func Statements(client *http.Client, result *verify.VerificationResult, issuerURLs []string, opts verify.Options) error {
+	ra := result.ResourceAttestation
+	if !result.Verified || ra == nil {
+		return nil
+	}
+
+	var errs []error
+	for _, issuerURL := range issuerURLs {
+		issuerNA, index, err := StatementIndex(client, issuerURL)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("issuer %s: %w", issuerURL, err))
+			continue
+		}
+		for _, st := range index.Statements {
+			if verify.NormalizeURL(st.Payload.FragmentURL) != verify.NormalizeURL(ra.FragmentURL) {
+				continue
+			}
+			result.Statements = append(result.Statements, verify.VerifyStatement(st, *ra, *issuerNA, issuerURL, opts))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// ResourceAttestationV01 fetches and strictly decodes an archived v0.1 Resource Attestation
+func ResourceAttestationV01(client *http.Client, url string) (wire.ResourceAttestationV01, error) {
+	resp, err := client.Get(url)
+	if err != nil {
+		return wire.ResourceAttestationV01{}, fmt.Errorf("fetch failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != 200 {
+		return wire.ResourceAttestationV01{}, fmt.Errorf("fetch failed with status %d", resp.StatusCode)
+	}
+	return wire.DecodeResourceAttestationV01(resp.Body)
+}
+
+// NamespaceAttestationV01 returns the first v0.1 Namespace Attestation served in an ancestor
+// directory of the resource, nearest first, and the URL it was found at
+func NamespaceAttestationV01(client *http.Client, resourceURL string) (wire.NamespaceAttestationV01, string, error) {
+	for _, candidate := range wire.NamespaceAttestationCandidatesV01(resourceURL) {
+		resp, err := client.Get(candidate)
+		if err != nil {
+			return wire.NamespaceAttestationV01{}, "", fmt.Errorf("fetch failed: %v", err)
+		}
+		if resp.StatusCode != 200 {
+			resp.Body.Close()
+			continue
+		}
+		na, err := wire.DecodeNamespaceAttestationV01(resp.Body)
+		resp.Body.Close()
+		return na, candidate, err
+	}
+	return wire.NamespaceAttestationV01{}, "", fmt.Errorf("fetch failed: no namespace attestation found above %s", resourceURL)
+}
+
+// Content fetches content the fragment references by URL into its CanonicalContent, reading at most
+// maxBytes (zero selects DefaultMaxContentBytes). Content from another origin is not fetched;
+// verification then fails Resource Presence with origin_mismatch.
+func Content(client *http.Client, fragment *wire.Fragment, maxBytes int64) error {
+	if fragment.ContentURL == "" || len(fragment.CanonicalContent) > 0 {
+		return nil
+	}
+	contentURL, errContent := url.Parse(fragment.ContentURL)
+	fragmentURL, errFragment := url.Parse(fragment.FragmentURL)
+	if errContent != nil || errFragment != nil || !SameOrigin(contentURL, fragmentURL) {
+		return nil
+	}
+
+	resp, err := client.Get(fragment.ContentURL)
+	if err == nil && resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		err = fmt.Errorf("HTTP %d", resp.StatusCode)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to fetch content: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if maxBytes <= 0 {
+		maxBytes = DefaultMaxContentBytes
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxBytes+1))
+	if err != nil {
+		return fmt.Errorf("failed to read content: %v", err)
+	}
+	if int64(len(body)) > maxBytes {
+		return &ContentTooLargeError{MaxBytes: maxBytes}
+	}
+	fragment.CanonicalContent = body
+	if fragment.ContentType == "" {
+		fragment.ContentType = resp.Header.Get("Content-Type")
+	}
+	return nil
+}
+
+// LoadContent is Content for callers that report a failed fetch as a Resource Presence failure: it
+// returns nil once the content is loaded, or the failed result to return in place of verification
+func LoadContent(client *http.Client, fragment *wire.Fragment, maxBytes int64) *verify.VerificationResult {
+	err := Content(client, fragment, maxBytes)
+	if err == nil {
+		return nil
+	}
+	details := map[string]interface{}{"content_url": fragment.ContentURL}
+	var tooLarge *ContentTooLargeError
+	if errors.As(err, &tooLarge) {
+		details["max_bytes"] = tooLarge.MaxBytes
+	}
+	return verify.FailedResult(fragment, verify.CheckResourcePresence, FailureReason(err), err.Error(), details)
+}
+
+// FailureReason classifies a fetch error, giving strict decoding failures their own malformed_* reason
+func FailureReason(err error) string {
+	var tooLarge *ContentTooLargeError
+	if errors.As(err, &tooLarge) {
+		return "content_too_large"
+	}
+	if errors.Is(err, ErrHeaderMismatch) {
+		return "header_mismatch"
+	}
+	if reason := wire.MalformedReason(err); reason != "" {
+		return reason
+	}
+	if errors.Is(err, ErrInvalidHeader) {
+		return "malformed"
+	}
+	return "fetch_failed"
+}
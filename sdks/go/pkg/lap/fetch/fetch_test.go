@@ -0,0 +1,209 @@
+package fetch
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stonebraker/lap/sdks/go/pkg/lap/canonical"
+	"github.com/stonebraker/lap/sdks/go/pkg/lap/crypto"
+	"github.com/stonebraker/lap/sdks/go/pkg/lap/verify"
+	"github.com/stonebraker/lap/sdks/go/pkg/lap/wire"
+)
+
+func TestNewClient_CrossOriginRedirect(t *testing.T) {
+	other := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	defer other.Close()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/same":
+			http.Redirect(w, r, "/target", http.StatusFound)
+		case "/cross":
+			http.Redirect(w, r, other.URL+"/target", http.StatusFound)
+		default:
+			_, _ = w.Write([]byte(`{}`))
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient(time.Second)
+	resp, err := client.Get(server.URL + "/same")
+	if err != nil {
+		t.Fatalf("Expected a same-origin redirect to be followed: %v", err)
+	}
+	resp.Body.Close()
+	if _, err := client.Get(server.URL + "/cross"); err == nil {
+		t.Error("Expected a cross-origin redirect to be refused")
+	}
+}
+
+func TestContent(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain")
+		_, _ = w.Write([]byte("hello world"))
+	}))
+	defer server.Close()
+	client := NewClient(time.Second)
+
+	fragment := &wire.Fragment{FragmentURL: server.URL + "/posts/1", ContentURL: server.URL + "/posts/1/body.txt"}
+	if err := Content(client, fragment, 0); err != nil || string(fragment.CanonicalContent) != "hello world" || fragment.ContentType != "text/plain" {
+		t.Fatalf("Unexpected content %q (%s), %v", fragment.CanonicalContent, fragment.ContentType, err)
+	}
+
+	fragment = &wire.Fragment{FragmentURL: server.URL + "/posts/1", ContentURL: server.URL + "/posts/1/body.txt"}
+	err := Content(client, fragment, 5)
+	var tooLarge *ContentTooLargeError
+	if !errors.As(err, &tooLarge) || tooLarge.MaxBytes != 5 || FailureReason(err) != "content_too_large" {
+		t.Errorf("Expected content_too_large, got %v", err)
+	}
+	fragment = &wire.Fragment{FragmentURL: server.URL + "/posts/1", ContentURL: server.URL + "/posts/1/body.txt"}
+	if failed := LoadContent(client, fragment, 5); failed == nil || failed.ResourcePresence != "fail" || failed.Failure.Reason != "content_too_large" || failed.Failure.Details["max_bytes"] != int64(5) {
+		t.Errorf("Expected a content_too_large result, got %+v", failed)
+	}
+
+	// Content on another origin is left for Resource Presence to reject
+	fragment = &wire.Fragment{FragmentURL: "https://example.com/posts/1", ContentURL: server.URL + "/posts/1/body.txt"}
+	if err := Content(client, fragment, 0); err != nil || fragment.CanonicalContent != nil {
+		t.Errorf("Expected cross-origin content not to be fetched, got %q, %v", fragment.CanonicalContent, err)
+	}
+}
+
+func TestStatements(t *testing.T) {
+	mux := http.NewServeMux()
+	server := httptest.NewServer(mux)
+	defer server.Close()
+	client := NewClient(time.Second)
+
+	// Bob's NA expired an hour ago, so his statement only checks as of an earlier time
+	priv, pubKey, err := crypto.GenerateKeyPair()
+	if err != nil {
+		t.Fatal(err)
+	}
+	issuerURL := server.URL + "/people/bob/_la_namespace.json"
+	nsPayload := wire.NamespacePayload{Namespace: server.URL + "/people/bob/", Exp: time.Now().Add(-time.Hour).Unix()}
+	nsBytes, _ := canonical.MarshalNamespacePayloadCanonical(nsPayload.ToCanonical())
+	nsSig, err := crypto.SignSchnorrHex(priv, crypto.HashSHA256(nsBytes))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ra := wire.ResourceAttestation{FragmentURL: "https://alice.example/posts/1", Hash: crypto.ComputeContentHashField([]byte("<p>Hi</p>"))}
+	stPayload := wire.StatementPayload{FragmentURL: ra.FragmentURL, Hash: ra.Hash, Type: wire.StatementDispute, NamespaceAttestationURL: issuerURL, Iat: time.Now().Add(-2 * time.Hour).Unix()}
+	stBytes, _ := canonical.MarshalStatementPayloadCanonical(stPayload.ToCanonical())
+	stSig, err := crypto.SignSchnorrHex(priv, crypto.HashSHA256(stBytes))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	mux.HandleFunc("/people/bob/_la_namespace.json", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(wire.NamespaceAttestation{Payload: nsPayload, Key: pubKey, Sig: nsSig})
+	})
+	mux.HandleFunc("/people/bob/_la_statements.json", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(wire.StatementIndex{Statements: []wire.Statement{{Payload: stPayload, Key: pubKey, Sig: stSig}}})
+	})
+
+	verified := func() *verify.VerificationResult {
+		return &verify.VerificationResult{Verified: true, ResourceAttestation: &ra}
+	}
+
+	result := verified()
+	if err := Statements(client, result, []string{issuerURL}, verify.Options{At: time.Now().Add(-2 * time.Hour)}); err != nil {
+		t.Fatalf("Statements failed: %v", err)
+	}
+	if len(result.Statements) != 1 || result.Statements[0].Status != "pass" {
+		t.Fatalf("Expected the statement to pass as of the verification time, got %+v", result.Statements)
+	}
+
+	result = verified()
+	if err := Statements(client, result, []string{issuerURL}, verify.Options{}); err != nil {
+		t.Fatalf("Statements failed: %v", err)
+	}
+	if len(result.Statements) != 1 || result.Statements[0].Status != "fail" {
+		t.Errorf("Expected the statement to fail against the expired NA now, got %+v", result.Statements)
+	}
+
+	result = verified()
+	if err := Statements(client, result, []string{server.URL + "/missing/_la_namespace.json"}, verify.Options{}); err == nil || len(result.Statements) != 0 {
+		t.Errorf("Expected an error and no statements for an unreachable issuer, got %+v (%v)", result.Statements, err)
+	}
+}
+
+func TestResolveResourceAttestation(t *testing.T) {
+	served := wire.ResourceAttestation{FragmentURL: "https://example.com/posts/1", Hash: "sha256:aa", PublisherClaim: "aa", NamespaceAttestationURL: "https://example.com/_la_namespace.json"}
+	fetches := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fetches++
+		_ = json.NewEncoder(w).Encode(served)
+	}))
+	defer server.Close()
+	client := NewClient(time.Second)
+	fragment := &wire.Fragment{FragmentURL: served.FragmentURL, ResourceAttestationURL: server.URL + "/_la_resource.json"}
+
+	forged := served
+	forged.Hash = "sha256:bb"
+	forgedHeader, _ := wire.EncodeAttestationHeader(forged)
+	servedHeader, _ := wire.EncodeAttestationHeader(served)
+	sameOrigin, _ := url.Parse("https://example.com/posts/1/")
+	otherOrigin, _ := url.Parse("https://attacker.example/posts/1")
+
+	tests := []struct {
+		name        string
+		header      string
+		responseURL *url.URL
+		crossCheck  bool
+		source      string
+		fetched     bool
+		err         error
+	}{
+		{name: "header from the fragment origin", header: forgedHeader, responseURL: sameOrigin, source: verify.RASourceHeader},
+		{name: "header from another origin", header: forgedHeader, responseURL: otherOrigin, source: verify.RASourceHeaderChecked, fetched: true, err: ErrHeaderMismatch},
+		{name: "header without a response", header: forgedHeader, source: verify.RASourceHeaderChecked, fetched: true, err: ErrHeaderMismatch},
+		{name: "matching header without a response", header: servedHeader, source: verify.RASourceHeaderChecked, fetched: true},
+		{name: "cross-check", header: servedHeader, responseURL: sameOrigin, crossCheck: true, source: verify.RASourceHeaderChecked, fetched: true},
+		{name: "no header", source: "", fetched: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fetches = 0
+			ra, source, err := ResolveResourceAttestation(client, fragment, tt.header, tt.responseURL, tt.crossCheck)
+			if !errors.Is(err, tt.err) || (tt.err == nil && err != nil) {
+				t.Fatalf("Expected error %v, got %v", tt.err, err)
+			}
+			if source != tt.source {
+				t.Errorf("Expected source %q, got %q", tt.source, source)
+			}
+			if (fetches > 0) != tt.fetched {
+				t.Errorf("Expected fetched=%v, got %d fetches", tt.fetched, fetches)
+			}
+			if err == nil && ra == nil {
+				t.Error("Expected an RA")
+			}
+		})
+	}
+}
+
+func TestFailureReason(t *testing.T) {
+	_, malformed := wire.DecodeResourceAttestation(strings.NewReader(`{"fragment_url": "a", "extra": 1}`))
+	tests := []struct {
+		err  error
+		want string
+	}{
+		{ErrHeaderMismatch, "header_mismatch"},
+		{fmt.Errorf("%w: bad base64", ErrInvalidHeader), "malformed"},
+		{fmt.Errorf("invalid JSON in attestation: %w", malformed), wire.MalformedReason(malformed)},
+		{errors.New("fetch failed with status 404"), "fetch_failed"},
+	}
+	for _, tt := range tests {
+		if got := FailureReason(tt.err); got != tt.want {
+			t.Errorf("FailureReason(%v) = %q, want %q", tt.err, got, tt.want)
+		}
+	}
+}
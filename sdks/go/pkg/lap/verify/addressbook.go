@@ -0,0 +1,240 @@
+package verify
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// Sources of an address book contact, from the most to the least trusted
+const (
+	SourceSelf           = "self"             // Entered by the reader
+	SourceFriendOfFriend = "friend-of-friend" // Imported from a contact's own address book
+	SourceProfile        = "profile"          // Taken from the publisher's verified profile fragment
+)
+
+// sourceRank orders sources by trust; a contact is only replaced by one from an equal or more trusted source
+var sourceRank = map[string]int{SourceSelf: 3, SourceFriendOfFriend: 2, SourceProfile: 1}
+
+// AddressBook maps publisher keys to the names a reader knows them by (petnames). Verification
+// only proves that a key published a fragment; the address book says whose key it is.
+type AddressBook struct {
+	Contacts []Contact `json:"contacts"`
+}
+
+// Contact is one key with its petname and where the mapping came from
+type Contact struct {
+	Key    string `json:"key"`            // Publisher public key hex
+	Name   string `json:"name"`           // Petname shown in results
+	Note   string `json:"note,omitempty"` // Free-form note
+	Source string `json:"source"`         // SourceSelf, SourceFriendOfFriend or SourceProfile
+	Via    string `json:"via,omitempty"`  // Friend-of-friend: who it was imported from; profile: the profile fragment URL
+	Added  int64  `json:"added"`          // When the contact was added, seconds since epoch
+}
+
+// LoadAddressBook reads the address book at path; a missing file is an empty address book
+func LoadAddressBook(path string) (*AddressBook, error) {
+	f, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return &AddressBook{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	book, err := DecodeAddressBook(f)
+	if err != nil {
+		return nil, fmt.Errorf("address book %s: %w", path, err)
+	}
+	return book, nil
+}
+
+// DecodeAddressBook strictly decodes and validates an address book, such as one exported by a contact
+func DecodeAddressBook(r io.Reader) (*AddressBook, error) {
+	dec := json.NewDecoder(r)
+	dec.DisallowUnknownFields()
+	var book AddressBook
+	if err := dec.Decode(&book); err != nil {
+		return nil, fmt.Errorf("invalid address book: %w", err)
+	}
+	seen := map[string]bool{}
+	for _, contact := range book.Contacts {
+		if err := contact.validate(); err != nil {
+			return nil, fmt.Errorf("invalid address book: %w", err)
+		}
+		if seen[strings.ToLower(contact.Key)] {
+			return nil, fmt.Errorf("invalid address book: key %s listed twice", contact.Key)
+		}
+		seen[strings.ToLower(contact.Key)] = true
+	}
+	return &book, nil
+}
+
+// Save writes the address book to path
+func (b *AddressBook) Save(path string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
+	if err != nil {
+		return err
+	}
+	if err := b.Encode(f); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// Encode writes the address book as indented JSON, the format read by DecodeAddressBook
+func (b *AddressBook) Encode(w io.Writer) error {
+	if b.Contacts == nil {
+		b.Contacts = []Contact{}
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(b)
+}
+
+// Lookup returns the contact for a publisher key, ignoring case
+func (b *AddressBook) Lookup(key string) (Contact, bool) {
+	for _, contact := range b.Contacts {
+		if strings.EqualFold(contact.Key, key) {
+			return contact, true
+		}
+	}
+	return Contact{}, false
+}
+
+// Add adds a contact, or replaces the one for the same key. A contact from a less trusted source
+// does not replace one from a more trusted source, so a profile never renames a friend.
+func (b *AddressBook) Add(contact Contact) error {
+	if contact.Added == 0 {
+		contact.Added = time.Now().Unix()
+	}
+	if err := contact.validate(); err != nil {
+		return err
+	}
+	for i, existing := range b.Contacts {
+		if !strings.EqualFold(existing.Key, contact.Key) {
+			continue
+		}
+		if sourceRank[contact.Source] < sourceRank[existing.Source] {
+			return fmt.Errorf("key %s is already known as %q (%s)", contact.Key, existing.Name, existing.Source)
+		}
+		b.Contacts[i] = contact
+		return nil
+	}
+	b.Contacts = append(b.Contacts, contact)
+	return nil
+}
+
+// Remove removes the contact for a publisher key and reports whether there was one
+func (b *AddressBook) Remove(key string) bool {
+	for i, contact := range b.Contacts {
+		if strings.EqualFold(contact.Key, key) {
+			b.Contacts = append(b.Contacts[:i], b.Contacts[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// Import adds the contacts another reader entered themselves as friend-of-friend contacts via
+// that reader; what they imported in turn is not passed on. It returns how many were added.
+func (b *AddressBook) Import(other *AddressBook, via string) int {
+	imported := 0
+	for _, contact := range other.Contacts {
+		if contact.Source != SourceSelf {
+			continue
+		}
+		contact.Source, contact.Via, contact.Added = SourceFriendOfFriend, via, 0
+		if b.Add(contact) == nil {
+			imported++
+		}
+	}
+	return imported
+}
+
+// profileNameAttributes are the profile fragment attributes naming its publisher, in order of preference
+var profileNameAttributes = []*regexp.Regexp{
+	regexp.MustCompile(`data-profile-name="([^"]+)"`),
+	regexp.MustCompile(`data-profile-display-name="([^"]+)"`),
+}
+
+// ProfileContact returns the contact a publisher declares in its profile fragment: the name from the
+// data-profile-name (or data-profile-display-name) attribute of content, for the key the fragment
+// verified under. content must be the fragment's verified canonical content, not its preview.
+func ProfileContact(result VerificationResult, profileURL string, content []byte) (Contact, error) {
+	if !result.Verified || result.Context == nil || result.Context.PublisherClaim == "" {
+		return Contact{}, errors.New("profile fragment did not verify")
+	}
+	for _, attribute := range profileNameAttributes {
+		if matches := attribute.FindSubmatch(content); matches != nil {
+			if name := strings.TrimSpace(html.UnescapeString(string(matches[1]))); name != "" {
+				return Contact{
+					Key:    result.Context.PublisherClaim,
+					Name:   name,
+					Source: SourceProfile,
+					Via:    profileURL,
+				}, nil
+			}
+		}
+	}
+	return Contact{}, errors.New("profile fragment does not declare a name")
+}
+
+// Describe says who a verification result is from: "verified as Alice (your contact)", or
+// "verified, unknown publisher" when the key is not in the address book. Failed results have no
+// publisher to name and are described as "not verified"; neither do results whose resource was not
+// fetched live (stapled or offline), since their fragment could have been copied from anywhere.
+func (b *AddressBook) Describe(result VerificationResult) string {
+	if !result.Verified || result.Context == nil {
+		return "not verified"
+	}
+	if result.ResourcePresence != "pass" {
+		return "verified, publisher not named (resource not fetched live)"
+	}
+	contact, ok := b.Lookup(result.Context.PublisherClaim)
+	if !ok || result.Context.PublisherClaim == "" {
+		return "verified, unknown publisher"
+	}
+	return fmt.Sprintf("verified as %s (%s)", contact.Name, contact.SourceLabel())
+}
+
+// SourceLabel explains a contact's source to the reader, e.g. "your contact" or "via Bob"
+func (c Contact) SourceLabel() string {
+	switch c.Source {
+	case SourceSelf:
+		return "your contact"
+	case SourceFriendOfFriend:
+		return "via " + c.Via
+	default:
+		return "from their profile"
+	}
+}
+
+// validate checks a contact has a hex key, a name and a known source
+func (c Contact) validate() error {
+	if _, err := hex.DecodeString(c.Key); err != nil || c.Key == "" {
+		return fmt.Errorf("key %q is not a hex public key", c.Key)
+	}
+	if strings.TrimSpace(c.Name) == "" {
+		return fmt.Errorf("contact %s has no name", c.Key)
+	}
+	if _, ok := sourceRank[c.Source]; !ok {
+		return fmt.Errorf("contact %s has unknown source %q", c.Key, c.Source)
+	}
+	if c.Source == SourceFriendOfFriend && c.Via == "" {
+		return fmt.Errorf("friend-of-friend contact %s does not say who it came via", c.Key)
+	}
+	return nil
+}
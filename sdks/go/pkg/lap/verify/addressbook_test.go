@@ -0,0 +1,135 @@
+package verify
+
+import (
+	"bytes"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestAddressBook_Describe(t *testing.T) {
+	fragment, ra, na := newSignedFixture(t, "")
+	result := VerifyFragment(fragment, ra, na)
+	if !result.Verified || result.Context.PublisherClaim != na.Key {
+		t.Fatalf("Expected a verified result naming the publisher key, got %+v", result.Context)
+	}
+
+	book := &AddressBook{}
+	if got := book.Describe(result); got != "verified, unknown publisher" {
+		t.Errorf("Expected an unknown publisher, got %q", got)
+	}
+
+	// A profile names the key until the reader names it themselves
+	if err := book.Add(Contact{Key: na.Key, Name: "Alice Cooks", Source: SourceProfile, Via: fragment.FragmentURL}); err != nil {
+		t.Fatal(err)
+	}
+	if got := book.Describe(result); got != "verified as Alice Cooks (from their profile)" {
+		t.Errorf("Unexpected description %q", got)
+	}
+	if err := book.Add(Contact{Key: strings.ToUpper(na.Key), Name: "Alice", Source: SourceSelf}); err != nil {
+		t.Fatal(err)
+	}
+	if got := book.Describe(result); got != "verified as Alice (your contact)" {
+		t.Errorf("Unexpected description %q", got)
+	}
+	if err := book.Add(Contact{Key: na.Key, Name: "Mallory", Source: SourceProfile, Via: fragment.FragmentURL}); err == nil {
+		t.Error("Expected a profile not to rename a contact the reader entered")
+	}
+	if len(book.Contacts) != 1 {
+		t.Errorf("Expected one contact per key, got %d", len(book.Contacts))
+	}
+
+	failed := VerifyFragment(fragment, ra, na)
+	failed.Verified = false
+	if got := book.Describe(failed); got != "not verified" {
+		t.Errorf("Expected a failed result not to be attributed, got %q", got)
+	}
+
+	// A resource that was not fetched live could be a copy, so its publisher is not named
+	opts := DefaultOptions()
+	opts.Offline = true
+	offline := VerifyFragmentWithOptions(fragment, ra, na, opts)
+	if !offline.Verified || offline.Context.PublisherClaim != "" {
+		t.Errorf("Expected an offline result without a publisher key, got %+v", offline.Context)
+	}
+	stapled := result
+	stapled.ResourcePresence = StatusSkipStapled
+	if got := book.Describe(stapled); got != "verified, publisher not named (resource not fetched live)" {
+		t.Errorf("Expected a stapled result not to be attributed, got %q", got)
+	}
+}
+
+func TestAddressBook_ImportExport(t *testing.T) {
+	bob := &AddressBook{}
+	_ = bob.Add(Contact{Key: strings.Repeat("ab", 32), Name: "Alice", Note: "met at the market", Source: SourceSelf})
+	_ = bob.Add(Contact{Key: strings.Repeat("cd", 32), Name: "Carol", Source: SourceFriendOfFriend, Via: "Dave"})
+
+	var exported bytes.Buffer
+	if err := bob.Encode(&exported); err != nil {
+		t.Fatal(err)
+	}
+	decoded, err := DecodeAddressBook(&exported)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	mine := &AddressBook{}
+	if n := mine.Import(decoded, "Bob"); n != 1 {
+		t.Fatalf("Expected only Bob's own contacts to be imported, got %d", n)
+	}
+	alice, ok := mine.Lookup(strings.Repeat("ab", 32))
+	if !ok || alice.Source != SourceFriendOfFriend || alice.Via != "Bob" || alice.SourceLabel() != "via Bob" {
+		t.Errorf("Unexpected imported contact %+v", alice)
+	}
+
+	path := filepath.Join(t.TempDir(), "lap", "contacts.json")
+	if err := mine.Save(path); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := LoadAddressBook(path)
+	if err != nil || len(loaded.Contacts) != 1 || loaded.Contacts[0] != alice {
+		t.Errorf("Expected the saved address book back, got %+v, %v", loaded, err)
+	}
+	if !loaded.Remove(alice.Key) || len(loaded.Contacts) != 0 {
+		t.Error("Expected the contact to be removed")
+	}
+
+	for _, bad := range []string{
+		`{"contacts": [{"key": "zz", "name": "Alice", "source": "self", "added": 1}]}`,
+		`{"contacts": [{"key": "ab", "name": "", "source": "self", "added": 1}]}`,
+		`{"contacts": [{"key": "ab", "name": "Alice", "source": "rumour", "added": 1}]}`,
+		`{"contacts": [{"key": "ab", "name": "Alice", "source": "friend-of-friend", "added": 1}]}`,
+		`{"contacts": [{"key": "ab", "name": "Alice", "source": "self"}, {"key": "AB", "name": "Al", "source": "self"}]}`,
+		`{"contacts": [], "extra": true}`,
+	} {
+		if _, err := DecodeAddressBook(strings.NewReader(bad)); err == nil {
+			t.Errorf("Expected %s to be rejected", bad)
+		}
+	}
+}
+
+func TestProfileContact(t *testing.T) {
+	fragment, ra, na := newSignedFixture(t, "")
+	result := VerifyFragment(fragment, ra, na)
+	profileURL := "https://example.com/people/alice/profile/index.htmx"
+
+	content := []byte(`<header><h1 data-profile-display-name="Alice Cooks">Alice Cooks</h1><p data-profile-name="Alice &amp; Co">@alice</p></header>`)
+	contact, err := ProfileContact(result, profileURL, content)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if contact.Key != na.Key || contact.Name != "Alice & Co" || contact.Source != SourceProfile || contact.Via != profileURL {
+		t.Errorf("Unexpected profile contact %+v", contact)
+	}
+
+	if contact, _ := ProfileContact(result, profileURL, []byte(`<h1 data-profile-display-name="Alice Cooks">Alice Cooks</h1>`)); contact.Name != "Alice Cooks" {
+		t.Errorf("Expected the display name as a fallback, got %q", contact.Name)
+	}
+	if _, err := ProfileContact(result, profileURL, []byte(`<h1>Alice</h1>`)); err == nil {
+		t.Error("Expected a profile without a name to be rejected")
+	}
+	result.Verified = false
+	if _, err := ProfileContact(result, profileURL, content); err == nil {
+		t.Error("Expected an unverified profile to be rejected")
+	}
+}
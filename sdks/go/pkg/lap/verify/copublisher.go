@@ -0,0 +1,148 @@
+package verify
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/stonebraker/lap/sdks/go/pkg/lap/canonical"
+	"github.com/stonebraker/lap/sdks/go/pkg/lap/crypto"
+	"github.com/stonebraker/lap/sdks/go/pkg/lap/wire"
+)
+
+// PublisherResult is the Publisher Association outcome for one author of a co-authored resource
+type PublisherResult struct {
+	PublisherClaim          string          `json:"publisher_claim"`
+	NamespaceAttestationURL string          `json:"namespace_attestation_url"`
+	Namespace               string          `json:"namespace,omitempty"` // Namespace of the author's NA, when it was available
+	Status                  string          `json:"status"`              // "pass", "fail"
+	Failure                 *FailureDetails `json:"failure,omitempty"`
+}
+
+// verifyCoPublishers reports the association of every author, the publisher first. primaryFailure is
+// the publisher's own association failure, if any; each co-publisher is checked independently.
+func verifyCoPublishers(ra wire.ResourceAttestation, na wire.NamespaceAttestation, primaryFailure *FailureDetails, coAttestations map[string]wire.NamespaceAttestation, opts Options) []PublisherResult {
+	results := []PublisherResult{{
+		PublisherClaim:          ra.PublisherClaim,
+		NamespaceAttestationURL: ra.NamespaceAttestationURL,
+		Namespace:               na.Payload.Namespace,
+		Status:                  "pass",
+		Failure:                 primaryFailure,
+	}}
+	if primaryFailure != nil {
+		results[0].Status = "fail"
+	}
+
+	for _, cp := range ra.CoPublishers {
+		result := PublisherResult{
+			PublisherClaim:          cp.PublisherClaim,
+			NamespaceAttestationURL: cp.NamespaceAttestationURL,
+			Status:                  "pass",
+		}
+		coNA, found := coAttestations[cp.NamespaceAttestationURL]
+		if found {
+			result.Namespace = coNA.Payload.Namespace
+		}
+		if err := verifyCoPublisher(ra, cp, coNA, found, opts); err != nil {
+			result.Status = "fail"
+			result.Failure = &FailureDetails{
+				Check:   "publisher_association",
+				Reason:  classifyCoPublisherError(err),
+				Message: err.Error(),
+				Details: getCoPublisherFailureDetails(err, cp, coNA, opts),
+			}
+		}
+		results = append(results, result)
+	}
+	return results
+}
+
+// verifyCoPublisher checks one co-publisher: its Namespace Attestation must be served from the origin
+// of the namespace it attests, carry the claimed key and be valid, and that key must have signed the
+// RA's fragment URL and hash
+func verifyCoPublisher(ra wire.ResourceAttestation, cp wire.CoPublisher, na wire.NamespaceAttestation, found bool, opts Options) error {
+	if !found {
+		return fmt.Errorf("co-publisher namespace attestation unavailable: %s", cp.NamespaceAttestationURL)
+	}
+	if !isSameOrigin(cp.NamespaceAttestationURL, na.Payload.Namespace) {
+		return fmt.Errorf("co-publisher namespace attestation URL origin mismatch: namespace %s, attestation %s", na.Payload.Namespace, cp.NamespaceAttestationURL)
+	}
+	if na.Key != cp.PublisherClaim {
+		return fmt.Errorf("namespace attestation key mismatch: got %s, want %s", na.Key, cp.PublisherClaim)
+	}
+	if err := verifyNamespaceSignature(na, opts); err != nil {
+		return err
+	}
+
+	payloadBytes, err := canonical.MarshalCoPublisherPayloadCanonical(ra.CoPublisherPayload())
+	if err != nil {
+		return fmt.Errorf("failed to marshal canonical payload: %w", err)
+	}
+	ok, err := crypto.VerifySignatureHex(na.Alg, na.Key, cp.Sig, crypto.HashSHA256(payloadBytes))
+	if err != nil || !ok {
+		return errors.New("co-publisher signature invalid")
+	}
+	return nil
+}
+
+// classifyCoPublisherError categorizes co-publisher association errors
+func classifyCoPublisherError(err error) string {
+	errStr := err.Error()
+	if contains(errStr, "co-publisher namespace attestation unavailable") {
+		return "namespace_attestation_unavailable"
+	}
+	if contains(errStr, "co-publisher namespace attestation URL origin mismatch") {
+		return "origin_mismatch"
+	}
+	if contains(errStr, "co-publisher signature invalid") {
+		return "co_signature_invalid"
+	}
+	return classifyPublisherAssociationError(err)
+}
+
+// getCoPublisherFailureDetails provides detailed failure information for a co-publisher
+func getCoPublisherFailureDetails(err error, cp wire.CoPublisher, na wire.NamespaceAttestation, opts Options) map[string]interface{} {
+	errStr := err.Error()
+	details := map[string]interface{}{
+		"publisher_claim":           cp.PublisherClaim,
+		"namespace_attestation_url": cp.NamespaceAttestationURL,
+	}
+
+	if contains(errStr, "co-publisher namespace attestation URL origin mismatch") {
+		details["namespace"] = na.Payload.Namespace
+	} else if contains(errStr, "namespace attestation key mismatch") {
+		details["expected"] = cp.PublisherClaim
+		details["actual"] = na.Key
+	} else if contains(errStr, "namespace attestation expired") {
+		details["expires_at"] = na.Payload.Exp
+		details["current_time"] = opts.now().Unix()
+	} else if contains(errStr, "unsupported signature algorithm") {
+		details["algorithm"] = na.Alg
+		details["supported"] = crypto.SignatureAlgorithmNames()
+	}
+
+	return details
+}
+
+// sameCoPublishers reports whether a fragment lists exactly the RA's co-publishers, in order
+func sameCoPublishers(listed []wire.Publisher, ra wire.ResourceAttestation) bool {
+	attested := wire.ListedCoPublishers(ra)
+	if len(listed) != len(attested) {
+		return false
+	}
+	for i := range listed {
+		if listed[i] != attested[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// publisherClaims formats co-publisher claims for messages
+func publisherClaims(publishers []wire.Publisher) string {
+	claims := make([]string, len(publishers))
+	for i, p := range publishers {
+		claims[i] = p.PublisherClaim
+	}
+	return "[" + strings.Join(claims, ", ") + "]"
+}
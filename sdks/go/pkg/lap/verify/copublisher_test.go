@@ -0,0 +1,138 @@
+package verify
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stonebraker/lap/sdks/go/pkg/lap/canonical"
+	"github.com/stonebraker/lap/sdks/go/pkg/lap/crypto"
+	"github.com/stonebraker/lap/sdks/go/pkg/lap/wire"
+)
+
+const coPublisherNAURL = "https://bob.example/people/bob/_la_namespace.json"
+
+// coAuthoredFixture returns a fragment co-authored by Bob, its RA carrying Bob's co-signature, Alice's
+// NA, Bob's NA and a signer for further co-signatures with Bob's key
+func coAuthoredFixture(t *testing.T) (wire.Fragment, wire.ResourceAttestation, wire.NamespaceAttestation, wire.NamespaceAttestation, func(canonical.CoPublisherPayloadCanonical) string) {
+	t.Helper()
+	fragment, ra, na := newSignedFixture(t, "")
+
+	priv, pubKey, err := crypto.GenerateKeyPair()
+	if err != nil {
+		t.Fatal(err)
+	}
+	payload := wire.NamespacePayload{Namespace: "https://bob.example/people/bob/", Exp: time.Now().Add(time.Hour).Unix()}
+	payloadBytes, err := canonical.MarshalNamespacePayloadCanonical(payload.ToCanonical())
+	if err != nil {
+		t.Fatal(err)
+	}
+	sig, err := crypto.SignSchnorrHex(priv, crypto.HashSHA256(payloadBytes))
+	if err != nil {
+		t.Fatal(err)
+	}
+	coNA := wire.NamespaceAttestation{Payload: payload, Key: pubKey, Sig: sig}
+
+	coSign := func(p canonical.CoPublisherPayloadCanonical) string {
+		payloadBytes, err := canonical.MarshalCoPublisherPayloadCanonical(p)
+		if err != nil {
+			t.Fatal(err)
+		}
+		sig, err := crypto.SignSchnorrHex(priv, crypto.HashSHA256(payloadBytes))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return sig
+	}
+
+	ra.CoPublishers = []wire.CoPublisher{{
+		PublisherClaim:          pubKey,
+		NamespaceAttestationURL: coPublisherNAURL,
+		Sig:                     coSign(ra.CoPublisherPayload()),
+	}}
+	fragment.CoPublishers = []wire.Publisher{{PublisherClaim: pubKey, NamespaceAttestationURL: coPublisherNAURL}}
+	return fragment, ra, na, coNA, coSign
+}
+
+func TestVerifyFragmentWithCoPublishers(t *testing.T) {
+	fragment, ra, na, coNA, _ := coAuthoredFixture(t)
+
+	result := VerifyFragmentWithCoPublishers(fragment, ra, na, map[string]wire.NamespaceAttestation{coPublisherNAURL: coNA}, DefaultOptions())
+	if !result.Verified {
+		t.Fatalf("Expected co-authored fragment to verify, got %+v", result.Failure)
+	}
+	if len(result.Publishers) != 2 {
+		t.Fatalf("Expected two publisher results, got %+v", result.Publishers)
+	}
+	if result.Publishers[0].PublisherClaim != ra.PublisherClaim || result.Publishers[1].Namespace != coNA.Payload.Namespace {
+		t.Errorf("Unexpected publisher results: %+v", result.Publishers)
+	}
+	for _, p := range result.Publishers {
+		if p.Status != "pass" {
+			t.Errorf("Expected %s to pass, got %s %+v", p.PublisherClaim, p.Status, p.Failure)
+		}
+	}
+}
+
+func TestVerifyFragmentWithCoPublishers_Failures(t *testing.T) {
+	tests := []struct {
+		name   string
+		mutate func(*wire.Fragment, *wire.ResourceAttestation, map[string]wire.NamespaceAttestation, func(canonical.CoPublisherPayloadCanonical) string)
+		check  string
+		reason string
+	}{
+		{
+			name: "unavailable",
+			mutate: func(f *wire.Fragment, ra *wire.ResourceAttestation, coNAs map[string]wire.NamespaceAttestation, _ func(canonical.CoPublisherPayloadCanonical) string) {
+				delete(coNAs, coPublisherNAURL)
+			},
+			check:  "publisher_association",
+			reason: "namespace_attestation_unavailable",
+		},
+		{
+			name: "signed other content",
+			mutate: func(f *wire.Fragment, ra *wire.ResourceAttestation, _ map[string]wire.NamespaceAttestation, coSign func(canonical.CoPublisherPayloadCanonical) string) {
+				ra.CoPublishers[0].Sig = coSign(canonical.CoPublisherPayloadCanonical{FragmentURL: ra.FragmentURL, Hash: crypto.ComputeContentHashField([]byte("draft"))})
+			},
+			check:  "publisher_association",
+			reason: "co_signature_invalid",
+		},
+		{
+			name: "attestation on another origin",
+			mutate: func(f *wire.Fragment, ra *wire.ResourceAttestation, coNAs map[string]wire.NamespaceAttestation, _ func(canonical.CoPublisherPayloadCanonical) string) {
+				moved := "https://mallory.example/bob/_la_namespace.json"
+				coNAs[moved] = coNAs[coPublisherNAURL]
+				ra.CoPublishers[0].NamespaceAttestationURL = moved
+				f.CoPublishers[0].NamespaceAttestationURL = moved
+			},
+			check:  "publisher_association",
+			reason: "origin_mismatch",
+		},
+		{
+			name: "fragment omits co-publisher",
+			mutate: func(f *wire.Fragment, _ *wire.ResourceAttestation, _ map[string]wire.NamespaceAttestation, _ func(canonical.CoPublisherPayloadCanonical) string) {
+				f.CoPublishers = nil
+			},
+			check:  "resource_presence",
+			reason: "co_publisher_mismatch",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fragment, ra, na, coNA, coSign := coAuthoredFixture(t)
+			coNAs := map[string]wire.NamespaceAttestation{coPublisherNAURL: coNA}
+			tt.mutate(&fragment, &ra, coNAs, coSign)
+
+			result := VerifyFragmentWithCoPublishers(fragment, ra, na, coNAs, DefaultOptions())
+			if result.Verified {
+				t.Fatal("Expected verification to fail")
+			}
+			if result.Failure.Check != tt.check || result.Failure.Reason != tt.reason {
+				t.Errorf("Expected %s/%s, got %s/%s: %s", tt.check, tt.reason, result.Failure.Check, result.Failure.Reason, result.Failure.Message)
+			}
+			if tt.check == "publisher_association" && (len(result.Publishers) != 2 || result.Publishers[0].Status != "pass" || result.Publishers[1].Status != "fail") {
+				t.Errorf("Expected only the co-publisher to fail, got %+v", result.Publishers)
+			}
+		})
+	}
+}
@@ -0,0 +1,63 @@
+package verify
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/stonebraker/lap/sdks/go/pkg/lap/crypto"
+	"github.com/stonebraker/lap/sdks/go/pkg/lap/wire"
+)
+
+// DecryptFragment opens the sealed content of a subscriber-only fragment with the reader's private
+// key and returns the fragment with the plaintext as its canonical content. The RA hash commits to
+// the plaintext, so the result verifies like any other fragment.
+func DecryptFragment(fragment wire.Fragment, readerKey string) (wire.Fragment, error) {
+	var sealed crypto.SealedContent
+	if err := json.Unmarshal(fragment.EncryptedContent, &sealed); err != nil {
+		return fragment, fmt.Errorf("malformed encrypted content: %v", err)
+	}
+	if readerKey == "" {
+		return fragment, errors.New("encrypted content requires a reader key")
+	}
+
+	plaintext, err := crypto.OpenContent(sealed, readerKey)
+	if errors.Is(err, crypto.ErrNotRecipient) {
+		return fragment, errors.New("reader key is not a recipient of the encrypted content")
+	}
+	if err != nil {
+		return fragment, fmt.Errorf("content decryption failed: %w", err)
+	}
+
+	fragment.CanonicalContent = plaintext
+	if fragment.ContentType == "" || strings.HasPrefix(fragment.ContentType, wire.DefaultContentType) {
+		fragment.PreviewContent = string(plaintext)
+	}
+	return fragment, nil
+}
+
+// classifyDecryptionError categorizes errors opening encrypted content
+func classifyDecryptionError(err error) string {
+	errStr := err.Error()
+	if contains(errStr, "malformed encrypted content") {
+		return "malformed"
+	}
+	if contains(errStr, "encrypted content requires a reader key") {
+		return "reader_key_required"
+	}
+	if contains(errStr, "reader key is not a recipient") {
+		return "not_a_recipient"
+	}
+	return "decryption_failed"
+}
+
+// getDecryptionFailureDetails provides detailed failure information for encrypted content
+func getDecryptionFailureDetails(fragment wire.Fragment) map[string]interface{} {
+	var sealed crypto.SealedContent
+	_ = json.Unmarshal(fragment.EncryptedContent, &sealed)
+	return map[string]interface{}{
+		"algorithm":  sealed.Alg,
+		"recipients": len(sealed.Recipients),
+	}
+}
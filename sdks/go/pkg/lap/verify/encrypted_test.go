@@ -0,0 +1,122 @@
+package verify
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"testing"
+
+	"github.com/stonebraker/lap/sdks/go/pkg/lap/crypto"
+	"github.com/stonebraker/lap/sdks/go/pkg/lap/wire"
+)
+
+// encryptedFixture seals the signed fixture's content to a new reader and returns the fragment
+// without its plaintext, along with the reader's private key
+func encryptedFixture(t *testing.T) (wire.Fragment, wire.ResourceAttestation, wire.NamespaceAttestation, string) {
+	t.Helper()
+	fragment, ra, na := newSignedFixture(t, "")
+
+	reader, readerPub, err := crypto.GenerateKeyPair()
+	if err != nil {
+		t.Fatal(err)
+	}
+	sealed, err := crypto.SealContent(fragment.CanonicalContent, []string{readerPub})
+	if err != nil {
+		t.Fatal(err)
+	}
+	fragment.EncryptedContent, err = json.Marshal(sealed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fragment.CanonicalContent = nil
+	fragment.PreviewContent = ""
+	return fragment, ra, na, hex.EncodeToString(reader.Serialize())
+}
+
+func TestVerifyFragment_Encrypted(t *testing.T) {
+	fragment, ra, na, readerKey := encryptedFixture(t)
+
+	opts := DefaultOptions()
+	opts.ReaderKey = readerKey
+	result := VerifyFragmentWithOptions(fragment, ra, na, opts)
+	if !result.Verified {
+		t.Fatalf("Expected encrypted fragment to verify for a recipient, got %+v", result.Failure)
+	}
+	if !result.Context.Encrypted {
+		t.Error("Expected context to record the decryption")
+	}
+}
+
+func TestVerifyFragment_EncryptedFailures(t *testing.T) {
+	outsider, _, err := crypto.GenerateKeyPair()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name      string
+		readerKey func(string) string
+		mutate    func(*wire.Fragment)
+		reason    string
+	}{
+		{name: "no reader key", readerKey: func(string) string { return "" }, reason: "reader_key_required"},
+		{name: "not a recipient", readerKey: func(string) string { return hex.EncodeToString(outsider.Serialize()) }, reason: "not_a_recipient"},
+		{
+			name:      "tampered ciphertext",
+			readerKey: func(key string) string { return key },
+			mutate: func(f *wire.Fragment) {
+				var sealed crypto.SealedContent
+				json.Unmarshal(f.EncryptedContent, &sealed)
+				sealed.Ciphertext[0] ^= 1
+				f.EncryptedContent, _ = json.Marshal(sealed)
+			},
+			reason: "decryption_failed",
+		},
+		{
+			name:      "malformed",
+			readerKey: func(key string) string { return key },
+			mutate:    func(f *wire.Fragment) { f.EncryptedContent = []byte("not json") },
+			reason:    "malformed",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fragment, ra, na, readerKey := encryptedFixture(t)
+			if tt.mutate != nil {
+				tt.mutate(&fragment)
+			}
+			opts := DefaultOptions()
+			opts.ReaderKey = tt.readerKey(readerKey)
+
+			result := VerifyFragmentWithOptions(fragment, ra, na, opts)
+			if result.Verified {
+				t.Fatal("Expected verification to fail")
+			}
+			if result.Failure.Check != "resource_integrity" || result.Failure.Reason != tt.reason {
+				t.Errorf("Expected resource_integrity/%s, got %s/%s: %s", tt.reason, result.Failure.Check, result.Failure.Reason, result.Failure.Message)
+			}
+		})
+	}
+}
+
+func TestVerifyFragment_EncryptedWrongPlaintext(t *testing.T) {
+	fragment, ra, na, readerKey := encryptedFixture(t)
+
+	// Content sealed correctly but differing from what the RA attests fails as usual
+	reader, err := crypto.ParsePrivateKeyHex(readerKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sealed, err := crypto.SealContent([]byte("<p>Other</p>"), []string{hex.EncodeToString(reader.PubKey().SerializeCompressed()[1:])})
+	if err != nil {
+		t.Fatal(err)
+	}
+	fragment.EncryptedContent, _ = json.Marshal(sealed)
+
+	opts := DefaultOptions()
+	opts.ReaderKey = readerKey
+	result := VerifyFragmentWithOptions(fragment, ra, na, opts)
+	if result.Verified || result.Failure.Reason != "hash_mismatch" {
+		t.Fatalf("Expected hash_mismatch, got %+v", result.Failure)
+	}
+}
@@ -0,0 +1,105 @@
+package verify
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/stonebraker/lap/sdks/go/pkg/lap/crypto"
+	"github.com/stonebraker/lap/sdks/go/pkg/lap/wire"
+)
+
+// CheckExcerptInclusion is the check code of ExcerptInclusionCheck
+const CheckExcerptInclusion = "excerpt_inclusion"
+
+// VerifyExcerpt verifies a quoted excerpt against the attestations of the resource it was taken from.
+// Resource Presence and Publisher Association run as for a fragment. Resource Integrity is skipped,
+// since only one block of the content is present; instead the Excerpt Inclusion check proves the
+// block is an unmodified top-level block of the content committed to by the RA's block_root. It
+// takes the place of Resource Integrity in opts.Pipeline (or the default pipeline).
+func VerifyExcerpt(excerpt wire.Excerpt, resourceAttestation wire.ResourceAttestation, namespaceAttestation wire.NamespaceAttestation, opts Options) VerificationResult {
+	// An excerpt attributes the quote to the publisher alone; co-publishers are not checked
+	ra := resourceAttestation
+	ra.CoPublishers = nil
+	fragment := wire.Fragment{
+		Spec:                    wire.SpecV02,
+		FragmentURL:             excerpt.FragmentURL,
+		PublisherClaim:          excerpt.PublisherClaim,
+		ResourceAttestationURL:  excerpt.ResourceAttestationURL,
+		NamespaceAttestationURL: excerpt.NamespaceAttestationURL,
+	}
+	opts.Pipeline = replaceIntegrityCheck(opts.Pipeline, ExcerptInclusionCheck{Excerpt: excerpt})
+	return VerifyFragmentWithOptions(fragment, ra, namespaceAttestation, opts)
+}
+
+// ExcerptInclusionCheck replaces the built-in Resource Integrity check for an excerpt: the quoted
+// block and its proof must lead to the RA's block_root
+type ExcerptInclusionCheck struct {
+	Excerpt wire.Excerpt
+}
+
+func (ExcerptInclusionCheck) Name() string { return CheckExcerptInclusion }
+
+func (c ExcerptInclusionCheck) Run(in *CheckInput, result *VerificationResult) (string, *FailureDetails) {
+	if err := verifyExcerptInclusion(c.Excerpt, in.ResourceAttestation); err != nil {
+		return "fail", &FailureDetails{
+			Check:   CheckExcerptInclusion,
+			Reason:  classifyExcerptInclusionError(err),
+			Message: err.Error(),
+			Details: map[string]interface{}{
+				"block_root":     in.ResourceAttestation.BlockRoot,
+				"ra_block_count": in.ResourceAttestation.BlockCount,
+				"block_index":    c.Excerpt.BlockIndex,
+				"block_count":    c.Excerpt.BlockCount,
+			},
+		}
+	}
+	return "pass", nil
+}
+
+// verifyExcerptInclusion checks the excerpt's inclusion proof against the RA's block_root. The block
+// count comes from the RA: a Merkle proof alone does not fix the tree size, so block 2 of 3 would
+// otherwise also verify as block 1 of 2.
+func verifyExcerptInclusion(excerpt wire.Excerpt, ra wire.ResourceAttestation) error {
+	if ra.BlockRoot == "" {
+		return errors.New("no block commitment in resource attestation")
+	}
+	if ra.BlockCount <= 0 {
+		return errors.New("malformed block root: resource attestation has no block_count")
+	}
+	if excerpt.BlockCount != ra.BlockCount {
+		return fmt.Errorf("excerpt claims %d blocks but the resource attestation commits to %d", excerpt.BlockCount, ra.BlockCount)
+	}
+	algorithm, root, err := crypto.ParseContentHashField(ra.BlockRoot)
+	if errors.Is(err, crypto.ErrUnknownHashAlgorithm) || (err == nil && algorithm != crypto.MerkleHashAlgorithm) {
+		return fmt.Errorf("unsupported block root algorithm: %s", algorithm)
+	}
+	if err != nil {
+		return fmt.Errorf("malformed block root: %w", err)
+	}
+	proof, err := decodeProof(excerpt.Proof)
+	if err != nil {
+		return fmt.Errorf("malformed excerpt proof: %w", err)
+	}
+
+	var rootHash [32]byte
+	copy(rootHash[:], root)
+	if !crypto.VerifyMerkleInclusion(rootHash, excerpt.BlockIndex, excerpt.BlockCount, excerpt.Content, proof) {
+		return fmt.Errorf("excerpt is not block %d of %d committed to by the resource attestation", excerpt.BlockIndex, excerpt.BlockCount)
+	}
+	return nil
+}
+
+// classifyExcerptInclusionError categorizes excerpt inclusion errors
+func classifyExcerptInclusionError(err error) string {
+	errStr := err.Error()
+	if contains(errStr, "no block commitment") {
+		return "no_block_commitment"
+	}
+	if contains(errStr, "unsupported block root algorithm") {
+		return "unsupported_hash_algorithm"
+	}
+	if contains(errStr, "malformed") {
+		return "malformed"
+	}
+	return "excerpt_mismatch"
+}
@@ -0,0 +1,139 @@
+package verify
+
+import (
+	"encoding/hex"
+	"testing"
+	"time"
+
+	"github.com/stonebraker/lap/sdks/go/pkg/lap/canonical"
+	"github.com/stonebraker/lap/sdks/go/pkg/lap/crypto"
+	"github.com/stonebraker/lap/sdks/go/pkg/lap/wire"
+)
+
+// excerptFixture returns attestations for a post with a block commitment and an excerpt of one block
+func excerptFixture(t *testing.T, index int) (wire.Excerpt, wire.ResourceAttestation, wire.NamespaceAttestation) {
+	t.Helper()
+	return excerptFixtureOf(t, []byte("<h2>Title</h2>\n<p>First paragraph.</p>\n<p>Second paragraph.</p>\n<blockquote>Quote</blockquote>"), index)
+}
+
+// excerptFixtureOf is excerptFixture for the given content
+func excerptFixtureOf(t *testing.T, content []byte, index int) (wire.Excerpt, wire.ResourceAttestation, wire.NamespaceAttestation) {
+	t.Helper()
+	priv, pubKey, err := crypto.GenerateKeyPair()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	blocks := canonical.SplitBlocks(content)
+	leaves := make([][32]byte, len(blocks))
+	for i, block := range blocks {
+		leaves[i] = crypto.MerkleLeafHash(block)
+	}
+	proof, err := crypto.MerkleInclusionProof(leaves, index)
+	if err != nil {
+		t.Fatal(err)
+	}
+	hexProof := make([]string, len(proof))
+	for i, h := range proof {
+		hexProof[i] = hex.EncodeToString(h[:])
+	}
+
+	excerpt := wire.Excerpt{
+		FragmentURL:             "https://example.com/people/alice/frc/posts/123",
+		PublisherClaim:          pubKey,
+		ResourceAttestationURL:  "https://example.com/people/alice/frc/posts/123/_la_resource.json",
+		NamespaceAttestationURL: "https://example.com/people/alice/_la_namespace.json",
+		Content:                 blocks[index],
+		BlockIndex:              index,
+		BlockCount:              len(blocks),
+		Proof:                   hexProof,
+	}
+	ra := wire.ResourceAttestation{
+		FragmentURL:             excerpt.FragmentURL,
+		Hash:                    crypto.ComputeContentHashField(content),
+		BlockRoot:               crypto.MerkleRootField(blocks),
+		BlockCount:              len(blocks),
+		PublisherClaim:          pubKey,
+		NamespaceAttestationURL: excerpt.NamespaceAttestationURL,
+	}
+
+	payload := wire.NamespacePayload{Namespace: "https://example.com/people/alice/", Exp: time.Now().Add(time.Hour).Unix()}
+	payloadBytes, err := canonical.MarshalNamespacePayloadCanonical(payload.ToCanonical())
+	if err != nil {
+		t.Fatal(err)
+	}
+	sig, err := crypto.SignSchnorrHex(priv, crypto.HashSHA256(payloadBytes))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return excerpt, ra, wire.NamespaceAttestation{Payload: payload, Key: pubKey, Sig: sig}
+}
+
+func TestVerifyExcerpt_Success(t *testing.T) {
+	for index := 0; index < 4; index++ {
+		excerpt, ra, na := excerptFixture(t, index)
+		result := VerifyExcerpt(excerpt, ra, na, DefaultOptions())
+		if !result.Verified {
+			t.Fatalf("block %d: expected excerpt to verify, got %+v", index, result.Failure)
+		}
+		if result.ExcerptInclusion != "pass" || result.ResourceIntegrity != "skip" {
+			t.Errorf("block %d: expected inclusion pass and integrity skip, got %s/%s", index, result.ExcerptInclusion, result.ResourceIntegrity)
+		}
+	}
+}
+
+func TestVerifyExcerpt_Modified(t *testing.T) {
+	excerpt, ra, na := excerptFixture(t, 2)
+	excerpt.Content = []byte("<p>Second paragraph, edited.</p>")
+
+	result := VerifyExcerpt(excerpt, ra, na, DefaultOptions())
+	if result.Verified || result.Failure == nil || result.Failure.Reason != "excerpt_mismatch" {
+		t.Errorf("Expected failure reason 'excerpt_mismatch', got %+v", result.Failure)
+	}
+	if result.ExcerptInclusion != "fail" {
+		t.Errorf("Expected excerpt_inclusion 'fail', got '%s'", result.ExcerptInclusion)
+	}
+}
+
+func TestVerifyExcerpt_NoBlockCommitment(t *testing.T) {
+	excerpt, ra, na := excerptFixture(t, 1)
+	ra.BlockRoot = ""
+
+	result := VerifyExcerpt(excerpt, ra, na, DefaultOptions())
+	if result.Verified || result.Failure == nil || result.Failure.Reason != "no_block_commitment" {
+		t.Errorf("Expected failure reason 'no_block_commitment', got %+v", result.Failure)
+	}
+}
+
+func TestVerifyExcerpt_WrongPosition(t *testing.T) {
+	excerpt, ra, na := excerptFixture(t, 1)
+	excerpt.BlockIndex = 2
+
+	result := VerifyExcerpt(excerpt, ra, na, DefaultOptions())
+	if result.Verified || result.Failure == nil || result.Failure.Reason != "excerpt_mismatch" {
+		t.Errorf("Expected failure reason 'excerpt_mismatch', got %+v", result.Failure)
+	}
+}
+
+func TestVerifyExcerpt_WrongBlockCount(t *testing.T) {
+	// With three blocks, the proof of block 2 is also a valid proof of block 1 in a two-block tree
+	content := []byte("<p>One.</p>\n<p>Two.</p>\n<p>Three.</p>")
+	excerpt, ra, na := excerptFixtureOf(t, content, 2)
+	if result := VerifyExcerpt(excerpt, ra, na, DefaultOptions()); !result.Verified {
+		t.Fatalf("Expected the last block to verify, got %+v", result.Failure)
+	}
+
+	excerpt.BlockIndex, excerpt.BlockCount = 1, 2
+	result := VerifyExcerpt(excerpt, ra, na, DefaultOptions())
+	if result.Verified || result.Failure == nil || result.Failure.Reason != "excerpt_mismatch" {
+		t.Errorf("Expected a re-counted excerpt to fail with excerpt_mismatch, got %+v", result.Failure)
+	}
+
+	// An RA without a block count cannot pin the tree size
+	excerpt, ra, na = excerptFixtureOf(t, content, 2)
+	ra.BlockCount = 0
+	result = VerifyExcerpt(excerpt, ra, na, DefaultOptions())
+	if result.Verified || result.Failure == nil || result.Failure.Reason != "malformed" {
+		t.Errorf("Expected an RA without block_count to fail as malformed, got %+v", result.Failure)
+	}
+}
@@ -0,0 +1,59 @@
+package verify
+
+import (
+	"github.com/stonebraker/lap/sdks/go/pkg/lap/crypto"
+	"github.com/stonebraker/lap/sdks/go/pkg/lap/wire"
+)
+
+// FragmentBundle groups a fragment with the attestations fetched for it
+type FragmentBundle struct {
+	Fragment             wire.Fragment
+	ResourceAttestation  wire.ResourceAttestation
+	NamespaceAttestation wire.NamespaceAttestation
+
+	// CoPublisherAttestations maps each co-publisher's NA URL to its attestation, for co-authored resources
+	CoPublisherAttestations map[string]wire.NamespaceAttestation
+}
+
+// VerifyFragments verifies many fragments and returns one result per bundle, in order.
+// BIP-340 Namespace Attestation signatures are checked up front with crypto.VerifySchnorrParallel,
+// so bundles sharing an NA pay for a single signature verification and distinct ones are verified
+// in parallel. This is deduplication and a worker pool, not batch verification: each distinct
+// signature is still verified alone.
+func VerifyFragments(bundles []FragmentBundle, opts Options) []VerificationResult {
+	items := make([]crypto.SchnorrCheck, 0, len(bundles))
+	for _, b := range bundles {
+		nas := []wire.NamespaceAttestation{b.NamespaceAttestation}
+		for _, coNA := range b.CoPublisherAttestations {
+			nas = append(nas, coNA)
+		}
+		for _, na := range nas {
+			if crypto.NormalizeSignatureAlgorithm(na.Alg) != crypto.SignatureAlgorithmBIP340 {
+				continue
+			}
+			digest, err := namespacePayloadDigest(na)
+			if err != nil {
+				continue
+			}
+			items = append(items, schnorrCheck(na, digest))
+		}
+	}
+
+	signatureResults := crypto.VerifySchnorrParallel(items, crypto.DefaultKeyCache)
+	opts.signatureResults = make(map[crypto.SchnorrCheck]crypto.SchnorrCheckResult, len(items))
+	for i, item := range items {
+		opts.signatureResults[item] = signatureResults[i]
+	}
+
+	results := make([]VerificationResult, len(bundles))
+	for i, b := range bundles {
+		results[i] = VerifyFragmentWithCoPublishers(b.Fragment, b.ResourceAttestation, b.NamespaceAttestation, b.CoPublisherAttestations, opts)
+	}
+	return results
+}
+
+// schnorrCheck builds the lookup key for a Namespace Attestation signature. The key carries no
+// algorithm, so results are only looked up for bip340 attestations.
+func schnorrCheck(na wire.NamespaceAttestation, digest [32]byte) crypto.SchnorrCheck {
+	return crypto.SchnorrCheck{PubKeyHex: na.Key, SigHex: na.Sig, Digest: digest}
+}
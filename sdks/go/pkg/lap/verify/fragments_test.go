@@ -0,0 +1,65 @@
+package verify
+
+import (
+	"testing"
+
+	"github.com/stonebraker/lap/sdks/go/pkg/lap/crypto"
+)
+
+func TestVerifyFragments_MatchesSingle(t *testing.T) {
+	fragment, ra, na := newSignedFixture(t, "")
+	tamperedFragment, tamperedRA, tamperedNA := newSignedFixture(t, "")
+	tamperedNA.Sig = na.Sig // signature from a different key
+
+	bundles := []FragmentBundle{
+		{Fragment: fragment, ResourceAttestation: ra, NamespaceAttestation: na},
+		{Fragment: fragment, ResourceAttestation: ra, NamespaceAttestation: na},
+		{Fragment: tamperedFragment, ResourceAttestation: tamperedRA, NamespaceAttestation: tamperedNA},
+	}
+
+	results := VerifyFragments(bundles, DefaultOptions())
+	if len(results) != len(bundles) {
+		t.Fatalf("got %d results, want %d", len(results), len(bundles))
+	}
+
+	for i, b := range bundles {
+		single := VerifyFragmentWithOptions(b.Fragment, b.ResourceAttestation, b.NamespaceAttestation, DefaultOptions())
+		if results[i].Verified != single.Verified {
+			t.Errorf("bundle %d: batch verified=%v, single verified=%v", i, results[i].Verified, single.Verified)
+		}
+		if results[i].PublisherAssociation != single.PublisherAssociation {
+			t.Errorf("bundle %d: batch publisher_association=%s, single=%s", i, results[i].PublisherAssociation, single.PublisherAssociation)
+		}
+	}
+
+	if !results[0].Verified || !results[1].Verified {
+		t.Error("expected valid bundles to verify")
+	}
+	if results[2].Verified || results[2].PublisherAssociation != "fail" {
+		t.Errorf("expected tampered bundle to fail publisher association, got %+v", results[2])
+	}
+}
+
+func TestVerifyFragments_Empty(t *testing.T) {
+	if results := VerifyFragments(nil, DefaultOptions()); len(results) != 0 {
+		t.Errorf("expected no results, got %d", len(results))
+	}
+}
+
+func TestVerifyNamespaceSignature_PrecomputedOnlyForBIP340(t *testing.T) {
+	_, _, na := newSignedFixture(t, "")
+	na.Alg = crypto.SignatureAlgorithmEd25519
+	digest, err := namespacePayloadDigest(na)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// A precomputed BIP-340 result for the same key, signature and digest does not vouch for another alg
+	opts := DefaultOptions()
+	opts.signatureResults = map[crypto.SchnorrCheck]crypto.SchnorrCheckResult{
+		schnorrCheck(na, digest): {Valid: true},
+	}
+	if err := verifyNamespaceSignature(na, opts); err == nil {
+		t.Error("Expected an ed25519 attestation not to use a precomputed BIP-340 result")
+	}
+}
@@ -0,0 +1,65 @@
+package verify
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/stonebraker/lap/sdks/go/pkg/lap/wire"
+)
+
+// ResourceClockSkew is the tolerance applied to a Resource Attestation's iat, so an RA created just
+// now on a server with a slightly fast clock is not rejected
+const ResourceClockSkew = 5 * time.Minute
+
+// verifyResourceFreshness enforces the RA's optional time fields: it must not be issued in the
+// future, must not be past exp, and a copy that was not fetched live (offline or stapled) must be
+// no older than max_age seconds after iat
+func verifyResourceFreshness(ra wire.ResourceAttestation, opts Options) error {
+	now := opts.now().Unix()
+	if ra.IAT > now+int64(ResourceClockSkew/time.Second) {
+		return fmt.Errorf("resource attestation not yet valid: issued at %d", ra.IAT)
+	}
+	if ra.Exp > 0 && ra.Exp <= now {
+		return fmt.Errorf("resource attestation expired at %d", ra.Exp)
+	}
+	if ra.MaxAge > 0 && ra.IAT > 0 && (opts.Offline || opts.Stapled) && ra.IAT+ra.MaxAge <= now {
+		return fmt.Errorf("resource attestation expired: copy older than max_age %ds", ra.MaxAge)
+	}
+	return nil
+}
+
+// freshUntil returns when a verification of ra should be repeated with a freshly fetched RA: max_age
+// seconds after the RA was fetched (or, for copies, issued), and never after exp. Zero means the RA
+// gives no hint.
+func freshUntil(ra wire.ResourceAttestation, opts Options) int64 {
+	var until int64
+	if ra.MaxAge > 0 {
+		base := opts.now().Unix()
+		if (opts.Offline || opts.Stapled) && ra.IAT > 0 {
+			base = ra.IAT
+		}
+		until = base + ra.MaxAge
+	}
+	if ra.Exp > 0 && (until == 0 || ra.Exp < until) {
+		until = ra.Exp
+	}
+	return until
+}
+
+// getResourceFreshnessFailureDetails provides the RA's time fields and the evaluation time
+func getResourceFreshnessFailureDetails(ra wire.ResourceAttestation, opts Options) map[string]interface{} {
+	details := map[string]interface{}{
+		"fragment_url": ra.FragmentURL,
+		"current_time": opts.now().Unix(),
+	}
+	if ra.IAT > 0 {
+		details["issued_at"] = ra.IAT
+	}
+	if ra.Exp > 0 {
+		details["expires_at"] = ra.Exp
+	}
+	if ra.MaxAge > 0 {
+		details["max_age"] = ra.MaxAge
+	}
+	return details
+}
@@ -0,0 +1,64 @@
+package verify
+
+import (
+	"testing"
+	"time"
+)
+
+func TestVerifyFragment_ResourceFreshness(t *testing.T) {
+	now := time.Now().Unix()
+
+	tests := []struct {
+		name          string
+		iat, exp, age int64
+		stapled       bool
+		reason        string
+	}{
+		{name: "expired", iat: now - 7200, exp: now - 60, reason: "resource_expired"},
+		{name: "issued in the future", iat: now + 3600, reason: "resource_not_yet_valid"},
+		{name: "stapled copy older than max_age", iat: now - 600, age: 300, stapled: true, reason: "resource_expired"},
+		{name: "live fetch ignores copy age", iat: now - 600, age: 300},
+		{name: "stapled copy within window", iat: now - 60, exp: now + 3600, age: 300, stapled: true, reason: ReasonStapledOnly},
+		{name: "within window", iat: now - 60, exp: now + 3600, age: 300},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fragment, ra, na := newSignedFixture(t, "")
+			ra.IAT, ra.Exp, ra.MaxAge = tt.iat, tt.exp, tt.age
+
+			result := VerifyFragmentWithOptions(fragment, ra, na, Options{Stapled: tt.stapled})
+			if tt.reason == "" {
+				if !result.Verified {
+					t.Fatalf("Expected verification to pass, got %+v", result.Failure)
+				}
+				return
+			}
+			if result.Verified {
+				t.Fatal("Expected verification to fail")
+			}
+			if result.Failure.Check != "resource_presence" || result.Failure.Reason != tt.reason {
+				t.Errorf("Expected resource_presence/%s, got %s/%s", tt.reason, result.Failure.Check, result.Failure.Reason)
+			}
+		})
+	}
+}
+
+func TestVerifyFragment_FreshUntil(t *testing.T) {
+	fragment, ra, na := newSignedFixture(t, "")
+	at := time.Unix(1700000000, 0)
+	ra.IAT = at.Unix() - 60
+	ra.MaxAge = 300
+
+	// A live RA is fresh for max_age from now; a copy for max_age from iat; neither past exp
+	if result := VerifyFragmentWithOptions(fragment, ra, na, Options{At: at}); result.Context.FreshUntil != at.Unix()+300 {
+		t.Errorf("Expected live fresh_until %d, got %d", at.Unix()+300, result.Context.FreshUntil)
+	}
+	if result := VerifyFragmentWithOptions(fragment, ra, na, Options{At: at, Offline: true}); result.Context.FreshUntil != ra.IAT+300 {
+		t.Errorf("Expected offline fresh_until %d, got %d", ra.IAT+300, result.Context.FreshUntil)
+	}
+	ra.Exp = at.Unix() + 100
+	if result := VerifyFragmentWithOptions(fragment, ra, na, Options{At: at}); result.Context.FreshUntil != ra.Exp {
+		t.Errorf("Expected fresh_until capped at exp %d, got %d", ra.Exp, result.Context.FreshUntil)
+	}
+}
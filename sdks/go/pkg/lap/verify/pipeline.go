@@ -0,0 +1,285 @@
+package verify
+
+import (
+	"time"
+
+	"github.com/stonebraker/lap/sdks/go/pkg/lap/wire"
+)
+
+// Check codes of the built-in checks, as reported in FailureDetails.Check
+const (
+	CheckResourcePresence     = "resource_presence"
+	CheckResourceIntegrity    = "resource_integrity"
+	CheckPublisherAssociation = "publisher_association"
+)
+
+// CheckInput is what each Check in a Pipeline sees. Checks run in order on the same input, so a
+// check may replace the fragment for the ones after it, as Resource Integrity does when it
+// decrypts subscriber-only content.
+type CheckInput struct {
+	Fragment             wire.Fragment
+	ResourceAttestation  wire.ResourceAttestation
+	NamespaceAttestation wire.NamespaceAttestation
+
+	// CoAttestations maps each co-publisher's NA URL to its attestation, for co-authored resources
+	CoAttestations map[string]wire.NamespaceAttestation
+
+	Options Options
+}
+
+// Check is one step of verification. Name is the check code: its status is reported under it in
+// VerificationResult.Checks (or in the dedicated field of a built-in check), and it is the Check of
+// any failure it returns. Run returns the check's status, "pass" when empty, and a failure that
+// stops the pipeline, in which case the status defaults to "fail". Run may also record context on
+// result, such as VerificationResult.Context fields.
+type Check interface {
+	Name() string
+	Run(in *CheckInput, result *VerificationResult) (string, *FailureDetails)
+}
+
+// NewCheck returns a Check named name that runs fn
+func NewCheck(name string, fn func(in *CheckInput, result *VerificationResult) (string, *FailureDetails)) Check {
+	return funcCheck{name: name, fn: fn}
+}
+
+// funcCheck adapts a function to the Check interface
+type funcCheck struct {
+	name string
+	fn   func(in *CheckInput, result *VerificationResult) (string, *FailureDetails)
+}
+
+func (c funcCheck) Name() string { return c.name }
+
+func (c funcCheck) Run(in *CheckInput, result *VerificationResult) (string, *FailureDetails) {
+	return c.fn(in, result)
+}
+
+// Pipeline is an ordered list of checks. Verification stops at the first failing check, and the
+// checks after it are reported as "skip".
+type Pipeline []Check
+
+// DefaultPipeline returns the three v0.2 checks in order. Custom checks are added by inserting them
+// into the returned pipeline and passing it as Options.Pipeline.
+func DefaultPipeline() Pipeline {
+	return Pipeline{ResourcePresenceCheck{}, ResourceIntegrityCheck{}, PublisherAssociationCheck{}}
+}
+
+// replaceIntegrityCheck returns a copy of pipeline (or the default pipeline when nil) with check in
+// place of Resource Integrity, for verifications that only hold part of the content
+func replaceIntegrityCheck(pipeline Pipeline, check Check) Pipeline {
+	if pipeline == nil {
+		pipeline = DefaultPipeline()
+	}
+	replaced := make(Pipeline, len(pipeline))
+	for i, c := range pipeline {
+		if c.Name() == CheckResourceIntegrity {
+			c = check
+		}
+		replaced[i] = c
+	}
+	return replaced
+}
+
+// Verify runs the pipeline over a v0.2 fragment; the fragment verifies when every check passes
+func (p Pipeline) Verify(in CheckInput) VerificationResult {
+	// Only v0.2 fragments are verified here; see VerifyFragmentV01 for archived v0.1 fragments
+	if spec, ok := wire.NormalizeSpec(in.Fragment.Spec); !ok || spec != wire.SpecV02 {
+		return UnsupportedSpecResult(in.Fragment.FragmentURL, in.Fragment.Spec)
+	}
+
+	result := VerificationResult{
+		ResourcePresence:     "skip",
+		ResourceIntegrity:    "skip",
+		PublisherAssociation: "skip",
+		Context: &VerificationContext{
+			ResourceAttestationURL:  in.Fragment.ResourceAttestationURL,
+			NamespaceAttestationURL: in.Fragment.NamespaceAttestationURL,
+			VerifiedAt:              time.Now().Unix(),
+			Offline:                 in.Options.Offline,
+			Policy:                  in.Options.Policy,
+		},
+	}
+	for _, check := range p {
+		setCheckStatus(&result, check.Name(), "skip")
+	}
+
+	for _, check := range p {
+		if !runCheck(check, &in, &result) {
+			return result
+		}
+	}
+
+	// An RA that was never fetched from its origin is unsigned and cannot verify the resource
+	if in.Options.Stapled {
+		result.Failure = &FailureDetails{
+			Check:   CheckResourcePresence,
+			Reason:  ReasonStapledOnly,
+			Message: "stapled attestations were not confirmed against the live attestations",
+			Details: map[string]interface{}{
+				"resource_attestation_url": in.Fragment.ResourceAttestationURL,
+			},
+		}
+		return result
+	}
+
+	// All checks passed
+	result.Verified = true
+	result.ResourceAttestation = &in.ResourceAttestation
+	return result
+}
+
+// runCheck runs one check and records its status, and its failure if any. It reports whether
+// verification continues.
+func runCheck(check Check, in *CheckInput, result *VerificationResult) bool {
+	status, failure := check.Run(in, result)
+	if failure != nil {
+		if failure.Check == "" {
+			failure.Check = check.Name()
+		}
+		if status == "" {
+			status = "fail"
+		}
+		setCheckStatus(result, check.Name(), status)
+		result.Failure = failure
+		return false
+	}
+	if status == "" {
+		status = "pass"
+	}
+	setCheckStatus(result, check.Name(), status)
+	return true
+}
+
+// setCheckStatus records a check's status in its dedicated field, or in Checks for custom checks
+func setCheckStatus(result *VerificationResult, name, status string) {
+	switch name {
+	case CheckResourcePresence:
+		result.ResourcePresence = status
+	case CheckResourceIntegrity:
+		result.ResourceIntegrity = status
+	case CheckPublisherAssociation:
+		result.PublisherAssociation = status
+	case CheckExcerptInclusion:
+		result.ExcerptInclusion = status
+	default:
+		if result.Checks == nil {
+			result.Checks = map[string]string{}
+		}
+		result.Checks[name] = status
+	}
+}
+
+// ResourcePresenceCheck is the built-in Resource Presence check: the RA matches the fragment, is
+// served from the resource's origin and is within its validity period
+type ResourcePresenceCheck struct{}
+
+func (ResourcePresenceCheck) Name() string { return CheckResourcePresence }
+
+func (ResourcePresenceCheck) Run(in *CheckInput, result *VerificationResult) (string, *FailureDetails) {
+	ra, opts := in.ResourceAttestation, in.Options
+	if ra.Version > 0 || len(ra.PreviousHashes) > 0 {
+		result.Context.Version = currentVersion(ra)
+	}
+
+	if err := verifyResourcePresence(in.Fragment, ra); err != nil {
+		return "fail", &FailureDetails{
+			Check:   CheckResourcePresence,
+			Reason:  classifyResourcePresenceError(err),
+			Message: err.Error(),
+			Details: getResourcePresenceFailureDetails(err, in.Fragment, ra),
+		}
+	}
+	if err := verifyResourceFreshness(ra, opts); err != nil {
+		return "fail", &FailureDetails{
+			Check:   CheckResourcePresence,
+			Reason:  classifyResourcePresenceError(err),
+			Message: err.Error(),
+			Details: getResourceFreshnessFailureDetails(ra, opts),
+		}
+	}
+	result.Context.FreshUntil = freshUntil(ra, opts)
+
+	if opts.Offline {
+		return StatusSkipOffline, nil
+	} else if opts.Stapled {
+		return StatusSkipStapled, nil
+	}
+	return "pass", nil
+}
+
+// ResourceIntegrityCheck is the built-in Resource Integrity check: the content, decrypted first if
+// it is subscriber-only, hashes to the RA's hash. An authentic earlier version passes as stale
+// unless Options.RejectStale is set.
+type ResourceIntegrityCheck struct{}
+
+func (ResourceIntegrityCheck) Name() string { return CheckResourceIntegrity }
+
+func (ResourceIntegrityCheck) Run(in *CheckInput, result *VerificationResult) (string, *FailureDetails) {
+	ra, opts := in.ResourceAttestation, in.Options
+
+	// Subscriber-only content is decrypted first; the RA hash commits to the plaintext
+	if len(in.Fragment.EncryptedContent) > 0 {
+		decrypted, err := DecryptFragment(in.Fragment, opts.ReaderKey)
+		if err != nil {
+			return "fail", &FailureDetails{
+				Check:   CheckResourceIntegrity,
+				Reason:  classifyDecryptionError(err),
+				Message: err.Error(),
+				Details: getDecryptionFailureDetails(in.Fragment),
+			}
+		}
+		in.Fragment = decrypted
+		result.Context.Encrypted = true
+	}
+
+	err := verifyResourceIntegrity(in.Fragment, ra, opts)
+	if embedded, superseded := supersededVersion(err, in.Fragment, ra, opts); superseded && !opts.RejectStale {
+		result.Context.Stale = true
+		result.Context.EmbeddedVersion = embedded
+		return StatusPassStale, nil
+	} else if err != nil {
+		return "fail", &FailureDetails{
+			Check:   CheckResourceIntegrity,
+			Reason:  classifyResourceIntegrityError(err),
+			Message: err.Error(),
+			Details: getResourceIntegrityFailureDetails(err, in.Fragment, ra, opts),
+		}
+	}
+	return "pass", nil
+}
+
+// PublisherAssociationCheck is the built-in Publisher Association check, for the publisher and then
+// each co-publisher of a co-authored resource
+type PublisherAssociationCheck struct{}
+
+func (PublisherAssociationCheck) Name() string { return CheckPublisherAssociation }
+
+func (PublisherAssociationCheck) Run(in *CheckInput, result *VerificationResult) (string, *FailureDetails) {
+	ra, na, opts := in.ResourceAttestation, in.NamespaceAttestation, in.Options
+
+	var failure *FailureDetails
+	if err := verifyPublisherAssociation(in.Fragment, ra, na, opts); err != nil {
+		failure = &FailureDetails{
+			Check:   CheckPublisherAssociation,
+			Reason:  classifyPublisherAssociationError(err),
+			Message: err.Error(),
+			Details: getPublisherAssociationFailureDetails(err, in.Fragment, ra, na, opts),
+		}
+	}
+	if len(ra.CoPublishers) > 0 {
+		result.Publishers = verifyCoPublishers(ra, na, failure, in.CoAttestations, opts)
+		for _, publisher := range result.Publishers {
+			if publisher.Failure != nil && failure == nil {
+				failure = publisher.Failure
+			}
+		}
+	}
+	if failure != nil {
+		return "fail", failure
+	}
+	// A stapled or offline fragment can be replayed anywhere, so only a live resource names its publisher
+	if result.ResourcePresence == "pass" {
+		result.Context.PublisherClaim = ra.PublisherClaim
+	}
+	return "pass", nil
+}
@@ -0,0 +1,87 @@
+package verify
+
+import (
+	"fmt"
+	"testing"
+)
+
+// sizeLimitCheck fails fragments whose canonical content exceeds max bytes
+func sizeLimitCheck(max int) Check {
+	return NewCheck("content_size", func(in *CheckInput, _ *VerificationResult) (string, *FailureDetails) {
+		if n := len(in.Fragment.CanonicalContent); n > max {
+			return "", &FailureDetails{
+				Reason:  "too_large",
+				Message: fmt.Sprintf("content is %d bytes, limit is %d", n, max),
+			}
+		}
+		return "", nil
+	})
+}
+
+func TestPipeline_DefaultMatchesVerifyFragment(t *testing.T) {
+	fragment, ra, na := newSignedFixture(t, "")
+
+	result := VerifyFragmentWithOptions(fragment, ra, na, Options{Pipeline: DefaultPipeline()})
+	if !result.Verified {
+		t.Fatalf("Expected default pipeline to verify, got %+v", result.Failure)
+	}
+	if result.Checks != nil {
+		t.Errorf("Expected no custom check statuses, got %v", result.Checks)
+	}
+	if result.ResourceAttestation == nil || result.ResourceAttestation.Hash != ra.Hash {
+		t.Errorf("Expected the verified RA on the result, got %+v", result.ResourceAttestation)
+	}
+}
+
+func TestPipeline_CustomCheck(t *testing.T) {
+	fragment, ra, na := newSignedFixture(t, "")
+
+	result := VerifyFragmentWithOptions(fragment, ra, na, Options{Pipeline: append(DefaultPipeline(), sizeLimitCheck(1<<20))})
+	if !result.Verified {
+		t.Fatalf("Expected verification to pass, got %+v", result.Failure)
+	}
+	if result.Checks["content_size"] != "pass" {
+		t.Errorf("Expected content_size pass, got %q", result.Checks["content_size"])
+	}
+
+	result = VerifyFragmentWithOptions(fragment, ra, na, Options{Pipeline: append(DefaultPipeline(), sizeLimitCheck(1))})
+	if result.Verified {
+		t.Fatal("Expected the size limit to fail verification")
+	}
+	if result.ResourceAttestation != nil {
+		t.Error("Expected no RA on an unverified result")
+	}
+	if result.Checks["content_size"] != "fail" {
+		t.Errorf("Expected content_size fail, got %q", result.Checks["content_size"])
+	}
+	if result.Failure.Check != "content_size" || result.Failure.Reason != "too_large" {
+		t.Errorf("Expected content_size/too_large, got %s/%s", result.Failure.Check, result.Failure.Reason)
+	}
+	if result.PublisherAssociation != "pass" {
+		t.Errorf("Expected built-in checks before the failure to pass, got %s", result.PublisherAssociation)
+	}
+}
+
+func TestPipeline_FailureSkipsLaterChecks(t *testing.T) {
+	fragment, ra, na := newSignedFixture(t, "")
+
+	// A custom check placed first stops the built-in checks from running
+	pipeline := append(Pipeline{sizeLimitCheck(1)}, DefaultPipeline()...)
+	result := VerifyFragmentWithOptions(fragment, ra, na, Options{Pipeline: pipeline})
+	if result.Verified {
+		t.Fatal("Expected verification to fail")
+	}
+	if result.ResourcePresence != "skip" || result.ResourceIntegrity != "skip" || result.PublisherAssociation != "skip" {
+		t.Errorf("Expected built-in checks to be skipped, got %s/%s/%s", result.ResourcePresence, result.ResourceIntegrity, result.PublisherAssociation)
+	}
+
+	// A built-in failure skips the custom checks after it
+	ra.Hash = "sha256:" + fmt.Sprintf("%064x", 0)
+	result = VerifyFragmentWithOptions(fragment, ra, na, Options{Pipeline: append(DefaultPipeline(), sizeLimitCheck(1<<20))})
+	if result.Failure == nil || result.Failure.Check != CheckResourceIntegrity {
+		t.Fatalf("Expected resource_integrity failure, got %+v", result.Failure)
+	}
+	if result.Checks["content_size"] != "skip" {
+		t.Errorf("Expected content_size skip, got %q", result.Checks["content_size"])
+	}
+}
@@ -0,0 +1,202 @@
+package verify
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/stonebraker/lap/sdks/go/pkg/lap/crypto"
+)
+
+// CheckPolicy is the check code of policy violations
+const CheckPolicy = "policy"
+
+// Policy is a declarative verifier policy, loaded from a JSON file. The three checks prove a
+// fragment is authentic; a policy decides whether an authentic fragment is acceptable. Every rule
+// is optional and the zero value accepts everything. Durations are in seconds.
+type Policy struct {
+	RequireHTTPS          bool     `json:"require_https,omitempty"`           // Fragment and attestation URLs must use https
+	MaxNALifetime         int64    `json:"max_na_lifetime,omitempty"`         // NAs may not expire later than this from now
+	MinRemainingValidity  int64    `json:"min_remaining_validity,omitempty"`  // NAs must remain valid at least this long
+	AllowedOrigins        []string `json:"allowed_origins,omitempty"`         // Only resources on these origins are accepted
+	DeniedOrigins         []string `json:"denied_origins,omitempty"`          // Resources on these origins are refused
+	AllowedKeys           []string `json:"allowed_keys,omitempty"`            // Only these publisher keys are accepted
+	DeniedKeys            []string `json:"denied_keys,omitempty"`             // These publisher keys are refused
+	AllowedHashAlgorithms []string `json:"allowed_hash_algorithms,omitempty"` // Only RAs hashed with these algorithms are accepted
+}
+
+// LoadPolicy reads and validates the policy file at path
+func LoadPolicy(path string) (*Policy, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	policy, err := DecodePolicy(f)
+	if err != nil {
+		return nil, fmt.Errorf("policy %s: %w", path, err)
+	}
+	return policy, nil
+}
+
+// DecodePolicy strictly decodes and validates a policy; unknown rules are rejected rather than ignored
+func DecodePolicy(r io.Reader) (*Policy, error) {
+	dec := json.NewDecoder(r)
+	dec.DisallowUnknownFields()
+	var policy Policy
+	if err := dec.Decode(&policy); err != nil {
+		return nil, fmt.Errorf("invalid policy: %w", err)
+	}
+
+	if policy.MaxNALifetime < 0 || policy.MinRemainingValidity < 0 {
+		return nil, fmt.Errorf("invalid policy: durations must not be negative")
+	}
+	for _, origin := range append(append([]string{}, policy.AllowedOrigins...), policy.DeniedOrigins...) {
+		if originOf(origin) == "" {
+			return nil, fmt.Errorf("invalid policy: origin %q is not scheme://host[:port]", origin)
+		}
+	}
+	for _, key := range append(append([]string{}, policy.AllowedKeys...), policy.DeniedKeys...) {
+		if _, err := hex.DecodeString(key); err != nil || key == "" {
+			return nil, fmt.Errorf("invalid policy: key %q is not a hex public key", key)
+		}
+	}
+	for _, alg := range policy.AllowedHashAlgorithms {
+		if _, ok := crypto.LookupHashAlgorithm(alg); !ok {
+			return nil, fmt.Errorf("invalid policy: unknown hash algorithm %q", alg)
+		}
+	}
+	return &policy, nil
+}
+
+// Check returns the pipeline check enforcing the policy
+func (p Policy) Check() Check {
+	return NewCheck(CheckPolicy, func(in *CheckInput, _ *VerificationResult) (string, *FailureDetails) {
+		return "", p.evaluate(in)
+	})
+}
+
+// evaluate returns the first rule the verified fragment violates, or nil
+func (p Policy) evaluate(in *CheckInput) *FailureDetails {
+	fragment, ra, na := in.Fragment, in.ResourceAttestation, in.NamespaceAttestation
+
+	if p.RequireHTTPS {
+		urls := []string{fragment.FragmentURL, fragment.ResourceAttestationURL, fragment.NamespaceAttestationURL}
+		for _, coPublisher := range ra.CoPublishers {
+			urls = append(urls, coPublisher.NamespaceAttestationURL)
+		}
+		for _, u := range urls {
+			if parsed, err := url.Parse(u); err != nil || !strings.EqualFold(parsed.Scheme, "https") {
+				return policyFailure("insecure_scheme", fmt.Sprintf("policy requires https: %s", u), map[string]interface{}{
+					"rule": "require_https",
+					"url":  u,
+				})
+			}
+		}
+	}
+
+	now := in.Options.now().Unix()
+	if p.MaxNALifetime > 0 && na.Payload.Exp-now > p.MaxNALifetime {
+		return policyFailure("na_lifetime_exceeded", fmt.Sprintf("namespace attestation valid for %ds, policy allows at most %ds", na.Payload.Exp-now, p.MaxNALifetime), map[string]interface{}{
+			"rule":            "max_na_lifetime",
+			"max_na_lifetime": p.MaxNALifetime,
+			"expires_at":      na.Payload.Exp,
+			"current_time":    now,
+		})
+	}
+	if p.MinRemainingValidity > 0 && na.Payload.Exp-now < p.MinRemainingValidity {
+		return policyFailure("na_expiring", fmt.Sprintf("namespace attestation expires in %ds, policy requires at least %ds", na.Payload.Exp-now, p.MinRemainingValidity), map[string]interface{}{
+			"rule":                   "min_remaining_validity",
+			"min_remaining_validity": p.MinRemainingValidity,
+			"expires_at":             na.Payload.Exp,
+			"current_time":           now,
+		})
+	}
+
+	origin := originOf(fragment.FragmentURL)
+	if matchesOrigin(origin, p.DeniedOrigins) {
+		return policyFailure("origin_denied", fmt.Sprintf("origin %s is denied by policy", origin), map[string]interface{}{
+			"rule":   "denied_origins",
+			"origin": origin,
+		})
+	}
+	if len(p.AllowedOrigins) > 0 && !matchesOrigin(origin, p.AllowedOrigins) {
+		return policyFailure("origin_not_allowed", fmt.Sprintf("origin %s is not allowed by policy", origin), map[string]interface{}{
+			"rule":   "allowed_origins",
+			"origin": origin,
+		})
+	}
+
+	// Every author of a co-authored resource is subject to the key rules
+	keys := []string{na.Key}
+	for _, coPublisher := range ra.CoPublishers {
+		keys = append(keys, coPublisher.PublisherClaim)
+	}
+	for _, key := range keys {
+		if containsFold(p.DeniedKeys, key) {
+			return policyFailure("key_denied", fmt.Sprintf("publisher key %s is denied by policy", key), map[string]interface{}{
+				"rule": "denied_keys",
+				"key":  key,
+			})
+		}
+		if len(p.AllowedKeys) > 0 && !containsFold(p.AllowedKeys, key) {
+			return policyFailure("key_not_allowed", fmt.Sprintf("publisher key %s is not allowed by policy", key), map[string]interface{}{
+				"rule": "allowed_keys",
+				"key":  key,
+			})
+		}
+	}
+
+	algorithm, _, _ := crypto.ParseContentHashField(ra.Hash)
+	if !isHashAlgorithmAllowed(algorithm, p.AllowedHashAlgorithms) {
+		return policyFailure("hash_algorithm_not_allowed", fmt.Sprintf("hash algorithm %s is not allowed by policy", algorithm), map[string]interface{}{
+			"rule":      "allowed_hash_algorithms",
+			"algorithm": algorithm,
+			"allowed":   p.AllowedHashAlgorithms,
+		})
+	}
+	return nil
+}
+
+// policyFailure builds the failure for a violated policy rule
+func policyFailure(reason, message string, details map[string]interface{}) *FailureDetails {
+	return &FailureDetails{
+		Check:   CheckPolicy,
+		Reason:  reason,
+		Message: message,
+		Details: details,
+	}
+}
+
+// originOf returns the lower-cased scheme://host[:port] of u, or "" if it has none
+func originOf(u string) string {
+	parsed, err := url.Parse(u)
+	if err != nil || parsed.Scheme == "" || parsed.Host == "" {
+		return ""
+	}
+	return strings.ToLower(parsed.Scheme + "://" + parsed.Host)
+}
+
+// matchesOrigin reports whether origin is one of origins
+func matchesOrigin(origin string, origins []string) bool {
+	for _, o := range origins {
+		if originOf(o) == origin {
+			return true
+		}
+	}
+	return false
+}
+
+// containsFold reports whether s is in list, ignoring case
+func containsFold(list []string, s string) bool {
+	for _, item := range list {
+		if strings.EqualFold(item, s) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,113 @@
+package verify
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestVerifyFragment_Policy(t *testing.T) {
+	fragment, ra, na := newSignedFixture(t, "")
+
+	tests := []struct {
+		name   string
+		policy Policy
+		reason string
+	}{
+		{name: "empty policy", policy: Policy{}},
+		{
+			name: "all rules satisfied",
+			policy: Policy{
+				RequireHTTPS:          true,
+				MaxNALifetime:         2 * 3600,
+				MinRemainingValidity:  60,
+				AllowedOrigins:        []string{"https://EXAMPLE.com/"},
+				AllowedKeys:           []string{na.Key},
+				AllowedHashAlgorithms: []string{"sha256"},
+			},
+		},
+		{name: "na lifetime too long", policy: Policy{MaxNALifetime: 600}, reason: "na_lifetime_exceeded"},
+		{name: "na expiring", policy: Policy{MinRemainingValidity: 2 * 3600}, reason: "na_expiring"},
+		{name: "origin denied", policy: Policy{DeniedOrigins: []string{"https://example.com"}}, reason: "origin_denied"},
+		{name: "origin not allowed", policy: Policy{AllowedOrigins: []string{"https://example.org"}}, reason: "origin_not_allowed"},
+		{name: "key denied", policy: Policy{DeniedKeys: []string{strings.ToUpper(na.Key)}}, reason: "key_denied"},
+		{name: "key not allowed", policy: Policy{AllowedKeys: []string{strings.Repeat("ab", 32)}}, reason: "key_not_allowed"},
+		{name: "hash algorithm", policy: Policy{AllowedHashAlgorithms: []string{"sha512"}}, reason: "hash_algorithm_not_allowed"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			policy := tt.policy
+			result := VerifyFragmentWithOptions(fragment, ra, na, Options{Policy: &policy})
+			if result.Context.Policy != &policy {
+				t.Errorf("Expected the policy in effect to be echoed in the context")
+			}
+			if tt.reason == "" {
+				if !result.Verified {
+					t.Fatalf("Expected verification to pass, got %+v", result.Failure)
+				}
+				if result.Checks[CheckPolicy] != "pass" {
+					t.Errorf("Expected policy pass, got %q", result.Checks[CheckPolicy])
+				}
+				return
+			}
+			if result.Verified {
+				t.Fatal("Expected verification to fail")
+			}
+			if result.Failure.Check != CheckPolicy || result.Failure.Reason != tt.reason {
+				t.Errorf("Expected policy/%s, got %s/%s", tt.reason, result.Failure.Check, result.Failure.Reason)
+			}
+			if result.PublisherAssociation != "pass" {
+				t.Errorf("Expected the three checks to pass before the policy, got %s", result.PublisherAssociation)
+			}
+		})
+	}
+}
+
+func TestPolicy_RequireHTTPS(t *testing.T) {
+	fragment, ra, na := newSignedFixture(t, "")
+	fragment.NamespaceAttestationURL = "http://example.com/people/alice/_la_namespace.json"
+
+	failure := Policy{RequireHTTPS: true}.evaluate(&CheckInput{Fragment: fragment, ResourceAttestation: ra, NamespaceAttestation: na})
+	if failure == nil || failure.Reason != "insecure_scheme" {
+		t.Fatalf("Expected insecure_scheme, got %+v", failure)
+	}
+	if failure.Details["url"] != fragment.NamespaceAttestationURL {
+		t.Errorf("Expected the insecure URL in details, got %v", failure.Details["url"])
+	}
+}
+
+func TestPolicy_EchoedWhenEarlierCheckFails(t *testing.T) {
+	fragment, ra, na := newSignedFixture(t, "")
+	ra.PublisherClaim = strings.Repeat("ab", 32)
+
+	policy := &Policy{RequireHTTPS: true}
+	result := VerifyFragmentWithOptions(fragment, ra, na, Options{Policy: policy})
+	if result.Failure == nil || result.Failure.Check != CheckResourcePresence {
+		t.Fatalf("Expected resource_presence failure, got %+v", result.Failure)
+	}
+	if result.Checks[CheckPolicy] != "skip" || result.Context.Policy != policy {
+		t.Errorf("Expected skipped policy echoed in context, got %q and %v", result.Checks[CheckPolicy], result.Context.Policy)
+	}
+}
+
+func TestDecodePolicy(t *testing.T) {
+	policy, err := DecodePolicy(strings.NewReader(`{"require_https": true, "max_na_lifetime": 31536000, "allowed_hash_algorithms": ["sha256"]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !policy.RequireHTTPS || policy.MaxNALifetime != 31536000 {
+		t.Errorf("Unexpected policy %+v", policy)
+	}
+
+	for _, bad := range []string{
+		`{"require_http": true}`,
+		`{"allowed_origins": ["example.com"]}`,
+		`{"denied_keys": ["not-hex"]}`,
+		`{"allowed_hash_algorithms": ["md5"]}`,
+		`{"min_remaining_validity": -1}`,
+	} {
+		if _, err := DecodePolicy(strings.NewReader(bad)); err == nil {
+			t.Errorf("Expected %s to be rejected", bad)
+		}
+	}
+}
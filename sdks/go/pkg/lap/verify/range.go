@@ -0,0 +1,141 @@
+package verify
+
+import (
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	"github.com/stonebraker/lap/sdks/go/pkg/lap/canonical"
+	"github.com/stonebraker/lap/sdks/go/pkg/lap/crypto"
+	"github.com/stonebraker/lap/sdks/go/pkg/lap/wire"
+)
+
+// VerifyContentRange checks that data is the content at offset attested by ra, using the inclusion
+// proofs of the chunks it covers. The RA hash must be a Merkle root over the raw content and the RA
+// size the length the proofs are for; the range must start on a chunk boundary and end on one or at
+// the end of the content.
+func VerifyContentRange(ra wire.ResourceAttestation, proofs wire.MerkleProofs, offset int64, data []byte) error {
+	algorithm, root, err := crypto.ParseContentHashField(ra.Hash)
+	if err != nil {
+		return err
+	}
+	if algorithm != crypto.MerkleHashAlgorithm {
+		return fmt.Errorf("range verification requires a %s hash, got %s", crypto.MerkleHashAlgorithm, algorithm)
+	}
+	if canonical.NormalizeProfile(ra.Canonicalization) != canonical.ProfileRaw {
+		return fmt.Errorf("range verification requires the %s canonicalization profile", canonical.ProfileRaw)
+	}
+	if proofs.Hash != ra.Hash {
+		return fmt.Errorf("proofs are for %s, not %s", proofs.Hash, ra.Hash)
+	}
+	if proofs.Size != ra.Size {
+		return fmt.Errorf("proofs are for %d bytes, the attestation for %d", proofs.Size, ra.Size)
+	}
+	if proofs.ChunkSize != crypto.MerkleChunkSize {
+		return fmt.Errorf("unsupported chunk size %d", proofs.ChunkSize)
+	}
+	count := crypto.MerkleChunkCount(proofs.Size)
+	if len(proofs.Proofs) != count {
+		return fmt.Errorf("proofs cover %d chunks, content has %d", len(proofs.Proofs), count)
+	}
+
+	end := offset + int64(len(data))
+	if offset < 0 || offset%proofs.ChunkSize != 0 || end > proofs.Size {
+		return fmt.Errorf("range [%d, %d) is not chunk aligned within %d bytes", offset, end, proofs.Size)
+	}
+	if end%proofs.ChunkSize != 0 && end != proofs.Size {
+		return fmt.Errorf("range [%d, %d) must end on a chunk boundary or at the end of the content", offset, end)
+	}
+
+	if len(data) == 0 && proofs.Size != 0 {
+		return fmt.Errorf("empty range")
+	}
+
+	var rootHash [32]byte
+	copy(rootHash[:], root)
+	index := int(offset / proofs.ChunkSize)
+	for {
+		n := min(int(proofs.ChunkSize), len(data))
+		proof, err := decodeProof(proofs.Proofs[index])
+		if err != nil {
+			return fmt.Errorf("chunk %d: %w", index, err)
+		}
+		if !crypto.VerifyMerkleInclusion(rootHash, index, count, data[:n], proof) {
+			return fmt.Errorf("chunk %d does not match the attested hash", index)
+		}
+		data = data[n:]
+		index++
+		if len(data) == 0 {
+			return nil
+		}
+	}
+}
+
+// RangeIntegrityCheck replaces the built-in Resource Integrity check when only a byte range of the
+// content is at hand: Data is the content at Offset, checked with VerifyContentRange
+type RangeIntegrityCheck struct {
+	Proofs wire.MerkleProofs
+	Offset int64
+	Data   []byte
+}
+
+func (RangeIntegrityCheck) Name() string { return CheckResourceIntegrity }
+
+func (c RangeIntegrityCheck) Run(in *CheckInput, result *VerificationResult) (string, *FailureDetails) {
+	err := VerifyContentRange(in.ResourceAttestation, c.Proofs, c.Offset, c.Data)
+	if err == nil && !isHashAlgorithmAllowed(crypto.MerkleHashAlgorithm, in.Options.AllowedHashAlgorithms) {
+		err = fmt.Errorf("hash algorithm not allowed by policy: %s", crypto.MerkleHashAlgorithm)
+	}
+	if err != nil {
+		return "fail", &FailureDetails{
+			Check:   CheckResourceIntegrity,
+			Reason:  classifyRangeError(err),
+			Message: err.Error(),
+			Details: map[string]interface{}{
+				"offset":        c.Offset,
+				"length":        len(c.Data),
+				"size":          c.Proofs.Size,
+				"expected_hash": in.ResourceAttestation.Hash,
+			},
+		}
+	}
+	return "pass", nil
+}
+
+// classifyRangeError maps range verification errors to failure reasons
+func classifyRangeError(err error) string {
+	errStr := err.Error()
+	if strings.HasSuffix(errStr, "does not match the attested hash") {
+		return "hash_mismatch"
+	}
+	if contains(errStr, "range verification requires a") {
+		return "unsupported_hash_algorithm"
+	}
+	if contains(errStr, "hash algorithm not allowed by policy") {
+		return "hash_algorithm_not_allowed"
+	}
+	if contains(errStr, "range verification requires the") {
+		return "unsupported_canonicalization"
+	}
+	return "range_mismatch"
+}
+
+// VerifyRange verifies a byte range of a resource that is its own content, such as a video: Resource
+// Presence and Publisher Association run on the bundle as for the whole resource, and
+// RangeIntegrityCheck takes the place of Resource Integrity in opts.Pipeline (or the default pipeline).
+func VerifyRange(bundle FragmentBundle, proofs wire.MerkleProofs, offset int64, data []byte, opts Options) VerificationResult {
+	opts.Pipeline = replaceIntegrityCheck(opts.Pipeline, RangeIntegrityCheck{Proofs: proofs, Offset: offset, Data: data})
+	return VerifyFragmentWithCoPublishers(bundle.Fragment, bundle.ResourceAttestation, bundle.NamespaceAttestation, bundle.CoPublisherAttestations, opts)
+}
+
+func decodeProof(hexHashes []string) ([][32]byte, error) {
+	proof := make([][32]byte, len(hexHashes))
+	for i, h := range hexHashes {
+		b, err := hex.DecodeString(h)
+		if err != nil || len(b) != 32 {
+			return nil, fmt.Errorf("malformed proof hash %q", h)
+		}
+		copy(proof[i][:], b)
+	}
+	return proof, nil
+}
@@ -0,0 +1,128 @@
+package verify
+
+import (
+	"encoding/hex"
+	"strings"
+	"testing"
+
+	"github.com/stonebraker/lap/sdks/go/pkg/lap/crypto"
+	"github.com/stonebraker/lap/sdks/go/pkg/lap/wire"
+)
+
+// merkleFixture returns content spanning several chunks with its RA and proof sidecar
+func merkleFixture(t *testing.T) ([]byte, wire.ResourceAttestation, wire.MerkleProofs) {
+	t.Helper()
+	content := []byte(strings.Repeat("0123456789abcdef", 3*crypto.MerkleChunkSize/16+100))
+	// Chunks of repeated text are identical, so vary each one
+	for i := 0; i < len(content); i += crypto.MerkleChunkSize {
+		content[i] = byte('A' + i/crypto.MerkleChunkSize)
+	}
+	hash, err := crypto.ComputeContentHashFieldWithAlgorithm(crypto.MerkleHashAlgorithm, content)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	leaves := crypto.MerkleLeafHashes(content)
+	proofs := wire.MerkleProofs{Hash: hash, ChunkSize: crypto.MerkleChunkSize, Size: int64(len(content))}
+	for i := range leaves {
+		proof, err := crypto.MerkleInclusionProof(leaves, i)
+		if err != nil {
+			t.Fatal(err)
+		}
+		hexProof := make([]string, len(proof))
+		for j, h := range proof {
+			hexProof[j] = hex.EncodeToString(h[:])
+		}
+		proofs.Proofs = append(proofs.Proofs, hexProof)
+	}
+	return content, wire.ResourceAttestation{Hash: hash, Size: int64(len(content))}, proofs
+}
+
+func TestVerifyContentRange(t *testing.T) {
+	content, ra, proofs := merkleFixture(t)
+	chunk := int64(crypto.MerkleChunkSize)
+
+	// Whole-chunk ranges, including the short last chunk
+	for _, r := range [][2]int64{{0, chunk}, {chunk, 3 * chunk}, {3 * chunk, int64(len(content))}, {0, int64(len(content))}} {
+		if err := VerifyContentRange(ra, proofs, r[0], content[r[0]:r[1]]); err != nil {
+			t.Errorf("range %v: %v", r, err)
+		}
+	}
+
+	tampered := append([]byte{}, content[chunk:2*chunk]...)
+	tampered[10] ^= 1
+	if err := VerifyContentRange(ra, proofs, chunk, tampered); err == nil {
+		t.Error("expected tampered chunk to be rejected")
+	}
+	if err := VerifyContentRange(ra, proofs, 2*chunk, content[chunk:2*chunk]); err == nil {
+		t.Error("expected chunk at the wrong offset to be rejected")
+	}
+	if err := VerifyContentRange(ra, proofs, 10, content[10:chunk]); err == nil {
+		t.Error("expected unaligned range to be rejected")
+	}
+}
+
+func TestVerifyContentRange_SizeBoundToAttestation(t *testing.T) {
+	content, ra, proofs := merkleFixture(t)
+
+	// Proofs claiming the content ends after the first chunk are rejected before any chunk is checked
+	ra.Size = crypto.MerkleChunkSize
+	if err := VerifyContentRange(ra, proofs, 0, content[:crypto.MerkleChunkSize]); err == nil {
+		t.Error("expected proofs for another size to be rejected")
+	}
+	ra.Size = 0
+	if err := VerifyContentRange(ra, proofs, 0, content[:crypto.MerkleChunkSize]); err == nil {
+		t.Error("expected an RA without size to be rejected for range verification")
+	}
+}
+
+func TestVerifyRange(t *testing.T) {
+	fragment, ra, na := newSignedFixture(t, "")
+	content, merkleRA, proofs := merkleFixture(t)
+	ra.Hash, ra.Size = merkleRA.Hash, merkleRA.Size
+	fragment.CanonicalContent = nil
+	chunk := content[:crypto.MerkleChunkSize]
+
+	result := VerifyRange(FragmentBundle{Fragment: fragment, ResourceAttestation: ra, NamespaceAttestation: na}, proofs, 0, chunk, DefaultOptions())
+	if !result.Verified || result.ResourcePresence != "pass" || result.PublisherAssociation != "pass" {
+		t.Fatalf("Expected the range to verify, got %+v", result.Failure)
+	}
+
+	// Presence runs first: an RA for another resource does not cover these bytes
+	other := fragment
+	other.FragmentURL = "https://example.com/people/alice/frc/posts/456"
+	result = VerifyRange(FragmentBundle{Fragment: other, ResourceAttestation: ra, NamespaceAttestation: na}, proofs, 0, chunk, DefaultOptions())
+	if result.Verified || result.Failure == nil || result.Failure.Reason != "fragment_url_mismatch" {
+		t.Errorf("Expected fragment_url_mismatch, got %+v", result.Failure)
+	}
+
+	// The NA must associate the publisher
+	na.Sig = strings.Repeat("00", 64)
+	result = VerifyRange(FragmentBundle{Fragment: fragment, ResourceAttestation: ra, NamespaceAttestation: na}, proofs, 0, chunk, DefaultOptions())
+	if result.Verified || result.PublisherAssociation != "fail" {
+		t.Errorf("Expected publisher association to fail, got %+v", result)
+	}
+
+	tampered := append([]byte{}, chunk...)
+	tampered[0] ^= 1
+	result = VerifyRange(FragmentBundle{Fragment: fragment, ResourceAttestation: ra, NamespaceAttestation: na}, proofs, 0, tampered, DefaultOptions())
+	if result.Verified || result.Failure == nil || result.Failure.Reason != "hash_mismatch" {
+		t.Errorf("Expected hash_mismatch, got %+v", result.Failure)
+	}
+}
+
+func TestVerifyContentRange_RequiresMerkleHash(t *testing.T) {
+	content, _, proofs := merkleFixture(t)
+	ra := wire.ResourceAttestation{Hash: crypto.ComputeContentHashField(content)}
+	if err := VerifyContentRange(ra, proofs, 0, content[:crypto.MerkleChunkSize]); err == nil {
+		t.Error("expected a sha256 RA to be rejected for range verification")
+	}
+}
+
+func TestVerifyFragment_MerkleHash(t *testing.T) {
+	content, ra, _ := merkleFixture(t)
+	fragment := wire.Fragment{CanonicalContent: content}
+	if err := verifyResourceIntegrity(fragment, ra, Options{}); err != nil {
+		t.Errorf("expected full content to match its Merkle root, got %v", err)
+	}
+}
@@ -0,0 +1,49 @@
+package verify
+
+import (
+	"fmt"
+
+	"github.com/stonebraker/lap/sdks/go/pkg/lap/canonical"
+	"github.com/stonebraker/lap/sdks/go/pkg/lap/crypto"
+	"github.com/stonebraker/lap/sdks/go/pkg/lap/wire"
+)
+
+// VerifyKeyRotation checks that the rotations in index lead namespace from a key the reader trusted
+// earlier to its current key. Each step must be signed by the key it replaces, so the chain cannot be
+// extended by anyone who does not hold the trusted key or one of its successors.
+func VerifyKeyRotation(index wire.KeyRotationIndex, namespace, trustedAlg, trustedKey, currentAlg, currentKey string) error {
+	alg, key := crypto.NormalizeSignatureAlgorithm(trustedAlg), trustedKey
+	used := make([]bool, len(index.Rotations))
+	for {
+		if key == currentKey && alg == crypto.NormalizeSignatureAlgorithm(currentAlg) {
+			return nil
+		}
+		next := -1
+		for i, rotation := range index.Rotations {
+			if !used[i] && rotation.Key == key && crypto.NormalizeSignatureAlgorithm(rotation.Alg) == alg &&
+				NormalizeURL(rotation.Payload.Namespace) == NormalizeURL(namespace) && verifyRotationSignature(rotation) == nil {
+				next = i
+				break
+			}
+		}
+		if next < 0 {
+			return fmt.Errorf("no valid key rotation from %s for %s", key, namespace)
+		}
+		used[next] = true
+		alg = crypto.NormalizeSignatureAlgorithm(index.Rotations[next].Payload.NewAlg)
+		key = index.Rotations[next].Payload.NewKey
+	}
+}
+
+// verifyRotationSignature checks a rotation's signature by the key it replaces
+func verifyRotationSignature(rotation wire.KeyRotation) error {
+	payloadBytes, err := canonical.MarshalKeyRotationPayloadCanonical(rotation.SignedPayload())
+	if err != nil {
+		return fmt.Errorf("failed to marshal canonical payload: %w", err)
+	}
+	ok, err := crypto.VerifySignatureHex(rotation.Alg, rotation.Key, rotation.Sig, crypto.HashSHA256(payloadBytes))
+	if err != nil || !ok {
+		return fmt.Errorf("key rotation signature invalid")
+	}
+	return nil
+}
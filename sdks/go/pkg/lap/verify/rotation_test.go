@@ -0,0 +1,73 @@
+package verify
+
+import (
+	"testing"
+
+	"github.com/stonebraker/lap/sdks/go/pkg/lap/canonical"
+	"github.com/stonebraker/lap/sdks/go/pkg/lap/crypto"
+	"github.com/stonebraker/lap/sdks/go/pkg/lap/wire"
+)
+
+// signRotation has from announce that namespace moved to the key of to
+func signRotation(t *testing.T, from, to crypto.Signer, namespace string) wire.KeyRotation {
+	t.Helper()
+	rotation := wire.KeyRotation{
+		Payload: wire.KeyRotationPayload{Namespace: namespace, NewKey: to.PublicKeyHex(), Iat: 1700000000},
+		Key:     from.PublicKeyHex(),
+	}
+	if to.Algorithm() != crypto.SignatureAlgorithmBIP340 {
+		rotation.Payload.NewAlg = to.Algorithm()
+	}
+	if from.Algorithm() != crypto.SignatureAlgorithmBIP340 {
+		rotation.Alg = from.Algorithm()
+	}
+	payloadBytes, err := canonical.MarshalKeyRotationPayloadCanonical(rotation.SignedPayload())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rotation.Sig, err = from.SignHex(crypto.HashSHA256(payloadBytes)); err != nil {
+		t.Fatal(err)
+	}
+	return rotation
+}
+
+func TestVerifyKeyRotation(t *testing.T) {
+	const namespace = "https://example.com/people/alice/"
+	signers := make([]crypto.Signer, 3)
+	for i, alg := range []string{crypto.SignatureAlgorithmBIP340, crypto.SignatureAlgorithmBIP340, crypto.SignatureAlgorithmEd25519} {
+		signer, err := crypto.GenerateSigner(alg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		signers[i] = signer
+	}
+	a, b, c := signers[0], signers[1], signers[2]
+
+	chain := wire.KeyRotationIndex{Rotations: []wire.KeyRotation{
+		signRotation(t, a, b, namespace),
+		signRotation(t, b, c, namespace),
+	}}
+	if err := VerifyKeyRotation(chain, namespace, "", a.PublicKeyHex(), c.Algorithm(), c.PublicKeyHex()); err != nil {
+		t.Errorf("Expected the chain a -> b -> c to be accepted: %v", err)
+	}
+	if err := VerifyKeyRotation(chain, namespace, "", b.PublicKeyHex(), c.Algorithm(), c.PublicKeyHex()); err != nil {
+		t.Errorf("Expected a reader who trusted b to follow the last step: %v", err)
+	}
+	if err := VerifyKeyRotation(chain, namespace, "", a.PublicKeyHex(), "", b.PublicKeyHex()); err != nil {
+		t.Errorf("Expected a rotation to an intermediate key to be accepted: %v", err)
+	}
+
+	// A rotation signed by anyone but the trusted key is ignored
+	mallory, _ := crypto.GenerateSigner(crypto.SignatureAlgorithmBIP340)
+	forged := signRotation(t, mallory, c, namespace)
+	forged.Key = a.PublicKeyHex()
+	if err := VerifyKeyRotation(wire.KeyRotationIndex{Rotations: []wire.KeyRotation{forged}}, namespace, "", a.PublicKeyHex(), c.Algorithm(), c.PublicKeyHex()); err == nil {
+		t.Error("Expected a rotation not signed by the trusted key to be rejected")
+	}
+
+	// So is a rotation for another namespace
+	other := wire.KeyRotationIndex{Rotations: []wire.KeyRotation{signRotation(t, a, b, "https://example.com/people/bob/")}}
+	if err := VerifyKeyRotation(other, namespace, "", a.PublicKeyHex(), "", b.PublicKeyHex()); err == nil {
+		t.Error("Expected a rotation for another namespace to be rejected")
+	}
+}
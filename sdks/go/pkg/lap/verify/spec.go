@@ -0,0 +1,31 @@
+package verify
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/stonebraker/lap/sdks/go/pkg/lap/wire"
+)
+
+// UnsupportedSpecResult is the result for a fragment declaring a protocol version no verifier here
+// implements. Nothing else about the fragment is checked, since its structure is unknown.
+func UnsupportedSpecResult(fragmentURL, spec string) VerificationResult {
+	return VerificationResult{
+		ResourcePresence:     "fail",
+		ResourceIntegrity:    "skip",
+		PublisherAssociation: "skip",
+		Failure: &FailureDetails{
+			Check:   "resource_presence",
+			Reason:  "unsupported_spec",
+			Message: fmt.Sprintf("unsupported spec: %s", spec),
+			Details: map[string]interface{}{
+				"url":       fragmentURL,
+				"spec":      spec,
+				"supported": wire.SupportedSpecs(),
+			},
+		},
+		Context: &VerificationContext{
+			VerifiedAt: time.Now().Unix(),
+		},
+	}
+}
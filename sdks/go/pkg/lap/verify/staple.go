@@ -0,0 +1,148 @@
+package verify
+
+import (
+	"bytes"
+	"fmt"
+	"time"
+
+	"github.com/stonebraker/lap/sdks/go/pkg/lap/wire"
+)
+
+// Freshness modes deciding whether stapled attestations are confirmed by a live fetch
+const (
+	FreshnessAlways = "always"  // always fetch the live attestations
+	FreshnessMaxAge = "max-age" // fetch the live RA, and the live NA only when the staple is older than MaxAge
+	FreshnessNever  = "never"   // check the stapled attestations alone; the result is never verified
+)
+
+// DefaultStapleMaxAge is how long a stapled NA replaces the live one under FreshnessMaxAge when the
+// policy sets no MaxAge
+const DefaultStapleMaxAge = time.Hour
+
+// StatusSkipStapled is the Resource Presence status when only stapled attestations were checked
+const StatusSkipStapled = "skip (stapled)"
+
+// ReasonStapledOnly is the failure reason of stapled attestations that passed all checks but were
+// not confirmed live. A stapled RA is unsigned, so only a fetch from its origin shows it is current.
+const ReasonStapledOnly = "stapled_only"
+
+// FreshnessPolicy decides whether stapled attestations are confirmed by a live fetch
+type FreshnessPolicy struct {
+	Mode   string        // FreshnessAlways, FreshnessMaxAge or FreshnessNever; empty means FreshnessAlways
+	MaxAge time.Duration // Under FreshnessMaxAge, how old a staple may be to stand in for the live NA; zero means DefaultStapleMaxAge
+}
+
+// StapleContext records how stapled attestations were used for a verification
+type StapleContext struct {
+	Freshness   string `json:"freshness"`            // Freshness mode applied
+	StapledAt   int64  `json:"stapled_at,omitempty"` // Unsigned staple time declared by the fragment, if any
+	LiveChecked bool   `json:"live_checked"`         // Whether the live attestations were fetched and verified
+	StapledNA   bool   `json:"stapled_na,omitempty"` // Whether the stapled NA was used in place of fetching the live one
+}
+
+// DefaultFreshnessPolicy always confirms staples live
+func DefaultFreshnessPolicy() FreshnessPolicy {
+	return FreshnessPolicy{Mode: FreshnessAlways}
+}
+
+// ParseFreshnessMode validates a freshness mode name
+func ParseFreshnessMode(mode string) (string, error) {
+	switch mode {
+	case FreshnessAlways, FreshnessMaxAge, FreshnessNever:
+		return mode, nil
+	default:
+		return "", fmt.Errorf("unknown freshness mode %q (want %s, %s or %s)", mode, FreshnessAlways, FreshnessMaxAge, FreshnessNever)
+	}
+}
+
+// NeedsLiveCheck reports whether stapled attestations must be confirmed by fetching the live ones.
+// A stapled RA is unsigned, so only the never mode skips the live RA; the result is then unverified.
+func (p FreshnessPolicy) NeedsLiveCheck() bool {
+	return p.Mode != FreshnessNever
+}
+
+// UseStapledNA reports whether the stapled NA of a staple that passed VerifyStapled may replace the
+// live NA fetch at now. It may only under FreshnessMaxAge, for a staple declaring a stapled_at no
+// older than MaxAge and not in the future. The stapled NA is signed and its exp was checked, so the
+// declared time only bounds how long a publisher's change of NA can go unnoticed; the RA, which
+// carries the association, is still confirmed live.
+func (p FreshnessPolicy) UseStapledNA(staple *StapleContext, now time.Time) bool {
+	if p.Mode != FreshnessMaxAge || staple == nil || staple.StapledAt == 0 {
+		return false
+	}
+	maxAge := p.MaxAge
+	if maxAge <= 0 {
+		maxAge = DefaultStapleMaxAge
+	}
+	stapledAt := time.Unix(staple.StapledAt, 0)
+	return !stapledAt.After(now) && now.Sub(stapledAt) <= maxAge
+}
+
+// StapledNamespaceAttestation decodes the fragment's stapled NA
+func StapledNamespaceAttestation(fragment wire.Fragment) (wire.NamespaceAttestation, error) {
+	return wire.DecodeNamespaceAttestation(bytes.NewReader(fragment.StapledNamespaceAttestation))
+}
+
+// HasStapledAttestations reports whether the fragment carries both stapled attestations
+func HasStapledAttestations(fragment wire.Fragment) bool {
+	return len(fragment.StapledResourceAttestation) > 0 && len(fragment.StapledNamespaceAttestation) > 0
+}
+
+// VerifyStapled performs the three-step verification against the attestations stapled in the fragment.
+// The stapled JSON is decoded strictly; decoding failures are reported with their malformed_* reason.
+// The result is never verified: staples that pass every check fail with ReasonStapledOnly until the
+// caller confirms them against the live attestations.
+func VerifyStapled(fragment wire.Fragment, opts Options) VerificationResult {
+	stapleContext := &StapleContext{StapledAt: fragment.StapledAt}
+
+	ra, err := wire.DecodeResourceAttestation(bytes.NewReader(fragment.StapledResourceAttestation))
+	if err != nil {
+		result := stapledDecodeFailure(fragment, "resource_presence", "stapled resource attestation", err)
+		result.ResourcePresence = "fail"
+		result.Context.Stapled = stapleContext
+		return result
+	}
+
+	na, err := StapledNamespaceAttestation(fragment)
+	if err != nil {
+		result := stapledDecodeFailure(fragment, "publisher_association", "stapled namespace attestation", err)
+		result.ResourcePresence = StatusSkipStapled
+		result.ResourceIntegrity = "pass"
+		result.PublisherAssociation = "fail"
+		result.Context.Stapled = stapleContext
+		return result
+	}
+
+	opts.Stapled = true
+	result := VerifyFragmentWithOptions(fragment, ra, na, opts)
+	result.Context.Stapled = stapleContext
+	return result
+}
+
+// IsStapledOnly reports whether a VerifyStapled result passed every check against the staples
+func IsStapledOnly(result VerificationResult) bool {
+	return result.Failure != nil && result.Failure.Reason == ReasonStapledOnly
+}
+
+// stapledDecodeFailure builds the failed result for stapled JSON that could not be decoded
+func stapledDecodeFailure(fragment wire.Fragment, check, what string, err error) VerificationResult {
+	reason := wire.MalformedReason(err)
+	if reason == "" {
+		reason = "malformed"
+	}
+	return VerificationResult{
+		ResourcePresence:     "skip",
+		ResourceIntegrity:    "skip",
+		PublisherAssociation: "skip",
+		Failure: &FailureDetails{
+			Check:   check,
+			Reason:  reason,
+			Message: fmt.Sprintf("failed to parse %s: %v", what, err),
+		},
+		Context: &VerificationContext{
+			ResourceAttestationURL:  fragment.ResourceAttestationURL,
+			NamespaceAttestationURL: fragment.NamespaceAttestationURL,
+			VerifiedAt:              time.Now().Unix(),
+		},
+	}
+}
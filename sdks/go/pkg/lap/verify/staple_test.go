@@ -0,0 +1,108 @@
+package verify
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestVerifyStapled(t *testing.T) {
+	fragment, ra, na := newSignedFixture(t, "")
+	raJSON, _ := json.Marshal(ra)
+	naJSON, _ := json.Marshal(na)
+	fragment.StapledResourceAttestation = raJSON
+	fragment.StapledNamespaceAttestation = naJSON
+	fragment.StapledAt = 1754908800
+
+	if !HasStapledAttestations(fragment) {
+		t.Fatal("Expected fragment to report stapled attestations")
+	}
+
+	result := VerifyStapled(fragment, DefaultOptions())
+	if result.Verified || !IsStapledOnly(result) {
+		t.Fatalf("Expected passing staples to be reported unverified, got %+v", result.Failure)
+	}
+	if result.ResourceIntegrity != "pass" || result.PublisherAssociation != "pass" {
+		t.Errorf("Expected integrity and association to pass, got %+v", result)
+	}
+	if result.ResourcePresence != StatusSkipStapled {
+		t.Errorf("Expected resource_presence '%s', got '%s'", StatusSkipStapled, result.ResourcePresence)
+	}
+	if result.Context.Stapled == nil || result.Context.Stapled.StapledAt != 1754908800 {
+		t.Errorf("Expected staple context with stapled_at, got %+v", result.Context.Stapled)
+	}
+}
+
+func TestVerifyStapled_BadSignature(t *testing.T) {
+	fragment, ra, na := newSignedFixture(t, "")
+	na.Payload.Namespace = "https://example.com/"
+	raJSON, _ := json.Marshal(ra)
+	naJSON, _ := json.Marshal(na)
+	fragment.StapledResourceAttestation = raJSON
+	fragment.StapledNamespaceAttestation = naJSON
+
+	result := VerifyStapled(fragment, DefaultOptions())
+	if result.Verified || IsStapledOnly(result) || result.PublisherAssociation != "fail" {
+		t.Errorf("Expected publisher association failure for altered stapled NA, got %+v", result)
+	}
+}
+
+func TestVerifyStapled_Malformed(t *testing.T) {
+	fragment, ra, _ := newSignedFixture(t, "")
+	raJSON, _ := json.Marshal(ra)
+	fragment.StapledResourceAttestation = raJSON
+	fragment.StapledNamespaceAttestation = []byte(`{"payload":{"namespace":"a","exp":1},"key":"k","key":"k","sig":"s"}`)
+
+	result := VerifyStapled(fragment, DefaultOptions())
+	if result.Failure == nil || result.Failure.Check != "publisher_association" || result.Failure.Reason != "malformed_duplicate_key" {
+		t.Errorf("Expected publisher_association malformed_duplicate_key, got %+v", result.Failure)
+	}
+}
+
+func TestFreshnessPolicy_NeedsLiveCheck(t *testing.T) {
+	if !DefaultFreshnessPolicy().NeedsLiveCheck() || !(FreshnessPolicy{}).NeedsLiveCheck() {
+		t.Error("Expected the default and zero policies to check staples live")
+	}
+	if (FreshnessPolicy{Mode: FreshnessNever}).NeedsLiveCheck() {
+		t.Error("Expected never to skip the live check")
+	}
+}
+
+func TestParseFreshnessMode(t *testing.T) {
+	for _, mode := range []string{FreshnessAlways, FreshnessMaxAge, FreshnessNever} {
+		if _, err := ParseFreshnessMode(mode); err != nil {
+			t.Errorf("ParseFreshnessMode(%q): %v", mode, err)
+		}
+	}
+	for _, mode := range []string{"sometimes", "maxage"} {
+		if _, err := ParseFreshnessMode(mode); err == nil {
+			t.Errorf("Expected error for unknown freshness mode %q", mode)
+		}
+	}
+}
+
+func TestFreshnessPolicy_UseStapledNA(t *testing.T) {
+	now := time.Unix(1754908800, 0)
+	policy := FreshnessPolicy{Mode: FreshnessMaxAge, MaxAge: 10 * time.Minute}
+	tests := []struct {
+		name      string
+		policy    FreshnessPolicy
+		stapledAt int64
+		want      bool
+	}{
+		{"fresh staple", policy, now.Add(-5 * time.Minute).Unix(), true},
+		{"old staple", policy, now.Add(-11 * time.Minute).Unix(), false},
+		{"future staple", policy, now.Add(time.Minute).Unix(), false},
+		{"no staple time", policy, 0, false},
+		{"default max age", FreshnessPolicy{Mode: FreshnessMaxAge}, now.Add(-30 * time.Minute).Unix(), true},
+		{"always mode", FreshnessPolicy{Mode: FreshnessAlways}, now.Unix(), false},
+	}
+	for _, tt := range tests {
+		if got := tt.policy.UseStapledNA(&StapleContext{StapledAt: tt.stapledAt}, now); got != tt.want {
+			t.Errorf("%s: UseStapledNA = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+	if !policy.NeedsLiveCheck() {
+		t.Error("Expected max-age to still confirm the RA live")
+	}
+}
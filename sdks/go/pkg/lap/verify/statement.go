@@ -0,0 +1,137 @@
+package verify
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/stonebraker/lap/sdks/go/pkg/lap/canonical"
+	"github.com/stonebraker/lap/sdks/go/pkg/lap/crypto"
+	"github.com/stonebraker/lap/sdks/go/pkg/lap/wire"
+)
+
+// StatementResult is a third-party statement about a verified resource, with the outcome of its checks
+type StatementResult struct {
+	Type                    string          `json:"type"`
+	Body                    string          `json:"body,omitempty"`
+	Issuer                  string          `json:"issuer"`           // Issuer's public key
+	IssuerNamespace         string          `json:"issuer_namespace"` // Namespace of the issuer's NA
+	NamespaceAttestationURL string          `json:"namespace_attestation_url"`
+	IssuedAt                int64           `json:"issued_at"`
+	Status                  string          `json:"status"` // "pass", "pass (stale)", "fail"
+	Failure                 *FailureDetails `json:"failure,omitempty"`
+}
+
+// VerifyStatement checks a statement from an issuer the caller trusts, identified by the URL of its
+// Namespace Attestation, against the subject resource's RA. The statement must be signed with the key
+// of that NA, which must itself be valid. A statement about content the publisher has since edited
+// reports StatusPassStale when its hash is among the RA's previous_hashes.
+func VerifyStatement(st wire.Statement, ra wire.ResourceAttestation, issuerNA wire.NamespaceAttestation, issuerNAURL string, opts Options) StatementResult {
+	result := StatementResult{
+		Type:                    st.Payload.Type,
+		Body:                    st.Payload.Body,
+		Issuer:                  st.Key,
+		IssuerNamespace:         issuerNA.Payload.Namespace,
+		NamespaceAttestationURL: st.Payload.NamespaceAttestationURL,
+		IssuedAt:                st.Payload.Iat,
+	}
+
+	err := verifyStatement(st, ra, issuerNA, issuerNAURL, opts)
+	if err != nil {
+		result.Status = "fail"
+		result.Failure = &FailureDetails{
+			Check:   "statement",
+			Reason:  classifyStatementError(err),
+			Message: err.Error(),
+			Details: map[string]interface{}{
+				"fragment_url": st.Payload.FragmentURL,
+				"hash":         st.Payload.Hash,
+			},
+		}
+		return result
+	}
+
+	result.Status = "pass"
+	if st.Payload.Hash != ra.Hash {
+		result.Status = StatusPassStale
+	}
+	return result
+}
+
+func verifyStatement(st wire.Statement, ra wire.ResourceAttestation, issuerNA wire.NamespaceAttestation, issuerNAURL string, opts Options) error {
+	// The statement must name the issuer the caller trusts, and be signed with that issuer's key
+	if st.Payload.NamespaceAttestationURL != issuerNAURL {
+		return fmt.Errorf("statement issuer mismatch: got %s, want %s", st.Payload.NamespaceAttestationURL, issuerNAURL)
+	}
+	if st.Key != issuerNA.Key || crypto.NormalizeSignatureAlgorithm(st.Alg) != crypto.NormalizeSignatureAlgorithm(issuerNA.Alg) {
+		return fmt.Errorf("statement key mismatch: got %s, want %s", st.Key, issuerNA.Key)
+	}
+	if err := verifyNamespaceSignature(issuerNA, opts); err != nil {
+		return fmt.Errorf("issuer namespace attestation invalid: %w", err)
+	}
+
+	payloadBytes, err := canonical.MarshalStatementPayloadCanonical(st.SignedPayload())
+	if err != nil {
+		return fmt.Errorf("failed to marshal canonical payload: %w", err)
+	}
+	ok, err := crypto.VerifySignatureHex(st.Alg, st.Key, st.Sig, crypto.HashSHA256(payloadBytes))
+	if errors.Is(err, crypto.ErrUnknownSignatureAlgorithm) {
+		return fmt.Errorf("unsupported signature algorithm: %s", st.Alg)
+	}
+	if err != nil || !ok {
+		return errors.New("statement signature invalid")
+	}
+
+	if !isStatementType(st.Payload.Type) {
+		return fmt.Errorf("unsupported statement type: %s", st.Payload.Type)
+	}
+
+	// The statement must be about this resource, in its current or an earlier version
+	if NormalizeURL(st.Payload.FragmentURL) != NormalizeURL(ra.FragmentURL) {
+		return fmt.Errorf("statement subject mismatch: got %s, want %s", st.Payload.FragmentURL, ra.FragmentURL)
+	}
+	if st.Payload.Hash == ra.Hash {
+		return nil
+	}
+	for _, previous := range ra.PreviousHashes {
+		if st.Payload.Hash == previous {
+			return nil
+		}
+	}
+	return fmt.Errorf("statement subject hash mismatch: got %s, want %s", st.Payload.Hash, ra.Hash)
+}
+
+func isStatementType(statementType string) bool {
+	for _, t := range wire.StatementTypes {
+		if t == statementType {
+			return true
+		}
+	}
+	return false
+}
+
+// classifyStatementError categorizes statement errors
+func classifyStatementError(err error) string {
+	errStr := err.Error()
+	if contains(errStr, "statement issuer mismatch") {
+		return "issuer_mismatch"
+	}
+	if contains(errStr, "statement key mismatch") {
+		return "key_mismatch"
+	}
+	if contains(errStr, "issuer namespace attestation invalid") {
+		return "issuer_invalid"
+	}
+	if contains(errStr, "unsupported signature algorithm") {
+		return "unsupported_signature_algorithm"
+	}
+	if contains(errStr, "unsupported statement type") {
+		return "unsupported_statement_type"
+	}
+	if contains(errStr, "statement subject mismatch") {
+		return "subject_mismatch"
+	}
+	if contains(errStr, "statement subject hash mismatch") {
+		return "subject_hash_mismatch"
+	}
+	return "signature_invalid"
+}
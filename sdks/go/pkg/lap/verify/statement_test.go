@@ -0,0 +1,139 @@
+package verify
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stonebraker/lap/sdks/go/pkg/lap/canonical"
+	"github.com/stonebraker/lap/sdks/go/pkg/lap/crypto"
+	"github.com/stonebraker/lap/sdks/go/pkg/lap/wire"
+)
+
+const issuerNAURL = "https://bob.example/people/bob/_la_namespace.json"
+
+// statementFixture returns an RA, the issuer's NA, and a signer for statements by that issuer
+func statementFixture(t *testing.T) (wire.ResourceAttestation, wire.NamespaceAttestation, func(wire.StatementPayload) wire.Statement) {
+	t.Helper()
+	priv, pubKey, err := crypto.GenerateKeyPair()
+	if err != nil {
+		t.Fatal(err)
+	}
+	payload := wire.NamespacePayload{Namespace: "https://bob.example/people/bob/", Exp: time.Now().Add(time.Hour).Unix()}
+	payloadBytes, err := canonical.MarshalNamespacePayloadCanonical(payload.ToCanonical())
+	if err != nil {
+		t.Fatal(err)
+	}
+	sig, err := crypto.SignSchnorrHex(priv, crypto.HashSHA256(payloadBytes))
+	if err != nil {
+		t.Fatal(err)
+	}
+	na := wire.NamespaceAttestation{Payload: payload, Key: pubKey, Sig: sig}
+
+	ra := wire.ResourceAttestation{
+		FragmentURL:    "https://alice.example/people/alice/frc/posts/1",
+		Hash:           crypto.ComputeContentHashField([]byte("<p>Version 2</p>")),
+		Version:        2,
+		PreviousHashes: []string{crypto.ComputeContentHashField([]byte("<p>Version 1</p>"))},
+	}
+
+	sign := func(p wire.StatementPayload) wire.Statement {
+		payloadBytes, err := canonical.MarshalStatementPayloadCanonical(p.ToCanonical())
+		if err != nil {
+			t.Fatal(err)
+		}
+		sig, err := crypto.SignSchnorrHex(priv, crypto.HashSHA256(payloadBytes))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return wire.Statement{Payload: p, Key: pubKey, Sig: sig}
+	}
+	return ra, na, sign
+}
+
+func TestVerifyStatement(t *testing.T) {
+	ra, na, sign := statementFixture(t)
+	payload := wire.StatementPayload{
+		FragmentURL:             ra.FragmentURL,
+		Hash:                    ra.Hash,
+		Type:                    wire.StatementDispute,
+		Body:                    "The quoted figures are from 2019",
+		NamespaceAttestationURL: issuerNAURL,
+		Iat:                     time.Now().Unix(),
+	}
+
+	result := VerifyStatement(sign(payload), ra, na, issuerNAURL, DefaultOptions())
+	if result.Status != "pass" || result.Failure != nil {
+		t.Fatalf("Expected statement to pass, got %s %+v", result.Status, result.Failure)
+	}
+	if result.Type != wire.StatementDispute || result.Issuer != na.Key || result.IssuerNamespace != na.Payload.Namespace {
+		t.Errorf("Unexpected statement result: %+v", result)
+	}
+
+	// A statement about the previous version still counts, but is stale
+	stale := payload
+	stale.Hash = ra.PreviousHashes[0]
+	if result := VerifyStatement(sign(stale), ra, na, issuerNAURL, DefaultOptions()); result.Status != StatusPassStale {
+		t.Errorf("Expected %q, got %s %+v", StatusPassStale, result.Status, result.Failure)
+	}
+}
+
+func TestVerifyStatement_Failures(t *testing.T) {
+	ra, na, sign := statementFixture(t)
+	valid := wire.StatementPayload{
+		FragmentURL:             ra.FragmentURL,
+		Hash:                    ra.Hash,
+		Type:                    wire.StatementEndorse,
+		NamespaceAttestationURL: issuerNAURL,
+		Iat:                     time.Now().Unix(),
+	}
+
+	tampered := sign(valid)
+	tampered.Payload.Type = wire.StatementDispute
+
+	otherKey := sign(valid)
+	_, otherPub, err := crypto.GenerateKeyPair()
+	if err != nil {
+		t.Fatal(err)
+	}
+	otherKey.Key = otherPub
+
+	expiredNA := na
+	expiredNA.Payload.Exp = time.Now().Add(-time.Hour).Unix()
+
+	testCases := []struct {
+		name   string
+		st     wire.Statement
+		na     wire.NamespaceAttestation
+		edit   func(*wire.StatementPayload)
+		reason string
+	}{
+		{name: "tampered", st: tampered, na: na, reason: "signature_invalid"},
+		{name: "other key", st: otherKey, na: na, reason: "key_mismatch"},
+		{name: "expired issuer", st: sign(valid), na: expiredNA, reason: "issuer_invalid"},
+		{name: "untrusted issuer", na: na, edit: func(p *wire.StatementPayload) {
+			p.NamespaceAttestationURL = "https://mallory.example/_la_namespace.json"
+		}, reason: "issuer_mismatch"},
+		{name: "unknown type", na: na, edit: func(p *wire.StatementPayload) { p.Type = "like" }, reason: "unsupported_statement_type"},
+		{name: "other resource", na: na, edit: func(p *wire.StatementPayload) {
+			p.FragmentURL = "https://alice.example/people/alice/frc/posts/2"
+		}, reason: "subject_mismatch"},
+		{name: "unknown version", na: na, edit: func(p *wire.StatementPayload) {
+			p.Hash = crypto.ComputeContentHashField([]byte("<p>Never published</p>"))
+		}, reason: "subject_hash_mismatch"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			st := tc.st
+			if tc.edit != nil {
+				payload := valid
+				tc.edit(&payload)
+				st = sign(payload)
+			}
+			result := VerifyStatement(st, ra, tc.na, issuerNAURL, DefaultOptions())
+			if result.Status != "fail" || result.Failure == nil || result.Failure.Reason != tc.reason {
+				t.Errorf("Expected failure reason %q, got %s %+v", tc.reason, result.Status, result.Failure)
+			}
+		})
+	}
+}
@@ -0,0 +1,50 @@
+package verify
+
+import (
+	"fmt"
+
+	"github.com/stonebraker/lap/sdks/go/pkg/lap/wire"
+)
+
+// VerifyReplyLink checks that a reply's in_reply_to reference points at the parent's Resource Attestation.
+// A reply to content the parent has since edited is reported as StatusPassStale when the referenced hash
+// appears in the parent's previous_hashes. Both attestations must already have been verified.
+func VerifyReplyLink(reply, parent wire.ResourceAttestation) (string, *FailureDetails) {
+	ref := reply.InReplyTo
+	if ref == nil {
+		return "fail", &FailureDetails{
+			Check:   "reply_link",
+			Reason:  "not_a_reply",
+			Message: fmt.Sprintf("%s has no in_reply_to reference", reply.FragmentURL),
+			Details: map[string]interface{}{},
+		}
+	}
+	if NormalizeURL(ref.FragmentURL) != NormalizeURL(parent.FragmentURL) {
+		return "fail", &FailureDetails{
+			Check:   "reply_link",
+			Reason:  "reply_target_mismatch",
+			Message: fmt.Sprintf("reply target mismatch: got %s, want %s", parent.FragmentURL, ref.FragmentURL),
+			Details: map[string]interface{}{
+				"expected": ref.FragmentURL,
+				"actual":   parent.FragmentURL,
+			},
+		}
+	}
+	if ref.Hash == parent.Hash {
+		return "pass", nil
+	}
+	for _, previous := range parent.PreviousHashes {
+		if ref.Hash == previous {
+			return StatusPassStale, nil
+		}
+	}
+	return "fail", &FailureDetails{
+		Check:   "reply_link",
+		Reason:  "reply_hash_mismatch",
+		Message: fmt.Sprintf("reply hash mismatch: %s is not a version of %s", ref.Hash, parent.FragmentURL),
+		Details: map[string]interface{}{
+			"expected": ref.Hash,
+			"actual":   parent.Hash,
+		},
+	}
+}
@@ -0,0 +1,45 @@
+package verify
+
+import (
+	"testing"
+
+	"github.com/stonebraker/lap/sdks/go/pkg/lap/wire"
+)
+
+func TestVerifyReplyLink(t *testing.T) {
+	parent := wire.ResourceAttestation{
+		FragmentURL:    "https://alice.example/people/alice/frc/posts/1",
+		Hash:           "sha256:2222",
+		Version:        2,
+		PreviousHashes: []string{"sha256:1111"},
+	}
+	reply := wire.ResourceAttestation{
+		FragmentURL: "https://westley.example/people/westley/frc/posts/9",
+		InReplyTo:   &wire.ReplyReference{FragmentURL: parent.FragmentURL + "/", Hash: parent.Hash},
+	}
+
+	if status, failure := VerifyReplyLink(reply, parent); status != "pass" || failure != nil {
+		t.Errorf("Expected pass, got %s %+v", status, failure)
+	}
+
+	// Replying to a version the parent has since edited
+	reply.InReplyTo.Hash = "sha256:1111"
+	if status, failure := VerifyReplyLink(reply, parent); status != StatusPassStale || failure != nil {
+		t.Errorf("Expected %q, got %s %+v", StatusPassStale, status, failure)
+	}
+
+	reply.InReplyTo.Hash = "sha256:3333"
+	if status, failure := VerifyReplyLink(reply, parent); status != "fail" || failure == nil || failure.Reason != "reply_hash_mismatch" {
+		t.Errorf("Expected reply_hash_mismatch, got %s %+v", status, failure)
+	}
+
+	reply.InReplyTo = &wire.ReplyReference{FragmentURL: "https://alice.example/people/alice/frc/posts/2", Hash: parent.Hash}
+	if status, failure := VerifyReplyLink(reply, parent); status != "fail" || failure == nil || failure.Reason != "reply_target_mismatch" {
+		t.Errorf("Expected reply_target_mismatch, got %s %+v", status, failure)
+	}
+
+	reply.InReplyTo = nil
+	if _, failure := VerifyReplyLink(reply, parent); failure == nil || failure.Reason != "not_a_reply" {
+		t.Errorf("Expected not_a_reply, got %+v", failure)
+	}
+}
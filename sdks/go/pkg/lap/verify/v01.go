@@ -0,0 +1,348 @@
+package verify
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/stonebraker/lap/sdks/go/pkg/lap/canonical"
+	"github.com/stonebraker/lap/sdks/go/pkg/lap/crypto"
+	"github.com/stonebraker/lap/sdks/go/pkg/lap/wire"
+)
+
+// ClockSkewV01 is the tolerance applied to v0.1 iat/exp windows, the default of the v0.1 result contract
+const ClockSkewV01 = 120 * time.Second
+
+// legacyCodesV01 maps failure reasons to the LA_ codes of the v0.1 result contract
+var legacyCodesV01 = map[string]string{
+	"url_mismatch":               "LA_URL_MISMATCH",
+	"etag_mismatch":              "LA_ETAG_MISMATCH",
+	"hash_mismatch":              "LA_HASH_MISMATCH",
+	"unsupported_hash_algorithm": "LA_UNSUPPORTED_ALG",
+	"signature_invalid":          "LA_SIG_INVALID",
+	"key_invalid":                "LA_KEY_INVALID",
+	"expired":                    "LA_EXPIRED",
+	"not_yet_valid":              "LA_IAT_IN_FUTURE",
+	"malformed":                  "LA_ATTESTATION_MALFORMED",
+	"url_drift":                  "LA_URL_DRIFT",
+	"attestation_url_drift":      "LA_ATTESTATION_URL_DRIFT",
+	"hash_drift":                 "LA_HASH_DRIFT",
+	"resource_key_drift":         "LA_RESOURCE_KEY_DRIFT",
+	"kid_drift":                  "LA_KID_DRIFT",
+}
+
+// VerifyFragmentV01 verifies an archived v0.1 fragment with the v0.1 rules, reported as the three
+// v0.2 checks. ra is the live Resource Attestation, na the Namespace Attestation fetched from naURL,
+// and etag the resource response's ETag header, if any.
+//
+//   - Resource Presence: the RA is for the fragment URL and served at its canonical attestation URL,
+//     the stapled RA has not drifted from the live one, and the ETag matches
+//   - Resource Integrity: the RA is within its iat/exp window, the bytes match its hash, and it is
+//     signed by its resource key
+//   - Publisher Association: the NA's namespaces are canonical, one of them covers the URL it was
+//     fetched from and another (or the same) the fragment URL, and it is fresh and validly signed
+//
+// Failure details carry the v0.1 result code under "code" where one exists. The custom checks of
+// opts.Pipeline and then opts.Policy run after the three checks, on the v0.2 fields the v0.1
+// attestations map onto (see checkInputV01); the built-in checks of the pipeline are replaced by the
+// v0.1 rules.
+func VerifyFragmentV01(fragment wire.FragmentV01, ra wire.ResourceAttestationV01, na wire.NamespaceAttestationV01, naURL, etag string, opts Options) VerificationResult {
+	result := VerificationResult{
+		ResourcePresence:     "skip",
+		ResourceIntegrity:    "skip",
+		PublisherAssociation: "skip",
+		Context: &VerificationContext{
+			ResourceAttestationURL:  ra.Payload.AttestationURL,
+			NamespaceAttestationURL: naURL,
+			VerifiedAt:              time.Now().Unix(),
+			Offline:                 opts.Offline,
+			Spec:                    wire.SpecV01,
+			Policy:                  opts.Policy,
+		},
+	}
+	var checks Pipeline
+	for _, check := range opts.Pipeline {
+		switch check.Name() {
+		case CheckResourcePresence, CheckResourceIntegrity, CheckPublisherAssociation, CheckExcerptInclusion:
+		default:
+			checks = append(checks, check)
+		}
+	}
+	if opts.Policy != nil {
+		checks = append(checks, opts.Policy.Check())
+	}
+	for _, check := range checks {
+		setCheckStatus(&result, check.Name(), "skip")
+	}
+
+	steps := []struct {
+		check  string
+		status *string
+		run    func() error
+	}{
+		{"resource_presence", &result.ResourcePresence, func() error { return verifyResourcePresenceV01(fragment, ra, etag) }},
+		{"resource_integrity", &result.ResourceIntegrity, func() error { return verifyResourceIntegrityV01(fragment, ra, opts) }},
+		{"publisher_association", &result.PublisherAssociation, func() error { return verifyPublisherAssociationV01(fragment, na, naURL, opts) }},
+	}
+	for _, step := range steps {
+		if err := step.run(); err != nil {
+			reason := classifyV01Error(err)
+			details := getV01FailureDetails(err, fragment, ra, na, etag, opts)
+			if code, ok := legacyCodesV01[reason]; ok {
+				details["code"] = code
+			}
+			result.Failure = &FailureDetails{
+				Check:   step.check,
+				Reason:  reason,
+				Message: err.Error(),
+				Details: details,
+			}
+			*step.status = "fail"
+			return result
+		}
+		*step.status = "pass"
+	}
+
+	in := checkInputV01(fragment, ra, na, naURL, opts)
+	for _, check := range checks {
+		if !runCheck(check, &in, &result) {
+			return result
+		}
+	}
+	if opts.Offline {
+		result.ResourcePresence = StatusSkipOffline
+	}
+
+	result.Verified = true
+	return result
+}
+
+// checkInputV01 maps a v0.1 fragment and its attestations onto the v0.2 fields read by policy rules
+// and custom checks: the publisher key, the RA and NA URLs, the content and its hash, and the NA's expiry. The namespace is the
+// most specific one of the NA that covers the fragment URL.
+func checkInputV01(fragment wire.FragmentV01, ra wire.ResourceAttestationV01, na wire.NamespaceAttestationV01, naURL string, opts Options) CheckInput {
+	var namespace string
+	for _, ns := range na.Payload.Namespace {
+		if isURLUnderNamespace(fragment.URL, ns) && len(ns) > len(namespace) {
+			namespace = ns
+		}
+	}
+	return CheckInput{
+		Fragment: wire.Fragment{
+			Spec:                    wire.SpecV01,
+			FragmentURL:             fragment.URL,
+			CanonicalContent:        fragment.CanonicalContent,
+			PublisherClaim:          na.PublisherKey,
+			ResourceAttestationURL:  ra.Payload.AttestationURL,
+			NamespaceAttestationURL: naURL,
+			ContentType:             fragment.ContentType,
+		},
+		ResourceAttestation: wire.ResourceAttestation{
+			FragmentURL:             ra.Payload.URL,
+			Hash:                    ra.Payload.Hash,
+			PublisherClaim:          na.PublisherKey,
+			NamespaceAttestationURL: naURL,
+		},
+		NamespaceAttestation: wire.NamespaceAttestation{
+			Payload: wire.NamespacePayload{Namespace: namespace, Exp: na.Payload.Exp},
+			Key:     na.PublisherKey,
+		},
+		Options: opts,
+	}
+}
+
+// verifyResourcePresenceV01 checks that the live RA belongs to the fragment's URL and matches the
+// attestation stapled into the fragment
+func verifyResourcePresenceV01(fragment wire.FragmentV01, ra wire.ResourceAttestationV01, etag string) error {
+	if NormalizeURL(ra.Payload.URL) != NormalizeURL(fragment.URL) {
+		return fmt.Errorf("resource attestation URL mismatch: got %s, want %s", ra.Payload.URL, fragment.URL)
+	}
+	if !isSameOrigin(fragment.URL, ra.Payload.AttestationURL) {
+		return fmt.Errorf("resource attestation URL origin mismatch: resource %s, attestation %s", fragment.URL, ra.Payload.AttestationURL)
+	}
+	if want := wire.ResourceAttestationURLV01(fragment.URL); ra.Payload.AttestationURL != want {
+		return fmt.Errorf("resource attestation location mismatch: got %s, want %s", ra.Payload.AttestationURL, want)
+	}
+
+	// Values that must stay stable across refreshes of the stapled attestation
+	stapled := fragment.StapledResourceAttestation
+	for _, field := range []struct{ name, stapled, live string }{
+		{"url", stapled.Payload.URL, ra.Payload.URL},
+		{"attestation_url", stapled.Payload.AttestationURL, ra.Payload.AttestationURL},
+		{"hash", stapled.Payload.Hash, ra.Payload.Hash},
+		{"resource_key", stapled.ResourceKey, ra.ResourceKey},
+		{"kid", stapled.Payload.KID, ra.Payload.KID},
+	} {
+		if field.stapled != field.live {
+			return fmt.Errorf("stapled attestation drift: %s: stapled %s, live %s", field.name, field.stapled, field.live)
+		}
+	}
+
+	if etag != "" && ra.Payload.ETag != etag {
+		return fmt.Errorf("etag mismatch: got %s, want %s", etag, ra.Payload.ETag)
+	}
+	return nil
+}
+
+// verifyResourceIntegrityV01 checks the RA's time window, the content hash and the resource key's signature
+func verifyResourceIntegrityV01(fragment wire.FragmentV01, ra wire.ResourceAttestationV01, opts Options) error {
+	if err := checkWindowV01("resource attestation", ra.Payload.IAT, ra.Payload.Exp, opts); err != nil {
+		return err
+	}
+
+	if !strings.HasPrefix(ra.Payload.Hash, "sha256:") {
+		algorithm, _, _ := strings.Cut(ra.Payload.Hash, ":")
+		return fmt.Errorf("unsupported hash algorithm: %s", algorithm)
+	}
+	if !isHashAlgorithmAllowed("sha256", opts.AllowedHashAlgorithms) {
+		return errors.New("hash algorithm not allowed by policy: sha256")
+	}
+	computed := crypto.ComputeContentHashField(fragment.CanonicalContent)
+	if computed != ra.Payload.Hash {
+		return fmt.Errorf("content hash mismatch: got %s, want %s", ra.Payload.Hash, computed)
+	}
+	if fragment.DataHash != "" && fragment.DataHash != ra.Payload.Hash {
+		return fmt.Errorf("content hash mismatch: data-hash %s, attested %s", fragment.DataHash, ra.Payload.Hash)
+	}
+
+	payloadBytes, err := canonical.MarshalResourcePayloadV01Canonical(ra.Payload.ToCanonical())
+	if err != nil {
+		return fmt.Errorf("failed to marshal canonical payload: %w", err)
+	}
+	if _, err := crypto.ParseXOnlyPubKeyHex(ra.ResourceKey); err != nil {
+		return fmt.Errorf("resource key invalid: %v", err)
+	}
+	if ok, err := crypto.VerifySchnorrHex(ra.ResourceKey, ra.Sig, crypto.HashSHA256(payloadBytes)); err != nil || !ok {
+		return errors.New("resource attestation signature invalid")
+	}
+	return nil
+}
+
+// verifyPublisherAssociationV01 checks that the NA was served within one of its namespaces, covers
+// the fragment URL, and is fresh and signed by the publisher key
+func verifyPublisherAssociationV01(fragment wire.FragmentV01, na wire.NamespaceAttestationV01, naURL string, opts Options) error {
+	namespaces := na.Payload.Namespace
+	if len(namespaces) == 0 || !sort.StringsAreSorted(namespaces) {
+		return errors.New("malformed namespace list: must be sorted and non-empty")
+	}
+	for i, ns := range namespaces {
+		if !strings.HasSuffix(ns, "/") || (i > 0 && namespaces[i-1] == ns) {
+			return fmt.Errorf("malformed namespace list: %s", ns)
+		}
+	}
+
+	var servedWithin, covered bool
+	for _, ns := range namespaces {
+		servedWithin = servedWithin || strings.HasPrefix(naURL, ns)
+		covered = covered || isURLUnderNamespace(fragment.URL, ns)
+	}
+	if !servedWithin {
+		return fmt.Errorf("namespace attestation URL origin mismatch: %s is outside %s", naURL, strings.Join(namespaces, ", "))
+	}
+	if !covered {
+		return fmt.Errorf("fragment URL %s is not covered by namespace %s", fragment.URL, strings.Join(namespaces, ", "))
+	}
+
+	if err := checkWindowV01("namespace attestation", na.Payload.IAT, na.Payload.Exp, opts); err != nil {
+		return err
+	}
+
+	payloadBytes, err := canonical.MarshalNamespacePayloadV01Canonical(na.Payload.ToCanonical())
+	if err != nil {
+		return fmt.Errorf("failed to marshal canonical payload: %w", err)
+	}
+	if _, err := crypto.ParseXOnlyPubKeyHex(na.PublisherKey); err != nil {
+		return fmt.Errorf("publisher key invalid: %v", err)
+	}
+	if ok, err := crypto.VerifySchnorrHex(na.PublisherKey, na.Sig, crypto.HashSHA256(payloadBytes)); err != nil || !ok {
+		return errors.New("namespace attestation signature invalid")
+	}
+	return nil
+}
+
+// checkWindowV01 requires iat <= now < exp, within ClockSkewV01
+func checkWindowV01(kind string, iat, exp int64, opts Options) error {
+	now := opts.now().Unix()
+	skew := int64(ClockSkewV01 / time.Second)
+	if now < iat-skew {
+		return fmt.Errorf("%s not yet valid", kind)
+	}
+	if now >= exp+skew {
+		return fmt.Errorf("%s expired", kind)
+	}
+	return nil
+}
+
+// classifyV01Error categorizes v0.1 verification errors
+func classifyV01Error(err error) string {
+	errStr := err.Error()
+	switch {
+	case contains(errStr, "resource attestation URL mismatch"):
+		return "url_mismatch"
+	case contains(errStr, "resource attestation URL origin mismatch"), contains(errStr, "namespace attestation URL origin mismatch"):
+		return "origin_mismatch"
+	case contains(errStr, "resource attestation location mismatch"):
+		return "attestation_url_mismatch"
+	case contains(errStr, "stapled attestation drift: "):
+		field, _, _ := strings.Cut(strings.TrimPrefix(errStr, "stapled attestation drift: "), ":")
+		return field + "_drift"
+	case contains(errStr, "etag mismatch"):
+		return "etag_mismatch"
+	case strings.HasSuffix(errStr, "not yet valid"):
+		return "not_yet_valid"
+	case strings.HasSuffix(errStr, "expired"):
+		return "expired"
+	case contains(errStr, "unsupported hash algorithm"):
+		return "unsupported_hash_algorithm"
+	case contains(errStr, "hash algorithm not allowed by policy"):
+		return "hash_algorithm_not_allowed"
+	case contains(errStr, "content hash mismatch"):
+		return "hash_mismatch"
+	case contains(errStr, "resource key invalid"), contains(errStr, "publisher key invalid"):
+		return "key_invalid"
+	case strings.HasSuffix(errStr, "signature invalid"):
+		return "signature_invalid"
+	case contains(errStr, "malformed namespace list"):
+		return "malformed"
+	case contains(errStr, "fragment URL"):
+		return "url_not_under_namespace"
+	}
+	return "validation_failed"
+}
+
+// getV01FailureDetails provides detailed failure information for v0.1 verification
+func getV01FailureDetails(err error, fragment wire.FragmentV01, ra wire.ResourceAttestationV01, na wire.NamespaceAttestationV01, etag string, opts Options) map[string]interface{} {
+	errStr := err.Error()
+	details := map[string]interface{}{}
+	switch {
+	case contains(errStr, "resource attestation URL mismatch"):
+		details["expected"] = fragment.URL
+		details["actual"] = ra.Payload.URL
+	case contains(errStr, "resource attestation location mismatch"):
+		details["expected"] = wire.ResourceAttestationURLV01(fragment.URL)
+		details["actual"] = ra.Payload.AttestationURL
+	case contains(errStr, "stapled attestation drift: "):
+		details["stapled_kid"] = fragment.StapledResourceAttestation.Payload.KID
+		details["live_kid"] = ra.Payload.KID
+	case contains(errStr, "etag mismatch"):
+		details["expected"] = ra.Payload.ETag
+		details["actual"] = etag
+	case contains(errStr, "content hash mismatch"):
+		details["expected"] = ra.Payload.Hash
+		details["actual"] = crypto.ComputeContentHashField(fragment.CanonicalContent)
+	case contains(errStr, "resource attestation"):
+		details["iat"] = ra.Payload.IAT
+		details["exp"] = ra.Payload.Exp
+		details["kid"] = ra.Payload.KID
+	case contains(errStr, "namespace attestation"), contains(errStr, "fragment URL"), contains(errStr, "malformed namespace list"):
+		details["namespace"] = na.Payload.Namespace
+		details["iat"] = na.Payload.IAT
+		details["exp"] = na.Payload.Exp
+	}
+	if strings.HasSuffix(errStr, "expired") || strings.HasSuffix(errStr, "not yet valid") {
+		details["now"] = opts.now().Unix()
+	}
+	return details
+}
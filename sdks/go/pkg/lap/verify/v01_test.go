@@ -0,0 +1,226 @@
+package verify
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stonebraker/lap/sdks/go/pkg/lap/canonical"
+	"github.com/stonebraker/lap/sdks/go/pkg/lap/crypto"
+	"github.com/stonebraker/lap/sdks/go/pkg/lap/wire"
+)
+
+// v01Fixture builds an archived v0.1 fragment with its live RA and an NA that pass the v0.1 rules
+func v01Fixture(t *testing.T) (wire.FragmentV01, wire.ResourceAttestationV01, wire.NamespaceAttestationV01, string) {
+	t.Helper()
+
+	resourcePriv, resourceKey, err := crypto.GenerateKeyPair()
+	if err != nil {
+		t.Fatal(err)
+	}
+	publisherPriv, publisherKey, err := crypto.GenerateKeyPair()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	content := []byte(`<span id="msg-123">Hello, my name is Alice.</span>`)
+	now := time.Now().Unix()
+	ra := wire.ResourceAttestationV01{
+		Payload: wire.ResourcePayloadV01{
+			URL:            "https://example.com/people/alice/messages/123",
+			AttestationURL: "https://example.com/people/alice/messages/123/_la_resource.json",
+			Hash:           crypto.ComputeContentHashField(content),
+			ETag:           `W/"123-abcde"`,
+			IAT:            now,
+			Exp:            now + 600,
+			KID:            "resource-key-2025-08-12",
+		},
+		ResourceKey: resourceKey,
+	}
+	payloadBytes, err := canonical.MarshalResourcePayloadV01Canonical(ra.Payload.ToCanonical())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ra.Sig, err = crypto.SignSchnorrHex(resourcePriv, crypto.HashSHA256(payloadBytes)); err != nil {
+		t.Fatal(err)
+	}
+
+	na := wire.NamespaceAttestationV01{
+		Payload: wire.NamespacePayloadV01{
+			Namespace:       []string{"https://example.com/people/alice/"},
+			AttestationPath: "/people/alice/_la_namespace.json",
+			IAT:             now,
+			Exp:             now + 3600,
+		},
+		PublisherKey: publisherKey,
+	}
+	payloadBytes, err = canonical.MarshalNamespacePayloadV01Canonical(na.Payload.ToCanonical())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if na.Sig, err = crypto.SignSchnorrHex(publisherPriv, crypto.HashSHA256(payloadBytes)); err != nil {
+		t.Fatal(err)
+	}
+
+	fragment := wire.FragmentV01{
+		Spec:                       "https://lap.dev/spec/v0-1",
+		URL:                        ra.Payload.URL,
+		CanonicalContent:           content,
+		ContentType:                "text/html; charset=utf-8",
+		DataHash:                   ra.Payload.Hash,
+		StapledResourceAttestation: ra,
+	}
+	return fragment, ra, na, "https://example.com/people/alice/_la_namespace.json"
+}
+
+func TestVerifyFragmentV01_Success(t *testing.T) {
+	fragment, ra, na, naURL := v01Fixture(t)
+
+	result := VerifyFragmentV01(fragment, ra, na, naURL, ra.Payload.ETag, Options{})
+	if !result.Verified {
+		t.Fatalf("Expected v0.1 fragment to verify, got %+v", result.Failure)
+	}
+	if result.Context.Spec != wire.SpecV01 {
+		t.Errorf("Expected context spec %s, got %q", wire.SpecV01, result.Context.Spec)
+	}
+}
+
+func TestVerifyFragmentV01_Failures(t *testing.T) {
+	tests := []struct {
+		name   string
+		mutate func(*wire.FragmentV01, *wire.ResourceAttestationV01, *wire.NamespaceAttestationV01, *Options)
+		check  string
+		reason string
+		code   string
+	}{
+		{
+			name: "kid drift",
+			mutate: func(f *wire.FragmentV01, _ *wire.ResourceAttestationV01, _ *wire.NamespaceAttestationV01, _ *Options) {
+				f.StapledResourceAttestation.Payload.KID = "resource-key-2025-01-01"
+			},
+			check: "resource_presence", reason: "kid_drift", code: "LA_KID_DRIFT",
+		},
+		{
+			name: "hash mismatch",
+			mutate: func(f *wire.FragmentV01, _ *wire.ResourceAttestationV01, _ *wire.NamespaceAttestationV01, _ *Options) {
+				f.CanonicalContent = []byte(`<span id="msg-123">Hello, my name is Mallory.</span>`)
+			},
+			check: "resource_integrity", reason: "hash_mismatch", code: "LA_HASH_MISMATCH",
+		},
+		{
+			name: "expired",
+			mutate: func(_ *wire.FragmentV01, ra *wire.ResourceAttestationV01, _ *wire.NamespaceAttestationV01, opts *Options) {
+				opts.At = time.Unix(ra.Payload.Exp, 0).Add(ClockSkewV01 + time.Second)
+			},
+			check: "resource_integrity", reason: "expired", code: "LA_EXPIRED",
+		},
+		{
+			name: "hash algorithm not allowed",
+			mutate: func(_ *wire.FragmentV01, _ *wire.ResourceAttestationV01, _ *wire.NamespaceAttestationV01, opts *Options) {
+				opts.AllowedHashAlgorithms = []string{"sha512"}
+			},
+			check: "resource_integrity", reason: "hash_algorithm_not_allowed",
+		},
+		{
+			name: "signature invalid",
+			mutate: func(f *wire.FragmentV01, ra *wire.ResourceAttestationV01, _ *wire.NamespaceAttestationV01, _ *Options) {
+				ra.Payload.ETag = `W/"124-fffff"`
+				f.StapledResourceAttestation.Payload.ETag = ra.Payload.ETag
+			},
+			check: "resource_integrity", reason: "signature_invalid", code: "LA_SIG_INVALID",
+		},
+		{
+			name: "namespace signature invalid",
+			mutate: func(_ *wire.FragmentV01, _ *wire.ResourceAttestationV01, na *wire.NamespaceAttestationV01, _ *Options) {
+				na.Payload.Namespace = []string{"https://example.com/people/"}
+			},
+			check: "publisher_association", reason: "signature_invalid", code: "LA_SIG_INVALID",
+		},
+		{
+			name: "unsorted namespaces",
+			mutate: func(_ *wire.FragmentV01, _ *wire.ResourceAttestationV01, na *wire.NamespaceAttestationV01, _ *Options) {
+				na.Payload.Namespace = []string{"https://example.com/people/bob/", "https://example.com/people/alice/"}
+			},
+			check: "publisher_association", reason: "malformed", code: "LA_ATTESTATION_MALFORMED",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fragment, ra, na, naURL := v01Fixture(t)
+			opts := Options{}
+			tt.mutate(&fragment, &ra, &na, &opts)
+
+			result := VerifyFragmentV01(fragment, ra, na, naURL, ra.Payload.ETag, opts)
+			if result.Verified {
+				t.Fatal("Expected verification to fail")
+			}
+			if result.Failure.Check != tt.check || result.Failure.Reason != tt.reason {
+				t.Fatalf("Expected %s/%s, got %s/%s: %s", tt.check, tt.reason, result.Failure.Check, result.Failure.Reason, result.Failure.Message)
+			}
+			if tt.code != "" && result.Failure.Details["code"] != tt.code {
+				t.Errorf("Expected code %s, got %v", tt.code, result.Failure.Details["code"])
+			}
+		})
+	}
+}
+
+func TestVerifyFragmentV01_Policy(t *testing.T) {
+	fragment, ra, na, naURL := v01Fixture(t)
+
+	result := VerifyFragmentV01(fragment, ra, na, naURL, ra.Payload.ETag, Options{Policy: &Policy{AllowedKeys: []string{na.PublisherKey}}})
+	if !result.Verified || result.Checks[CheckPolicy] != "pass" {
+		t.Fatalf("Expected the policy to pass, got %+v (%v)", result.Failure, result.Checks)
+	}
+
+	result = VerifyFragmentV01(fragment, ra, na, naURL, ra.Payload.ETag, Options{Policy: &Policy{DeniedOrigins: []string{"https://example.com"}}})
+	if result.Verified || result.Failure.Check != CheckPolicy || result.Failure.Reason != "origin_denied" {
+		t.Fatalf("Expected policy/origin_denied, got %+v", result.Failure)
+	}
+	if result.Context.Policy == nil || result.PublisherAssociation != "pass" {
+		t.Errorf("Expected the policy in context after the three checks passed, got %+v, %s", result.Context.Policy, result.PublisherAssociation)
+	}
+}
+
+func TestVerifyFragmentV01_CustomChecks(t *testing.T) {
+	fragment, ra, na, naURL := v01Fixture(t)
+
+	var seen CheckInput
+	keyCheck := NewCheck("key_pin", func(in *CheckInput, _ *VerificationResult) (string, *FailureDetails) {
+		seen = *in
+		return "pass (first use)", nil
+	})
+	result := VerifyFragmentV01(fragment, ra, na, naURL, ra.Payload.ETag, Options{Pipeline: append(DefaultPipeline(), keyCheck)})
+	if !result.Verified || result.Checks["key_pin"] != "pass (first use)" {
+		t.Fatalf("Expected the custom check to run, got %+v (%v)", result.Failure, result.Checks)
+	}
+	if seen.NamespaceAttestation.Key != na.PublisherKey || seen.NamespaceAttestation.Payload.Namespace != "https://example.com/people/alice/" || seen.Fragment.NamespaceAttestationURL != naURL {
+		t.Errorf("Expected the v0.1 NA mapped onto the check input, got %+v", seen.NamespaceAttestation)
+	}
+}
+
+func TestVerifyFragmentV01_URLNotUnderNamespace(t *testing.T) {
+	fragment, ra, na, _ := v01Fixture(t)
+
+	// Served from within bob's namespace, which does not cover alice's message
+	na.Payload.Namespace = []string{"https://example.com/people/bob/"}
+	result := VerifyFragmentV01(fragment, ra, na, "https://example.com/people/bob/_la_namespace.json", ra.Payload.ETag, Options{})
+	if result.Failure == nil || result.Failure.Reason != "url_not_under_namespace" {
+		t.Fatalf("Expected url_not_under_namespace, got %+v", result.Failure)
+	}
+}
+
+func TestVerifyFragment_UnsupportedSpec(t *testing.T) {
+	fragment, ra, na := newSignedFixture(t, "")
+	fragment.Spec = "v0.3"
+
+	result := VerifyFragment(fragment, ra, na)
+	if result.Verified {
+		t.Fatal("Expected a fragment declaring an unknown spec to fail")
+	}
+	if result.Failure.Check != "resource_presence" || result.Failure.Reason != "unsupported_spec" {
+		t.Errorf("Expected resource_presence/unsupported_spec, got %s/%s", result.Failure.Check, result.Failure.Reason)
+	}
+	if result.ResourceIntegrity != "skip" || result.PublisherAssociation != "skip" {
+		t.Errorf("Expected remaining checks to be skipped, got %s/%s", result.ResourceIntegrity, result.PublisherAssociation)
+	}
+}
@@ -3,6 +3,7 @@ package verify
 import (
 	"errors"
 	"fmt"
+	"mime"
 	"net/url"
 	"strings"
 	"time"
@@ -15,11 +16,18 @@ import (
 // VerificationResult represents the result of v0.2 verification
 type VerificationResult struct {
 	Verified             bool                 `json:"verified"`
-	ResourcePresence     string              `json:"resource_presence"`     // "pass", "fail", "skip"
-	ResourceIntegrity    string              `json:"resource_integrity"`    // "pass", "fail", "skip"
-	PublisherAssociation string              `json:"publisher_association"` // "pass", "fail", "skip"
-	Failure              *FailureDetails     `json:"failure"`
+	ResourcePresence     string               `json:"resource_presence"`           // "pass", "fail", "skip", "skip (offline)"
+	ResourceIntegrity    string               `json:"resource_integrity"`          // "pass", "pass (stale)", "fail", "skip"
+	PublisherAssociation string               `json:"publisher_association"`       // "pass", "fail", "skip"
+	ExcerptInclusion     string               `json:"excerpt_inclusion,omitempty"` // Excerpts only: "pass", "fail", "skip"
+	Statements           []StatementResult    `json:"statements,omitempty"`        // Third-party statements from trusted issuers
+	Publishers           []PublisherResult    `json:"publishers,omitempty"`        // Per-author association, for co-authored resources
+	Checks               map[string]string    `json:"checks,omitempty"`            // Statuses of custom checks in the pipeline, by name
+	Failure              *FailureDetails      `json:"failure"`
 	Context              *VerificationContext `json:"context"`
+
+	// ResourceAttestation is the RA the resource was verified against, set only when Verified
+	ResourceAttestation *wire.ResourceAttestation `json:"-"`
 }
 
 // FailureDetails provides information about verification failures
@@ -35,74 +43,126 @@ type VerificationContext struct {
 	ResourceAttestationURL  string `json:"resource_attestation_url"`
 	NamespaceAttestationURL string `json:"namespace_attestation_url"`
 	VerifiedAt             int64  `json:"verified_at"`
+	Offline                 bool           `json:"offline,omitempty"` // Attestations were loaded from local copies
+	Stapled                 *StapleContext `json:"stapled,omitempty"` // Set when the fragment carried stapled attestations
+	ResourceAttestationSource string       `json:"resource_attestation_source,omitempty"` // Set when the RA came from a response header
+	Version                   int          `json:"version,omitempty"`          // Current content version from a versioned RA
+	Stale                     bool         `json:"stale,omitempty"`            // The fragment carries a superseded version of the content
+	EmbeddedVersion           int          `json:"embedded_version,omitempty"` // Version of the fragment's content when stale
+	Encrypted                 bool         `json:"encrypted,omitempty"`        // The fragment's content was decrypted with the reader key
+	Spec                      string       `json:"spec,omitempty"`             // Protocol version, when verified by a legacy verifier
+	FreshUntil                int64        `json:"fresh_until,omitempty"`      // When to verify again with a freshly fetched RA, from its exp and max_age
+	Policy                    *Policy      `json:"policy,omitempty"`           // Verifier policy in effect, when one is configured
+	PublisherClaim            string       `json:"publisher_claim,omitempty"`  // Publisher key, once Resource Presence and Publisher Association have passed
 }
 
-// VerifyFragment performs the three-step v0.2 verification process
-func VerifyFragment(fragment wire.Fragment, resourceAttestation wire.ResourceAttestation, namespaceAttestation wire.NamespaceAttestation) VerificationResult {
-	result := VerificationResult{
-		ResourcePresence:     "skip",
-		ResourceIntegrity:    "skip",
-		PublisherAssociation: "skip",
-		Context: &VerificationContext{
-			ResourceAttestationURL:  fragment.ResourceAttestationURL,
-			NamespaceAttestationURL: fragment.NamespaceAttestationURL,
-			VerifiedAt:             time.Now().Unix(),
-		},
-	}
+// StatusSkipOffline is the Resource Presence status for offline verification
+const StatusSkipOffline = "skip (offline)"
 
-	// Step 1: Resource Presence check
-	if err := verifyResourcePresence(fragment, resourceAttestation); err != nil {
-		result.Failure = &FailureDetails{
-			Check:   "resource_presence",
-			Reason:  classifyResourcePresenceError(err),
-			Message: err.Error(),
-			Details:  getResourcePresenceFailureDetails(err, fragment, resourceAttestation),
-		}
-		result.ResourcePresence = "fail"
-		return result
-	}
-	result.ResourcePresence = "pass"
+// StatusPassStale is the Resource Integrity status when the content matches a superseded version
+// listed in the RA's previous_hashes rather than the current hash
+const StatusPassStale = "pass (stale)"
 
-	// Step 2: Resource Integrity check
-	if err := verifyResourceIntegrity(fragment, resourceAttestation); err != nil {
-		result.Failure = &FailureDetails{
-			Check:   "resource_integrity",
-			Reason:  "hash_mismatch",
-			Message: err.Error(),
-			Details:  getResourceIntegrityFailureDetails(fragment, resourceAttestation),
-		}
-		result.ResourceIntegrity = "fail"
-		return result
-	}
-	result.ResourceIntegrity = "pass"
-
-	// Step 3: Publisher Association check
-	if err := verifyPublisherAssociation(fragment, resourceAttestation, namespaceAttestation); err != nil {
-		result.Failure = &FailureDetails{
-			Check:   "publisher_association",
-			Reason:  classifyPublisherAssociationError(err),
-			Message: err.Error(),
-			Details:  getPublisherAssociationFailureDetails(err, fragment, resourceAttestation, namespaceAttestation),
-		}
-		result.PublisherAssociation = "fail"
-		return result
+// Resource Attestation sources recorded in VerificationContext.ResourceAttestationSource
+const (
+	RASourceHeader        = "header"     // RA taken from the resource response header
+	RASourceHeaderChecked = "header+url" // header RA matched the RA served at its URL
+)
+
+// Options configures optional verifier policy for VerifyFragmentWithOptions
+type Options struct {
+	// AllowedHashAlgorithms restricts which Resource Attestation hash algorithms are accepted.
+	// When empty, every algorithm registered in the crypto package is accepted.
+	AllowedHashAlgorithms []string
+
+	// Offline indicates the attestations were loaded from local copies rather than fetched live.
+	// Resource Presence cannot be demonstrated offline, so once the fragment and RA are consistent
+	// it is reported as StatusSkipOffline instead of "pass".
+	Offline bool
+
+	// Stapled indicates the attestations were taken from the fragment itself rather than fetched
+	// live; Resource Presence is then reported as StatusSkipStapled and a result that passes every
+	// check still fails with ReasonStapledOnly, since a stapled RA is unsigned.
+	Stapled bool
+
+	// RejectStale fails Resource Integrity with reason "superseded" when the fragment carries an
+	// earlier version of the content, instead of reporting StatusPassStale.
+	RejectStale bool
+
+	// At evaluates time-dependent checks, such as Namespace Attestation expiry, as of the given time
+	// instead of now. Used when re-checking evidence captured earlier; the zero value means now.
+	At time.Time
+
+	// ReaderKey is the hex private key used to open the sealed content of subscriber-only fragments.
+	// Without it, or when it is not among the recipients, such fragments fail Resource Integrity.
+	ReaderKey string
+
+	// Pipeline is the ordered list of checks to run; nil runs DefaultPipeline. Custom checks, such
+	// as an allow-listed publisher or a size limit, are added to the default pipeline.
+	Pipeline Pipeline
+
+	// Policy, when set, is enforced by a policy check after the pipeline's own checks and echoed in
+	// the result's context
+	Policy *Policy
+
+	// signatureResults holds BIP-340 results precomputed by VerifyFragments
+	signatureResults map[crypto.SchnorrCheck]crypto.SchnorrCheckResult
+}
+
+// DefaultOptions returns the options used by VerifyFragment
+func DefaultOptions() Options {
+	return Options{}
+}
+
+// now returns the time that time-dependent checks are evaluated at
+func (o Options) now() time.Time {
+	if o.At.IsZero() {
+		return time.Now()
 	}
-	result.PublisherAssociation = "pass"
+	return o.At
+}
 
-	// All checks passed
-	result.Verified = true
-	return result
+// VerifyFragment performs the three-step v0.2 verification process
+func VerifyFragment(fragment wire.Fragment, resourceAttestation wire.ResourceAttestation, namespaceAttestation wire.NamespaceAttestation) VerificationResult {
+	return VerifyFragmentWithOptions(fragment, resourceAttestation, namespaceAttestation, DefaultOptions())
+}
+
+// VerifyFragmentWithOptions performs the three-step v0.2 verification process under the given options.
+// A co-authored resource fails Publisher Association here, since its co-publishers' Namespace
+// Attestations are not supplied; use VerifyFragmentWithCoPublishers for those.
+func VerifyFragmentWithOptions(fragment wire.Fragment, resourceAttestation wire.ResourceAttestation, namespaceAttestation wire.NamespaceAttestation, opts Options) VerificationResult {
+	return VerifyFragmentWithCoPublishers(fragment, resourceAttestation, namespaceAttestation, nil, opts)
+}
+
+// VerifyFragmentWithCoPublishers performs the three-step v0.2 verification process for a resource that
+// may list co-publishers. coAttestations maps each co-publisher's Namespace Attestation URL to the
+// attestation fetched from it; every author's association is checked independently.
+func VerifyFragmentWithCoPublishers(fragment wire.Fragment, resourceAttestation wire.ResourceAttestation, namespaceAttestation wire.NamespaceAttestation, coAttestations map[string]wire.NamespaceAttestation, opts Options) VerificationResult {
+	pipeline := opts.Pipeline
+	if pipeline == nil {
+		pipeline = DefaultPipeline()
+	}
+	if opts.Policy != nil {
+		pipeline = append(pipeline[:len(pipeline):len(pipeline)], opts.Policy.Check())
+	}
+	return pipeline.Verify(CheckInput{
+		Fragment:             fragment,
+		ResourceAttestation:  resourceAttestation,
+		NamespaceAttestation: namespaceAttestation,
+		CoAttestations:       coAttestations,
+		Options:              opts,
+	})
 }
 
-// normalizeURL removes trailing slash for consistent URL comparison
-func normalizeURL(url string) string {
+// NormalizeURL removes a trailing slash so fragment and namespace URLs compare consistently
+func NormalizeURL(url string) string {
 	return strings.TrimSuffix(url, "/")
 }
 
 // verifyResourcePresence checks that the Resource Attestation is accessible and matches the fragment
 func verifyResourcePresence(fragment wire.Fragment, ra wire.ResourceAttestation) error {
 	// Check URL matching (normalize both URLs to handle trailing slashes)
-	if normalizeURL(ra.FragmentURL) != normalizeURL(fragment.FragmentURL) {
+	if NormalizeURL(ra.FragmentURL) != NormalizeURL(fragment.FragmentURL) {
 		return fmt.Errorf("resource attestation fragment URL mismatch: got %s, want %s", ra.FragmentURL, fragment.FragmentURL)
 	}
 
@@ -116,6 +176,11 @@ func verifyResourcePresence(fragment wire.Fragment, ra wire.ResourceAttestation)
 		return fmt.Errorf("namespace attestation URL mismatch: got %s, want %s", ra.NamespaceAttestationURL, fragment.NamespaceAttestationURL)
 	}
 
+	// Check co-publisher triangulation: the fragment lists the RA's further authors, in order
+	if !sameCoPublishers(fragment.CoPublishers, ra) {
+		return fmt.Errorf("co-publisher mismatch: got %s, want %s", publisherClaims(wire.ListedCoPublishers(ra)), publisherClaims(fragment.CoPublishers))
+	}
+
 	// Check same-origin validation: Resource Attestation URL must have same origin as claimed resource URL
 	if !isSameOrigin(fragment.FragmentURL, fragment.ResourceAttestationURL) {
 		return fmt.Errorf("resource attestation URL origin mismatch: resource %s, attestation %s", fragment.FragmentURL, fragment.ResourceAttestationURL)
@@ -126,20 +191,113 @@ func verifyResourcePresence(fragment wire.Fragment, ra wire.ResourceAttestation)
 		return fmt.Errorf("namespace attestation URL origin mismatch: resource %s, attestation %s", fragment.FragmentURL, fragment.NamespaceAttestationURL)
 	}
 
+	// Check same-origin validation: content referenced instead of inlined must come from the resource's origin
+	if fragment.ContentURL != "" && !isSameOrigin(fragment.FragmentURL, fragment.ContentURL) {
+		return fmt.Errorf("content URL origin mismatch: resource %s, content %s", fragment.FragmentURL, fragment.ContentURL)
+	}
+
 	return nil
 }
 
-// verifyResourceIntegrity checks that the content hash matches the Resource Attestation
-func verifyResourceIntegrity(fragment wire.Fragment, ra wire.ResourceAttestation) error {
-	computedHash := crypto.ComputeContentHashField(fragment.CanonicalContent)
-	if ra.Hash != computedHash {
+// verifyResourceIntegrity checks that the content hash matches the Resource Attestation.
+// The hash algorithm is selected by the prefix of the attested hash and must be allowed by opts.
+// Content is first canonicalized with the profile declared by the RA, and must have the RA's media type.
+func verifyResourceIntegrity(fragment wire.Fragment, ra wire.ResourceAttestation, opts Options) error {
+	if !sameMediaType(ra.ContentType, fragment.ContentType) {
+		return fmt.Errorf("content type mismatch: got %s, want %s", mediaType(fragment.ContentType), mediaType(ra.ContentType))
+	}
+
+	algorithm, _, err := crypto.ParseContentHashField(ra.Hash)
+	if errors.Is(err, crypto.ErrUnknownHashAlgorithm) {
+		return fmt.Errorf("unsupported hash algorithm: %s", algorithm)
+	}
+	if algorithm != "" && !isHashAlgorithmAllowed(algorithm, opts.AllowedHashAlgorithms) {
+		return fmt.Errorf("hash algorithm not allowed by policy: %s", algorithm)
+	}
+
+	content, err := canonical.CanonicalizeContent(ra.Canonicalization, fragment.CanonicalContent)
+	if errors.Is(err, canonical.ErrUnknownProfile) {
+		return fmt.Errorf("unsupported canonicalization profile: %s", ra.Canonicalization)
+	}
+	if err != nil {
+		return fmt.Errorf("content canonicalization failed: %w", err)
+	}
+
+	// A malformed digest cannot match, so it is reported as a plain mismatch
+	if matched, _ := crypto.VerifyContentHashField(ra.Hash, content); !matched {
+		if embedded, ok := matchPreviousHash(ra, content, opts); ok {
+			return fmt.Errorf("content superseded: fragment carries version %d, current version is %d", embedded, currentVersion(ra))
+		}
+		computedHash, _ := crypto.ComputeContentHashFieldWithAlgorithm(algorithm, content)
 		return fmt.Errorf("content hash mismatch: got %s, want %s", ra.Hash, computedHash)
 	}
 	return nil
 }
 
+// currentVersion returns the RA's version; an unversioned RA with history counts its entries
+func currentVersion(ra wire.ResourceAttestation) int {
+	if ra.Version > 0 {
+		return ra.Version
+	}
+	return len(ra.PreviousHashes) + 1
+}
+
+// matchPreviousHash looks for content among the RA's superseded versions and returns the version it
+// matches. previous_hashes[i] is version currentVersion-1-i; algorithms outside policy are not tried.
+func matchPreviousHash(ra wire.ResourceAttestation, content []byte, opts Options) (int, bool) {
+	for i, previous := range ra.PreviousHashes {
+		algorithm, _, err := crypto.ParseContentHashField(previous)
+		if err != nil || !isHashAlgorithmAllowed(algorithm, opts.AllowedHashAlgorithms) {
+			continue
+		}
+		if matched, _ := crypto.VerifyContentHashField(previous, content); matched {
+			return currentVersion(ra) - 1 - i, true
+		}
+	}
+	return 0, false
+}
+
+// supersededVersion reports whether err is a superseded-content integrity failure and, if so, the
+// version the fragment carries
+func supersededVersion(err error, fragment wire.Fragment, ra wire.ResourceAttestation, opts Options) (int, bool) {
+	if err == nil || classifyResourceIntegrityError(err) != "superseded" {
+		return 0, false
+	}
+	content, _ := canonical.CanonicalizeContent(ra.Canonicalization, fragment.CanonicalContent)
+	return matchPreviousHash(ra, content, opts)
+}
+
+// mediaType returns the lowercase media type without parameters, defaulting to wire.DefaultContentType
+func mediaType(contentType string) string {
+	if contentType == "" {
+		return wire.DefaultContentType
+	}
+	if parsed, _, err := mime.ParseMediaType(contentType); err == nil {
+		return parsed
+	}
+	return strings.ToLower(strings.TrimSpace(contentType))
+}
+
+// sameMediaType compares two content types, ignoring parameters such as charset
+func sameMediaType(a, b string) bool {
+	return mediaType(a) == mediaType(b)
+}
+
+// isHashAlgorithmAllowed reports whether algorithm appears in allowed; an empty list allows everything
+func isHashAlgorithmAllowed(algorithm string, allowed []string) bool {
+	if len(allowed) == 0 {
+		return true
+	}
+	for _, a := range allowed {
+		if strings.EqualFold(a, algorithm) {
+			return true
+		}
+	}
+	return false
+}
+
 // verifyPublisherAssociation checks the Namespace Attestation signature and coverage
-func verifyPublisherAssociation(fragment wire.Fragment, ra wire.ResourceAttestation, na wire.NamespaceAttestation) error {
+func verifyPublisherAssociation(fragment wire.Fragment, ra wire.ResourceAttestation, na wire.NamespaceAttestation, opts Options) error {
 	// Check that the fragment URL is covered by the namespace
 	if !isURLUnderNamespace(fragment.FragmentURL, na.Payload.Namespace) {
 		return fmt.Errorf("fragment URL %s is not covered by namespace %s", fragment.FragmentURL, na.Payload.Namespace)
@@ -150,20 +308,32 @@ func verifyPublisherAssociation(fragment wire.Fragment, ra wire.ResourceAttestat
 		return fmt.Errorf("namespace attestation key mismatch: got %s, want %s", na.Key, fragment.PublisherClaim)
 	}
 
+	return verifyNamespaceSignature(na, opts)
+}
+
+// verifyNamespaceSignature checks that the Namespace Attestation has not expired and is validly signed
+func verifyNamespaceSignature(na wire.NamespaceAttestation, opts Options) error {
 	// Check expiration
-	if na.Payload.Exp <= time.Now().Unix() {
+	if na.Payload.Exp <= opts.now().Unix() {
 		return errors.New("namespace attestation expired")
 	}
 
 	// Verify the signature over the canonical payload
-	canonicalPayload := na.Payload.ToCanonical()
-	payloadBytes, err := canonical.MarshalNamespacePayloadCanonical(canonicalPayload)
+	digest, err := namespacePayloadDigest(na)
 	if err != nil {
-		return fmt.Errorf("failed to marshal canonical payload: %w", err)
+		return err
 	}
 
-	digest := crypto.HashSHA256(payloadBytes)
-	ok, err := crypto.VerifySchnorrHex(na.Key, na.Sig, digest)
+	var ok bool
+	precomputed, found := opts.signatureResults[schnorrCheck(na, digest)]
+	if found && crypto.NormalizeSignatureAlgorithm(na.Alg) == crypto.SignatureAlgorithmBIP340 {
+		ok, err = precomputed.Valid, precomputed.Err
+	} else {
+		ok, err = crypto.VerifySignatureHex(na.Alg, na.Key, na.Sig, digest)
+	}
+	if errors.Is(err, crypto.ErrUnknownSignatureAlgorithm) {
+		return fmt.Errorf("unsupported signature algorithm: %s", na.Alg)
+	}
 	if err != nil {
 		return fmt.Errorf("signature verification failed: %w", err)
 	}
@@ -174,6 +344,15 @@ func verifyPublisherAssociation(fragment wire.Fragment, ra wire.ResourceAttestat
 	return nil
 }
 
+// namespacePayloadDigest returns the SHA-256 digest of the canonical Namespace Attestation payload
+func namespacePayloadDigest(na wire.NamespaceAttestation) ([32]byte, error) {
+	payloadBytes, err := canonical.MarshalNamespacePayloadCanonical(na.SignedPayload())
+	if err != nil {
+		return [32]byte{}, fmt.Errorf("failed to marshal canonical payload: %w", err)
+	}
+	return crypto.HashSHA256(payloadBytes), nil
+}
+
 // isURLUnderNamespace checks if a URL is covered by a namespace
 func isURLUnderNamespace(url, namespace string) bool {
 	// Handle exact match
@@ -235,15 +414,51 @@ func classifyResourcePresenceError(err error) string {
 	if contains(errStr, "namespace attestation URL mismatch") {
 		return "namespace_url_mismatch"
 	}
+	if contains(errStr, "co-publisher mismatch") {
+		return "co_publisher_mismatch"
+	}
 	if contains(errStr, "resource attestation URL origin mismatch") {
 		return "origin_mismatch"
 	}
 	if contains(errStr, "namespace attestation URL origin mismatch") {
 		return "origin_mismatch"
 	}
+	if contains(errStr, "content URL origin mismatch") {
+		return "origin_mismatch"
+	}
+	if contains(errStr, "resource attestation not yet valid") {
+		return "resource_not_yet_valid"
+	}
+	if contains(errStr, "resource attestation expired") {
+		return "resource_expired"
+	}
 	return "validation_failed"
 }
 
+// classifyResourceIntegrityError categorizes resource integrity errors
+func classifyResourceIntegrityError(err error) string {
+	errStr := err.Error()
+	if contains(errStr, "content type mismatch") {
+		return "content_type_mismatch"
+	}
+	if contains(errStr, "unsupported hash algorithm") {
+		return "unsupported_hash_algorithm"
+	}
+	if contains(errStr, "hash algorithm not allowed by policy") {
+		return "hash_algorithm_not_allowed"
+	}
+	if contains(errStr, "unsupported canonicalization profile") {
+		return "unsupported_canonicalization"
+	}
+	if contains(errStr, "content canonicalization failed") {
+		return "canonicalization_failed"
+	}
+	if contains(errStr, "content superseded") {
+		return "superseded"
+	}
+	return "hash_mismatch"
+}
+
 // classifyPublisherAssociationError categorizes publisher association errors
 func classifyPublisherAssociationError(err error) string {
 	errStr := err.Error()
@@ -256,6 +471,9 @@ func classifyPublisherAssociationError(err error) string {
 	if contains(errStr, "namespace attestation expired") {
 		return "expired"
 	}
+	if contains(errStr, "unsupported signature algorithm") {
+		return "unsupported_signature_algorithm"
+	}
 	if contains(errStr, "signature invalid") {
 		return "signature_invalid"
 	}
@@ -284,29 +502,84 @@ func getResourcePresenceFailureDetails(err error, fragment wire.Fragment, ra wir
 	} else if contains(errStr, "namespace attestation URL mismatch") {
 		details["expected"] = fragment.NamespaceAttestationURL
 		details["actual"] = ra.NamespaceAttestationURL
+	} else if contains(errStr, "co-publisher mismatch") {
+		details["expected"] = publisherClaims(fragment.CoPublishers)
+		details["actual"] = publisherClaims(wire.ListedCoPublishers(ra))
 	} else if contains(errStr, "resource attestation URL origin mismatch") {
 		details["resource_url"] = fragment.FragmentURL
 		details["attestation_url"] = fragment.ResourceAttestationURL
 	} else if contains(errStr, "namespace attestation URL origin mismatch") {
 		details["resource_url"] = fragment.FragmentURL
 		details["attestation_url"] = fragment.NamespaceAttestationURL
+	} else if contains(errStr, "content URL origin mismatch") {
+		details["resource_url"] = fragment.FragmentURL
+		details["content_url"] = fragment.ContentURL
 	}
 	
 	return details
 }
 
 // getResourceIntegrityFailureDetails provides detailed failure information for Resource Integrity check
-func getResourceIntegrityFailureDetails(fragment wire.Fragment, ra wire.ResourceAttestation) map[string]interface{} {
-	computedHash := crypto.ComputeContentHashField(fragment.CanonicalContent)
+func getResourceIntegrityFailureDetails(err error, fragment wire.Fragment, ra wire.ResourceAttestation, opts Options) map[string]interface{} {
+	errStr := err.Error()
+	algorithm, _, _ := strings.Cut(ra.Hash, ":")
+
+	if contains(errStr, "content type mismatch") {
+		return map[string]interface{}{
+			"expected": mediaType(ra.ContentType),
+			"actual":   mediaType(fragment.ContentType),
+		}
+	}
+	if contains(errStr, "unsupported hash algorithm") {
+		return map[string]interface{}{
+			"algorithm": algorithm,
+			"supported": crypto.HashAlgorithmNames(),
+		}
+	}
+	if contains(errStr, "hash algorithm not allowed by policy") {
+		return map[string]interface{}{
+			"algorithm": algorithm,
+			"allowed":   opts.AllowedHashAlgorithms,
+		}
+	}
+
+	if contains(errStr, "unsupported canonicalization profile") {
+		return map[string]interface{}{
+			"canonicalization": ra.Canonicalization,
+			"supported":        canonical.ProfileNames(),
+		}
+	}
+	if contains(errStr, "content canonicalization failed") {
+		return map[string]interface{}{
+			"canonicalization": ra.Canonicalization,
+			"content_length":   len(fragment.CanonicalContent),
+		}
+	}
+
+	content, _ := canonical.CanonicalizeContent(ra.Canonicalization, fragment.CanonicalContent)
+	if contains(errStr, "content superseded") {
+		embedded, _ := matchPreviousHash(ra, content, opts)
+		return map[string]interface{}{
+			"current_version":  currentVersion(ra),
+			"embedded_version": embedded,
+			"current_hash":     ra.Hash,
+		}
+	}
+	algorithm, _, _ = crypto.ParseContentHashField(ra.Hash)
+	computedHash, hashErr := crypto.ComputeContentHashFieldWithAlgorithm(algorithm, content)
+	if hashErr != nil {
+		computedHash = crypto.ComputeContentHashField(content)
+	}
 	return map[string]interface{}{
 		"expected": ra.Hash,
 		"actual":   computedHash,
 		"content_length": len(fragment.CanonicalContent),
+		"canonicalization": canonical.NormalizeProfile(ra.Canonicalization),
 	}
 }
 
 // getPublisherAssociationFailureDetails provides detailed failure information for Publisher Association check
-func getPublisherAssociationFailureDetails(err error, fragment wire.Fragment, ra wire.ResourceAttestation, na wire.NamespaceAttestation) map[string]interface{} {
+func getPublisherAssociationFailureDetails(err error, fragment wire.Fragment, ra wire.ResourceAttestation, na wire.NamespaceAttestation, opts Options) map[string]interface{} {
 	errStr := err.Error()
 	details := map[string]interface{}{
 		"fragment_url": fragment.FragmentURL,
@@ -321,8 +594,40 @@ func getPublisherAssociationFailureDetails(err error, fragment wire.Fragment, ra
 		details["actual"] = na.Key
 	} else if contains(errStr, "namespace attestation expired") {
 		details["expires_at"] = na.Payload.Exp
-		details["current_time"] = time.Now().Unix()
+		details["current_time"] = opts.now().Unix()
+	} else if contains(errStr, "unsupported signature algorithm") {
+		details["algorithm"] = na.Alg
+		details["supported"] = crypto.SignatureAlgorithmNames()
 	}
 	
 	return details
 }
+
+// FailedResult builds a result for a failure that happens before verification runs, such as a
+// fragment that does not parse or an attestation that cannot be fetched. The failing check is
+// marked "fail"; checks before it must be set by the caller.
+func FailedResult(fragment *wire.Fragment, check, reason, message string, details map[string]interface{}) *VerificationResult {
+	result := &VerificationResult{
+		Verified: false,
+		Failure: &FailureDetails{
+			Check:   check,
+			Reason:  reason,
+			Message: message,
+			Details: details,
+		},
+		Context: &VerificationContext{
+			VerifiedAt: time.Now().Unix(),
+		},
+	}
+	switch check {
+	case CheckResourcePresence:
+		result.ResourcePresence = "fail"
+	case CheckPublisherAssociation:
+		result.PublisherAssociation = "fail"
+	}
+	if fragment != nil {
+		result.Context.ResourceAttestationURL = fragment.ResourceAttestationURL
+		result.Context.NamespaceAttestationURL = fragment.NamespaceAttestationURL
+	}
+	return result
+}
@@ -1,6 +1,7 @@
 package verify
 
 import (
+	"strings"
 	"testing"
 	"time"
 
@@ -395,3 +396,361 @@ func TestIsURLUnderNamespace(t *testing.T) {
 		}
 	}
 }
+
+// newSignedFixture builds a fragment, RA and NA that pass verification, hashing the content with hashAlg
+func newSignedFixture(t *testing.T, hashAlg string) (wire.Fragment, wire.ResourceAttestation, wire.NamespaceAttestation) {
+	t.Helper()
+
+	priv, pubKey, err := crypto.GenerateKeyPair()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	content := []byte("<h1>Test Post</h1><p>Content</p>")
+	contentHash, err := crypto.ComputeContentHashFieldWithAlgorithm(hashAlg, content)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fragment := wire.Fragment{
+		Spec:                    "v0.2",
+		FragmentURL:             "https://example.com/people/alice/frc/posts/123",
+		PreviewContent:          string(content),
+		CanonicalContent:        content,
+		PublisherClaim:          pubKey,
+		ResourceAttestationURL:  "https://example.com/people/alice/frc/posts/123/_la_resource.json",
+		NamespaceAttestationURL: "https://example.com/people/alice/_la_namespace.json",
+	}
+
+	resourceAttestation := wire.ResourceAttestation{
+		FragmentURL:             fragment.FragmentURL,
+		Hash:                    contentHash,
+		PublisherClaim:          pubKey,
+		NamespaceAttestationURL: fragment.NamespaceAttestationURL,
+	}
+
+	namespacePayload := wire.NamespacePayload{
+		Namespace: "https://example.com/people/alice/",
+		Exp:       time.Now().Add(1 * time.Hour).Unix(),
+	}
+	payloadBytes, err := canonical.MarshalNamespacePayloadCanonical(namespacePayload.ToCanonical())
+	if err != nil {
+		t.Fatal(err)
+	}
+	sig, err := crypto.SignSchnorrHex(priv, crypto.HashSHA256(payloadBytes))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	namespaceAttestation := wire.NamespaceAttestation{
+		Payload: namespacePayload,
+		Key:     pubKey,
+		Sig:     sig,
+	}
+
+	return fragment, resourceAttestation, namespaceAttestation
+}
+
+func TestVerifyFragment_HashAlgorithms(t *testing.T) {
+	for _, alg := range crypto.HashAlgorithmNames() {
+		t.Run(alg, func(t *testing.T) {
+			fragment, ra, na := newSignedFixture(t, alg)
+			result := VerifyFragment(fragment, ra, na)
+			if !result.Verified {
+				t.Errorf("Expected verification to pass with %s, got failure: %+v", alg, result.Failure)
+			}
+		})
+	}
+}
+
+func TestVerifyFragment_HashFieldCase(t *testing.T) {
+	fragment, ra, na := newSignedFixture(t, "sha256")
+	ra.Hash = "SHA256:" + strings.ToUpper(strings.TrimPrefix(ra.Hash, "sha256:"))
+
+	if result := VerifyFragment(fragment, ra, na); !result.Verified {
+		t.Errorf("Expected a valid hash with an upper-case field to verify, got %+v", result.Failure)
+	}
+}
+
+func TestVerifyFragment_UnsupportedHashAlgorithm(t *testing.T) {
+	fragment, ra, na := newSignedFixture(t, "sha256")
+	ra.Hash = "md5:900150983cd24fb0d6963f7d28e17f72"
+
+	result := VerifyFragment(fragment, ra, na)
+
+	if result.Verified {
+		t.Error("Expected verification to fail")
+	}
+	if result.ResourceIntegrity != "fail" {
+		t.Errorf("Expected resource_integrity to be 'fail', got '%s'", result.ResourceIntegrity)
+	}
+	if result.Failure == nil || result.Failure.Reason != "unsupported_hash_algorithm" {
+		t.Fatalf("Expected failure reason 'unsupported_hash_algorithm', got %+v", result.Failure)
+	}
+}
+
+func TestVerifyFragment_HTMLNormalizedCanonicalization(t *testing.T) {
+	fragment, ra, na := newSignedFixture(t, "sha256")
+
+	// The RA was computed over normalized content; the fragment carries CRLF line endings, a BOM and a trailing newline
+	normalized := []byte("<h1>Test Post</h1>\n<p>Content</p>")
+	ra.Hash = crypto.ComputeContentHashField(normalized)
+	ra.Canonicalization = canonical.ProfileHTMLNormalized
+	fragment.CanonicalContent = []byte("\xEF\xBB\xBF<h1>Test Post</h1>\r\n<p>Content</p>\r\n")
+
+	result := VerifyFragment(fragment, ra, na)
+	if !result.Verified {
+		t.Fatalf("Expected verification to pass, got %+v", result.Failure)
+	}
+
+	// Without the profile the same bytes no longer match
+	ra.Canonicalization = ""
+	result = VerifyFragment(fragment, ra, na)
+	if result.Failure == nil || result.Failure.Reason != "hash_mismatch" {
+		t.Errorf("Expected failure reason 'hash_mismatch' for raw profile, got %+v", result.Failure)
+	}
+}
+
+func TestVerifyFragment_UnsupportedCanonicalization(t *testing.T) {
+	fragment, ra, na := newSignedFixture(t, "sha256")
+	ra.Canonicalization = "xml-c14n"
+
+	result := VerifyFragment(fragment, ra, na)
+
+	if result.ResourceIntegrity != "fail" {
+		t.Errorf("Expected resource_integrity to be 'fail', got '%s'", result.ResourceIntegrity)
+	}
+	if result.Failure == nil || result.Failure.Reason != "unsupported_canonicalization" {
+		t.Fatalf("Expected failure reason 'unsupported_canonicalization', got %+v", result.Failure)
+	}
+}
+
+func TestVerifyFragmentWithOptions_DisallowedHashAlgorithm(t *testing.T) {
+	fragment, ra, na := newSignedFixture(t, "sha384")
+
+	opts := Options{AllowedHashAlgorithms: []string{"sha256", "sha512"}}
+	result := VerifyFragmentWithOptions(fragment, ra, na, opts)
+
+	if result.Verified {
+		t.Error("Expected verification to fail")
+	}
+	if result.Failure == nil || result.Failure.Check != "resource_integrity" {
+		t.Fatalf("Expected resource_integrity failure, got %+v", result.Failure)
+	}
+	if result.Failure.Reason != "hash_algorithm_not_allowed" {
+		t.Errorf("Expected failure reason 'hash_algorithm_not_allowed', got '%s'", result.Failure.Reason)
+	}
+
+	opts.AllowedHashAlgorithms = append(opts.AllowedHashAlgorithms, "SHA384")
+	result = VerifyFragmentWithOptions(fragment, ra, na, opts)
+	if !result.Verified {
+		t.Errorf("Expected verification to pass once sha384 is allowed, got failure: %+v", result.Failure)
+	}
+}
+
+func TestVerifyFragment_SignatureAlgorithms(t *testing.T) {
+	for _, alg := range crypto.SignatureAlgorithmNames() {
+		t.Run(alg, func(t *testing.T) {
+			signer, err := crypto.GenerateSigner(alg)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			fragment, ra, na := newSignedFixture(t, "sha256")
+			fragment.PublisherClaim = signer.PublicKeyHex()
+			ra.PublisherClaim = signer.PublicKeyHex()
+
+			na.Alg = alg
+			na.Key = signer.PublicKeyHex()
+			payloadBytes, err := canonical.MarshalNamespacePayloadCanonical(na.SignedPayload())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if na.Sig, err = signer.SignHex(crypto.HashSHA256(payloadBytes)); err != nil {
+				t.Fatal(err)
+			}
+
+			result := VerifyFragment(fragment, ra, na)
+			if !result.Verified {
+				t.Errorf("Expected verification to pass with %s, got failure: %+v", alg, result.Failure)
+			}
+
+			// The alg is signed, so a signature over the payload alone does not verify for another algorithm
+			if alg == crypto.SignatureAlgorithmBIP340 {
+				return
+			}
+			payloadBytes, _ = canonical.MarshalNamespacePayloadCanonical(na.Payload.ToCanonical())
+			na.Sig, _ = signer.SignHex(crypto.HashSHA256(payloadBytes))
+			if result := VerifyFragment(fragment, ra, na); result.Verified {
+				t.Errorf("Expected a signature not covering alg %s to be rejected", alg)
+			}
+		})
+	}
+}
+
+func TestVerifyFragment_UnsupportedSignatureAlgorithm(t *testing.T) {
+	fragment, ra, na := newSignedFixture(t, "sha256")
+	na.Alg = "rsa-pss"
+
+	result := VerifyFragment(fragment, ra, na)
+
+	if result.Verified {
+		t.Error("Expected verification to fail")
+	}
+	if result.PublisherAssociation != "fail" {
+		t.Errorf("Expected publisher_association to be 'fail', got '%s'", result.PublisherAssociation)
+	}
+	if result.Failure == nil || result.Failure.Reason != "unsupported_signature_algorithm" {
+		t.Fatalf("Expected failure reason 'unsupported_signature_algorithm', got %+v", result.Failure)
+	}
+}
+
+func TestVerifyFragmentWithOptions_Offline(t *testing.T) {
+	fragment, ra, na := newSignedFixture(t, "")
+
+	result := VerifyFragmentWithOptions(fragment, ra, na, Options{Offline: true})
+
+	if !result.Verified {
+		t.Fatalf("Expected offline verification to pass, got %+v", result.Failure)
+	}
+	if result.ResourcePresence != StatusSkipOffline {
+		t.Errorf("Expected resource_presence to be '%s', got '%s'", StatusSkipOffline, result.ResourcePresence)
+	}
+	if result.ResourceIntegrity != "pass" || result.PublisherAssociation != "pass" {
+		t.Errorf("Expected integrity and association to pass, got %s/%s", result.ResourceIntegrity, result.PublisherAssociation)
+	}
+	if !result.Context.Offline {
+		t.Error("Expected context to be marked offline")
+	}
+
+	// Consistency between fragment and RA is still enforced offline
+	ra.PublisherClaim = "0000000000000000000000000000000000000000000000000000000000000000"
+	result = VerifyFragmentWithOptions(fragment, ra, na, Options{Offline: true})
+	if result.ResourcePresence != "fail" {
+		t.Errorf("Expected resource_presence to be 'fail' for mismatched RA, got '%s'", result.ResourcePresence)
+	}
+}
+
+func TestVerifyFragmentWithOptions_At(t *testing.T) {
+	fragment, ra, na := newSignedFixture(t, "")
+
+	// Evaluated after expiry the NA is rejected; evaluated at capture time it is accepted
+	later := time.Unix(na.Payload.Exp, 0).Add(time.Minute)
+	result := VerifyFragmentWithOptions(fragment, ra, na, Options{At: later})
+	if result.Failure == nil || result.Failure.Reason != "expired" {
+		t.Fatalf("Expected failure reason 'expired', got %+v", result.Failure)
+	}
+	if result.Failure.Details["current_time"] != later.Unix() {
+		t.Errorf("Expected current_time %d, got %v", later.Unix(), result.Failure.Details["current_time"])
+	}
+
+	earlier := time.Unix(na.Payload.Exp, 0).Add(-time.Minute)
+	result = VerifyFragmentWithOptions(fragment, ra, na, Options{At: earlier})
+	if !result.Verified {
+		t.Errorf("Expected verification at capture time to pass, got %+v", result.Failure)
+	}
+}
+
+func TestVerifyFragment_ContentType(t *testing.T) {
+	fragment, ra, na := newSignedFixture(t, "sha256")
+
+	// Any media type can be attested; the bytes are hashed as-is
+	image := []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR")
+	fragment.CanonicalContent = image
+	fragment.ContentType = "image/png"
+	ra.Hash = crypto.ComputeContentHashField(image)
+	ra.ContentType = "image/png"
+
+	result := VerifyFragment(fragment, ra, na)
+	if !result.Verified {
+		t.Fatalf("Expected verification to pass, got %+v", result.Failure)
+	}
+
+	// Parameters such as charset are ignored when comparing media types
+	fragment.ContentType = "IMAGE/PNG; foo=bar"
+	if result := VerifyFragment(fragment, ra, na); !result.Verified {
+		t.Errorf("Expected media type parameters to be ignored, got %+v", result.Failure)
+	}
+
+	fragment.ContentType = "text/html"
+	result = VerifyFragment(fragment, ra, na)
+	if result.ResourceIntegrity != "fail" {
+		t.Errorf("Expected resource_integrity to be 'fail', got '%s'", result.ResourceIntegrity)
+	}
+	if result.Failure == nil || result.Failure.Reason != "content_type_mismatch" {
+		t.Fatalf("Expected failure reason 'content_type_mismatch', got %+v", result.Failure)
+	}
+
+	// An RA without content_type attests HTML
+	fragment, ra, na = newSignedFixture(t, "sha256")
+	fragment.ContentType = "text/html; charset=utf-8"
+	if result := VerifyFragment(fragment, ra, na); !result.Verified {
+		t.Errorf("Expected default content type to match text/html, got %+v", result.Failure)
+	}
+}
+
+func TestVerifyFragment_ContentURLOrigin(t *testing.T) {
+	fragment, ra, na := newSignedFixture(t, "sha256")
+	fragment.ContentURL = strings.Replace(fragment.FragmentURL, "example.com", "cdn.example.net", 1)
+
+	result := VerifyFragment(fragment, ra, na)
+	if result.ResourcePresence != "fail" {
+		t.Errorf("Expected resource_presence to be 'fail', got '%s'", result.ResourcePresence)
+	}
+	if result.Failure == nil || result.Failure.Reason != "origin_mismatch" {
+		t.Fatalf("Expected failure reason 'origin_mismatch', got %+v", result.Failure)
+	}
+	if result.Failure.Details["content_url"] != fragment.ContentURL {
+		t.Errorf("Expected details to name the content URL, got %+v", result.Failure.Details)
+	}
+}
+
+func TestVerifyFragment_SupersededVersion(t *testing.T) {
+	fragment, ra, na := newSignedFixture(t, "sha256")
+
+	// The publisher edited the post twice; the fragment still embeds version 2
+	embeddedHash := ra.Hash
+	ra.Hash = crypto.ComputeContentHashField([]byte("<h1>Test Post</h1><p>Edited again</p>"))
+	ra.Version = 3
+	ra.PreviousHashes = []string{embeddedHash, crypto.ComputeContentHashField([]byte("<h1>Draft</h1>"))}
+
+	result := VerifyFragment(fragment, ra, na)
+	if !result.Verified {
+		t.Fatalf("Expected a superseded authentic copy to verify, got %+v", result.Failure)
+	}
+	if result.ResourceIntegrity != StatusPassStale {
+		t.Errorf("Expected resource_integrity to be %q, got %q", StatusPassStale, result.ResourceIntegrity)
+	}
+	if !result.Context.Stale || result.Context.Version != 3 || result.Context.EmbeddedVersion != 2 {
+		t.Errorf("Expected stale version 2 of 3, got %+v", result.Context)
+	}
+
+	result = VerifyFragmentWithOptions(fragment, ra, na, Options{RejectStale: true})
+	if result.Failure == nil || result.Failure.Reason != "superseded" {
+		t.Fatalf("Expected failure reason 'superseded', got %+v", result.Failure)
+	}
+	if result.Failure.Details["embedded_version"] != 2 || result.Failure.Details["current_version"] != 3 {
+		t.Errorf("Expected version details, got %+v", result.Failure.Details)
+	}
+
+	// Content matching no version is still a hash mismatch
+	fragment.CanonicalContent = []byte("<h1>Forged</h1>")
+	result = VerifyFragment(fragment, ra, na)
+	if result.Failure == nil || result.Failure.Reason != "hash_mismatch" {
+		t.Errorf("Expected failure reason 'hash_mismatch', got %+v", result.Failure)
+	}
+}
+
+func TestVerifyFragment_CurrentVersion(t *testing.T) {
+	fragment, ra, na := newSignedFixture(t, "sha256")
+	ra.Version = 2
+	ra.PreviousHashes = []string{crypto.ComputeContentHashField([]byte("<h1>Draft</h1>"))}
+
+	result := VerifyFragment(fragment, ra, na)
+	if !result.Verified || result.ResourceIntegrity != "pass" {
+		t.Fatalf("Expected the current version to verify, got %+v", result.Failure)
+	}
+	if result.Context.Stale || result.Context.Version != 2 {
+		t.Errorf("Expected current version 2, got %+v", result.Context)
+	}
+}
@@ -0,0 +1,47 @@
+package wire
+
+import (
+	"fmt"
+	"strings"
+)
+
+// CoPublishersAttribute is the fragment attribute listing the co-publishers of a co-authored resource
+const CoPublishersAttribute = "data-la-co-publishers"
+
+// FormatCoPublishers encodes co-publishers for CoPublishersAttribute: comma-separated entries, each a
+// publisher claim and a Namespace Attestation URL separated by a space
+func FormatCoPublishers(publishers []Publisher) string {
+	entries := make([]string, len(publishers))
+	for i, p := range publishers {
+		entries[i] = p.PublisherClaim + " " + p.NamespaceAttestationURL
+	}
+	return strings.Join(entries, ", ")
+}
+
+// ParseCoPublishers decodes a CoPublishersAttribute value; an empty value lists no co-publishers
+func ParseCoPublishers(value string) ([]Publisher, error) {
+	if strings.TrimSpace(value) == "" {
+		return nil, nil
+	}
+	var publishers []Publisher
+	for _, entry := range strings.Split(value, ",") {
+		fields := strings.Fields(entry)
+		if len(fields) != 2 {
+			return nil, fmt.Errorf("invalid co-publisher %q: want a publisher claim and a namespace attestation URL", strings.TrimSpace(entry))
+		}
+		publishers = append(publishers, Publisher{PublisherClaim: fields[0], NamespaceAttestationURL: fields[1]})
+	}
+	return publishers, nil
+}
+
+// ListedCoPublishers returns the RA's co-publishers as a fragment lists them, without their signatures
+func ListedCoPublishers(ra ResourceAttestation) []Publisher {
+	if len(ra.CoPublishers) == 0 {
+		return nil
+	}
+	publishers := make([]Publisher, len(ra.CoPublishers))
+	for i, cp := range ra.CoPublishers {
+		publishers[i] = Publisher{PublisherClaim: cp.PublisherClaim, NamespaceAttestationURL: cp.NamespaceAttestationURL}
+	}
+	return publishers
+}
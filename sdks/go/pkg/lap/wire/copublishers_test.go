@@ -0,0 +1,24 @@
+package wire
+
+import "testing"
+
+func TestParseCoPublishers(t *testing.T) {
+	publishers := []Publisher{
+		{PublisherClaim: "ac20898e", NamespaceAttestationURL: "https://bob.example/people/bob/_la_namespace.json"},
+		{PublisherClaim: "f1a2d3c4", NamespaceAttestationURL: "https://carol.example/_la_namespace.json"},
+	}
+	got, err := ParseCoPublishers(FormatCoPublishers(publishers))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 2 || got[0] != publishers[0] || got[1] != publishers[1] {
+		t.Errorf("ParseCoPublishers() = %+v, want %+v", got, publishers)
+	}
+
+	if got, err := ParseCoPublishers(""); err != nil || got != nil {
+		t.Errorf("ParseCoPublishers(\"\") = %+v, %v", got, err)
+	}
+	if _, err := ParseCoPublishers("ac20898e"); err == nil {
+		t.Error("Expected an error for an entry without a namespace attestation URL")
+	}
+}
@@ -0,0 +1,182 @@
+package wire
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// MaxAttestationSize is the largest attestation document, in bytes, accepted by the strict decoders.
+const MaxAttestationSize = 64 * 1024
+
+// Errors returned by the strict decoders. Each class maps to its own failure reason via MalformedReason.
+var (
+	ErrMalformedJSON    = errors.New("malformed JSON")
+	ErrDocumentTooLarge = errors.New("document too large")
+	ErrDuplicateKey     = errors.New("duplicate key")
+	ErrUnknownField     = errors.New("unknown field")
+	ErrTrailingData     = errors.New("trailing data after JSON document")
+	ErrUnexpectedType   = errors.New("unexpected value type")
+)
+
+// DecodeResourceAttestation strictly decodes a Resource Attestation document from r.
+func DecodeResourceAttestation(r io.Reader) (ResourceAttestation, error) {
+	var ra ResourceAttestation
+	data, err := readLimited(r)
+	if err != nil {
+		return ra, err
+	}
+	err = UnmarshalStrict(data, &ra)
+	return ra, err
+}
+
+// DecodeNamespaceAttestation strictly decodes a Namespace Attestation document from r.
+func DecodeNamespaceAttestation(r io.Reader) (NamespaceAttestation, error) {
+	var na NamespaceAttestation
+	data, err := readLimited(r)
+	if err != nil {
+		return na, err
+	}
+	err = UnmarshalStrict(data, &na)
+	return na, err
+}
+
+// UnmarshalStrict decodes a single JSON document into v, rejecting inputs that lenient parsers
+// may read differently: duplicate keys (including keys differing only in case), non-lowercase or
+// unknown keys, null values, values of the wrong type (such as a fractional exp), trailing data
+// and documents larger than MaxAttestationSize.
+func UnmarshalStrict(data []byte, v interface{}) error {
+	return unmarshalStrictLimit(data, v, MaxAttestationSize)
+}
+
+// unmarshalStrictLimit is UnmarshalStrict with a different size limit, for documents such as
+// statement indexes that hold several attestation-sized items
+func unmarshalStrictLimit(data []byte, v interface{}, limit int) error {
+	if len(data) > limit {
+		return fmt.Errorf("%w: %d bytes exceeds limit of %d", ErrDocumentTooLarge, len(data), limit)
+	}
+
+	// Walk the token stream first; encoding/json silently keeps the last of duplicate keys
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	if err := checkStrictValue(dec); err != nil {
+		return err
+	}
+	if _, err := dec.Token(); err != io.EOF {
+		return ErrTrailingData
+	}
+
+	dec = json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(v); err != nil {
+		var typeErr *json.UnmarshalTypeError
+		switch {
+		case errors.As(err, &typeErr):
+			return fmt.Errorf("%w: field %q cannot hold JSON %s", ErrUnexpectedType, typeErr.Field, typeErr.Value)
+		case strings.HasPrefix(err.Error(), "json: unknown field"):
+			return fmt.Errorf("%w: %s", ErrUnknownField, strings.TrimPrefix(err.Error(), "json: unknown field "))
+		default:
+			return fmt.Errorf("%w: %v", ErrMalformedJSON, err)
+		}
+	}
+	return nil
+}
+
+// MalformedReason returns the failure reason for an error from the strict decoders,
+// or "" when err did not come from strict decoding.
+func MalformedReason(err error) string {
+	switch {
+	case errors.Is(err, ErrDocumentTooLarge):
+		return "malformed_too_large"
+	case errors.Is(err, ErrDuplicateKey):
+		return "malformed_duplicate_key"
+	case errors.Is(err, ErrUnknownField):
+		return "malformed_unknown_field"
+	case errors.Is(err, ErrTrailingData):
+		return "malformed_trailing_data"
+	case errors.Is(err, ErrUnexpectedType):
+		return "malformed_unexpected_type"
+	case errors.Is(err, ErrMalformedJSON):
+		return "malformed_json"
+	default:
+		return ""
+	}
+}
+
+// readLimited reads at most MaxAttestationSize bytes, failing if r holds more.
+func readLimited(r io.Reader) ([]byte, error) {
+	data, err := io.ReadAll(io.LimitReader(r, MaxAttestationSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > MaxAttestationSize {
+		return nil, fmt.Errorf("%w: exceeds limit of %d bytes", ErrDocumentTooLarge, MaxAttestationSize)
+	}
+	return data, nil
+}
+
+// checkStrictValue consumes one JSON value from dec, checking object keys and rejecting nulls.
+func checkStrictValue(dec *json.Decoder) error {
+	tok, err := dec.Token()
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrMalformedJSON, err)
+	}
+
+	switch t := tok.(type) {
+	case nil:
+		return fmt.Errorf("%w: null value", ErrUnexpectedType)
+	case json.Delim:
+		switch t {
+		case '{':
+			var seen []string
+			for dec.More() {
+				keyTok, err := dec.Token()
+				if err != nil {
+					return fmt.Errorf("%w: %v", ErrMalformedJSON, err)
+				}
+				key, ok := keyTok.(string)
+				if !ok {
+					return fmt.Errorf("%w: non-string object key", ErrMalformedJSON)
+				}
+				// LAP keys are lowercase ASCII; encoding/json would match "HASH", or "ſig" with a
+				// U+017F long s, to "hash" and "sig" by Unicode case folding
+				if key != strings.ToLower(key) || !isASCII(key) {
+					return fmt.Errorf("%w: %q", ErrUnknownField, key)
+				}
+				for _, prev := range seen {
+					if strings.EqualFold(prev, key) {
+						return fmt.Errorf("%w: %q", ErrDuplicateKey, key)
+					}
+				}
+				seen = append(seen, key)
+				if err := checkStrictValue(dec); err != nil {
+					return err
+				}
+			}
+		case '[':
+			for dec.More() {
+				if err := checkStrictValue(dec); err != nil {
+					return err
+				}
+			}
+		}
+		// Consume the closing delimiter
+		if _, err := dec.Token(); err != nil {
+			return fmt.Errorf("%w: %v", ErrMalformedJSON, err)
+		}
+	}
+	return nil
+}
+
+// isASCII reports whether s holds only ASCII characters
+func isASCII(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] >= 0x80 {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,121 @@
+package wire
+
+import (
+	"encoding/base64"
+	"errors"
+	"strings"
+	"testing"
+)
+
+const validRAJSON = `{"fragment_url":"https://example.com/a","hash":"sha256:00","publisher_claim":"ab","namespace_attestation_url":"https://example.com/_la_namespace.json"}`
+
+const validNAJSON = `{"payload":{"namespace":"https://example.com/","exp":1754909400},"key":"ab","sig":"cd"}`
+
+func TestDecodeResourceAttestation_Valid(t *testing.T) {
+	ra, err := DecodeResourceAttestation(strings.NewReader(validRAJSON + "\n"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if ra.FragmentURL != "https://example.com/a" || ra.Hash != "sha256:00" {
+		t.Errorf("unexpected decode result: %+v", ra)
+	}
+}
+
+func TestDecodeNamespaceAttestation_Valid(t *testing.T) {
+	na, err := DecodeNamespaceAttestation(strings.NewReader(validNAJSON))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if na.Payload.Exp != 1754909400 || na.Key != "ab" {
+		t.Errorf("unexpected decode result: %+v", na)
+	}
+}
+
+func TestDecodeNamespaceAttestation_Rejections(t *testing.T) {
+	testCases := []struct {
+		name   string
+		input  string
+		want   error
+		reason string
+	}{
+		{"duplicate key", `{"payload":{"namespace":"https://example.com/","exp":1},"key":"ab","key":"ef","sig":"cd"}`, ErrDuplicateKey, "malformed_duplicate_key"},
+		{"nested duplicate key", `{"payload":{"namespace":"https://a/","namespace":"https://b/","exp":1},"key":"ab","sig":"cd"}`, ErrDuplicateKey, "malformed_duplicate_key"},
+		{"escaped duplicate key", `{"payload":{"namespace":"https://example.com/","exp":1},"key":"ab","\u006bey":"ef","sig":"cd"}`, ErrDuplicateKey, "malformed_duplicate_key"},
+		{"unknown field", `{"payload":{"namespace":"https://example.com/","exp":1},"key":"ab","sig":"cd","extra":true}`, ErrUnknownField, "malformed_unknown_field"},
+		{"case-folded field", `{"payload":{"namespace":"https://example.com/","exp":1},"KEY":"ab","sig":"cd"}`, ErrUnknownField, "malformed_unknown_field"},
+		{"unicode-folded field", `{"payload":{"namespace":"https://example.com/","exp":1},"key":"ab","ſig":"cd"}`, ErrUnknownField, "malformed_unknown_field"},
+		{"unicode-folded duplicate", `{"payload":{"namespace":"https://example.com/","exp":1},"key":"ab","sig":"cd","ſig":"ef"}`, ErrUnknownField, "malformed_unknown_field"},
+		{"escaped unicode-folded field", `{"payload":{"namespace":"https://example.com/","exp":1},"\u212aey":"ab","sig":"cd"}`, ErrUnknownField, "malformed_unknown_field"},
+		{"trailing data", validNAJSON + `{}`, ErrTrailingData, "malformed_trailing_data"},
+		{"trailing garbage", validNAJSON + ` xyz`, ErrTrailingData, "malformed_trailing_data"},
+		{"fractional exp", `{"payload":{"namespace":"https://example.com/","exp":1.5},"key":"ab","sig":"cd"}`, ErrUnexpectedType, "malformed_unexpected_type"},
+		{"string exp", `{"payload":{"namespace":"https://example.com/","exp":"1"},"key":"ab","sig":"cd"}`, ErrUnexpectedType, "malformed_unexpected_type"},
+		{"null exp", `{"payload":{"namespace":"https://example.com/","exp":null},"key":"ab","sig":"cd"}`, ErrUnexpectedType, "malformed_unexpected_type"},
+		{"top-level array", `[]`, ErrUnexpectedType, "malformed_unexpected_type"},
+		{"truncated", `{"payload":`, ErrMalformedJSON, "malformed_json"},
+		{"empty", ``, ErrMalformedJSON, "malformed_json"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := DecodeNamespaceAttestation(strings.NewReader(tc.input))
+			if !errors.Is(err, tc.want) {
+				t.Fatalf("expected %v, got %v", tc.want, err)
+			}
+			if reason := MalformedReason(err); reason != tc.reason {
+				t.Errorf("MalformedReason = %q, want %q", reason, tc.reason)
+			}
+		})
+	}
+}
+
+func TestDecodeResourceAttestation_TooLarge(t *testing.T) {
+	input := `{"fragment_url":"` + strings.Repeat("a", MaxAttestationSize) + `"}`
+	_, err := DecodeResourceAttestation(strings.NewReader(input))
+	if !errors.Is(err, ErrDocumentTooLarge) {
+		t.Fatalf("expected ErrDocumentTooLarge, got %v", err)
+	}
+	if reason := MalformedReason(err); reason != "malformed_too_large" {
+		t.Errorf("MalformedReason = %q, want malformed_too_large", reason)
+	}
+}
+
+func TestDecodeAttestationHeader_Strict(t *testing.T) {
+	dup := `{"fragment_url":"https://example.com/a","fragment_url":"https://evil.example/","hash":"sha256:00","publisher_claim":"ab","namespace_attestation_url":"https://example.com/_la_namespace.json"}`
+	_, err := DecodeAttestationHeader(base64.RawURLEncoding.EncodeToString([]byte(dup)))
+	if !errors.Is(err, ErrDuplicateKey) {
+		t.Errorf("expected ErrDuplicateKey, got %v", err)
+	}
+}
+
+func TestMalformedReason_OtherErrors(t *testing.T) {
+	if reason := MalformedReason(errors.New("connection refused")); reason != "" {
+		t.Errorf("expected empty reason for unrelated error, got %q", reason)
+	}
+}
+
+func TestDecodeStatementIndex(t *testing.T) {
+	statement := `{"payload":{"fragment_url":"https://example.com/a","hash":"sha256:00","type":"endorse","namespace_attestation_url":"https://other.example/_la_namespace.json","iat":1754909400},"key":"ab","sig":"cd"}`
+	index, err := DecodeStatementIndex(strings.NewReader(`{"statements":[` + statement + `]}`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(index.Statements) != 1 || index.Statements[0].Payload.Type != StatementEndorse {
+		t.Errorf("unexpected decode result: %+v", index)
+	}
+
+	// Each statement is decoded strictly
+	duplicate := strings.Replace(statement, `"key":"ab"`, `"key":"ab","key":"ef"`, 1)
+	if _, err := DecodeStatementIndex(strings.NewReader(`{"statements":[` + duplicate + `]}`)); !errors.Is(err, ErrDuplicateKey) {
+		t.Errorf("expected ErrDuplicateKey, got %v", err)
+	}
+	if _, err := DecodeStatementIndex(strings.NewReader(`{"statements":[],"extra":1}`)); !errors.Is(err, ErrUnknownField) {
+		t.Errorf("expected ErrUnknownField, got %v", err)
+	}
+}
+
+func TestStatementsLocation(t *testing.T) {
+	if got := StatementsLocation("https://example.com/people/bob/_la_namespace.json"); got != "https://example.com/people/bob/_la_statements.json" {
+		t.Errorf("unexpected location: %s", got)
+	}
+}
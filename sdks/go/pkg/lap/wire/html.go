@@ -0,0 +1,283 @@
+package wire
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// ParseFragmentHTML extracts the first v0.2 fragment, the <article> carrying data-la-fragment-url,
+// from HTML content. Content referenced by URL is only resolved into ContentURL, not fetched.
+func ParseFragmentHTML(htmlContent string) (*Fragment, error) {
+	needle := `data-la-fragment-url="`
+	idx := strings.Index(htmlContent, needle)
+	if idx < 0 {
+		return nil, fmt.Errorf("no fragment found with data-la-fragment-url attribute")
+	}
+
+	// Extract the fragment URL from the HTML
+	fragmentURLStart := idx + len(needle)
+	fragmentURLEnd := strings.Index(htmlContent[fragmentURLStart:], `"`)
+	if fragmentURLEnd < 0 {
+		return nil, fmt.Errorf("fragment structure malformed: incomplete data-la-fragment-url attribute")
+	}
+	fragmentURL := htmlContent[fragmentURLStart : fragmentURLStart+fragmentURLEnd]
+
+	// Find the start of the article element
+	start := strings.LastIndex(htmlContent[:idx], "<article")
+	if start < 0 {
+		return nil, fmt.Errorf("fragment structure malformed: no <article> tag found")
+	}
+
+	// Find the end of the article element
+	rest := htmlContent[start:]
+	depth := 0
+	i := 0
+	for i < len(rest) {
+		if rest[i] == '<' {
+			if strings.HasPrefix(rest[i:], "<article") {
+				depth++
+			} else if strings.HasPrefix(rest[i:], "</article") {
+				depth--
+				endTag := strings.Index(rest[i:], ">")
+				if endTag >= 0 {
+					i += endTag + 1
+				} else {
+					break
+				}
+				if depth == 0 {
+					return parseFragmentArticle(htmlContent[start:start+i], fragmentURL)
+				}
+				continue
+			}
+			end := strings.Index(rest[i:], ">")
+			if end >= 0 {
+				i += end + 1
+				continue
+			}
+			break
+		}
+		i++
+	}
+
+	return nil, fmt.Errorf("fragment structure malformed: incomplete <article> tag")
+}
+
+// parseFragmentArticle parses a fragment from its <article> element
+func parseFragmentArticle(articleHTML, fragmentURL string) (*Fragment, error) {
+	fragment := &Fragment{
+		Spec:        SpecV02,
+		FragmentURL: fragmentURL,
+	}
+
+	// A declared spec replaces the default; unknown versions are rejected before verification
+	if spec := attributeValue(articleHTML, SpecAttribute); spec != "" {
+		fragment.Spec = spec
+	}
+
+	fragment.PublisherClaim = attributeValue(articleHTML, "data-la-publisher-claim")
+	fragment.ResourceAttestationURL = attributeValue(articleHTML, "data-la-resource-attestation-url")
+	fragment.NamespaceAttestationURL = attributeValue(articleHTML, "data-la-namespace-attestation-url")
+
+	// Extract the co-publishers of a co-authored resource, if any
+	coPublishers, err := ParseCoPublishers(attributeValue(articleHTML, CoPublishersAttribute))
+	if err != nil {
+		return nil, err
+	}
+	fragment.CoPublishers = coPublishers
+
+	// Extract canonical content from href
+	if err := extractCanonicalContent(articleHTML, fragment); err != nil {
+		return nil, err
+	}
+
+	// Extract stapled attestations, if any
+	if err := extractStapledAttestations(articleHTML, fragment); err != nil {
+		return nil, err
+	}
+
+	// Validate required fields
+	if fragment.PublisherClaim == "" {
+		return nil, fmt.Errorf("missing data-la-publisher-claim")
+	}
+	if fragment.ResourceAttestationURL == "" {
+		return nil, fmt.Errorf("missing data-la-resource-attestation-url")
+	}
+	if fragment.NamespaceAttestationURL == "" {
+		return nil, fmt.Errorf("missing data-la-namespace-attestation-url")
+	}
+	if len(fragment.CanonicalContent) == 0 && len(fragment.EncryptedContent) == 0 && fragment.ContentURL == "" {
+		return nil, fmt.Errorf("missing canonical content in href")
+	}
+
+	return fragment, nil
+}
+
+// extractCanonicalContent reads the canonical <link> element. Its href either inlines the content
+// as a base64 data URL of any media type, or references the content bytes by URL, resolved against
+// the fragment URL; referenced content is fetched by the caller.
+func extractCanonicalContent(articleHTML string, fragment *Fragment) error {
+	idx := strings.Index(articleHTML, `rel="canonical"`)
+	if idx < 0 {
+		return nil
+	}
+	start := strings.LastIndex(articleHTML[:idx], "<link")
+	end := strings.Index(articleHTML[idx:], ">")
+	if start < 0 || end < 0 {
+		return fmt.Errorf("fragment structure malformed: incomplete canonical <link> element")
+	}
+	linkTag := articleHTML[start : idx+end]
+	fragment.ContentType = attributeValue(linkTag, "type")
+
+	href := attributeValue(linkTag, "href")
+	if href == "" {
+		return nil
+	}
+	if dataURL, ok := strings.CutPrefix(href, "data:"); ok {
+		meta, base64Content, ok := strings.Cut(dataURL, ",")
+		mediaType, isBase64 := strings.CutSuffix(meta, ";base64")
+		if !ok || !isBase64 {
+			return fmt.Errorf("canonical content must be a base64 data URL")
+		}
+		canonicalBytes, err := base64.StdEncoding.DecodeString(base64Content)
+		if err != nil {
+			return fmt.Errorf("failed to decode base64 content: %v", err)
+		}
+		// Sealed content keeps the plaintext media type from the type attribute
+		if mediaType == EncryptedContentType {
+			fragment.EncryptedContent = canonicalBytes
+			return nil
+		}
+		if mediaType != "" {
+			fragment.ContentType = mediaType
+		}
+		fragment.CanonicalContent = canonicalBytes
+		if strings.HasPrefix(fragment.ContentType, DefaultContentType) || fragment.ContentType == "" {
+			fragment.PreviewContent = string(canonicalBytes)
+		}
+		return nil
+	}
+
+	ref, err := url.Parse(href)
+	if err != nil {
+		return fmt.Errorf("invalid canonical content URL: %v", err)
+	}
+	base, err := url.Parse(fragment.FragmentURL)
+	if err != nil {
+		return fmt.Errorf("invalid fragment URL: %v", err)
+	}
+	fragment.ContentURL = base.ResolveReference(ref).String()
+	return nil
+}
+
+// extractStapledAttestations reads <script type="application/lap+json" data-la-stapled="..."> elements
+// into the fragment, keeping the exact JSON bytes. Repeated staples of one kind are rejected.
+func extractStapledAttestations(articleHTML string, fragment *Fragment) error {
+	rest := articleHTML
+	for {
+		idx := strings.Index(rest, `<script type="application/lap+json"`)
+		if idx < 0 {
+			return nil
+		}
+		rest = rest[idx:]
+		tagEnd := strings.Index(rest, ">")
+		if tagEnd < 0 {
+			return fmt.Errorf("fragment structure malformed: incomplete stapled attestation")
+		}
+		closeIdx := strings.Index(rest[tagEnd+1:], "</script>")
+		if closeIdx < 0 {
+			return fmt.Errorf("fragment structure malformed: unterminated stapled attestation")
+		}
+		openTag := rest[:tagEnd]
+		body := []byte(rest[tagEnd+1 : tagEnd+1+closeIdx])
+		rest = rest[tagEnd+1+closeIdx:]
+
+		var target *[]byte
+		switch attributeValue(openTag, "data-la-stapled") {
+		case "resource-attestation":
+			target = &fragment.StapledResourceAttestation
+		case "namespace-attestation":
+			target = &fragment.StapledNamespaceAttestation
+		default:
+			continue
+		}
+		if *target != nil {
+			return fmt.Errorf("fragment structure malformed: duplicate stapled attestation")
+		}
+		*target = body
+
+		if stapledAt, err := strconv.ParseInt(attributeValue(openTag, "data-la-stapled-at"), 10, 64); err == nil {
+			fragment.StapledAt = stapledAt
+		}
+	}
+}
+
+// ParseExcerptHTML extracts the first excerpt, a <blockquote data-la-excerpt-of="..."> quoting one
+// block of another resource, from HTML content
+func ParseExcerptHTML(htmlContent string) (*Excerpt, error) {
+	needle := `data-la-excerpt-of="`
+	idx := strings.Index(htmlContent, needle)
+	if idx < 0 {
+		return nil, fmt.Errorf("no excerpt found with data-la-excerpt-of attribute")
+	}
+	excerpt := &Excerpt{FragmentURL: attributeValue(htmlContent[idx:], "data-la-excerpt-of")}
+
+	// The proof attributes sit on the excerpt's canonical <link>, after the quoted preview
+	proofIdx := strings.Index(htmlContent[idx:], `data-la-block-proof="`)
+	if proofIdx < 0 {
+		return nil, fmt.Errorf("excerpt structure malformed: missing data-la-block-proof")
+	}
+	proofIdx += idx
+	start := strings.LastIndex(htmlContent[:proofIdx], "<link")
+	end := strings.Index(htmlContent[proofIdx:], ">")
+	if start < idx || end < 0 {
+		return nil, fmt.Errorf("excerpt structure malformed: incomplete canonical <link> element")
+	}
+	linkTag := htmlContent[start : proofIdx+end+1]
+
+	excerpt.PublisherClaim = attributeValue(linkTag, "data-la-publisher-claim")
+	excerpt.ResourceAttestationURL = attributeValue(linkTag, "data-la-resource-attestation-url")
+	excerpt.NamespaceAttestationURL = attributeValue(linkTag, "data-la-namespace-attestation-url")
+	if proof := attributeValue(linkTag, "data-la-block-proof"); proof != "" {
+		excerpt.Proof = strings.Split(proof, ",")
+	}
+	var err error
+	if excerpt.BlockIndex, err = strconv.Atoi(attributeValue(linkTag, "data-la-block-index")); err != nil {
+		return nil, fmt.Errorf("invalid data-la-block-index: %v", err)
+	}
+	if excerpt.BlockCount, err = strconv.Atoi(attributeValue(linkTag, "data-la-block-count")); err != nil {
+		return nil, fmt.Errorf("invalid data-la-block-count: %v", err)
+	}
+
+	// The quoted block is inlined like fragment content
+	block := &Fragment{FragmentURL: excerpt.FragmentURL}
+	if err := extractCanonicalContent(linkTag, block); err != nil {
+		return nil, err
+	}
+	if block.ContentURL != "" {
+		return nil, fmt.Errorf("excerpt content must be a base64 data URL")
+	}
+	excerpt.Content = block.CanonicalContent
+
+	if excerpt.FragmentURL == "" || excerpt.PublisherClaim == "" || excerpt.ResourceAttestationURL == "" || excerpt.NamespaceAttestationURL == "" {
+		return nil, fmt.Errorf("excerpt structure malformed: missing attestation attributes")
+	}
+	return excerpt, nil
+}
+
+// attributeValue returns the double-quoted value of the named attribute in an opening tag, verbatim.
+// v0.2 fragments are written by lapctl with plain double-quoted attributes; v0.1 uses htmlAttribute.
+func attributeValue(tag, name string) string {
+	idx := strings.Index(tag, name+`="`)
+	if idx < 0 {
+		return ""
+	}
+	start := idx + len(name) + 2
+	end := strings.Index(tag[start:], `"`)
+	if end < 0 {
+		return ""
+	}
+	return tag[start : start+end]
+}
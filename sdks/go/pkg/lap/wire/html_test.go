@@ -0,0 +1,65 @@
+package wire
+
+import (
+	"encoding/base64"
+	"testing"
+)
+
+func TestParseFragmentHTML(t *testing.T) {
+	content := "<p>Hello</p>"
+	page := `<html><body><article data-la-spec="v0.2" data-la-fragment-url="https://example.com/people/alice/posts/1"` +
+		` data-la-publisher-claim="ab12" data-la-resource-attestation-url="https://example.com/people/alice/posts/1/_la_resource.json"` +
+		` data-la-namespace-attestation-url="https://example.com/people/alice/_la_namespace.json">` +
+		`<link rel="canonical" type="text/html" href="data:text/html;base64,` + base64.StdEncoding.EncodeToString([]byte(content)) + `">` +
+		`<script type="application/lap+json" data-la-stapled="resource-attestation" data-la-stapled-at="1700000000">{}</script>` +
+		`<article><p>nested</p></article></article></body></html>`
+
+	fragment, err := ParseFragmentHTML(page)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fragment.Spec != SpecV02 || fragment.FragmentURL != "https://example.com/people/alice/posts/1" || fragment.PublisherClaim != "ab12" {
+		t.Errorf("Unexpected fragment %+v", fragment)
+	}
+	if string(fragment.CanonicalContent) != content || fragment.PreviewContent != content || fragment.ContentType != "text/html" {
+		t.Errorf("Unexpected canonical content %q (%s)", fragment.CanonicalContent, fragment.ContentType)
+	}
+	if string(fragment.StapledResourceAttestation) != "{}" || fragment.StapledAt != 1700000000 {
+		t.Errorf("Unexpected staple %q at %d", fragment.StapledResourceAttestation, fragment.StapledAt)
+	}
+
+	// Content referenced by URL is resolved against the fragment URL, not fetched
+	referenced := `<article data-la-fragment-url="https://example.com/people/alice/posts/1" data-la-publisher-claim="ab12"` +
+		` data-la-resource-attestation-url="ra" data-la-namespace-attestation-url="na"><link rel="canonical" href="body.html"></article>`
+	if fragment, err := ParseFragmentHTML(referenced); err != nil || fragment.ContentURL != "https://example.com/people/alice/posts/body.html" {
+		t.Errorf("Expected a resolved content URL, got %+v, %v", fragment, err)
+	}
+
+	for _, bad := range []string{
+		`<p>no fragment</p>`,
+		`<article data-la-fragment-url="https://example.com/a"><link rel="canonical" href="data:text/html;base64,PHA+">`,
+		`<article data-la-fragment-url="https://example.com/a" data-la-resource-attestation-url="ra" data-la-namespace-attestation-url="na"><link rel="canonical" href="data:text/html;base64,PHA+"></article>`,
+	} {
+		if _, err := ParseFragmentHTML(bad); err == nil {
+			t.Errorf("Expected %s to be rejected", bad)
+		}
+	}
+}
+
+func TestParseExcerptHTML(t *testing.T) {
+	page := `<blockquote data-la-excerpt-of="https://example.com/people/alice/posts/1"><p>Quoted</p>` +
+		`<link rel="canonical" href="data:text/html;base64,` + base64.StdEncoding.EncodeToString([]byte("<p>Quoted</p>")) + `"` +
+		` data-la-publisher-claim="ab12" data-la-resource-attestation-url="ra" data-la-namespace-attestation-url="na"` +
+		` data-la-block-index="1" data-la-block-count="3" data-la-block-proof="aa,bb"></blockquote>`
+
+	excerpt, err := ParseExcerptHTML(page)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(excerpt.Content) != "<p>Quoted</p>" || excerpt.BlockIndex != 1 || excerpt.BlockCount != 3 || len(excerpt.Proof) != 2 {
+		t.Errorf("Unexpected excerpt %+v", excerpt)
+	}
+	if _, err := ParseExcerptHTML(`<blockquote data-la-excerpt-of="https://example.com/a"></blockquote>`); err == nil {
+		t.Error("Expected an excerpt without a proof to be rejected")
+	}
+}
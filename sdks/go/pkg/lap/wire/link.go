@@ -0,0 +1,52 @@
+package wire
+
+import "strings"
+
+// LinkRelResourceAttestation is the Link header relation type that points at a resource's Resource Attestation.
+const LinkRelResourceAttestation = "lap-resource-attestation"
+
+// ResourceAttestationLink returns the target of the first link with relation LinkRelResourceAttestation
+// in the given Link header values, or "" when there is none. The target is returned unresolved.
+func ResourceAttestationLink(values []string) string {
+	for _, value := range values {
+		for rest := value; ; {
+			start := strings.Index(rest, "<")
+			if start < 0 {
+				break
+			}
+			end := strings.Index(rest[start:], ">")
+			if end < 0 {
+				break
+			}
+			target := rest[start+1 : start+end]
+			rest = rest[start+end+1:]
+
+			// Parameters run up to the next link
+			params := rest
+			if next := strings.Index(rest, "<"); next >= 0 {
+				params = rest[:next]
+			}
+			if hasLinkRel(params, LinkRelResourceAttestation) {
+				return strings.TrimSpace(target)
+			}
+		}
+	}
+	return ""
+}
+
+// hasLinkRel reports whether the link parameters include rel, which may be one of several space-separated relations
+func hasLinkRel(params, rel string) bool {
+	for _, param := range strings.Split(params, ";") {
+		name, value, ok := strings.Cut(strings.TrimSpace(param), "=")
+		if !ok || !strings.EqualFold(strings.TrimSpace(name), "rel") {
+			continue
+		}
+		value = strings.Trim(strings.TrimSpace(strings.TrimRight(strings.TrimSpace(value), ",")), `"`)
+		for _, r := range strings.Fields(value) {
+			if strings.EqualFold(r, rel) {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,25 @@
+package wire
+
+import "testing"
+
+func TestResourceAttestationLink(t *testing.T) {
+	tests := []struct {
+		name   string
+		values []string
+		want   string
+	}{
+		{"single", []string{`</img/cat.png._la_resource.json>; rel="lap-resource-attestation"`}, "/img/cat.png._la_resource.json"},
+		{"unquoted", []string{`<https://example.com/a/_la_resource.json>; rel=lap-resource-attestation`}, "https://example.com/a/_la_resource.json"},
+		{"among others", []string{`</style.css>; rel=preload, </ra.json>; rel="alternate lap-resource-attestation"`}, "/ra.json"},
+		{"second header", []string{`</style.css>; rel=preload`, `</ra.json>; rel="lap-resource-attestation"`}, "/ra.json"},
+		{"none", []string{`</style.css>; rel=preload`}, ""},
+		{"empty", nil, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ResourceAttestationLink(tt.values); got != tt.want {
+				t.Errorf("ResourceAttestationLink() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,35 @@
+package wire
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// MerkleProofs is the proof sidecar published next to a Resource Attestation whose hash is a
+// Merkle root. It lets a reader verify chunk-aligned byte ranges without the whole content.
+type MerkleProofs struct {
+	Hash      string     `json:"hash"`       // The RA hash field, "merkle-sha256:<root>"
+	ChunkSize int64      `json:"chunk_size"` // Leaf chunk size in bytes
+	Size      int64      `json:"size"`       // Length of the attested content in bytes
+	Proofs    [][]string `json:"proofs"`     // Per chunk, hex sibling hashes from the leaf up to the root
+}
+
+// DecodeMerkleProofs decodes a proof sidecar from r. Every proof is checked against the RA hash
+// before use, so the sidecar is not held to MaxAttestationSize, but unknown fields are rejected.
+func DecodeMerkleProofs(r io.Reader) (MerkleProofs, error) {
+	var proofs MerkleProofs
+	dec := json.NewDecoder(r)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&proofs); err != nil {
+		return proofs, fmt.Errorf("%w: %v", ErrMalformedJSON, err)
+	}
+	return proofs, nil
+}
+
+// MerkleProofsLocation returns the conventional location of the proof sidecar for the Resource
+// Attestation at raLocation, a URL or file path: "_la_resource.json" becomes "_la_resource.proofs.json".
+func MerkleProofsLocation(raLocation string) string {
+	return strings.TrimSuffix(raLocation, ".json") + ".proofs.json"
+}
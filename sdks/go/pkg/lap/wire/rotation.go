@@ -0,0 +1,83 @@
+package wire
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/stonebraker/lap/sdks/go/pkg/lap/canonical"
+)
+
+// KeyRotationsFileName is the conventional name of a namespace's key rotation index, next to its
+// Namespace Attestation
+const KeyRotationsFileName = "_la_rotations.json"
+
+// KeyRotation announces that a namespace moved to a new key. It is signed over its canonical payload
+// by the key being replaced, so a reader who trusted the old key can follow the change.
+type KeyRotation struct {
+	Payload KeyRotationPayload `json:"payload"`
+	Alg     string             `json:"alg,omitempty"` // Signature algorithm of the replaced key; empty means "bip340"
+	Key     string             `json:"key"`           // Replaced public key hex
+	Sig     string             `json:"sig"`
+}
+
+// KeyRotationPayload is the signed part of a KeyRotation
+type KeyRotationPayload struct {
+	Namespace string `json:"namespace"`         // Namespace changing keys, as in its Namespace Attestation
+	NewAlg    string `json:"new_alg,omitempty"` // Signature algorithm of the new key; empty means "bip340"
+	NewKey    string `json:"new_key"`           // New public key hex
+	Iat       int64  `json:"iat"`               // Issued at, seconds since epoch
+}
+
+// KeyRotationIndex lists a namespace's key rotations, oldest first, by convention at KeyRotationsLocation
+type KeyRotationIndex struct {
+	Rotations []KeyRotation `json:"rotations"`
+}
+
+// SignedPayload returns the canonical payload the replaced key signs, including a non-default alg
+func (r KeyRotation) SignedPayload() canonical.KeyRotationPayloadCanonical {
+	payload := r.Payload.ToCanonical()
+	payload.Alg = signedAlg(r.Alg)
+	return payload
+}
+
+// ToCanonical transforms wire.KeyRotationPayload into canonical.KeyRotationPayloadCanonical for deterministic serialization.
+func (p KeyRotationPayload) ToCanonical() canonical.KeyRotationPayloadCanonical {
+	return canonical.KeyRotationPayloadCanonical{
+		Namespace: p.Namespace,
+		NewAlg:    p.NewAlg,
+		NewKey:    p.NewKey,
+		Iat:       p.Iat,
+	}
+}
+
+// DecodeKeyRotationIndex decodes a key rotation index from r, decoding each rotation strictly.
+func DecodeKeyRotationIndex(r io.Reader) (KeyRotationIndex, error) {
+	var index KeyRotationIndex
+	data, err := readLimited(r)
+	if err != nil {
+		return index, err
+	}
+
+	var raw struct {
+		Rotations []json.RawMessage `json:"rotations"`
+	}
+	if err := UnmarshalStrict(data, &raw); err != nil {
+		return index, err
+	}
+	for i, item := range raw.Rotations {
+		var rotation KeyRotation
+		if err := UnmarshalStrict(item, &rotation); err != nil {
+			return index, fmt.Errorf("rotation %d: %w", i, err)
+		}
+		index.Rotations = append(index.Rotations, rotation)
+	}
+	return index, nil
+}
+
+// KeyRotationsLocation returns the conventional key rotation index location for the namespace whose
+// Namespace Attestation is at naLocation, a URL or file path: the KeyRotationsFileName next to it.
+func KeyRotationsLocation(naLocation string) string {
+	return naLocation[:strings.LastIndex(naLocation, "/")+1] + KeyRotationsFileName
+}
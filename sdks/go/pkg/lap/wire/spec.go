@@ -0,0 +1,36 @@
+package wire
+
+// Protocol versions a fragment may declare
+const (
+	SpecV01 = "v0.1"
+	SpecV02 = "v0.2"
+)
+
+// SpecAttribute declares the protocol version of a v0.2 fragment on its <article> element.
+// LegacySpecAttribute is the v0.1 equivalent, whose value is a spec URL.
+const (
+	SpecAttribute       = "data-la-spec"
+	LegacySpecAttribute = "data-lap-spec"
+)
+
+// specAliases maps declared spec values to the versions they identify
+var specAliases = map[string]string{
+	SpecV01:                     SpecV01,
+	"https://lap.dev/spec/v0-1": SpecV01,
+	SpecV02:                     SpecV02,
+}
+
+// NormalizeSpec returns the protocol version a declared spec value identifies. A fragment that
+// declares no spec is read as v0.2; unknown values are reported as unsupported.
+func NormalizeSpec(declared string) (string, bool) {
+	if declared == "" {
+		return SpecV02, true
+	}
+	spec, ok := specAliases[declared]
+	return spec, ok
+}
+
+// SupportedSpecs returns the protocol versions verifiers can dispatch to, current first.
+func SupportedSpecs() []string {
+	return []string{SpecV02, SpecV01}
+}
@@ -0,0 +1,104 @@
+package wire
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/stonebraker/lap/sdks/go/pkg/lap/canonical"
+)
+
+// Statement types recognized by verifiers
+const (
+	StatementEndorse = "endorse" // The issuer vouches for the resource
+	StatementReshare = "reshare" // The issuer republishes or links to the resource
+	StatementLabel   = "label"   // The issuer classifies the resource; the body holds the label
+	StatementDispute = "dispute" // The issuer contests the resource; the body holds the reason
+)
+
+// StatementTypes lists the recognized statement types
+var StatementTypes = []string{StatementEndorse, StatementReshare, StatementLabel, StatementDispute}
+
+// MaxStatementIndexSize bounds a statement index; each statement in it is also held to MaxAttestationSize
+const MaxStatementIndexSize = 1 << 20
+
+// StatementsFileName is the conventional name of an issuer's statement index, next to its Namespace Attestation
+const StatementsFileName = "_la_statements.json"
+
+// Statement is a signed claim by a third party about a resource, such as an endorsement or a dispute.
+// Like a Namespace Attestation it is signed over its canonical payload, with the key of the issuer's
+// Namespace Attestation named in the payload.
+type Statement struct {
+	Payload StatementPayload `json:"payload"`
+	Alg     string           `json:"alg,omitempty"` // Signature algorithm; empty means "bip340"
+	Key     string           `json:"key"`           // Issuer's public key hex
+	Sig     string           `json:"sig"`
+}
+
+// StatementPayload is the signed part of a Statement
+type StatementPayload struct {
+	FragmentURL             string `json:"fragment_url"`              // Subject resource
+	Hash                    string `json:"hash"`                      // Subject RA hash the statement was made about
+	Type                    string `json:"type"`                      // One of StatementTypes
+	Body                    string `json:"body,omitempty"`            // Free text, e.g. the label or the reason for a dispute
+	NamespaceAttestationURL string `json:"namespace_attestation_url"` // Issuer's Namespace Attestation
+	Iat                     int64  `json:"iat"`                       // Issued at, seconds since epoch
+}
+
+// StatementIndex lists the statements an issuer publishes, by convention at StatementsLocation
+type StatementIndex struct {
+	Statements []Statement `json:"statements"`
+}
+
+// SignedPayload returns the canonical payload the statement's key signs, including a non-default alg
+func (st Statement) SignedPayload() canonical.StatementPayloadCanonical {
+	payload := st.Payload.ToCanonical()
+	payload.Alg = signedAlg(st.Alg)
+	return payload
+}
+
+// ToCanonical transforms wire.StatementPayload into canonical.StatementPayloadCanonical for deterministic serialization.
+func (p StatementPayload) ToCanonical() canonical.StatementPayloadCanonical {
+	return canonical.StatementPayloadCanonical{
+		FragmentURL:             p.FragmentURL,
+		Hash:                    p.Hash,
+		Type:                    p.Type,
+		Body:                    p.Body,
+		NamespaceAttestationURL: p.NamespaceAttestationURL,
+		Iat:                     p.Iat,
+	}
+}
+
+// DecodeStatementIndex decodes a statement index from r, decoding each statement strictly.
+func DecodeStatementIndex(r io.Reader) (StatementIndex, error) {
+	var index StatementIndex
+	data, err := io.ReadAll(io.LimitReader(r, MaxStatementIndexSize+1))
+	if err != nil {
+		return index, err
+	}
+	if len(data) > MaxStatementIndexSize {
+		return index, fmt.Errorf("%w: exceeds limit of %d bytes", ErrDocumentTooLarge, MaxStatementIndexSize)
+	}
+
+	var raw struct {
+		Statements []json.RawMessage `json:"statements"`
+	}
+	if err := unmarshalStrictLimit(data, &raw, MaxStatementIndexSize); err != nil {
+		return index, err
+	}
+	for i, item := range raw.Statements {
+		var st Statement
+		if err := UnmarshalStrict(item, &st); err != nil {
+			return index, fmt.Errorf("statement %d: %w", i, err)
+		}
+		index.Statements = append(index.Statements, st)
+	}
+	return index, nil
+}
+
+// StatementsLocation returns the conventional statement index location for the issuer whose
+// Namespace Attestation is at naLocation, a URL or file path: the StatementsFileName next to it.
+func StatementsLocation(naLocation string) string {
+	return naLocation[:strings.LastIndex(naLocation, "/")+1] + StatementsFileName
+}
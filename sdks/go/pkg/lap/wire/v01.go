@@ -0,0 +1,312 @@
+package wire
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"html"
+	"io"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/stonebraker/lap/sdks/go/pkg/lap/canonical"
+)
+
+// FragmentV01 is an archived v0.1 fragment: the canonical bytes and the Resource Attestation
+// stapled next to them. v0.1 fragments name no publisher; the Namespace Attestation is discovered
+// from the fragment URL.
+type FragmentV01 struct {
+	Spec                       string                 `json:"spec"` // Declared data-lap-spec value
+	URL                        string                 `json:"url"`  // data-lap-url
+	CanonicalContent           []byte                 `json:"canonical_content"`
+	ContentType                string                 `json:"content_type,omitempty"`
+	DataHash                   string                 `json:"data_hash,omitempty"` // data-hash of the bytes link, if any
+	StapledResourceAttestation ResourceAttestationV01 `json:"stapled_resource_attestation"`
+}
+
+// ResourceAttestationV01 is a v0.1 Resource Attestation, signed by a per-resource key
+type ResourceAttestationV01 struct {
+	Payload     ResourcePayloadV01 `json:"payload"`
+	ResourceKey string             `json:"resource_key"` // X-only public key of the resource key
+	Sig         string             `json:"sig"`          // BIP-340 signature over SHA-256 of the canonical payload
+}
+
+// ResourcePayloadV01 is the signed payload of a v0.1 Resource Attestation
+type ResourcePayloadV01 struct {
+	URL            string `json:"url"`
+	AttestationURL string `json:"attestation_url"`
+	Hash           string `json:"hash"` // "sha256:..."
+	ETag           string `json:"etag"`
+	IAT            int64  `json:"iat"`
+	Exp            int64  `json:"exp"`
+	KID            string `json:"kid"`
+}
+
+// NamespaceAttestationV01 is a v0.1 Namespace Attestation, which may assert several namespaces
+type NamespaceAttestationV01 struct {
+	Payload      NamespacePayloadV01 `json:"payload"`
+	PublisherKey string              `json:"publisher_key"` // X-only public key
+	Sig          string              `json:"sig"`
+}
+
+// NamespacePayloadV01 is the signed payload of a v0.1 Namespace Attestation
+type NamespacePayloadV01 struct {
+	Namespace       []string `json:"namespace"` // Canonical namespace prefixes, sorted and unique
+	AttestationPath string   `json:"attestation_path"`
+	IAT             int64    `json:"iat"`
+	Exp             int64    `json:"exp"`
+	KID             string   `json:"kid,omitempty"`
+}
+
+// ToCanonical transforms a v0.1 resource payload for deterministic serialization.
+func (p ResourcePayloadV01) ToCanonical() canonical.ResourcePayloadV01Canonical {
+	return canonical.ResourcePayloadV01Canonical{
+		URL:            p.URL,
+		AttestationURL: p.AttestationURL,
+		Hash:           p.Hash,
+		ETag:           p.ETag,
+		IAT:            p.IAT,
+		Exp:            p.Exp,
+		KID:            p.KID,
+	}
+}
+
+// ToCanonical transforms a v0.1 namespace payload for deterministic serialization.
+func (p NamespacePayloadV01) ToCanonical() canonical.NamespacePayloadV01Canonical {
+	return canonical.NamespacePayloadV01Canonical{
+		Namespace:       p.Namespace,
+		AttestationPath: p.AttestationPath,
+		IAT:             p.IAT,
+		Exp:             p.Exp,
+		KID:             p.KID,
+	}
+}
+
+// DecodeResourceAttestationV01 strictly decodes a v0.1 Resource Attestation document from r.
+func DecodeResourceAttestationV01(r io.Reader) (ResourceAttestationV01, error) {
+	var ra ResourceAttestationV01
+	data, err := readLimited(r)
+	if err != nil {
+		return ra, err
+	}
+	err = UnmarshalStrict(data, &ra)
+	return ra, err
+}
+
+// DecodeNamespaceAttestationV01 strictly decodes a v0.1 Namespace Attestation document from r.
+func DecodeNamespaceAttestationV01(r io.Reader) (NamespaceAttestationV01, error) {
+	var na NamespaceAttestationV01
+	data, err := readLimited(r)
+	if err != nil {
+		return na, err
+	}
+	err = UnmarshalStrict(data, &na)
+	return na, err
+}
+
+// ResourceAttestationURLV01 returns the canonical v0.1 attestation URL for a resource: the resource
+// URL without a trailing /index.html or /index.json, followed by /_la_resource.json.
+func ResourceAttestationURLV01(resourceURL string) string {
+	u, err := url.Parse(resourceURL)
+	if err != nil {
+		return ""
+	}
+	u.Fragment = ""
+	u.RawQuery = ""
+	path := strings.TrimSuffix(strings.TrimSuffix(u.Path, "/index.html"), "/index.json")
+	u.Path = strings.TrimSuffix(path, "/") + "/_la_resource.json"
+	return u.String()
+}
+
+// NamespaceAttestationCandidatesV01 returns where a v0.1 Namespace Attestation covering the resource
+// may be served: _la_namespace.json in each ancestor directory of the resource URL, nearest first.
+func NamespaceAttestationCandidatesV01(resourceURL string) []string {
+	u, err := url.Parse(resourceURL)
+	if err != nil {
+		return nil
+	}
+	u.Fragment = ""
+	u.RawQuery = ""
+	var candidates []string
+	path := strings.TrimSuffix(u.Path, "/")
+	for {
+		idx := strings.LastIndex(path, "/")
+		if idx < 0 {
+			break
+		}
+		path = path[:idx]
+		u.Path = path + "/_la_namespace.json"
+		candidates = append(candidates, u.String())
+	}
+	return candidates
+}
+
+// FindFragmentV01 returns the first <article> in an HTML document whose data-lap-spec attribute
+// declares a version that normalizes to v0.1. Text that merely mentions the attribute, and articles
+// declaring another version, are passed over.
+func FindFragmentV01(htmlContent string) (string, bool) {
+	for offset := 0; ; {
+		start := strings.Index(htmlContent[offset:], "<article")
+		if start < 0 {
+			return "", false
+		}
+		start += offset
+		tagEnd := strings.Index(htmlContent[start:], ">")
+		if tagEnd < 0 {
+			return "", false
+		}
+		offset = start + tagEnd
+
+		declared := htmlAttribute(htmlContent[start:offset], LegacySpecAttribute)
+		if spec, ok := NormalizeSpec(declared); declared == "" || !ok || spec != SpecV01 {
+			continue
+		}
+		end := strings.Index(htmlContent[start:], "</article>")
+		if end < 0 {
+			return "", false
+		}
+		return htmlContent[start : start+end+len("</article>")], true
+	}
+}
+
+// ParseFragmentV01 parses a v0.1 fragment from its <article> element. The article's selectors for
+// the preview, the stapled attestation and the canonical bytes must all resolve; the attestation
+// is read from data-lap-* attributes ("div" format) or from embedded JSON ("script" format).
+func ParseFragmentV01(articleHTML string) (FragmentV01, error) {
+	var fragment FragmentV01
+	end := strings.Index(articleHTML, ">")
+	if !strings.HasPrefix(articleHTML, "<article") || end < 0 {
+		return fragment, fmt.Errorf("fragment structure malformed: no <article> tag found")
+	}
+	article := articleHTML[:end]
+
+	fragment.Spec = htmlAttribute(article, LegacySpecAttribute)
+	fragment.URL = htmlAttribute(article, "data-lap-url")
+	if profile := htmlAttribute(article, "data-lap-profile"); profile != "fragment" {
+		return fragment, fmt.Errorf("unsupported v0.1 profile: %q", profile)
+	}
+	if format := htmlAttribute(article, "data-lap-bytes-format"); format != "link-data" {
+		return fragment, fmt.Errorf("unsupported v0.1 bytes format: %q", format)
+	}
+	if fragment.URL == "" {
+		return fragment, fmt.Errorf("missing data-lap-url")
+	}
+
+	// Resolve the three selectors
+	if _, _, err := elementByID(articleHTML, htmlAttribute(article, "data-lap-preview")); err != nil {
+		return fragment, fmt.Errorf("preview: %w", err)
+	}
+	bytesTag, _, err := elementByID(articleHTML, htmlAttribute(article, "data-lap-bytes"))
+	if err != nil {
+		return fragment, fmt.Errorf("bytes: %w", err)
+	}
+	attestationTag, attestationBody, err := elementByID(articleHTML, htmlAttribute(article, "data-lap-attestation"))
+	if err != nil {
+		return fragment, fmt.Errorf("attestation: %w", err)
+	}
+
+	// Canonical bytes from the data URL
+	dataURL, ok := strings.CutPrefix(htmlAttribute(bytesTag, "href"), "data:")
+	meta, encoded, found := strings.Cut(dataURL, ",")
+	if !ok || !found || !strings.HasSuffix(meta, ";base64") {
+		return fragment, fmt.Errorf("canonical bytes must be a base64 data URL")
+	}
+	if fragment.CanonicalContent, err = base64.StdEncoding.DecodeString(encoded); err != nil {
+		return fragment, fmt.Errorf("failed to decode base64 content: %v", err)
+	}
+	fragment.ContentType = htmlAttribute(bytesTag, "type")
+	fragment.DataHash = htmlAttribute(bytesTag, "data-hash")
+
+	// Stapled attestation
+	switch format := htmlAttribute(article, "data-lap-attestation-format"); format {
+	case "script":
+		fragment.StapledResourceAttestation, err = DecodeResourceAttestationV01(bytes.NewReader(bytes.TrimSpace([]byte(attestationBody))))
+		if err != nil {
+			return fragment, fmt.Errorf("stapled attestation: %w", err)
+		}
+	case "div":
+		fragment.StapledResourceAttestation, err = parseAttestationDivV01(attestationTag, attestationBody)
+		if err != nil {
+			return fragment, fmt.Errorf("stapled attestation: %w", err)
+		}
+	default:
+		return fragment, fmt.Errorf("unsupported v0.1 attestation format: %q", format)
+	}
+	return fragment, nil
+}
+
+// parseAttestationDivV01 reads an attestation spread over data-lap-* attributes: the key and
+// signature on the attestation <div>, the payload on its nested lap-payload <div>
+func parseAttestationDivV01(tag, body string) (ResourceAttestationV01, error) {
+	ra := ResourceAttestationV01{
+		ResourceKey: htmlAttribute(tag, "data-lap-resource-key"),
+		Sig:         htmlAttribute(tag, "data-lap-sig"),
+	}
+	idx := strings.Index(body, `class="lap-payload"`)
+	if idx < 0 {
+		return ra, fmt.Errorf("missing lap-payload element")
+	}
+	start := strings.LastIndex(body[:idx], "<")
+	end := strings.Index(body[idx:], ">")
+	if start < 0 || end < 0 {
+		return ra, fmt.Errorf("malformed lap-payload element")
+	}
+	payload := body[start : idx+end]
+
+	var err error
+	ra.Payload = ResourcePayloadV01{
+		URL:            htmlAttribute(payload, "data-lap-url"),
+		AttestationURL: htmlAttribute(payload, "data-lap-attestation-url"),
+		Hash:           htmlAttribute(payload, "data-lap-hash"),
+		ETag:           htmlAttribute(payload, "data-lap-etag"),
+		KID:            htmlAttribute(payload, "data-lap-kid"),
+	}
+	if ra.Payload.IAT, err = strconv.ParseInt(htmlAttribute(payload, "data-lap-iat"), 10, 64); err != nil {
+		return ra, fmt.Errorf("invalid data-lap-iat: %v", err)
+	}
+	if ra.Payload.Exp, err = strconv.ParseInt(htmlAttribute(payload, "data-lap-exp"), 10, 64); err != nil {
+		return ra, fmt.Errorf("invalid data-lap-exp: %v", err)
+	}
+	return ra, nil
+}
+
+// elementByID finds the element a "#id" selector names and returns its opening tag and the markup up
+// to its closing tag
+func elementByID(document, selector string) (string, string, error) {
+	id, ok := strings.CutPrefix(selector, "#")
+	if !ok || id == "" {
+		return "", "", fmt.Errorf("missing or unsupported selector %q", selector)
+	}
+	loc := regexp.MustCompile(`\sid=["']` + regexp.QuoteMeta(id) + `["']`).FindStringIndex(document)
+	if loc == nil {
+		return "", "", fmt.Errorf("no element matches %s", selector)
+	}
+	start := strings.LastIndex(document[:loc[0]], "<")
+	end := strings.Index(document[loc[1]:], ">")
+	if start < 0 || end < 0 {
+		return "", "", fmt.Errorf("malformed element %s", selector)
+	}
+	tag := document[start : loc[1]+end]
+	rest := document[loc[1]+end+1:]
+
+	name := tag[1:]
+	if i := strings.IndexAny(name, " \t\r\n"); i >= 0 {
+		name = name[:i]
+	}
+	body := rest
+	if close := strings.Index(rest, "</"+name); close >= 0 {
+		body = rest[:close]
+	}
+	return tag, body, nil
+}
+
+// htmlAttribute returns the unescaped value of a single- or double-quoted attribute of tag
+func htmlAttribute(tag, name string) string {
+	m := regexp.MustCompile(`\s` + regexp.QuoteMeta(name) + `=(?:"([^"]*)"|'([^']*)')`).FindStringSubmatch(tag)
+	if m == nil {
+		return ""
+	}
+	return html.UnescapeString(m[1] + m[2])
+}
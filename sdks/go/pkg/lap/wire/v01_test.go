@@ -0,0 +1,170 @@
+package wire
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const v01Script = `<article
+    id="msg-123"
+    data-lap-spec="https://lap.dev/spec/v0-1"
+    data-lap-profile="fragment"
+    data-lap-attestation-format="script"
+    data-lap-bytes-format="link-data"
+    data-lap-url="https://example.com/people/alice/messages/123"
+    data-lap-preview="#msg-123-preview"
+    data-lap-attestation="#msg-123-attestation"
+    data-lap-bytes="#msg-123-bytes"
+>
+    <script id="msg-123-attestation" type="application/lap+json" class="lap-attestation">
+        {
+            "payload": {
+                "url": "https://example.com/people/alice/messages/123",
+                "attestation_url": "https://example.com/people/alice/messages/123/_la_resource.json",
+                "hash": "sha256:7b0c0d2f3a4b5c6d7e8f90112233445566778899aabbccddeeff001122334455",
+                "etag": "W/\"123-abcde\"",
+                "iat": 1754908800,
+                "exp": 1754909400,
+                "kid": "resource-key-2025-08-12"
+            },
+            "resource_key": "aa11bb22cc33dd44ee55ff6600112233445566778899aabbccddeeff00112233",
+            "sig": "bd7a51fe"
+        }
+    </script>
+    <div id="msg-123-preview" class="preview">Hello, my name is Alice.</div>
+    <link
+        id="msg-123-bytes"
+        rel="alternate"
+        type="text/html; charset=utf-8"
+        class="lap-bytes"
+        data-hash="sha256:7b0c0d2f3a4b5c6d7e8f90112233445566778899aabbccddeeff001122334455"
+        href="data:text/html;base64,PHNwYW4gaWQ9Im1zZy0xMjMiPkhlbGxvLCBteSBuYW1lIGlzIEFsaWNlLjwvc3Bhbj4="
+    />
+</article>`
+
+const v01Div = `<article
+    id="lap-article-msg-123"
+    data-lap-spec="https://lap.dev/spec/v0-1"
+    data-lap-profile="fragment"
+    data-lap-attestation-format="div"
+    data-lap-bytes-format="link-data"
+    data-lap-url="https://example.com/people/alice/messages/123"
+    data-lap-preview="#lap-preview-msg-123"
+    data-lap-attestation="#lap-attestation-msg-123"
+    data-lap-bytes="#lap-bytes-msg-123"
+>
+    <div id="lap-preview-msg-123" class="lap-preview">Hello, my name is Alice.</div>
+    <link
+        id="lap-bytes-msg-123"
+        rel="alternate"
+        type="text/html; charset=utf-8"
+        class="lap-bytes"
+        data-hash="sha256:7b0c0d2f3a4b5c6d7e8f90112233445566778899aabbccddeeff001122334455"
+        href="data:text/html;base64,PHNwYW4gaWQ9Im1zZy0xMjMiPkhlbGxvLCBteSBuYW1lIGlzIEFsaWNlLjwvc3Bhbj4="
+    />
+    <div
+        id="lap-attestation-msg-123"
+        class="lap-attestation"
+        data-lap-resource-key="aa11bb22cc33dd44ee55ff6600112233445566778899aabbccddeeff00112233"
+        data-lap-sig="bd7a51fe"
+    >
+        <div
+            class="lap-payload"
+            data-lap-url="https://example.com/people/alice/messages/123"
+            data-lap-attestation-url="https://example.com/people/alice/messages/123/_la_resource.json"
+            data-lap-hash="sha256:7b0c0d2f3a4b5c6d7e8f90112233445566778899aabbccddeeff001122334455"
+            data-lap-etag='W/"123-abcde"'
+            data-lap-iat="1754908800"
+            data-lap-exp="1754909400"
+            data-lap-kid="resource-key-2025-08-12"
+        ></div>
+    </div>
+</article>`
+
+func TestParseFragmentV01(t *testing.T) {
+	want := ResourceAttestationV01{
+		Payload: ResourcePayloadV01{
+			URL:            "https://example.com/people/alice/messages/123",
+			AttestationURL: "https://example.com/people/alice/messages/123/_la_resource.json",
+			Hash:           "sha256:7b0c0d2f3a4b5c6d7e8f90112233445566778899aabbccddeeff001122334455",
+			ETag:           `W/"123-abcde"`,
+			IAT:            1754908800,
+			Exp:            1754909400,
+			KID:            "resource-key-2025-08-12",
+		},
+		ResourceKey: "aa11bb22cc33dd44ee55ff6600112233445566778899aabbccddeeff00112233",
+		Sig:         "bd7a51fe",
+	}
+
+	for name, article := range map[string]string{"script": v01Script, "div": v01Div} {
+		t.Run(name, func(t *testing.T) {
+			fragment, err := ParseFragmentV01(article)
+			if err != nil {
+				t.Fatalf("ParseFragmentV01: %v", err)
+			}
+			if spec, ok := NormalizeSpec(fragment.Spec); !ok || spec != SpecV01 {
+				t.Errorf("spec %q normalized to %q, %v", fragment.Spec, spec, ok)
+			}
+			if fragment.URL != want.Payload.URL {
+				t.Errorf("URL = %s", fragment.URL)
+			}
+			if string(fragment.CanonicalContent) != `<span id="msg-123">Hello, my name is Alice.</span>` {
+				t.Errorf("CanonicalContent = %q", fragment.CanonicalContent)
+			}
+			if fragment.DataHash != want.Payload.Hash {
+				t.Errorf("DataHash = %s", fragment.DataHash)
+			}
+			if !reflect.DeepEqual(fragment.StapledResourceAttestation, want) {
+				t.Errorf("stapled attestation = %+v, want %+v", fragment.StapledResourceAttestation, want)
+			}
+		})
+	}
+}
+
+func TestFindFragmentV01(t *testing.T) {
+	document := `<p>Archived pages carry a data-lap-spec="v0.1" attribute.</p>
+<article data-lap-spec="v0.2"></article>
+` + v01Script
+	article, ok := FindFragmentV01(document)
+	if !ok || article != v01Script {
+		t.Fatalf("Expected the v0.1 article, got %q, %v", article, ok)
+	}
+
+	if _, ok := FindFragmentV01(`<article data-lap-spec="v0.3"></article>`); ok {
+		t.Error("Expected an article declaring another version to be passed over")
+	}
+}
+
+func TestParseFragmentV01_MissingSelector(t *testing.T) {
+	broken := strings.Replace(v01Div, `id="lap-bytes-msg-123"`, `id="other"`, 1)
+	if _, err := ParseFragmentV01(broken); err == nil {
+		t.Error("Expected an error when the bytes selector does not resolve")
+	}
+}
+
+func TestV01Locations(t *testing.T) {
+	if got := ResourceAttestationURLV01("https://example.com/people/alice/messages/123/index.html"); got != "https://example.com/people/alice/messages/123/_la_resource.json" {
+		t.Errorf("ResourceAttestationURLV01 = %s", got)
+	}
+	want := []string{
+		"https://example.com/people/alice/messages/_la_namespace.json",
+		"https://example.com/people/alice/_la_namespace.json",
+		"https://example.com/people/_la_namespace.json",
+		"https://example.com/_la_namespace.json",
+	}
+	if got := NamespaceAttestationCandidatesV01("https://example.com/people/alice/messages/123"); !reflect.DeepEqual(got, want) {
+		t.Errorf("NamespaceAttestationCandidatesV01 = %v, want %v", got, want)
+	}
+}
+
+func TestNormalizeSpec(t *testing.T) {
+	for declared, want := range map[string]string{"": SpecV02, "v0.2": SpecV02, "v0.1": SpecV01, "https://lap.dev/spec/v0-1": SpecV01} {
+		if got, ok := NormalizeSpec(declared); !ok || got != want {
+			t.Errorf("NormalizeSpec(%q) = %q, %v; want %q", declared, got, ok, want)
+		}
+	}
+	if _, ok := NormalizeSpec("v0.3"); ok {
+		t.Error("Expected v0.3 to be unsupported")
+	}
+}
@@ -2,36 +2,94 @@ package wire
 
 import (
 	"encoding/base64"
-	"encoding/json"
 	"errors"
+	"strings"
 
 	"github.com/stonebraker/lap/sdks/go/pkg/lap/canonical"
 )
 
 // Fragment represents the parsed LAP fragment for v0.2
 type Fragment struct {
-	Spec                        string `json:"spec"`                          // "v0.2"
-	FragmentURL                 string `json:"fragment_url"`
-	PreviewContent              string `json:"preview_content"`               // Raw HTML from .html file
-	CanonicalContent            []byte `json:"canonical_content"`             // Same as preview, but as bytes
-	PublisherClaim              string `json:"publisher_claim"`               // X-only public key
-	ResourceAttestationURL      string `json:"resource_attestation_url"`
-	NamespaceAttestationURL     string `json:"namespace_attestation_url"`
+	Spec                    string      `json:"spec"` // "v0.2"
+	FragmentURL             string      `json:"fragment_url"`
+	PreviewContent          string      `json:"preview_content"`   // Raw HTML from .html file
+	CanonicalContent        []byte      `json:"canonical_content"` // Same as preview, but as bytes
+	PublisherClaim          string      `json:"publisher_claim"`   // X-only public key
+	ResourceAttestationURL  string      `json:"resource_attestation_url"`
+	NamespaceAttestationURL string      `json:"namespace_attestation_url"`
+	ContentType             string      `json:"content_type,omitempty"`      // Media type of CanonicalContent; empty means DefaultContentType
+	ContentURL              string      `json:"content_url,omitempty"`       // Same-origin URL the content bytes were fetched from, when not inlined
+	CoPublishers            []Publisher `json:"co_publishers,omitempty"`     // Further authors of a co-authored resource, in RA order
+	EncryptedContent        []byte      `json:"encrypted_content,omitempty"` // Sealed content JSON of a subscriber-only fragment, decrypted into CanonicalContent by a recipient
+
+	// Stapled attestations embedded in the fragment (exact JSON bytes), if any
+	StapledResourceAttestation  []byte `json:"stapled_resource_attestation,omitempty"`
+	StapledNamespaceAttestation []byte `json:"stapled_namespace_attestation,omitempty"`
+	StapledAt                   int64  `json:"stapled_at,omitempty"` // Declared staple time (epoch seconds); unsigned, informational only
+}
+
+// Excerpt is one top-level block of an attested resource's canonical content, quoted on another
+// page together with the inclusion proof that ties it to the RA's block_root
+type Excerpt struct {
+	FragmentURL             string   `json:"fragment_url"` // Fragment URL of the quoted resource
+	PublisherClaim          string   `json:"publisher_claim"`
+	ResourceAttestationURL  string   `json:"resource_attestation_url"`
+	NamespaceAttestationURL string   `json:"namespace_attestation_url"`
+	Content                 []byte   `json:"content"`     // Exact bytes of the quoted block
+	BlockIndex              int      `json:"block_index"` // Position of the block among all blocks
+	BlockCount              int      `json:"block_count"` // Number of blocks in the resource
+	Proof                   []string `json:"proof"`       // Hex sibling hashes from the block's leaf up to the root
 }
 
 // ResourceAttestation for v0.2 (unsigned JSON format)
 type ResourceAttestation struct {
-	FragmentURL             string `json:"fragment_url"`
-	Hash                    string `json:"hash"`                    // "sha256:..."
-	PublisherClaim          string `json:"publisher_claim"`         // X-only public key for triangulation
+	FragmentURL             string          `json:"fragment_url"`
+	Hash                    string          `json:"hash"`                       // "sha256:..."
+	Canonicalization        string          `json:"canonicalization,omitempty"` // Content profile applied before hashing; empty means "raw"
+	ContentType             string          `json:"content_type,omitempty"`     // Media type of the attested bytes; empty means DefaultContentType
+	Size                    int64           `json:"size,omitempty"`             // Length of the attested bytes; set with a Merkle hash to bind range proofs
+	BlockRoot               string          `json:"block_root,omitempty"`       // Merkle root over the content's top-level HTML blocks, for excerpts
+	BlockCount              int             `json:"block_count,omitempty"`      // Number of blocks under block_root; required with it
+	Version                 int             `json:"version,omitempty"`          // Content version, counted from 1; empty means unversioned
+	PreviousHashes          []string        `json:"previous_hashes,omitempty"`  // Hashes of superseded versions, most recent first
+	InReplyTo               *ReplyReference `json:"in_reply_to,omitempty"`      // Parent resource this one replies to
+	IAT                     int64           `json:"iat,omitempty"`              // Issued at, in seconds since epoch
+	Exp                     int64           `json:"exp,omitempty"`              // Expiry, in seconds since epoch; empty means none
+	MaxAge                  int64           `json:"max_age,omitempty"`          // Seconds a copy of the RA may be relied on without refetching
+	PublisherClaim          string          `json:"publisher_claim"`            // X-only public key for triangulation
+	NamespaceAttestationURL string          `json:"namespace_attestation_url"`
+	CoPublishers            []CoPublisher   `json:"co_publishers,omitempty"` // Further authors, each on their own namespace
+}
+
+// Publisher names one author of a co-authored resource: the key it claims and the Namespace
+// Attestation that associates that key with the author's namespace.
+type Publisher struct {
+	PublisherClaim          string `json:"publisher_claim"`
+	NamespaceAttestationURL string `json:"namespace_attestation_url"`
+}
+
+// CoPublisher is a further author listed in a Resource Attestation. The author's namespace is usually
+// on another site and cannot cover the fragment URL, so the author signs the resource instead: Sig is
+// made with the key of the author's Namespace Attestation over CoPublisherPayload.
+type CoPublisher struct {
+	PublisherClaim          string `json:"publisher_claim"`
 	NamespaceAttestationURL string `json:"namespace_attestation_url"`
+	Sig                     string `json:"sig"`
+}
+
+// ReplyReference identifies the parent of a reply by its fragment URL and the hash of the parent
+// content being replied to, as attested by the parent's own Resource Attestation.
+type ReplyReference struct {
+	FragmentURL string `json:"fragment_url"`
+	Hash        string `json:"hash"`
 }
 
 // NamespaceAttestation for v0.2 (signed JSON format)
 type NamespaceAttestation struct {
 	Payload NamespacePayload `json:"payload"`
-	Key     string           `json:"key"`    // X-only public key (64 hex)
-	Sig     string           `json:"sig"`    // Schnorr signature (128 hex)
+	Alg     string           `json:"alg,omitempty"` // Signature algorithm; empty means "bip340"
+	Key     string           `json:"key"`           // Public key hex (x-only 64 hex for bip340)
+	Sig     string           `json:"sig"`           // Signature (128 hex)
 }
 
 type NamespacePayload struct {
@@ -44,11 +102,52 @@ func (ra ResourceAttestation) ToCanonical() canonical.ResourceAttestationCanonic
 	return canonical.ResourceAttestationCanonical{
 		FragmentURL:             ra.FragmentURL,
 		Hash:                    ra.Hash,
+		Canonicalization:        ra.Canonicalization,
+		ContentType:             ra.ContentType,
+		Size:                    ra.Size,
+		BlockRoot:               ra.BlockRoot,
+		BlockCount:              ra.BlockCount,
+		Version:                 ra.Version,
+		PreviousHashes:          ra.PreviousHashes,
+		InReplyTo:               ra.InReplyTo.toCanonical(),
+		IAT:                     ra.IAT,
+		Exp:                     ra.Exp,
+		MaxAge:                  ra.MaxAge,
 		PublisherClaim:          ra.PublisherClaim,
 		NamespaceAttestationURL: ra.NamespaceAttestationURL,
+		CoPublishers:            coPublishersToCanonical(ra.CoPublishers),
 	}
 }
 
+// CoPublisherPayload returns the payload each co-publisher signs: the fragment URL and current hash
+func (ra ResourceAttestation) CoPublisherPayload() canonical.CoPublisherPayloadCanonical {
+	return canonical.CoPublisherPayloadCanonical{FragmentURL: ra.FragmentURL, Hash: ra.Hash}
+}
+
+// coPublishersToCanonical returns nil for a resource with a single publisher
+func coPublishersToCanonical(coPublishers []CoPublisher) []canonical.CoPublisherCanonical {
+	if len(coPublishers) == 0 {
+		return nil
+	}
+	out := make([]canonical.CoPublisherCanonical, len(coPublishers))
+	for i, cp := range coPublishers {
+		out[i] = canonical.CoPublisherCanonical{
+			PublisherClaim:          cp.PublisherClaim,
+			NamespaceAttestationURL: cp.NamespaceAttestationURL,
+			Sig:                     cp.Sig,
+		}
+	}
+	return out
+}
+
+// toCanonical returns nil for a resource that is not a reply
+func (r *ReplyReference) toCanonical() *canonical.ReplyReferenceCanonical {
+	if r == nil {
+		return nil
+	}
+	return &canonical.ReplyReferenceCanonical{FragmentURL: r.FragmentURL, Hash: r.Hash}
+}
+
 // ToCanonical transforms wire.NamespacePayload into canonical.NamespacePayloadCanonical for deterministic serialization.
 func (p NamespacePayload) ToCanonical() canonical.NamespacePayloadCanonical {
 	return canonical.NamespacePayloadCanonical{
@@ -57,15 +156,45 @@ func (p NamespacePayload) ToCanonical() canonical.NamespacePayloadCanonical {
 	}
 }
 
+// SignedPayload returns the canonical payload the attestation's key signs. A non-default alg is signed
+// with the payload, so relabelling a signature as another algorithm invalidates it.
+func (na NamespaceAttestation) SignedPayload() canonical.NamespacePayloadCanonical {
+	payload := na.Payload.ToCanonical()
+	payload.Alg = signedAlg(na.Alg)
+	return payload
+}
+
+// signedAlg returns alg as it is signed: lowercase, and empty for the bip340 default so that
+// bip340 signatures cover the same bytes as before algorithms were signed.
+func signedAlg(alg string) string {
+	if alg = strings.ToLower(strings.TrimSpace(alg)); alg == "bip340" {
+		return ""
+	}
+	return alg
+}
+
 // ToCanonical transforms wire.NamespaceAttestation into canonical.NamespaceAttestationCanonical for deterministic serialization.
 func (na NamespaceAttestation) ToCanonical() canonical.NamespaceAttestationCanonical {
 	return canonical.NamespaceAttestationCanonical{
 		Payload: na.Payload.ToCanonical(),
+		Alg:     na.Alg,
 		Key:     na.Key,
 		Sig:     na.Sig,
 	}
 }
 
+// DefaultContentType is the media type of fragment content when none is declared.
+const DefaultContentType = "text/html"
+
+// EncryptedContentType is the media type of the canonical data URL of a subscriber-only fragment,
+// whose content is sealed to a list of recipient keys. The link's type attribute still names the
+// media type of the plaintext.
+const EncryptedContentType = "application/lap-encrypted+json"
+
+// AttestationHeaderName is the HTTP response header that carries a Resource Attestation
+// encoded with EncodeAttestationHeader.
+const AttestationHeaderName = "LAP-Resource-Attestation"
+
 // EncodeAttestationHeader returns base64url(JSON) of ResourceAttestation for v0.2.
 func EncodeAttestationHeader(ra ResourceAttestation) (string, error) {
 	bytesJSON, err := canonical.MarshalResourceAttestationCanonical(ra.ToCanonical())
@@ -76,6 +205,7 @@ func EncodeAttestationHeader(ra ResourceAttestation) (string, error) {
 }
 
 // DecodeAttestationHeader parses base64url(JSON) header value into ResourceAttestation for v0.2.
+// The JSON is decoded with UnmarshalStrict.
 func DecodeAttestationHeader(value string) (ResourceAttestation, error) {
 	var zero ResourceAttestation
 	if value == "" {
@@ -85,8 +215,9 @@ func DecodeAttestationHeader(value string) (ResourceAttestation, error) {
 	if err != nil {
 		return zero, err
 	}
-	if err := json.Unmarshal(bytesJSON, &zero); err != nil {
+	var ra ResourceAttestation
+	if err := UnmarshalStrict(bytesJSON, &ra); err != nil {
 		return zero, err
 	}
-	return zero, nil
+	return ra, nil
 }